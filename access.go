@@ -0,0 +1,125 @@
+package jsonschema
+
+// Mode is the direction in which an instance is exchanged, which
+// decides how readOnly and writeOnly are enforced. See
+// ValidateOptions.Mode and Schema.ValidateAndStrip.
+type Mode int
+
+const (
+	// ModeNone treats readOnly and writeOnly as annotations only.
+	ModeNone Mode = iota
+
+	// ModeRequest is for instances sent to the owner of resource,
+	// such as http request body. values with readOnly schema are not
+	// allowed, and required properties with readOnly schema may be missing.
+	ModeRequest
+
+	// ModeResponse is for instances sent by the owner of resource,
+	// such as http response body. values with writeOnly schema are not
+	// allowed, and required properties with writeOnly schema may be missing.
+	ModeResponse
+)
+
+// ValidateAndStrip removes object properties, which are not allowed in
+// given mode, from v and validates the resulting instance. In ModeRequest
+// properties with readOnly schema are removed, and in ModeResponse those
+// with writeOnly schema are removed.
+//
+// v is not modified. The instance with properties removed is returned
+// even when validation fails.
+//
+// Like defaults in ValidateAndFill, properties are found from "properties"
+// through "$ref" and "allOf", but not from "anyOf", "oneOf" or conditional
+// keywords. values with readOnly or writeOnly schema found elsewhere, are
+// reported as errors.
+//
+// NOTE: readOnly and writeOnly are available only if
+// Compiler.ExtractAnnotations is true.
+func (s *Schema) ValidateAndStrip(v interface{}, mode Mode) (interface{}, error) {
+	v = deepCopy(v)
+	s.strip(v, mode, nil)
+	return v, s.ValidateWithOptions(v, ValidateOptions{Mode: mode})
+}
+
+// strip removes properties not allowed in mode from v in place.
+//
+// stack holds schemas applied on same v, to avoid infinite loop.
+func (s *Schema) strip(v interface{}, mode Mode, stack []*Schema) {
+	if s == nil || mode == ModeNone {
+		return
+	}
+	for _, sch := range stack {
+		if sch == s {
+			return
+		}
+	}
+	stack = append(stack, s)
+
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for pname, sch := range s.Properties {
+			if pvalue, ok := vv[pname]; ok {
+				if sch.excluded(mode, nil) {
+					delete(vv, pname)
+				} else {
+					sch.strip(pvalue, mode, nil)
+				}
+			}
+		}
+	case []interface{}:
+		items := s.PrefixItems
+		if items == nil {
+			items, _ = s.Items.([]*Schema)
+		}
+		for i, sch := range items {
+			if i < len(vv) {
+				sch.strip(vv[i], mode, nil)
+			}
+		}
+		rest := s.Items2020
+		if sch, ok := s.Items.(*Schema); ok {
+			rest = sch
+		} else if sch, ok := s.AdditionalItems.(*Schema); ok {
+			rest = sch
+		}
+		if rest != nil {
+			for i := len(items); i < len(vv); i++ {
+				rest.strip(vv[i], mode, nil)
+			}
+		}
+	}
+
+	// subschemas applied on same instance
+	s.Ref.strip(v, mode, stack)
+	for _, sch := range s.AllOf {
+		sch.strip(v, mode, stack)
+	}
+}
+
+// excluded tells whether values of s are not allowed in mode, i.e.
+// s is readOnly in ModeRequest, or writeOnly in ModeResponse.
+// "$ref" and "allOf" are followed.
+func (s *Schema) excluded(mode Mode, stack []*Schema) bool {
+	if s == nil {
+		return false
+	}
+	for _, sch := range stack {
+		if sch == s {
+			return false
+		}
+	}
+	stack = append(stack, s)
+
+	if (mode == ModeRequest && s.ReadOnly) || (mode == ModeResponse && s.WriteOnly) {
+		return true
+	}
+	if s.Ref.excluded(mode, stack) {
+		return true
+	}
+	for _, sch := range s.AllOf {
+		if sch.excluded(mode, stack) {
+			return true
+		}
+	}
+	return false
+}
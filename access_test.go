@@ -0,0 +1,106 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestValidateMode(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"type": "object",
+		"required": ["id", "name", "password"],
+		"properties": {
+			"id": {"$ref": "#/$defs/id"},
+			"name": {"type": "string"},
+			"password": {"type": "string", "writeOnly": true},
+			"tags": {
+				"type": "array",
+				"items": {
+					"properties": {
+						"created": {"type": "string", "readOnly": true}
+					}
+				}
+			}
+		},
+		"allOf": [{
+			"properties": {
+				"etag": {"allOf": [{"readOnly": true}]}
+			}
+		}],
+		"$defs": {
+			"id": {"type": "integer", "readOnly": true}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	request := decodeString(t, `{"name": "john", "password": "secret", "tags": [{}]}`)
+	response := decodeString(t, `{"id": 1, "name": "john", "etag": "x", "tags": [{"created": "now"}]}`)
+	full := decodeString(t, `{"id": 1, "name": "john", "password": "secret"}`)
+	tests := []struct {
+		description string
+		v           interface{}
+		mode        jsonschema.Mode
+		keyword     string // of leaf error. empty if valid
+	}{
+		{"request", request, jsonschema.ModeRequest, ""},
+		{"response", response, jsonschema.ModeResponse, ""},
+		{"request with readOnly", full, jsonschema.ModeRequest, "readOnly"},
+		{"request with nested readOnly", decodeString(t, `{"name": "john", "password": "secret", "tags": [{"created": "now"}]}`), jsonschema.ModeRequest, "readOnly"},
+		{"response with writeOnly", full, jsonschema.ModeResponse, "writeOnly"},
+		{"request without mode", request, jsonschema.ModeNone, "required"},
+		{"response without mode", response, jsonschema.ModeNone, "required"},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := sch.ValidateWithOptions(test.v, jsonschema.ValidateOptions{Mode: test.mode})
+			if test.keyword == "" {
+				if err != nil {
+					t.Fatalf("%#v", err)
+				}
+				return
+			}
+			var ke *jsonschema.KeywordError
+			if !errors.As(err, &ke) {
+				t.Fatalf("got %v, want KeywordError", err)
+			}
+			if ke.Keyword != test.keyword {
+				t.Fatalf("keyword: got %q, want %q: %v", ke.Keyword, test.keyword, err)
+			}
+		})
+	}
+
+	t.Run("strip", func(t *testing.T) {
+		v := decodeString(t, `{"id": 1, "name": "john", "password": "secret", "etag": "x", "tags": [{"created": "now"}]}`)
+		stripped, err := sch.ValidateAndStrip(v, jsonschema.ModeRequest)
+		if err != nil {
+			t.Fatalf("%#v", err)
+		}
+		want := decodeString(t, `{"name": "john", "password": "secret", "tags": [{}]}`)
+		if !reflect.DeepEqual(stripped, want) {
+			t.Fatalf("got %v, want %v", stripped, want)
+		}
+		if _, ok := v.(map[string]interface{})["id"]; !ok {
+			t.Fatal("v must not be modified")
+		}
+
+		stripped, err = sch.ValidateAndStrip(v, jsonschema.ModeResponse)
+		if err != nil {
+			t.Fatalf("%#v", err)
+		}
+		want = decodeString(t, `{"id": 1, "name": "john", "etag": "x", "tags": [{"created": "now"}]}`)
+		if !reflect.DeepEqual(stripped, want) {
+			t.Fatalf("got %v, want %v", stripped, want)
+		}
+	})
+}
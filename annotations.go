@@ -0,0 +1,84 @@
+package jsonschema
+
+import (
+	"context"
+	"sort"
+)
+
+// Annotation is an annotation collected from a schema, that
+// successfully validated the instance at InstanceLocation.
+type Annotation struct {
+	Keyword                 string      // annotation keyword. e.g. "title", "readOnly" or any unknown keyword
+	KeywordLocation         string      // validation path of the annotation keyword
+	AbsoluteKeywordLocation string      // absolute location of the annotation keyword
+	InstanceLocation        string      // location of the annotated json value within the instance
+	Value                   interface{} // value of the annotation keyword
+}
+
+// ValidateWithAnnotations is like Validate, but also returns the annotations
+// collected from the schemas that successfully validated parts of instance.
+// Annotations from failing subschemas, such as from failed anyOf branch,
+// are dropped as specified in draft 2019-09.
+//
+// The annotations collected are title, description, default, examples,
+// readOnly, writeOnly, deprecated and keywords unknown to the draft.
+// Annotations are ordered by evaluation, i.e. nested schemas first.
+//
+// NOTE: annotations are available only if Compiler.ExtractAnnotations is true.
+func (s *Schema) ValidateWithAnnotations(v interface{}) ([]Annotation, error) {
+	vd := newValidator(context.Background())
+	defer vd.release()
+	vd.collect = true
+	if err := s.validateValue(vd, v, ""); err != nil {
+		return nil, err
+	}
+	return vd.annotations, nil
+}
+
+// collectAnnotations adds annotations in s, to vd.
+func (s *Schema) collectAnnotations(vd *validator, scope []schemaRef, vloc string) {
+	s.eachAnnotation(func(keyword string, value interface{}) {
+		vd.annotations = append(vd.annotations, Annotation{
+			Keyword:                 keyword,
+			KeywordLocation:         keywordLocation(scope, escape(keyword)),
+			AbsoluteKeywordLocation: joinPtr(s.Location, escape(keyword)),
+			InstanceLocation:        vloc,
+			Value:                   value,
+		})
+	})
+}
+
+// eachAnnotation calls add for each annotation keyword in s, with its value.
+func (s *Schema) eachAnnotation(add func(keyword string, value interface{})) {
+	if s.Title != "" {
+		add("title", s.Title)
+	}
+	if s.Description != "" {
+		add("description", s.Description)
+	}
+	if s.Default != nil {
+		add("default", s.Default)
+	}
+	if len(s.Examples) > 0 {
+		add("examples", s.Examples)
+	}
+	if s.ReadOnly {
+		add("readOnly", true)
+	}
+	if s.WriteOnly {
+		add("writeOnly", true)
+	}
+	if s.Deprecated {
+		add("deprecated", true)
+	}
+	if len(s.unknown) > 0 {
+		keywords := make([]string, 0, len(s.unknown))
+		for kw := range s.unknown {
+			keywords = append(keywords, kw)
+		}
+		sort.Strings(keywords)
+		for _, kw := range keywords {
+			add(kw, s.unknown[kw])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestValidateWithAnnotations(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"title": "user",
+		"properties": {
+			"id": {"type": "integer", "readOnly": true},
+			"password": {"type": "string", "writeOnly": true},
+			"nick": {"type": "string", "deprecated": true, "x-ui": "hidden"},
+			"role": {
+				"anyOf": [
+					{"type": "integer", "description": "role id"},
+					{"type": "string", "description": "role name"}
+				]
+			}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	annotations, err := sch.ValidateWithAnnotations(decodeString(t, `{"id": 1, "nick": "joe", "role": "admin"}`))
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	got := map[string]interface{}{}
+	for _, a := range annotations {
+		got[a.InstanceLocation+" "+a.Keyword] = a.Value
+	}
+	want := map[string]interface{}{
+		" title":            "user",
+		"/id readOnly":      true,
+		"/nick deprecated":  true,
+		"/nick x-ui":        "hidden",
+		"/role description": "role name",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%q: got %v, want %v", k, got[k], v)
+		}
+	}
+
+	if _, err := sch.ValidateWithAnnotations(decodeString(t, `{"id": "1"}`)); err == nil {
+		t.Error("validation error expected")
+	}
+}
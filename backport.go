@@ -0,0 +1,76 @@
+package jsonschema
+
+import "fmt"
+
+// backportKeywords are the keywords, which are honored in earlier drafts
+// by Compiler.BackportKeywords.
+var backportKeywords = []string{"if", "then", "else", "dependentSchemas", "dependentRequired"}
+
+// backported tells whether kw is a keyword not defined by draft d, but is
+// honored by c.
+func (c *Compiler) backported(d *Draft, kw string) bool {
+	if !c.BackportKeywords || d.keywords[kw] {
+		return false
+	}
+	for _, bkw := range backportKeywords {
+		if bkw == kw {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBackported checks the values of backported keywords in m, since
+// they are not validated by meta-schema of draft.
+func checkBackported(r *resource, res *resource, m map[string]interface{}) error {
+	isSchema := func(v interface{}) bool {
+		switch v.(type) {
+		case map[string]interface{}:
+			return true
+		case bool:
+			return r.draft.version >= 6
+		}
+		return false
+	}
+	for _, kw := range backportKeywords {
+		v, ok := m[kw]
+		if !ok || r.draft.keywords[kw] {
+			continue
+		}
+		valid := isSchema(v)
+		if kw == "dependentSchemas" || kw == "dependentRequired" {
+			deps, ok := v.(map[string]interface{})
+			valid = ok
+			for _, dep := range deps {
+				if kw == "dependentSchemas" {
+					valid = valid && isSchema(dep)
+					continue
+				}
+				arr, ok := dep.([]interface{})
+				valid = valid && ok
+				for _, item := range arr {
+					_, ok := item.(string)
+					valid = valid && ok
+				}
+			}
+		}
+		if !valid {
+			return fmt.Errorf("jsonschema: invalid %s in %s", kw, res)
+		}
+	}
+	return nil
+}
+
+// withoutBackported returns copy of m without backported keywords.
+func withoutBackported(d *Draft, m map[string]interface{}) map[string]interface{} {
+	cm := make(map[string]interface{}, len(m))
+	for kw, v := range m {
+		cm[kw] = v
+	}
+	for _, kw := range backportKeywords {
+		if !d.keywords[kw] {
+			delete(cm, kw)
+		}
+	}
+	return cm
+}
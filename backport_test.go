@@ -0,0 +1,108 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_BackportKeywords(t *testing.T) {
+	schema := `{
+		"$schema": "%s",
+		"type": "object",
+		"if": {"properties": {"country": {"enum": ["US"]}}, "required": ["country"], "title": "us"},
+		"then": {"required": ["zip"]},
+		"else": {"required": ["postcode"]},
+		"dependentRequired": {"card": ["cvv"]},
+		"dependentSchemas": {"card": {"properties": {"cvv": {"type": "string"}}}}
+	}`
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"country": "US", "zip": "1"}`, true},
+		{`{"country": "US", "postcode": "1"}`, false},
+		{`{"country": "IN", "postcode": "1"}`, true},
+		{`{"postcode": "1", "card": "x", "cvv": "1"}`, true},
+		{`{"postcode": "1", "card": "x"}`, false},
+		{`{"postcode": "1", "card": "x", "cvv": 1}`, false},
+	}
+	for _, draft := range []string{"http://json-schema.org/draft-04/schema#", "http://json-schema.org/draft-06/schema#", "http://json-schema.org/draft-07/schema#"} {
+		t.Run(draft, func(t *testing.T) {
+			url := "backport.json"
+			s := strings.Replace(schema, "%s", draft, 1)
+
+			// ignored by default
+			c := jsonschema.NewCompiler()
+			if err := c.AddResource(url, strings.NewReader(s)); err != nil {
+				t.Fatal(err)
+			}
+			sch := c.MustCompile(url)
+			if err := sch.Validate(decodeString(t, `{"postcode": "1", "card": "x"}`)); err != nil {
+				t.Fatalf("dependentRequired must be ignored: %v", err)
+			}
+
+			c = jsonschema.NewCompiler()
+			c.BackportKeywords = true
+			c.ExtractAnnotations = true
+			c.Strict = true
+			if err := c.AddResource(url, strings.NewReader(s)); err != nil {
+				t.Fatal(err)
+			}
+			sch = c.MustCompile(url)
+			if sch.If == nil || sch.Then == nil || sch.Else == nil || sch.DependentSchemas["card"] == nil || len(sch.DependentRequired["card"]) != 1 {
+				t.Fatal("backported keywords are not compiled")
+			}
+			subschemas := sch.Subschemas()
+			for _, loc := range []string{"if", "then", "else", "dependentSchemas/card"} {
+				if subschemas[loc] == nil {
+					t.Errorf("subschema %s missing", loc)
+				}
+			}
+			for _, test := range tests {
+				err := sch.Validate(decodeString(t, test.doc))
+				if test.valid && err != nil {
+					t.Errorf("%s: %v", test.doc, err)
+				} else if !test.valid && err == nil {
+					t.Errorf("%s: must be invalid", test.doc)
+				}
+			}
+
+			// annotations of failing "if" are dropped
+			for doc, want := range map[string]bool{`{"country": "US", "zip": "1"}`: true, `{"postcode": "1"}`: false} {
+				annotations, err := sch.ValidateWithAnnotations(decodeString(t, doc))
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got bool
+				for _, a := range annotations {
+					if a.Keyword == "title" && a.Value == "us" {
+						got = true
+					}
+				}
+				if got != want {
+					t.Errorf("%s: title annotation of if: got %v, want %v", doc, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompiler_BackportKeywordsInvalid(t *testing.T) {
+	for _, s := range []string{
+		`{"$schema": "http://json-schema.org/draft-04/schema#", "if": true}`,
+		`{"$schema": "http://json-schema.org/draft-06/schema#", "if": 1}`,
+		`{"$schema": "http://json-schema.org/draft-07/schema#", "dependentRequired": {"a": [1]}}`,
+		`{"$schema": "http://json-schema.org/draft-07/schema#", "dependentSchemas": {"a": 1}}`,
+	} {
+		c := jsonschema.NewCompiler()
+		c.BackportKeywords = true
+		if err := c.AddResource("invalid.json", strings.NewReader(s)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Compile("invalid.json"); err == nil {
+			t.Errorf("%s: must fail", s)
+		}
+	}
+}
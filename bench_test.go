@@ -0,0 +1,70 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func benchmarkValidate(b *testing.B, schema, doc string) {
+	sch, err := jsonschema.CompileString("schema.json", schema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	v := decodeString(&testing.T{}, doc)
+	if err := sch.Validate(v); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := sch.Validate(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	b.Run("string", func(b *testing.B) {
+		benchmarkValidate(b, `{"type": "string", "minLength": 1, "maxLength": 10}`, `"hello"`)
+	})
+	b.Run("integer", func(b *testing.B) {
+		benchmarkValidate(b, `{"type": "integer", "minimum": 0, "maximum": 100}`, `42`)
+	})
+	b.Run("object", func(b *testing.B) {
+		benchmarkValidate(b, `{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"age": {"type": "integer"},
+				"active": {"type": "boolean"}
+			},
+			"required": ["name"],
+			"additionalProperties": false
+		}`, `{"name": "john", "age": 30, "active": true}`)
+	})
+	b.Run("array", func(b *testing.B) {
+		benchmarkValidate(b, `{"type": "array", "items": {"type": "string"}, "maxItems": 10}`, `["a", "b", "c"]`)
+	})
+}
+
+func TestValidate_allocs(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"}
+		},
+		"dependentRequired": {"age": ["name"]},
+		"additionalProperties": false
+	}`)
+	v := decodeString(t, `{"name": "john", "age": 30}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := sch.Validate(v); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs, want 0", allocs)
+	}
+}
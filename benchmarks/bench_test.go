@@ -0,0 +1,131 @@
+package benchmarks_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var suites = []string{"crd", "openapi", "geojson"}
+
+func readFile(tb testing.TB, name ...string) []byte {
+	tb.Helper()
+	b, err := os.ReadFile(filepath.Join(append([]string{"testdata"}, name...)...))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return b
+}
+
+func compile(tb testing.TB, suite string) *jsonschema.Schema {
+	tb.Helper()
+	c := jsonschema.NewCompiler()
+	c.AssertFormat = true
+	if err := c.AddResource("schema.json", bytes.NewReader(readFile(tb, suite, "schema.json"))); err != nil {
+		tb.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		tb.Fatalf("%#v", err)
+	}
+	return sch
+}
+
+// unmarshal decodes json preserving numbers. It does not use DecodeJSON,
+// so that benchmarks can be run against older versions by compare.sh.
+func unmarshal(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func decode(tb testing.TB, suite, name string) interface{} {
+	tb.Helper()
+	v, err := unmarshal(readFile(tb, suite, name))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return v
+}
+
+// TestSuites ensures that valid.json is valid and invalid.json is
+// invalid, so that benchmarks measure what they claim to.
+func TestSuites(t *testing.T) {
+	for _, suite := range suites {
+		t.Run(suite, func(t *testing.T) {
+			sch := compile(t, suite)
+			if err := sch.Validate(decode(t, suite, "valid.json")); err != nil {
+				t.Fatalf("valid.json: %#v", err)
+			}
+			if err := sch.Validate(decode(t, suite, "invalid.json")); err == nil {
+				t.Fatal("invalid.json: validation must fail")
+			}
+		})
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	for _, suite := range suites {
+		b.Run(suite, func(b *testing.B) {
+			schema := readFile(b, suite, "schema.json")
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c := jsonschema.NewCompiler()
+				c.AssertFormat = true
+				if err := c.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := c.Compile("schema.json"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	for _, suite := range suites {
+		sch := compile(b, suite)
+		for _, name := range []string{"valid", "invalid"} {
+			v := decode(b, suite, name+".json")
+			b.Run(suite+"/"+name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					err := sch.Validate(v)
+					if (err == nil) != (name == "valid") {
+						b.Fatalf("unexpected result: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDecodeAndValidate(b *testing.B) {
+	for _, suite := range suites {
+		b.Run(suite, func(b *testing.B) {
+			sch := compile(b, suite)
+			doc := readFile(b, suite, "valid.json")
+			b.SetBytes(int64(len(doc)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				v, err := unmarshal(doc)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := sch.Validate(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
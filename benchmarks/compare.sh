@@ -0,0 +1,50 @@
+#!/usr/bin/env bash
+# compare.sh runs benchmarks of this package on given git ref and the
+# working tree, and compares the results using benchstat, if installed.
+#
+# usage: ./benchmarks/compare.sh [-count N] [-bench REGEX] <git-ref>
+set -euo pipefail
+
+count=6
+bench=.
+while [ $# -gt 1 ]; do
+	case "$1" in
+	-count) count="$2"; shift 2 ;;
+	-bench) bench="$2"; shift 2 ;;
+	*) break ;;
+	esac
+done
+if [ $# -ne 1 ]; then
+	echo "usage: $0 [-count N] [-bench REGEX] <git-ref>" >&2
+	exit 1
+fi
+ref="$1"
+
+root="$(git rev-parse --show-toplevel)"
+out="$(mktemp -d)"
+worktree="$out/worktree"
+trap 'git -C "$root" worktree remove --force "$worktree" >/dev/null 2>&1 || true; rm -rf "$out"' EXIT
+
+run() {
+	(cd "$1" && go test ./benchmarks -run '^$' -bench "$bench" -benchmem -count "$count")
+}
+
+git -C "$root" worktree add --detach "$worktree" "$ref" >/dev/null
+# refs older than this package, are benchmarked with current benchmarks
+rm -rf "$worktree/benchmarks"
+cp -r "$root/benchmarks" "$worktree/benchmarks"
+
+echo "benchmarking $ref ..." >&2
+run "$worktree" >"$out/old.txt"
+echo "benchmarking working tree ..." >&2
+run "$root" >"$out/new.txt"
+
+if command -v benchstat >/dev/null; then
+	benchstat "$out/old.txt" "$out/new.txt"
+else
+	echo "benchstat not found; install with: go install golang.org/x/perf/cmd/benchstat@latest" >&2
+	echo "--- $ref"
+	grep '^Benchmark' "$out/old.txt"
+	echo "--- working tree"
+	grep '^Benchmark' "$out/new.txt"
+fi
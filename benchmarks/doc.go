@@ -0,0 +1,24 @@
+// Package benchmarks measures compilation and validation performance
+// using representative real-world schemas and instances, so that
+// performance changes in the validator are measurable release to release.
+//
+// Typical usage:
+//
+//	go test ./benchmarks -run '^$' -bench . -benchmem
+//
+//	# compare with a git ref, say v5.3.1, using benchstat if available
+//	./benchmarks/compare.sh v5.3.1
+//
+// Each directory in testdata holds schema.json, valid.json and
+// invalid.json:
+//
+//   - crd: openAPIV3Schema of a Kubernetes custom resource, similar
+//     to a Deployment with containers, probes and resource quantities
+//   - openapi: components of an OpenAPI document with nested "$ref",
+//     validating a list of orders
+//   - geojson: FeatureCollection as per RFC 7946, where geometries are
+//     discriminated using "oneOf"
+//
+// invalid.json has several violations spread across the document, to
+// measure the cost of error reporting.
+package benchmarks
@@ -0,0 +1,100 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_Boolean(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {"a": true, "b": false, "c": {}, "d": {"type": "string"}}
+	}`)
+	tests := []struct {
+		pname     string
+		value, ok bool
+	}{
+		{"a", true, true},
+		{"b", false, true},
+		{"c", true, true},
+		{"d", false, false},
+	}
+	for _, test := range tests {
+		value, ok := sch.Properties[test.pname].Boolean()
+		if value != test.value || ok != test.ok {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", test.pname, value, ok, test.value, test.ok)
+		}
+	}
+	if _, ok := sch.Boolean(); ok {
+		t.Error("schema with properties must not be boolean")
+	}
+}
+
+func TestBooleanSchema_validate(t *testing.T) {
+	schema := `{
+		"properties": {"a": true, "b": false, "c": {}},
+		"items": false,
+		"not": false
+	}`
+	for _, draft := range []*jsonschema.Draft{jsonschema.Draft6, jsonschema.Draft7, jsonschema.Draft2019, jsonschema.Draft2020} {
+		c := jsonschema.NewCompiler()
+		c.Draft = draft
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		sch, err := c.Compile("schema.json")
+		if err != nil {
+			t.Fatalf("%v: %#v", draft, err)
+		}
+		if err := sch.Validate(decodeString(t, `{"a": 1, "c": 2}`)); err != nil {
+			t.Errorf("%v: %#v", draft, err)
+		}
+		err = sch.Validate(decodeString(t, `{"b": 1}`))
+		ve, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			t.Fatalf("%v: got %v, want *ValidationError", draft, err)
+		}
+		leaf := ve.Leaves()[0]
+		if leaf.InstanceLocation != "/b" || leaf.Message != "not allowed" || leaf.KeywordError.Keyword != "false" {
+			t.Errorf("%v: got %#v", draft, leaf)
+		}
+		if err := sch.Validate(decodeString(t, `[1]`)); err == nil {
+			t.Errorf("%v: items false must fail", draft)
+		}
+	}
+}
+
+func TestBooleanSchema_draft4(t *testing.T) {
+	tests := []struct {
+		schema string
+		loc    string
+	}{
+		{`true`, "#"},
+		{`{"properties": {"a": {"items": [{}, false]}}}`, "#/properties/a/items/1"},
+		{`{"not": true}`, "#/not"},
+		{`{"definitions": {"a": false}}`, "#/definitions/a"},
+	}
+	for _, test := range tests {
+		c := jsonschema.NewCompiler()
+		c.Draft = jsonschema.Draft4
+		if err := c.AddResource("http://example.com/schema.json", strings.NewReader(test.schema)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := c.Compile("http://example.com/schema.json")
+		want := "boolean schema is not allowed in Draft4, at http://example.com/schema.json" + test.loc
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", test.schema, err, want)
+		}
+	}
+
+	// boolean additionalProperties and additionalItems are valid
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft4
+	if err := c.AddResource("schema.json", strings.NewReader(`{"additionalProperties": false, "items": [{}], "additionalItems": false}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Compile("schema.json"); err != nil {
+		t.Fatalf("%#v", err)
+	}
+}
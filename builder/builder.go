@@ -0,0 +1,302 @@
+// Package builder constructs json-schemas programmatically, and folds
+// "allOf" subschemas into a single equivalent schema.
+//
+// Typical usage:
+//
+//	person := builder.Object().
+//		Prop("name", builder.String().MinLength(1)).
+//		Prop("age", builder.Integer().Minimum(0)).
+//		Required("name")
+//	sch, err := person.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//
+// Builder implements json.Marshaler, so it can be embedded in documents
+// built by other means, or written to file.
+//
+// MergeAllOf works on decoded json documents, such as those returned by
+// jsonschema.DecodeJSON or Builder.Doc:
+//
+//	doc, err := jsonschema.DecodeJSON(r)
+//	if err != nil {
+//		return err
+//	}
+//	doc = builder.MergeAllOf(doc)
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Builder builds a json-schema. The methods which add keywords modify the
+// receiver and return it, so that calls can be chained.
+//
+// The zero value is not usable. Use New or other constructors.
+type Builder struct {
+	boolean *bool
+	kw      map[string]interface{} // values may be *Builder, []*Builder, map[string]*Builder
+}
+
+// New returns a schema without keywords, which accepts any value.
+func New() *Builder {
+	return &Builder{kw: make(map[string]interface{})}
+}
+
+// True returns the boolean schema true, which accepts any value.
+func True() *Builder {
+	v := true
+	return &Builder{boolean: &v}
+}
+
+// False returns the boolean schema false, which rejects all values.
+func False() *Builder {
+	v := false
+	return &Builder{boolean: &v}
+}
+
+// Type returns a schema which accepts values of given json types.
+func Type(types ...string) *Builder {
+	return New().Type(types...)
+}
+
+// Object returns a schema of type "object".
+func Object() *Builder { return Type("object") }
+
+// Array returns a schema of type "array", whose items are valid against items.
+// items can be nil.
+func Array(items *Builder) *Builder {
+	b := Type("array")
+	if items != nil {
+		b.Items(items)
+	}
+	return b
+}
+
+// String returns a schema of type "string".
+func String() *Builder { return Type("string") }
+
+// Integer returns a schema of type "integer".
+func Integer() *Builder { return Type("integer") }
+
+// Number returns a schema of type "number".
+func Number() *Builder { return Type("number") }
+
+// Boolean returns a schema of type "boolean".
+func Boolean() *Builder { return Type("boolean") }
+
+// Null returns a schema of type "null".
+func Null() *Builder { return Type("null") }
+
+// Ref returns a schema which refers to schema at ref.
+func Ref(ref string) *Builder { return New().Set("$ref", ref) }
+
+// AllOf returns a schema which requires values to be valid against all schemas.
+func AllOf(schemas ...*Builder) *Builder { return New().Set("allOf", schemas) }
+
+// AnyOf returns a schema which requires values to be valid against any of schemas.
+func AnyOf(schemas ...*Builder) *Builder { return New().Set("anyOf", schemas) }
+
+// OneOf returns a schema which requires values to be valid against exactly
+// one of schemas.
+func OneOf(schemas ...*Builder) *Builder { return New().Set("oneOf", schemas) }
+
+// Not returns a schema which requires values to be not valid against s.
+func Not(s *Builder) *Builder { return New().Set("not", s) }
+
+// Set sets keyword to v. v is either a json value, *Builder, []*Builder
+// or map[string]*Builder. Set panics if b is a boolean schema.
+func (b *Builder) Set(keyword string, v interface{}) *Builder {
+	if b.boolean != nil {
+		panic("builder: cannot add keyword " + keyword + " to boolean schema")
+	}
+	b.kw[keyword] = v
+	return b
+}
+
+func (b *Builder) schemas(keyword string) map[string]*Builder {
+	m, ok := b.kw[keyword].(map[string]*Builder)
+	if !ok {
+		m = make(map[string]*Builder)
+		b.Set(keyword, m)
+	}
+	return m
+}
+
+// Type sets "type".
+func (b *Builder) Type(types ...string) *Builder {
+	if len(types) == 1 {
+		return b.Set("type", types[0])
+	}
+	return b.Set("type", toList(types))
+}
+
+// Title sets "title".
+func (b *Builder) Title(title string) *Builder { return b.Set("title", title) }
+
+// Description sets "description".
+func (b *Builder) Description(desc string) *Builder { return b.Set("description", desc) }
+
+// Default sets "default".
+func (b *Builder) Default(v interface{}) *Builder { return b.Set("default", v) }
+
+// Const sets "const".
+func (b *Builder) Const(v interface{}) *Builder { return b.Set("const", v) }
+
+// Enum sets "enum".
+func (b *Builder) Enum(values ...interface{}) *Builder { return b.Set("enum", values) }
+
+// Format sets "format".
+func (b *Builder) Format(format string) *Builder { return b.Set("format", format) }
+
+// Pattern sets "pattern".
+func (b *Builder) Pattern(pattern string) *Builder { return b.Set("pattern", pattern) }
+
+// MinLength sets "minLength".
+func (b *Builder) MinLength(n int) *Builder { return b.Set("minLength", n) }
+
+// MaxLength sets "maxLength".
+func (b *Builder) MaxLength(n int) *Builder { return b.Set("maxLength", n) }
+
+// Minimum sets "minimum".
+func (b *Builder) Minimum(n float64) *Builder { return b.Set("minimum", n) }
+
+// Maximum sets "maximum".
+func (b *Builder) Maximum(n float64) *Builder { return b.Set("maximum", n) }
+
+// ExclusiveMinimum sets "exclusiveMinimum", as number.
+func (b *Builder) ExclusiveMinimum(n float64) *Builder { return b.Set("exclusiveMinimum", n) }
+
+// ExclusiveMaximum sets "exclusiveMaximum", as number.
+func (b *Builder) ExclusiveMaximum(n float64) *Builder { return b.Set("exclusiveMaximum", n) }
+
+// MultipleOf sets "multipleOf".
+func (b *Builder) MultipleOf(n float64) *Builder { return b.Set("multipleOf", n) }
+
+// Prop adds property name with schema s to "properties".
+func (b *Builder) Prop(name string, s *Builder) *Builder {
+	b.schemas("properties")[name] = s
+	return b
+}
+
+// PatternProp adds pattern with schema s to "patternProperties".
+func (b *Builder) PatternProp(pattern string, s *Builder) *Builder {
+	b.schemas("patternProperties")[pattern] = s
+	return b
+}
+
+// AdditionalProperties sets "additionalProperties".
+func (b *Builder) AdditionalProperties(s *Builder) *Builder {
+	return b.Set("additionalProperties", s)
+}
+
+// Required adds names to "required".
+func (b *Builder) Required(names ...string) *Builder {
+	req, _ := b.kw["required"].([]interface{})
+	return b.Set("required", append(req, toList(names)...))
+}
+
+// MinProperties sets "minProperties".
+func (b *Builder) MinProperties(n int) *Builder { return b.Set("minProperties", n) }
+
+// MaxProperties sets "maxProperties".
+func (b *Builder) MaxProperties(n int) *Builder { return b.Set("maxProperties", n) }
+
+// Items sets "items".
+func (b *Builder) Items(s *Builder) *Builder { return b.Set("items", s) }
+
+// PrefixItems sets "prefixItems".
+func (b *Builder) PrefixItems(schemas ...*Builder) *Builder { return b.Set("prefixItems", schemas) }
+
+// MinItems sets "minItems".
+func (b *Builder) MinItems(n int) *Builder { return b.Set("minItems", n) }
+
+// MaxItems sets "maxItems".
+func (b *Builder) MaxItems(n int) *Builder { return b.Set("maxItems", n) }
+
+// UniqueItems sets "uniqueItems" to true.
+func (b *Builder) UniqueItems() *Builder { return b.Set("uniqueItems", true) }
+
+// Def adds schema s to "$defs" with given name. It can be referred
+// as Ref("#/$defs/"+name).
+func (b *Builder) Def(name string, s *Builder) *Builder {
+	b.schemas("$defs")[name] = s
+	return b
+}
+
+// Doc returns the schema as json document, i.e. either bool or
+// map[string]interface{}.
+func (b *Builder) Doc() interface{} {
+	if b.boolean != nil {
+		return *b.boolean
+	}
+	m := make(map[string]interface{}, len(b.kw))
+	for kw, v := range b.kw {
+		m[kw] = doc(v)
+	}
+	return m
+}
+
+func doc(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *Builder:
+		return v.Doc()
+	case []*Builder:
+		arr := make([]interface{}, len(v))
+		for i, s := range v {
+			arr[i] = s.Doc()
+		}
+		return arr
+	case map[string]*Builder:
+		m := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			m[k] = s.Doc()
+		}
+		return m
+	}
+	return v
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b *Builder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Doc())
+}
+
+// AddTo adds the schema to compiler c as resource at url.
+func (b *Builder) AddTo(c *jsonschema.Compiler, url string) error {
+	data, err := b.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return c.AddResource(url, bytes.NewReader(data))
+}
+
+// Compile compiles the schema with jsonschema.NewCompiler, using url as
+// its location.
+func (b *Builder) Compile(url string) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	if err := b.AddTo(c, url); err != nil {
+		return nil, err
+	}
+	return c.Compile(url)
+}
+
+// MustCompile is like Compile but panics if the schema cannot be compiled.
+func (b *Builder) MustCompile(url string) *jsonschema.Schema {
+	s, err := b.Compile(url)
+	if err != nil {
+		panic("builder: Compile(" + url + "): " + err.Error())
+	}
+	return s
+}
+
+func toList(s []string) []interface{} {
+	arr := make([]interface{}, len(s))
+	for i, v := range s {
+		arr[i] = v
+	}
+	return arr
+}
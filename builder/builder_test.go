@@ -0,0 +1,150 @@
+package builder_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/builder"
+)
+
+func TestBuilder(t *testing.T) {
+	person := builder.Object().
+		Prop("name", builder.String().MinLength(1)).
+		Prop("age", builder.Integer().Minimum(0)).
+		Prop("tags", builder.Array(builder.String()).UniqueItems()).
+		Prop("address", builder.Ref("#/$defs/address")).
+		Def("address", builder.Object().Prop("city", builder.String()).Required("city")).
+		AdditionalProperties(builder.False()).
+		Required("name")
+
+	b, err := json.Marshal(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"$defs":{"address":{"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"}},` +
+		`"additionalProperties":false,` +
+		`"properties":{"address":{"$ref":"#/$defs/address"},"age":{"minimum":0,"type":"integer"},"name":{"minLength":1,"type":"string"},"tags":{"items":{"type":"string"},"type":"array","uniqueItems":true}},` +
+		`"required":["name"],"type":"object"}`
+	if string(b) != want {
+		t.Fatalf("got %s\nwant %s", b, want)
+	}
+
+	sch := person.MustCompile("person.json")
+	valid := map[string]interface{}{"name": "x", "age": 1, "address": map[string]interface{}{"city": "y"}}
+	if err := sch.Validate(valid); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []interface{}{
+		map[string]interface{}{"age": 1},
+		map[string]interface{}{"name": ""},
+		map[string]interface{}{"name": "x", "address": map[string]interface{}{}},
+		map[string]interface{}{"name": "x", "other": 1},
+	} {
+		if err := sch.Validate(v); err == nil {
+			t.Errorf("%v must be invalid", v)
+		}
+	}
+}
+
+func TestBuilder_boolean(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("panic expected")
+		}
+	}()
+	builder.True().MinLength(1)
+}
+
+func TestMergeAllOf(t *testing.T) {
+	tests := []struct {
+		description string
+		doc, want   string
+	}{
+		{
+			"disjoint",
+			`{"allOf": [{"type": "object"}, {"required": ["a"]}]}`,
+			`{"type": "object", "required": ["a"]}`,
+		},
+		{
+			"intersect",
+			`{"type": ["number", "string"], "enum": [1, "a", 2.0], "allOf": [{"type": "integer", "enum": [2, 3]}]}`,
+			`{"type": "integer", "enum": [2.0]}`,
+		},
+		{
+			"limits",
+			`{"minimum": 1, "maxLength": 5, "allOf": [{"minimum": 3, "maxLength": 10}, {"required": ["a"]}, {"required": ["b", "a"]}]}`,
+			`{"minimum": 3, "maxLength": 5, "required": ["a", "b"]}`,
+		},
+		{
+			"properties",
+			`{"allOf": [{"properties": {"a": {"type": "string"}}}, {"properties": {"a": {"minLength": 1}, "b": true}}]}`,
+			`{"properties": {"a": {"type": "string", "minLength": 1}, "b": true}}`,
+		},
+		{
+			"nested",
+			`{"properties": {"a": {"allOf": [{"allOf": [{"type": "string"}]}, {"pattern": "^x"}]}}}`,
+			`{"properties": {"a": {"type": "string", "pattern": "^x"}}}`,
+		},
+		{
+			"ref",
+			`{"allOf": [{"$ref": "#/$defs/a"}, {"type": "object"}], "$defs": {"a": {}}}`,
+			`{"allOf": [{"$ref": "#/$defs/a"}], "type": "object", "$defs": {"a": {}}}`,
+		},
+		{
+			"additionalProperties",
+			`{"properties": {"a": true}, "allOf": [{"additionalProperties": false}]}`,
+			`{"properties": {"a": true}, "allOf": [{"additionalProperties": false}]}`,
+		},
+		{
+			"conflict",
+			`{"pattern": "a", "allOf": [{"pattern": "b"}, {"format": "email"}]}`,
+			`{"pattern": "a", "format": "email", "allOf": [{"pattern": "b"}]}`,
+		},
+		{
+			"annotations",
+			`{"title": "a", "allOf": [{"title": "b", "readOnly": true}]}`,
+			`{"title": "a", "readOnly": true}`,
+		},
+		{
+			"false",
+			`{"allOf": [true, {"properties": {"a": {"allOf": [false, {"type": "string"}]}}}]}`,
+			`{"properties": {"a": false}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			doc, err := jsonschema.DecodeJSON(strings.NewReader(test.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			orig, _ := json.Marshal(doc)
+			want, err := jsonschema.DecodeJSON(strings.NewReader(test.want))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := builder.MergeAllOf(doc)
+			if !reflect.DeepEqual(got, want) {
+				b, _ := json.Marshal(got)
+				t.Fatalf("got %s", b)
+			}
+			if b, _ := json.Marshal(doc); string(b) != string(orig) {
+				t.Fatal("doc is modified")
+			}
+		})
+	}
+}
+
+func TestMergeAllOf_builder(t *testing.T) {
+	b := builder.AllOf(
+		builder.Object().Prop("id", builder.Integer()).Required("id"),
+		builder.Object().Prop("id", builder.Integer().Minimum(1)).Required("name"),
+	)
+	got, _ := json.Marshal(builder.MergeAllOf(b.Doc()))
+	want := `{"properties":{"id":{"minimum":1,"type":"integer"}},"required":["id","name"],"type":"object"}`
+	if string(got) != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
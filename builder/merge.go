@@ -0,0 +1,370 @@
+package builder
+
+import (
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/jsonvalue"
+)
+
+// MergeAllOf returns doc, with subschemas in "allOf" folded into their
+// parent schema, wherever the result is equivalent. doc must be a decoded
+// json document. Subschemas which cannot be folded are left in "allOf".
+// doc is not modified.
+//
+// Keywords which are present in only one of the schemas are copied as is.
+// For keywords present in both, "type" and "enum" are intersected,
+// "required" is united, the stricter of numeric and size limits is picked,
+// and subschemas such as in "properties" are folded recursively. Other
+// keywords must be equal. Annotations like "title" are taken from the
+// parent.
+//
+// Subschemas with "$ref", "$id", "$anchor", "$defs" or unevaluated keywords
+// are never folded, nor any subschema into a schema with "$ref". Note that
+// references with json-pointers into folded subschemas, such as
+// "#/allOf/0/properties/name", are not rewritten.
+func MergeAllOf(doc interface{}) interface{} {
+	return merge(doc)
+}
+
+var (
+	schemaKeywords     = []string{"not", "if", "then", "else", "items", "additionalItems", "contains", "additionalProperties", "propertyNames", "unevaluatedProperties", "unevaluatedItems"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems", "items"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas", "dependencies"}
+
+	// subschemas with these keywords are not folded.
+	blockedKeywords = []string{"$ref", "$recursiveRef", "$dynamicRef", "$id", "id", "$anchor", "$recursiveAnchor", "$dynamicAnchor", "$schema", "$vocabulary", "$defs", "definitions", "unevaluatedProperties", "unevaluatedItems"}
+
+	// schemas with these keywords do not accept subschemas to be folded.
+	refKeywords = []string{"$ref", "$recursiveRef", "$dynamicRef"}
+)
+
+// merge folds allOf of v and all its subschemas.
+func merge(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	out := make(map[string]interface{}, len(m))
+	for kw, v := range m {
+		switch {
+		case contains(schemaListKeywords, kw):
+			if arr, ok := v.([]interface{}); ok {
+				l := make([]interface{}, len(arr))
+				for i, item := range arr {
+					l[i] = merge(item)
+				}
+				v = l
+			} else if contains(schemaKeywords, kw) {
+				v = merge(v)
+			}
+		case contains(schemaKeywords, kw):
+			v = merge(v)
+		case contains(schemaMapKeywords, kw):
+			if obj, ok := v.(map[string]interface{}); ok {
+				o := make(map[string]interface{}, len(obj))
+				for k, item := range obj {
+					o[k] = merge(item) // property dependencies are left as is
+				}
+				v = o
+			}
+		}
+		out[kw] = v
+	}
+	if allOf, ok := out["allOf"].([]interface{}); ok {
+		delete(out, "allOf")
+		return fold(out, allOf)
+	}
+	return out
+}
+
+// fold folds branches into m, which is owned by caller.
+// branches are already merged.
+func fold(m map[string]interface{}, branches []interface{}) interface{} {
+	if hasAny(m, refKeywords) {
+		m["allOf"] = branches
+		return m
+	}
+	var rest []interface{}
+	queue := append([]interface{}(nil), branches...)
+	for i := 0; i < len(queue); i++ {
+		switch b := queue[i].(type) {
+		case bool:
+			if !b {
+				if len(m) == 0 {
+					return false
+				}
+				rest = append(rest, b)
+			}
+		case map[string]interface{}:
+			if hasAny(b, blockedKeywords) {
+				rest = append(rest, b)
+				continue
+			}
+			stripped := b
+			nested, _ := b["allOf"].([]interface{})
+			if nested != nil {
+				stripped = make(map[string]interface{}, len(b))
+				for kw, v := range b {
+					if kw != "allOf" {
+						stripped[kw] = v
+					}
+				}
+			}
+			merged, ok := combineSchemas(m, stripped)
+			if !ok {
+				rest = append(rest, b)
+				continue
+			}
+			m = merged
+			queue = append(queue, nested...)
+		default:
+			rest = append(rest, b)
+		}
+	}
+	if len(rest) > 0 {
+		m["allOf"] = rest
+	}
+	return m
+}
+
+// subschema folds a and b into single subschema.
+func subschema(a, b interface{}) interface{} {
+	switch {
+	case a == true:
+		return b
+	case b == true:
+		return a
+	case a == false || b == false:
+		return false
+	}
+	return fold(map[string]interface{}{}, []interface{}{a, b})
+}
+
+// combineSchemas returns a new schema equivalent to allOf a and b.
+// Returns false if it cannot be done.
+func combineSchemas(a, b map[string]interface{}) (map[string]interface{}, bool) {
+	conflict := func(group1, group2 []string) bool {
+		return hasAny(a, group1) && hasAny(b, group2) || hasAny(b, group1) && hasAny(a, group2)
+	}
+	switch {
+	case conflict([]string{"additionalProperties"}, []string{"properties", "patternProperties"}):
+		return nil, false
+	case conflict([]string{"prefixItems", "additionalItems"}, []string{"items", "prefixItems", "additionalItems"}):
+		return nil, false
+	case isList(a["items"]) && has(b, "items") || isList(b["items"]) && has(a, "items"):
+		return nil, false
+	case conflict([]string{"contains", "minContains", "maxContains"}, []string{"contains", "minContains", "maxContains"}):
+		return nil, false
+	case conflict([]string{"if", "then", "else"}, []string{"if", "then", "else"}):
+		return nil, false
+	}
+	for _, kw := range []string{"exclusiveMinimum", "exclusiveMaximum"} {
+		// draft4 boolean form modifies minimum/maximum
+		limit := "minimum"
+		if kw == "exclusiveMaximum" {
+			limit = "maximum"
+		}
+		_, boolA := a[kw].(bool)
+		_, boolB := b[kw].(bool)
+		if (boolA || boolB) && hasAny(a, []string{kw, limit}) && hasAny(b, []string{kw, limit}) {
+			return nil, false
+		}
+	}
+
+	out := make(map[string]interface{}, len(a)+len(b))
+	for kw, v := range a {
+		out[kw] = v
+	}
+	for kw, bv := range b {
+		av, ok := out[kw]
+		if !ok {
+			out[kw] = bv
+			continue
+		}
+		v, ok := combine(kw, av, bv)
+		if !ok {
+			return nil, false
+		}
+		out[kw] = v
+	}
+	return out, true
+}
+
+// combine returns the value of keyword kw equivalent to kw:a and kw:b.
+func combine(kw string, a, b interface{}) (interface{}, bool) {
+	switch kw {
+	case "type":
+		return intersectTypes(a, b)
+	case "required":
+		return union(a, b)
+	case "enum":
+		aa, ok1 := a.([]interface{})
+		bb, ok2 := b.([]interface{})
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		var l []interface{}
+		for _, x := range aa {
+			for _, y := range bb {
+				if jsonvalue.Equal(x, y) {
+					l = append(l, x)
+					break
+				}
+			}
+		}
+		return l, len(l) > 0
+	case "properties", "patternProperties", "dependentSchemas", "dependentRequired":
+		am, ok1 := a.(map[string]interface{})
+		bm, ok2 := b.(map[string]interface{})
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		out := make(map[string]interface{}, len(am)+len(bm))
+		for k, v := range am {
+			out[k] = v
+		}
+		for k, bv := range bm {
+			av, ok := out[k]
+			switch {
+			case !ok:
+				out[k] = bv
+			case kw == "dependentRequired":
+				v, ok := union(av, bv)
+				if !ok {
+					return nil, false
+				}
+				out[k] = v
+			default:
+				out[k] = subschema(av, bv)
+			}
+		}
+		return out, true
+	case "items", "additionalProperties", "propertyNames":
+		return subschema(a, b), true
+	case "minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties":
+		return limit(a, b, 1)
+	case "maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties":
+		return limit(a, b, -1)
+	case "uniqueItems", "readOnly", "writeOnly", "deprecated":
+		if a == true || b == true {
+			return true, true
+		}
+		return a, true
+	case "title", "description", "$comment", "examples":
+		return a, true
+	}
+	return a, jsonvalue.Equal(a, b)
+}
+
+// limit returns the larger of a and b if sign is 1, otherwise the smaller.
+func limit(a, b interface{}, sign int) (interface{}, bool) {
+	x, ok1 := jsonvalue.Rat(a)
+	y, ok2 := jsonvalue.Rat(b)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	if x.Cmp(y) == sign {
+		return a, true
+	}
+	return b, true
+}
+
+func intersectTypes(a, b interface{}) (interface{}, bool) {
+	aa, ok1 := typesOf(a)
+	bb, ok2 := typesOf(b)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	var l []interface{}
+	add := func(t string) {
+		for _, x := range l {
+			if x == t {
+				return
+			}
+		}
+		l = append(l, t)
+	}
+	for _, x := range aa {
+		for _, y := range bb {
+			switch {
+			case x == y:
+				add(x)
+			case x == "integer" && y == "number", x == "number" && y == "integer":
+				add("integer")
+			}
+		}
+	}
+	switch len(l) {
+	case 0:
+		return nil, false
+	case 1:
+		return l[0], true
+	}
+	return l, true
+}
+
+func typesOf(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		var types []string
+		for _, t := range v {
+			s, ok := t.(string)
+			if !ok {
+				return nil, false
+			}
+			types = append(types, s)
+		}
+		return types, true
+	}
+	return nil, false
+}
+
+func union(a, b interface{}) (interface{}, bool) {
+	aa, ok1 := a.([]interface{})
+	bb, ok2 := b.([]interface{})
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	l := append([]interface{}(nil), aa...)
+	for _, y := range bb {
+		found := false
+		for _, x := range l {
+			if jsonvalue.Equal(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l = append(l, y)
+		}
+	}
+	return l, true
+}
+
+func isList(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+func has(m map[string]interface{}, kw string) bool {
+	_, ok := m[kw]
+	return ok
+}
+
+func hasAny(m map[string]interface{}, keywords []string) bool {
+	for _, kw := range keywords {
+		if has(m, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
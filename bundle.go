@@ -0,0 +1,344 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Bundle returns the schema at given url as a self-contained json document.
+//
+// Each external resource referenced, directly or indirectly, is embedded
+// into "$defs" ("definitions" before draft2019) of the root schema and all
+// "$ref" are rewritten to json-pointers within the bundled document. Because
+// of this rewrite, "$id" of embedded resources are removed. References to
+// meta-schemas of drafts are left as they are.
+//
+// The schema is compiled before bundling, so any compilation error is
+// returned as *SchemaError. Bundling fails if an external resource uses a
+// different draft than the root schema.
+func (c *Compiler) Bundle(url string) ([]byte, error) {
+	if _, err := c.Compile(url); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, err := c.newBundler(url)
+	if err != nil {
+		return nil, err
+	}
+	r := b.root
+	defsKw := b.defsKw()
+	if m, ok := r.doc.(map[string]interface{}); ok {
+		if defs, ok := m[defsKw].(map[string]interface{}); ok {
+			for name := range defs {
+				b.used[name] = true
+			}
+		}
+	}
+
+	doc, err := b.bundle(r, "#", r.doc)
+	if err != nil {
+		return nil, err
+	}
+	// embedded resources may refer to other external resources
+	for i := 0; i < len(b.queue); i++ {
+		er := b.queue[i]
+		if er.draft != r.draft {
+			return nil, fmt.Errorf("jsonschema: cannot bundle %s: uses %s, but %s uses %s", er.url, er.draft, r.url, r.draft)
+		}
+		edoc, err := b.bundle(er, "#", er.doc)
+		if err != nil {
+			return nil, err
+		}
+		b.defs[b.names[er]] = edoc
+	}
+	if len(b.defs) > 0 {
+		m := doc.(map[string]interface{})
+		if defs, ok := m[defsKw].(map[string]interface{}); ok {
+			for name, def := range defs {
+				b.defs[name] = def
+			}
+		}
+		m[defsKw] = b.defs
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Deref returns the schema at given url as a json document without any
+// "$ref", by inlining the schema referred in place of each "$ref".
+//
+// From draft2019, keywords next to "$ref" are kept, and the inlined schema
+// is added to "allOf". "$defs", "definitions", "$id" and anchors are removed
+// from the result, as they are no longer referenced.
+//
+// Recursive references cannot be inlined, and result in error. maxDepth
+// limits the number of nested references inlined; zero means no limit.
+// Schemas using "$recursiveRef" or "$dynamicRef" cannot be dereferenced.
+func (c *Compiler) Deref(url string, maxDepth int) ([]byte, error) {
+	if _, err := c.Compile(url); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, err := c.newBundler(url)
+	if err != nil {
+		return nil, err
+	}
+	b.deref, b.maxDepth = true, maxDepth
+	doc, err := b.bundle(b.root, "#", b.root.doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// newBundler returns bundler for the resource at url.
+func (c *Compiler) newBundler(url string) (*bundler, error) {
+	url, err := toAbs(url)
+	if err != nil {
+		return nil, err
+	}
+	u, _ := split(url)
+	r, err := c.findResource(u)
+	if err != nil {
+		return nil, err
+	}
+	return &bundler{
+		c:     c,
+		root:  r,
+		defs:  make(map[string]interface{}),
+		names: make(map[*resource]string),
+		used:  make(map[string]bool),
+	}, nil
+}
+
+// bundler embeds external resources into root resource.
+type bundler struct {
+	c     *Compiler
+	root  *resource
+	defs  map[string]interface{}
+	names map[*resource]string // external resource to its name in defs
+	used  map[string]bool      // names used in defs
+	queue []*resource          // external resources to be embedded
+
+	deref    bool     // inline the referred schemas instead of embedding
+	maxDepth int      // maximum nesting of inlined references
+	stack    []string // locations of references being inlined
+}
+
+// bundle returns a copy of schema v at floc in resource r, with "$ref"
+// rewritten to locations in bundled document.
+func (b *bundler) bundle(r *resource, floc string, v interface{}) (interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v, nil
+	}
+	sub := func(floc string, v interface{}) (interface{}, error) {
+		switch v.(type) {
+		case map[string]interface{}, bool:
+			return b.bundle(r, floc, v)
+		}
+		return v, nil
+	}
+
+	out := make(map[string]interface{}, len(m))
+	var ref interface{}
+	for kw, v := range m {
+		switch {
+		case kw == r.draft.id:
+			// rewritten refs are relative to bundled document
+			continue
+		case kw == "$schema" && (r != b.root || floc != "#"):
+			continue
+		case b.deref && (kw == "$defs" || kw == "definitions" || kw == "$anchor" || kw == "$dynamicAnchor" || kw == "$recursiveAnchor"):
+			continue
+		case b.deref && (kw == "$dynamicRef" || kw == "$recursiveRef"):
+			return nil, fmt.Errorf("jsonschema: cannot deref %s%s: %s is not supported", r.url, floc, kw)
+		case kw == "$ref" && b.deref:
+			ref = v
+			continue
+		case kw == "$ref":
+			ref, ok := v.(string)
+			if !ok {
+				out[kw] = v
+				continue
+			}
+			ref, err := b.rewrite(r, floc, ref)
+			if err != nil {
+				return nil, err
+			}
+			out[kw] = ref
+			continue
+		}
+
+		pos, ok := r.draft.subschemas[kw]
+		if !ok {
+			out[kw] = v
+			continue
+		}
+		switch vv := v.(type) {
+		case []interface{}:
+			if pos&item == 0 {
+				out[kw] = v
+				continue
+			}
+			arr := make([]interface{}, len(vv))
+			for i, item := range vv {
+				s, err := sub(floc+"/"+kw+"/"+strconv.Itoa(i), item)
+				if err != nil {
+					return nil, err
+				}
+				arr[i] = s
+			}
+			out[kw] = arr
+		case map[string]interface{}:
+			if pos&prop != 0 {
+				props := make(map[string]interface{}, len(vv))
+				for pname, pval := range vv {
+					s, err := sub(floc+"/"+kw+"/"+escape(pname), pval)
+					if err != nil {
+						return nil, err
+					}
+					props[pname] = s
+				}
+				out[kw] = props
+			} else {
+				s, err := sub(floc+"/"+kw, vv)
+				if err != nil {
+					return nil, err
+				}
+				out[kw] = s
+			}
+		default:
+			out[kw] = v
+		}
+	}
+
+	if ref, ok := ref.(string); ok {
+		target, err := b.inline(r, floc, ref)
+		if err != nil {
+			return nil, err
+		}
+		if r.draft.version < 2019 || len(out) == 0 {
+			// all other properties in a "$ref" object are ignored before draft2019
+			return target, nil
+		}
+		allOf, _ := out["allOf"].([]interface{})
+		out["allOf"] = append(allOf, target)
+	}
+	return out, nil
+}
+
+// inline returns the schema referred by ref, with its references inlined.
+func (b *bundler) inline(r *resource, floc, ref string) (interface{}, error) {
+	rr, tr, err := b.resolve(r, floc, ref)
+	if err != nil {
+		return nil, err
+	}
+	if rr == nil {
+		return nil, fmt.Errorf("jsonschema: cannot deref %s%s: %s is meta-schema", r.url, floc, ref)
+	}
+	if rr.draft != b.root.draft {
+		return nil, fmt.Errorf("jsonschema: cannot deref %s: uses %s, but %s uses %s", rr.url, rr.draft, b.root.url, b.root.draft)
+	}
+	loc := rr.url + tr.floc
+	for _, l := range b.stack {
+		if l == loc {
+			return nil, fmt.Errorf("jsonschema: cannot deref %s%s: recursive reference to %s", r.url, floc, loc)
+		}
+	}
+	if b.maxDepth > 0 && len(b.stack) >= b.maxDepth {
+		return nil, fmt.Errorf("jsonschema: cannot deref %s%s: references nested deeper than %d", r.url, floc, b.maxDepth)
+	}
+	b.stack = append(b.stack, loc)
+	defer func() { b.stack = b.stack[:len(b.stack)-1] }()
+	return b.bundle(rr, tr.floc, tr.doc)
+}
+
+// rewrite returns the location of schema referred by ref, in bundled document.
+func (b *bundler) rewrite(r *resource, floc, ref string) (string, error) {
+	rr, tr, err := b.resolve(r, floc, ref)
+	if err != nil {
+		return "", err
+	}
+	switch rr {
+	case nil:
+		return resolveURL(r.baseURL(floc), ref)
+	case b.root:
+		return tr.floc, nil
+	}
+	return "#/" + b.defsKw() + "/" + escape(b.name(rr)) + tr.floc[1:], nil
+}
+
+// resolve returns the root resource and the resource of schema referred by
+// ref from floc in r. returns nil resources if ref refers to a meta-schema.
+func (b *bundler) resolve(r *resource, floc, ref string) (*resource, *resource, error) {
+	abs, err := resolveURL(r.baseURL(floc), ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if findDraft(abs) != nil {
+		return nil, nil, nil
+	}
+	u, f := split(abs)
+	if _, ok := vocabSchemas[u]; ok {
+		return nil, nil, nil
+	}
+
+	rr := r
+	sr := rr.findResource(u)
+	if sr == nil && r != b.root {
+		rr = b.root
+		sr = rr.findResource(u)
+	}
+	if sr == nil {
+		if rr, err = b.c.findResource(u); err != nil {
+			return nil, nil, err
+		}
+		if sr = rr.findResource(u); sr == nil {
+			return nil, nil, fmt.Errorf("jsonschema: %s not found", abs)
+		}
+	}
+	tr, err := rr.resolveFragment(b.c, sr, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tr == nil {
+		return nil, nil, fmt.Errorf("jsonschema: %s not found", abs)
+	}
+	return rr, tr, nil
+}
+
+func (b *bundler) defsKw() string {
+	if b.root.draft.version < 2019 {
+		return "definitions"
+	}
+	return "$defs"
+}
+
+// name returns the name of external resource r in defs.
+func (b *bundler) name(r *resource) string {
+	if name, ok := b.names[r]; ok {
+		return name
+	}
+	u := r.url
+	if i := strings.IndexAny(u, "?#"); i != -1 {
+		u = u[:i]
+	}
+	base := path.Base(u)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if base == "" || base == "." || base == "/" {
+		base = "schema"
+	}
+	name := base
+	for i := 2; b.used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	b.used[name] = true
+	b.names[r] = name
+	b.queue = append(b.queue, r)
+	return name
+}
@@ -0,0 +1,187 @@
+package jsonschema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Bundle(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	resources := map[string]string{
+		"http://example.com/person.json": `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"name": {"$ref": "#/$defs/name"},
+				"home": {"$ref": "address.json"},
+				"work": {"$ref": "address.json#/$defs/office"},
+				"id": {"$ref": "common.json#id"}
+			},
+			"$defs": {
+				"name": {"type": "string"}
+			}
+		}`,
+		"http://example.com/address.json": `{
+			"$id": "http://example.com/address.json",
+			"type": "object",
+			"properties": {
+				"street": {"type": "string"},
+				"zip": {"$ref": "common.json#/$defs/zip"}
+			},
+			"required": ["street"],
+			"$defs": {
+				"office": {"$ref": "#", "required": ["zip"]}
+			}
+		}`,
+		"http://example.com/common.json": `{
+			"$defs": {
+				"zip": {"type": "string", "pattern": "^[0-9]{5}$"},
+				"id": {"$anchor": "id", "type": "integer"}
+			}
+		}`,
+	}
+	for url, doc := range resources {
+		if err := c.AddResource(url, strings.NewReader(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := c.Bundle("http://example.com/person.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	// bundled schema must not need external resources
+	bc := jsonschema.NewCompiler()
+	bc.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("must not load %s", s)
+	}
+	if err := bc.AddResource("bundle.json", bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := bc.Compile("bundle.json")
+	if err != nil {
+		t.Fatalf("%#v\n%s", err, b)
+	}
+
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"name": "john", "home": {"street": "main"}, "work": {"street": "main", "zip": "12345"}, "id": 1}`, true},
+		{`{"name": 1}`, false},
+		{`{"home": {}}`, false},
+		{`{"work": {"street": "main"}}`, false},
+		{`{"work": {"street": "main", "zip": "abc"}}`, false},
+		{`{"id": "one"}`, false},
+	}
+	for i, test := range tests {
+		err := sch.Validate(decodeString(t, test.doc))
+		if test.valid && err != nil {
+			t.Errorf("#%d: %#v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("#%d: validation must fail", i)
+		}
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	defs := doc["$defs"].(map[string]interface{})
+	for _, name := range []string{"name", "address", "common"} {
+		if _, ok := defs[name]; !ok {
+			t.Errorf("$defs/%s missing in %s", name, b)
+		}
+	}
+	if _, ok := defs["address"].(map[string]interface{})["$id"]; ok {
+		t.Errorf("$id of embedded resource must be removed")
+	}
+
+	t.Run("different draft", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		if err := c.AddResource("http://example.com/a.json", strings.NewReader(`{"$ref": "b.json"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.AddResource("http://example.com/b.json", strings.NewReader(`{"$schema": "http://json-schema.org/draft-07/schema#"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Bundle("http://example.com/a.json"); err == nil {
+			t.Fatal("bundle must fail")
+		}
+	})
+}
+
+func TestCompiler_Deref(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("http://example.com/person.json", strings.NewReader(`{
+		"type": "object",
+		"properties": {
+			"name": {"$ref": "#/$defs/name"},
+			"home": {"$ref": "address.json", "required": ["zip"]}
+		},
+		"$defs": {
+			"name": {"type": "string"}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddResource("http://example.com/address.json", strings.NewReader(`{
+		"$id": "http://example.com/address.json",
+		"type": "object",
+		"properties": {
+			"zip": {"$ref": "#/$defs/zip"}
+		},
+		"$defs": {
+			"zip": {"type": "string"}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Deref("http://example.com/person.json", 0)
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if bytes.Contains(b, []byte(`"$ref"`)) || bytes.Contains(b, []byte(`"$defs"`)) || bytes.Contains(b, []byte(`"$id"`)) {
+		t.Fatalf("deref schema must not contain $ref, $defs or $id: %s", b)
+	}
+
+	bc := jsonschema.NewCompiler()
+	if err := bc.AddResource("deref.json", bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := bc.Compile("deref.json")
+	if err != nil {
+		t.Fatalf("%#v\n%s", err, b)
+	}
+	if err := sch.Validate(decodeString(t, `{"name": "john", "home": {"zip": "12345"}}`)); err != nil {
+		t.Fatalf("%#v", err)
+	}
+	for _, doc := range []string{`{"name": 1}`, `{"home": {}}`, `{"home": {"zip": 1}}`} {
+		if err := sch.Validate(decodeString(t, doc)); err == nil {
+			t.Errorf("validation of %s must fail", doc)
+		}
+	}
+
+	if _, err := c.Deref("http://example.com/person.json", 1); err == nil {
+		t.Error("deref must fail when references are nested deeper than maxDepth")
+	}
+
+	t.Run("recursive", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		if err := c.AddResource("tree.json", strings.NewReader(`{
+			"properties": {"children": {"type": "array", "items": {"$ref": "#"}}}
+		}`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Deref("tree.json", 0); err == nil || !strings.Contains(err.Error(), "recursive") {
+			t.Fatalf("must fail with recursive reference error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,296 @@
+// Package cbor decodes CBOR (RFC 8949) documents into values that can be
+// validated using jsonschema.Schema.Validate.
+//
+// The package is typically only imported for the side effect of
+// registering its decoder for media type "application/cbor":
+//
+//	import _ "github.com/santhosh-tekuri/jsonschema/v5/cbor"
+//
+//	err := sch.ValidateBytes(payload, "application/cbor")
+//
+// CBOR data items map onto json values as follows:
+//
+//   - integers are decoded as int64, or uint64 if they do not fit in int64.
+//     bignums (tags 2 and 3) and negative integers below math.MinInt64 are
+//     decoded as *big.Int, so that no precision is lost
+//   - half, single and double precision floats are decoded as json.Number
+//     holding the shortest decimal that round-trips in that precision. so
+//     float32 0.1 is validated as 0.1, not as 0.10000000149011612. floats
+//     with integral value, such as 1.0, are integers as per json-schema
+//   - NaN and infinite floats are decoded as strings "NaN", "Infinity" and
+//     "-Infinity"
+//   - byte strings are decoded as base64 encoded strings, so that they can
+//     be validated using "contentEncoding": "base64"
+//   - undefined is decoded as null
+//   - map keys must be text strings or integers. integer keys are decoded as
+//     their decimal string
+//   - other tags are ignored, and their content is decoded
+package cbor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/bindec"
+)
+
+func init() {
+	jsonschema.InstanceDecoders["application/cbor"] = Decode
+}
+
+// MaxDepth is the maximum nesting of arrays and maps in a document.
+var MaxDepth = 1000
+
+// Decode decodes single CBOR data item from r. returns error if r has
+// more data after the data item.
+func Decode(r io.Reader) (interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(b)
+}
+
+// Unmarshal decodes single CBOR data item in b. returns error if b has
+// more data after the data item.
+func Unmarshal(b []byte) (interface{}, error) {
+	d := &decoder{b: b}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("cbor: %v at offset %d", err, d.off)
+	}
+	if d.off != len(b) {
+		return nil, fmt.Errorf("cbor: unexpected data after top-level value at offset %d", d.off)
+	}
+	return v, nil
+}
+
+var (
+	errUnexpectedEOF = errors.New("unexpected end of data")
+	errBreak         = errors.New("unexpected break")
+)
+
+type decoder struct {
+	b   []byte
+	off int
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)-d.off) {
+		return nil, errUnexpectedEOF
+	}
+	b := d.b[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// head reads initial byte and argument of data item. info 31
+// denotes indefinite length, or break for major type 7.
+func (d *decoder) head() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		b, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			arg = arg<<8 | uint64(c)
+		}
+	case info == 31:
+		// indefinite length or break
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid additional information %d", info)
+	}
+	return major, info, arg, nil
+}
+
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("exceeded max depth %d", MaxDepth)
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	indefinite := info == 31
+	if indefinite && (major < 2 || major == 6) {
+		return nil, fmt.Errorf("invalid indefinite length for major type %d", major)
+	}
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			n := new(big.Int).SetUint64(arg)
+			return n.Neg(n).Sub(n, big.NewInt(1)), nil
+		}
+		return -1 - int64(arg), nil
+	case 2:
+		b, err := d.bytes(major, indefinite, arg)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case 3:
+		b, err := d.bytes(major, indefinite, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, errors.New("invalid utf-8 in text string")
+		}
+		return string(b), nil
+	case 4:
+		arr := make([]interface{}, 0, bindec.Capacity(arg))
+		for i := uint64(0); indefinite || i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if indefinite && err == errBreak {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	case 5:
+		m := make(map[string]interface{}, bindec.Capacity(arg))
+		for i := uint64(0); indefinite || i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if indefinite && err == errBreak {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			key, err := mapKey(k)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m[key]; ok {
+				return nil, fmt.Errorf("duplicate map key %q", key)
+			}
+			if m[key], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case 6:
+		if arg == 2 || arg == 3 {
+			major, info, size, err := d.head()
+			if err != nil {
+				return nil, err
+			}
+			if major != 2 {
+				return nil, fmt.Errorf("bignum tag %d must enclose byte string", arg)
+			}
+			b, err := d.bytes(major, info == 31, size)
+			if err != nil {
+				return nil, err
+			}
+			n := new(big.Int).SetBytes(b)
+			if arg == 3 {
+				n.Neg(n).Sub(n, big.NewInt(1))
+			}
+			return n, nil
+		}
+		return d.decode(depth + 1)
+	}
+
+	// major type 7
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return bindec.Float(halfToFloat(uint16(arg)), 32), nil
+	case 26:
+		return bindec.Float(float64(math.Float32frombits(uint32(arg))), 32), nil
+	case 27:
+		return bindec.Float(math.Float64frombits(arg), 64), nil
+	case 31:
+		return nil, errBreak
+	}
+	return nil, fmt.Errorf("unsupported simple value %d", arg)
+}
+
+// bytes reads content of byte or text string, whose
+// major type and argument are given.
+func (d *decoder) bytes(major byte, indefinite bool, arg uint64) ([]byte, error) {
+	if !indefinite {
+		return d.read(arg)
+	}
+	var b []byte
+	for {
+		m, info, arg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m == 7 && info == 31 {
+			return b, nil
+		}
+		if m != major || info == 31 {
+			return nil, fmt.Errorf("invalid chunk of indefinite length string")
+		}
+		chunk, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+func mapKey(k interface{}) (string, error) {
+	switch k := k.(type) {
+	case string:
+		return k, nil
+	case int64:
+		return strconv.FormatInt(k, 10), nil
+	case uint64:
+		return strconv.FormatUint(k, 10), nil
+	case *big.Int:
+		return k.String(), nil
+	}
+	return "", fmt.Errorf("unsupported map key %v", k)
+}
+
+func halfToFloat(h uint16) float64 {
+	exp, mant := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,126 @@
+package cbor_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/cbor"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string // empty means error
+	}{
+		// examples from RFC 8949 Appendix A
+		{"00", `0`},
+		{"17", `23`},
+		{"1818", `24`},
+		{"1903e8", `1000`},
+		{"1bffffffffffffffff", `18446744073709551615`},
+		{"c249010000000000000000", `18446744073709551616`},
+		{"3bffffffffffffffff", `-18446744073709551616`},
+		{"c349010000000000000000", `-18446744073709551617`},
+		{"20", `-1`},
+		{"3903e7", `-1000`},
+		{"f90000", `0`},
+		{"f93c00", `1`},
+		{"f93e00", `1.5`},
+		{"fa47c35000", `100000`},
+		{"fa3dcccccd", `0.1`},
+		{"fb3ff199999999999a", `1.1`},
+		{"f97c00", `"Infinity"`},
+		{"f97e00", `"NaN"`},
+		{"fbfff0000000000000", `"-Infinity"`},
+		{"f4", `false`},
+		{"f5", `true`},
+		{"f6", `null`},
+		{"f7", `null`},
+		{"4401020304", `"AQIDBA=="`},
+		{"5f42010243030405ff", `"AQIDBAU="`},
+		{"6449455446", `"IETF"`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"83010203", `[1,2,3]`},
+		{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"a201020304", `{"1":2,"3":4}`},
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+		{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+
+		{"", ``},
+		{"1a0102", ``},
+		{"830102", ``},
+		{"0000", ``},
+		{"ff", ``},
+		{"a2616101616102", ``},
+		{"a1f401", ``},
+		{"62c328", ``},
+		{"c26161", ``},
+		{"1f", ``},
+	}
+	for _, test := range tests {
+		b, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := cbor.Unmarshal(b)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s: error expected", test.hex)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.hex, got, test.want)
+		}
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "maximum": 18446744073709551615},
+			"temp": {"type": "number", "multipleOf": 0.1},
+			"raw": {"type": "string", "contentEncoding": "base64", "maxLength": 8}
+		}
+	}`)
+	tests := []struct {
+		hex   string
+		valid bool
+	}{
+		// {"id": 18446744073709551615, "temp": float32(0.1), "raw": h'01020304'}
+		{"a36269641bffffffffffffffff6474656d70fa3dcccccd637261774401020304", true},
+		// {"id": float16(1.0)}
+		{"a1626964f93c00", true},
+		// {"id": float16(1.5)}
+		{"a1626964f93e00", false},
+		// {"id": 18446744073709551616}
+		{"a1626964c249010000000000000000", false},
+		// {"raw": h'0102030405060708'}
+		{"a163726177480102030405060708", false},
+	}
+	for _, test := range tests {
+		b, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sch.ValidateBytes(b, "application/cbor")
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: must be invalid", test.hex)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/santhosh-tekuri/jsonschema/cmd/jsonschema-gen
+
+go 1.19
+
+require github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+
+replace github.com/santhosh-tekuri/jsonschema/v5 => ../..
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/codegen"
+	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
+	"github.com/santhosh-tekuri/jsonschema/v5/tsgen"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "jsonschema-gen [-draft INT] [-lang go|ts] [-pkg NAME] [-type NAME] [-o FILE] <json-schema>...")
+	flag.PrintDefaults()
+}
+
+func main() {
+	draft := flag.Int("draft", 2020, "draft used when '$schema' attribute is missing. valid values 4, 6, 7, 2019, 2020")
+	lang := flag.String("lang", "go", "language of generated source. valid values go, ts")
+	pkg := flag.String("pkg", "main", "package name of generated source")
+	typeName := flag.String("type", "", "name of generated type. allowed only with single schema. defaults to name derived from schema location")
+	out := flag.String("o", "", "output file. defaults to stdout")
+	flag.Usage = usage
+	flag.Parse()
+	if len(flag.Args()) == 0 {
+		usage()
+		os.Exit(1)
+	}
+	if *typeName != "" && len(flag.Args()) > 1 {
+		fmt.Fprintln(os.Stderr, "-type is allowed only with single schema")
+		os.Exit(1)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	switch *draft {
+	case 4:
+		compiler.Draft = jsonschema.Draft4
+	case 6:
+		compiler.Draft = jsonschema.Draft6
+	case 7:
+		compiler.Draft = jsonschema.Draft7
+	case 2019:
+		compiler.Draft = jsonschema.Draft2019
+	case 2020:
+		compiler.Draft = jsonschema.Draft2020
+	default:
+		fmt.Fprintln(os.Stderr, "draft must be 4, 6, 7, 2019 or 2020")
+		os.Exit(1)
+	}
+	compiler.ExtractAnnotations = true
+
+	var schemas []*jsonschema.Schema
+	for _, f := range flag.Args() {
+		schema, err := compiler.Compile(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%#v\n", err)
+			os.Exit(1)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	var src []byte
+	var err error
+	switch *lang {
+	case "go":
+		g := codegen.NewGenerator(*pkg)
+		for _, schema := range schemas {
+			g.Add(*typeName, schema)
+		}
+		src, err = g.Source()
+	case "ts":
+		g := tsgen.NewGenerator()
+		for _, schema := range schemas {
+			g.Add(*typeName, schema)
+		}
+		src = g.Source()
+	default:
+		err = fmt.Errorf("lang must be go or ts")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*out, src, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
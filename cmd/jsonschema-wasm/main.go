@@ -0,0 +1,16 @@
+//go:build js && wasm
+
+// Command jsonschema-wasm exposes the validator as javascript global
+// object "jsonschema", when compiled to wasm:
+//
+//	GOOS=js GOARCH=wasm go build -o jsonschema.wasm ./cmd/jsonschema-wasm
+//
+// See package github.com/santhosh-tekuri/jsonschema/v5/wasm for its usage.
+package main
+
+import "github.com/santhosh-tekuri/jsonschema/v5/wasm"
+
+func main() {
+	wasm.Register("jsonschema")
+	select {}
+}
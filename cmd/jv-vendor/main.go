@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "jv-vendor [-draft INT] [-o DIR] <json-schema>")
+	flag.PrintDefaults()
+}
+
+func main() {
+	draft := flag.Int("draft", 2020, "draft used when '$schema' attribute is missing. valid values 4, 6, 7, 2019, 2020")
+	out := flag.String("o", "remote", "directory to save remote schemas into")
+	flag.Usage = usage
+	flag.Parse()
+	if len(flag.Args()) != 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	switch *draft {
+	case 4:
+		compiler.Draft = jsonschema.Draft4
+	case 6:
+		compiler.Draft = jsonschema.Draft6
+	case 7:
+		compiler.Draft = jsonschema.Draft7
+	case 2019:
+		compiler.Draft = jsonschema.Draft2019
+	case 2020:
+		compiler.Draft = jsonschema.Draft2020
+	default:
+		fmt.Fprintln(os.Stderr, "draft must be 4, 6, 7, 2019 or 2020")
+		os.Exit(1)
+	}
+
+	m, err := compiler.Vendor(flag.Arg(0), *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%#v\n", err)
+		os.Exit(1)
+	}
+	for _, r := range m.Resources {
+		fmt.Printf("%s %s\n", r.Path, r.URL)
+	}
+}
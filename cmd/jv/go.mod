@@ -6,3 +6,5 @@ require (
 	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+replace github.com/santhosh-tekuri/jsonschema/v5 => ../..
@@ -16,14 +16,23 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+func init() {
+	jsonschema.InstanceDecoders["application/yaml"] = func(r io.Reader) (interface{}, error) {
+		var v interface{}
+		err := yaml.NewDecoder(r).Decode(&v)
+		return v, err
+	}
+}
+
 func usage() {
 	fmt.Fprintln(os.Stderr, "jv [-draft INT] [-output FORMAT] [-assertformat] [-assertcontent] <json-schema> [<json-or-yaml-doc>]...")
+	fmt.Fprintln(os.Stderr, "use - as <json-or-yaml-doc> to read json document from stdin")
 	flag.PrintDefaults()
 }
 
 func main() {
-	draft := flag.Int("draft", 2020, "draft used when '$schema' attribute is missing. valid values 4, 5, 7, 2019, 2020")
-	output := flag.String("output", "", "output format. valid values flag, basic, detailed")
+	draft := flag.Int("draft", 2020, "draft used when '$schema' attribute is missing. valid values 4, 6, 7, 2019, 2020")
+	output := flag.String("output", "", "output format. valid values flag, basic, detailed, verbose")
 	assertFormat := flag.Bool("assertformat", false, "enable format assertions with draft >= 2019")
 	assertContent := flag.Bool("assertcontent", false, "enable content assertions with draft >= 2019")
 	flag.Usage = usage
@@ -46,7 +55,7 @@ func main() {
 	case 2020:
 		compiler.Draft = jsonschema.Draft2020
 	default:
-		fmt.Fprintln(os.Stderr, "draft must be 4, 5, 7, 2019 or 2020")
+		fmt.Fprintln(os.Stderr, "draft must be 4, 6, 7, 2019 or 2020")
 		os.Exit(1)
 	}
 
@@ -55,14 +64,14 @@ func main() {
 	compiler.AssertContent = *assertContent
 
 	var validOutput bool
-	for _, out := range []string{"", "flag", "basic", "detailed"} {
+	for _, out := range []string{"", "flag", "basic", "detailed", "verbose"} {
 		if *output == out {
 			validOutput = true
 			break
 		}
 	}
 	if !validOutput {
-		fmt.Fprintln(os.Stderr, "output must be flag, basic or detailed")
+		fmt.Fprintln(os.Stderr, "output must be flag, basic, detailed or verbose")
 		os.Exit(1)
 	}
 
@@ -74,15 +83,20 @@ func main() {
 
 	exitCode := 0
 	for _, f := range flag.Args()[1:] {
-		file, err := os.Open(f)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			exitCode = 1
-			continue
+		var v interface{}
+		if f == "-" {
+			v, err = decodeJSON(os.Stdin, "<stdin>")
+		} else {
+			var file *os.File
+			file, err = os.Open(f)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				exitCode = 1
+				continue
+			}
+			v, err = decodeFile(file)
+			_ = file.Close()
 		}
-
-		v, err := decodeFile(file)
-		_ = file.Close()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s\n", err)
 			exitCode = 1
@@ -90,18 +104,13 @@ func main() {
 		}
 
 		err = schema.Validate(v)
+		var out interface{}
+		if _, ok := err.(*jsonschema.ValidationError); (ok || err == nil) && *output != "" {
+			out, _ = schema.ValidateWithOutput(v, *output, jsonschema.ValidateOptions{})
+		}
 		if err != nil {
 			exitCode = 1
-			if ve, ok := err.(*jsonschema.ValidationError); ok {
-				var out interface{}
-				switch *output {
-				case "flag":
-					out = ve.FlagOutput()
-				case "basic":
-					out = ve.BasicOutput()
-				case "detailed":
-					out = ve.DetailedOutput()
-				}
+			if _, ok := err.(*jsonschema.ValidationError); ok {
 				if out == nil {
 					fmt.Fprintf(os.Stderr, "%#v\n", err)
 				} else {
@@ -111,10 +120,9 @@ func main() {
 			} else {
 				fmt.Fprintf(os.Stderr, "validation failed: %v\n", err)
 			}
-		} else {
-			if *output != "" {
-				fmt.Println("{\n  \"valid\": true\n}")
-			}
+		} else if out != nil {
+			b, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Println(string(b))
 		}
 	}
 	os.Exit(exitCode)
@@ -141,17 +149,23 @@ func loadURL(s string) (io.ReadCloser, error) {
 }
 
 func decodeFile(file *os.File) (interface{}, error) {
-	ext := filepath.Ext(file.Name())
-	if ext == ".yaml" || ext == ".yml" {
-		return decodeYAML(file, file.Name())
+	mediaType, kind := "application/json", "json"
+	if ext := filepath.Ext(file.Name()); ext == ".yaml" || ext == ".yml" {
+		mediaType, kind = "application/yaml", "yaml"
 	}
+	v, err := jsonschema.Decode(file, mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s file %s: %v", kind, file.Name(), err)
+	}
+	return v, nil
+}
 
-	// json file
+func decodeJSON(r io.Reader, name string) (interface{}, error) {
 	var v interface{}
-	dec := json.NewDecoder(file)
+	dec := json.NewDecoder(r)
 	dec.UseNumber()
 	if err := dec.Decode(&v); err != nil {
-		return nil, fmt.Errorf("invalid json file %s: %v", file.Name(), err)
+		return nil, fmt.Errorf("invalid json file %s: %v", name, err)
 	}
 	return v, nil
 }
@@ -0,0 +1,426 @@
+// Package codegen generates go type declarations from compiled json-schemas.
+//
+// Typical usage:
+//
+//	compiler := jsonschema.NewCompiler()
+//	compiler.ExtractAnnotations = true // to generate doc comments
+//	sch, err := compiler.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//	g := codegen.NewGenerator("model")
+//	g.Add("Person", sch)
+//	src, err := g.Source()
+//
+// The mapping from json-schema to go types is as follows:
+//   - object with properties is mapped to struct, with field for each property.
+//     Optional properties are pointers and tagged with omitempty.
+//   - object without properties is mapped to map[string]T, where T is from
+//     additionalProperties.
+//   - array is mapped to []T, where T is from items.
+//   - string, integer, number, boolean are mapped to string, int64, float64, bool.
+//   - string enum is mapped to named string type, with constant for each value.
+//   - nullable type, i.e. "type": ["T", "null"], is mapped to pointer.
+//   - schema referenced via $ref is mapped to named type.
+//   - allOf is mapped to struct with properties of all subschemas.
+//   - oneOf and anyOf are mapped to wrapper struct, with Value field holding
+//     one of the alternatives. The alternative is chosen during unmarshal by
+//     trying each one of them in order.
+//   - anything else is mapped to interface{}.
+//
+// Since compiled schema does not preserve the order of properties, struct
+// fields are generated in sorted order of property names.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+// Generator generates go type declarations for the schemas added to it.
+type Generator struct {
+	pkg     string
+	names   map[*jsonschema.Schema]string // named types generated
+	used    map[string]bool               // names already used
+	pending map[string]bool               // named structs whose fields are being generated
+	decls   []string
+	oneOf   bool // whether oneOf helpers are required
+}
+
+// NewGenerator returns Generator that generates source of given go package.
+func NewGenerator(pkg string) *Generator {
+	return &Generator{
+		pkg:     pkg,
+		names:   make(map[*jsonschema.Schema]string),
+		used:    make(map[string]bool),
+		pending: make(map[string]bool),
+	}
+}
+
+// Add generates named type with given name for sch, along with types for
+// its subschemas. If name is empty, it is derived from the schema location.
+//
+// returns the name of generated type. It differs from name if the name is
+// already used or sch is already added.
+func (g *Generator) Add(name string, sch *jsonschema.Schema) string {
+	if name == "" {
+		name = goName(schemautil.LocationName(sch.Location))
+	}
+	return g.named(sch, goName(name))
+}
+
+// Source returns the gofmt-ed source code of generated declarations.
+func (g *Generator) Source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated from json-schema. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "package %s\n", g.pkg)
+	if g.oneOf {
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, `import (`)
+		fmt.Fprintln(&buf, `	"bytes"`)
+		fmt.Fprintln(&buf, `	"encoding/json"`)
+		fmt.Fprintln(&buf, `	"errors"`)
+		fmt.Fprintln(&buf, `)`)
+	}
+	for _, decl := range g.decls {
+		fmt.Fprintln(&buf)
+		buf.WriteString(decl)
+	}
+	if g.oneOf {
+		fmt.Fprintln(&buf)
+		buf.WriteString(oneOfHelpers)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: generated invalid source: %v", err)
+	}
+	return src, nil
+}
+
+// named returns the name of go type generated for sch.
+func (g *Generator) named(sch *jsonschema.Schema, name string) string {
+	if n, ok := g.names[sch]; ok {
+		return n
+	}
+	name = schemautil.UniqueName(g.used, name)
+	g.names[sch] = name
+	index := len(g.decls)
+	g.decls = append(g.decls, "") // reserve, so that it precedes types it uses
+
+	var decl bytes.Buffer
+	writeDoc(&decl, name, sch)
+	sch = schemautil.Deref(sch, true)
+	types, _ := schemautil.TypesOf(sch, false)
+	switch {
+	case len(sch.OneOf) > 0 || len(sch.AnyOf) > 0:
+		g.writeOneOf(&decl, name, sch)
+	case len(types) == 1 && types[0] == "object" && schemautil.HasProperties(sch):
+		g.pending[name] = true
+		g.writeStruct(&decl, name, sch)
+		delete(g.pending, name)
+	case len(types) == 1 && types[0] == "string" && len(schemautil.EnumValues(sch)) > 0:
+		writeEnum(&decl, name, sch)
+	default:
+		fmt.Fprintf(&decl, "type %s %s\n", name, g.expr(sch, name))
+	}
+	g.decls[index] = decl.String()
+	return name
+}
+
+// typeOf returns the go type expression for sch. hint is used as name,
+// if a named type has to be generated.
+func (g *Generator) typeOf(sch *jsonschema.Schema, hint string) string {
+	if name, ok := g.names[sch]; ok {
+		return name
+	}
+	return g.expr(sch, hint)
+}
+
+// expr is like typeOf, but does not use the named type of sch.
+func (g *Generator) expr(sch *jsonschema.Schema, hint string) string {
+	if ref := schemautil.RefOf(sch); ref != nil && schemautil.IsRefOnly(sch) {
+		return g.named(ref, goName(schemautil.LocationName(ref.Location)))
+	}
+	if sch.Always != nil {
+		return "interface{}"
+	}
+	if len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 {
+		if t, ok := g.sameType(append(append([]*jsonschema.Schema{}, sch.OneOf...), sch.AnyOf...), hint); ok {
+			return t
+		}
+		return g.named(sch, hint)
+	}
+	if len(sch.AllOf) == 1 && schemautil.IsRefOnly(sch) {
+		return g.typeOf(sch.AllOf[0], hint)
+	}
+
+	types, nullable := schemautil.TypesOf(sch, false)
+	if len(types) != 1 {
+		return "interface{}"
+	}
+	var t string
+	switch types[0] {
+	case "string":
+		if len(schemautil.EnumValues(sch)) > 0 {
+			return g.nullable(g.named(sch, hint), nullable)
+		}
+		t = "string"
+	case "integer":
+		t = "int64"
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]" + g.itemType(sch, hint+"Item")
+	case "object":
+		if schemautil.HasProperties(sch) {
+			return g.nullable(g.named(sch, hint), nullable)
+		}
+		if ap, ok := sch.AdditionalProperties.(*jsonschema.Schema); ok {
+			return "map[string]" + g.typeOf(ap, hint+"Value")
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+	return g.nullable(t, nullable)
+}
+
+func (g *Generator) nullable(t string, nullable bool) string {
+	if nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// sameType returns the type of given schemas, if all of them
+// map to same go type.
+func (g *Generator) sameType(list []*jsonschema.Schema, hint string) (string, bool) {
+	var t string
+	for _, sch := range list {
+		if !isSimple(sch) {
+			return "", false
+		}
+		st := g.typeOf(sch, hint)
+		if t != "" && st != t {
+			return "", false
+		}
+		t = st
+	}
+	return t, true
+}
+
+func (g *Generator) itemType(sch *jsonschema.Schema, hint string) string {
+	switch items := sch.Items.(type) {
+	case *jsonschema.Schema:
+		return g.typeOf(items, hint)
+	case []*jsonschema.Schema:
+		return "interface{}"
+	}
+	if len(sch.PrefixItems) == 0 && sch.Items2020 != nil {
+		return g.typeOf(sch.Items2020, hint)
+	}
+	return "interface{}"
+}
+
+func (g *Generator) writeStruct(w *bytes.Buffer, name string, sch *jsonschema.Schema) {
+	props, required := schemautil.Properties(sch)
+	pnames := make([]string, 0, len(props))
+	for pname := range props {
+		pnames = append(pnames, pname)
+	}
+	sort.Strings(pnames)
+
+	fieldNames := make(map[string]bool)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, pname := range pnames {
+		psch := props[pname]
+		fname := goName(pname)
+		for i := 2; fieldNames[fname]; i++ {
+			fname = goName(pname) + strconv.Itoa(i)
+		}
+		fieldNames[fname] = true
+
+		t := g.typeOf(psch, name+fname)
+		tag := pname
+		if !required[pname] || g.pending[t] {
+			if !required[pname] {
+				tag += ",omitempty"
+			}
+			if !isNillable(t) {
+				t = "*" + t
+			}
+		}
+		if desc := description(psch); desc != "" {
+			for _, line := range strings.Split(desc, "\n") {
+				fmt.Fprintf(w, "\t// %s\n", line)
+			}
+		}
+		fmt.Fprintf(w, "\t%s %s `json:%s`\n", fname, t, strconv.Quote(tag))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func writeEnum(w *bytes.Buffer, name string, sch *jsonschema.Schema) {
+	fmt.Fprintf(w, "type %s string\n\n", name)
+	values := schemautil.EnumValues(sch)
+	fmt.Fprintln(w, "const (")
+	used := make(map[string]bool)
+	for i, v := range values {
+		cname := name + goName(v)
+		if cname == name || used[cname] {
+			cname = name + strconv.Itoa(i)
+		}
+		used[cname] = true
+		fmt.Fprintf(w, "\t%s %s = %s\n", cname, name, strconv.Quote(v))
+	}
+	fmt.Fprintln(w, ")")
+}
+
+func (g *Generator) writeOneOf(w *bytes.Buffer, name string, sch *jsonschema.Schema) {
+	g.oneOf = true
+	alts := sch.OneOf
+	if len(alts) == 0 {
+		alts = sch.AnyOf
+	}
+	var types []string
+	for i, alt := range alts {
+		t := g.typeOf(alt, name+strconv.Itoa(i))
+		dup := false
+		for _, at := range types {
+			dup = dup || at == t
+		}
+		if !dup {
+			types = append(types, t)
+		}
+	}
+
+	fmt.Fprintf(w, "//\n// Value holds one of: %s.\n", strings.Join(types, ", "))
+	fmt.Fprintf(w, "type %s struct {\n\tValue interface{}\n}\n\n", name)
+	fmt.Fprintf(w, "func (v %s) MarshalJSON() ([]byte, error) {\n\treturn json.Marshal(v.Value)\n}\n\n", name)
+	fmt.Fprintf(w, "func (v *%s) UnmarshalJSON(b []byte) error {\n", name)
+	for _, t := range types {
+		fmt.Fprintf(w, "\t{\n\t\tvar x %s\n\t\tif err := unmarshalStrict(b, &x); err == nil {\n\t\t\tv.Value = x\n\t\t\treturn nil\n\t\t}\n\t}\n", t)
+	}
+	fmt.Fprintf(w, "\treturn errors.New(%s)\n}\n", strconv.Quote("value does not match any alternative of "+name))
+}
+
+const oneOfHelpers = `// unmarshalStrict is like json.Unmarshal, but fails on unknown fields.
+func unmarshalStrict(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+`
+
+func writeDoc(w *bytes.Buffer, name string, sch *jsonschema.Schema) {
+	loc := sch.Location
+	if strings.HasPrefix(loc, "file://") {
+		// avoid local paths in generated source
+		loc = path.Base(loc)
+	}
+	fmt.Fprintf(w, "// %s is generated from %s.\n", name, loc)
+	if desc := description(sch); desc != "" {
+		fmt.Fprintln(w, "//")
+		for _, line := range strings.Split(desc, "\n") {
+			fmt.Fprintf(w, "// %s\n", line)
+		}
+	}
+}
+
+func description(sch *jsonschema.Schema) string {
+	for sch != nil {
+		if desc := strings.TrimSpace(sch.Description); desc != "" {
+			return desc
+		}
+		if title := strings.TrimSpace(sch.Title); title != "" {
+			return title
+		}
+		if !schemautil.IsRefOnly(sch) {
+			break
+		}
+		sch = schemautil.RefOf(sch)
+	}
+	return ""
+}
+
+// isSimple tells whether sch maps to go type without generating new types.
+func isSimple(sch *jsonschema.Schema) bool {
+	types, _ := schemautil.TypesOf(sch, false)
+	if len(types) != 1 || len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 {
+		return false
+	}
+	switch types[0] {
+	case "string":
+		return len(schemautil.EnumValues(sch)) == 0
+	case "integer", "number", "boolean":
+		return true
+	}
+	return false
+}
+
+func isNillable(t string) bool {
+	return strings.HasPrefix(t, "*") || strings.HasPrefix(t, "[]") ||
+		strings.HasPrefix(t, "map[") || t == "interface{}"
+}
+
+var initialisms = map[string]bool{
+	"API": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "TCP": true, "TTL": true,
+	"UDP": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts s into exported go identifier.
+// for example first_name and first-name are converted to FirstName.
+func goName(s string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name == "" {
+		return "Value"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
@@ -0,0 +1,127 @@
+package codegen_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/codegen"
+)
+
+func TestGenerator(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("https://example.com/person.json", strings.NewReader(`{
+		"description": "Person represents a person.",
+		"type": "object",
+		"required": ["first_name", "id"],
+		"properties": {
+			"id": {"type": "integer"},
+			"first_name": {"type": "string", "description": "given name"},
+			"nick": {"type": ["string", "null"]},
+			"age": {"type": "number"},
+			"color": {"enum": ["red", "green", "dark-blue"]},
+			"address": {"$ref": "#/$defs/address"},
+			"friends": {"type": "array", "items": {"$ref": "#"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"pet": {"oneOf": [{"$ref": "#/$defs/cat"}, {"$ref": "#/$defs/dog"}]},
+			"score": {"oneOf": [{"type": "integer"}, {"type": "integer", "minimum": 10}]},
+			"extra": {}
+		},
+		"$defs": {
+			"address": {
+				"type": "object",
+				"properties": {"street": {"type": "string"}, "zip_code": {"type": "string"}},
+				"required": ["street"]
+			},
+			"cat": {"type": "object", "properties": {"meow": {"type": "boolean"}}},
+			"dog": {"allOf": [{"$ref": "#/$defs/animal"}], "properties": {"bark": {"type": "boolean"}}},
+			"animal": {"properties": {"name": {"type": "string"}}}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("https://example.com/person.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	g := codegen.NewGenerator("model")
+	if name := g.Add("", sch); name != "Person" {
+		t.Fatalf("name: got %q, want %q", name, "Person")
+	}
+	b, err := g.Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(b)
+
+	for _, want := range []string{
+		"FirstName string            `json:\"first_name\"`",
+		"ID        int64             `json:\"id\"`",
+		"Nick      *string           `json:\"nick,omitempty\"`",
+		"Friends   []Person          `json:\"friends,omitempty\"`",
+		"Labels    map[string]string `json:\"labels,omitempty\"`",
+		"Score     *int64            `json:\"score,omitempty\"`",
+		"Extra   interface{}  `json:\"extra,omitempty\"`",
+		"Address *Address     `json:\"address,omitempty\"`",
+		"Street  string  `json:\"street\"`",
+		"PersonColorDarkBlue PersonColor = \"dark-blue\"",
+		"// Value holds one of: Cat, Dog.",
+		"Name *string `json:\"name,omitempty\"`", // from allOf
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source does not contain %q", want)
+		}
+	}
+	if t.Failed() {
+		t.Log(src)
+	}
+
+	// generated source must type-check
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "model.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("model", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeneratorNames(t *testing.T) {
+	sch, err := jsonschema.CompileString("https://example.com/item-list.json", `{
+		"type": "array",
+		"items": {"$ref": "#/$defs/item"},
+		"$defs": {
+			"item": {"type": "object", "properties": {"2fa": {"type": "boolean"}, "url": {"type": "string"}}}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	g := codegen.NewGenerator("model")
+	g.Add("", sch)
+	if name := g.Add("Item", sch); name != "ItemList" {
+		t.Fatalf("adding same schema again: got %q, want %q", name, "ItemList")
+	}
+	b, err := g.Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(b)
+	for _, want := range []string{
+		"type ItemList []Item",
+		"X2fa *bool   `json:\"2fa,omitempty\"`",
+		"URL  *string `json:\"url,omitempty\"`",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source does not contain %q\n%s", want, src)
+		}
+	}
+}
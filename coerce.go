@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"encoding/json"
+)
+
+// ValidateAndCoerce converts string values in v to the type expected by
+// the schema, and validates the resulting instance. This is useful to
+// validate query parameters and form data, where every value arrives as
+// string.
+//
+// v is not modified. The coerced instance is returned even when
+// validation fails.
+//
+// A value is coerced only if its type is not allowed by "type" keyword
+// of the schemas applied on it:
+//   - string with json number such as "42" is converted to json.Number,
+//     if "integer" or "number" is expected.
+//   - "true" and "false" are converted to bool, if "boolean" is expected.
+//   - "null" is converted to nil, if "null" is expected.
+//   - any other value is wrapped in single item array, if "array" is
+//     expected. The items of the array are then coerced.
+//
+// Like Schema.Prune, expected types are collected from all subschemas
+// applicable on the value, including "anyOf", "oneOf" and conditional
+// keywords.
+func (s *Schema) ValidateAndCoerce(v interface{}) (interface{}, error) {
+	v = coerce(deepCopy(v), []*Schema{s}, false)
+	return v, s.Validate(v)
+}
+
+// ValidateAndCoerceNumbers is like ValidateAndCoerce, but only string
+// values with json number are converted, if "integer" or "number" is
+// expected. This is useful for encodings such as proto-JSON, where
+// 64-bit integers are sent as strings.
+func (s *Schema) ValidateAndCoerceNumbers(v interface{}) (interface{}, error) {
+	v = coerce(deepCopy(v), []*Schema{s}, true)
+	return v, s.Validate(v)
+}
+
+// coerce returns v converted to the type expected by any of schemas.
+// objects and arrays are coerced in place. If numbersOnly is true,
+// only strings with json number are converted.
+func coerce(v interface{}, schemas []*Schema, numbersOnly bool) interface{} {
+	schemas = applicable(schemas)
+	var types []string
+	for _, s := range schemas {
+		types = append(types, s.Types...)
+	}
+	if len(types) > 0 && !typeAllows(types, v) {
+		v = coerceType(v, types, numbersOnly)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for pname, pvalue := range v {
+			var matched []*Schema
+			for _, s := range schemas {
+				sch, _ := s.propertySchemas(pname)
+				matched = append(matched, sch...)
+			}
+			v[pname] = coerce(pvalue, matched, numbersOnly)
+		}
+	case []interface{}:
+		for i, item := range v {
+			var matched []*Schema
+			for _, s := range schemas {
+				if sch := s.itemSchema(i); sch != nil {
+					matched = append(matched, sch)
+				}
+			}
+			v[i] = coerce(item, matched, numbersOnly)
+		}
+	}
+	return v
+}
+
+// coerceType converts v to one of types. v is returned as is
+// if it cannot be converted.
+func coerceType(v interface{}, types []string, numbersOnly bool) interface{} {
+	expects := make(map[string]bool, len(types))
+	for _, t := range types {
+		expects[t] = true
+	}
+	if s, ok := v.(string); ok {
+		if (expects["integer"] || expects["number"]) && isJSONNumber(s) {
+			n := json.Number(s)
+			if r := ratValue(n); r != nil && (expects["number"] || r.IsInt()) {
+				return n
+			}
+		}
+		if numbersOnly {
+			return v
+		}
+		switch {
+		case expects["boolean"] && (s == "true" || s == "false"):
+			return s == "true"
+		case expects["null"] && s == "null":
+			return nil
+		}
+	}
+	if expects["array"] && !numbersOnly {
+		return []interface{}{v}
+	}
+	return v
+}
+
+// isJSONNumber tells whether s is a number in json syntax.
+func isJSONNumber(s string) bool {
+	if s == "" || !(s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) {
+		return false
+	}
+	if c := s[len(s)-1]; c < '0' || c > '9' {
+		return false
+	}
+	var n json.Number
+	return json.Unmarshal([]byte(s), &n) == nil
+}
@@ -0,0 +1,149 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_ValidateAndCoerce(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"price": {"type": "number", "minimum": 0},
+			"active": {"type": "boolean"},
+			"parent": {"type": ["integer", "null"]},
+			"name": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"ids": {"type": "array", "items": {"$ref": "#/properties/id"}},
+			"any": {}
+		},
+		"additionalProperties": {"anyOf": [{"type": "boolean"}, {"type": "integer"}]}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  map[string]interface{}
+		valid bool
+	}{
+		{
+			name:  "integer",
+			input: map[string]interface{}{"id": "42"},
+			want:  map[string]interface{}{"id": json.Number("42")},
+			valid: true,
+		},
+		{
+			name:  "not integer",
+			input: map[string]interface{}{"id": "4.5"},
+			want:  map[string]interface{}{"id": "4.5"},
+		},
+		{
+			name:  "number",
+			input: map[string]interface{}{"price": "-1.5e2"},
+			want:  map[string]interface{}{"price": json.Number("-1.5e2")},
+		},
+		{
+			name:  "non json number",
+			input: map[string]interface{}{"price": "0x10"},
+			want:  map[string]interface{}{"price": "0x10"},
+		},
+		{
+			name:  "boolean",
+			input: map[string]interface{}{"active": "false"},
+			want:  map[string]interface{}{"active": false},
+			valid: true,
+		},
+		{
+			name:  "null",
+			input: map[string]interface{}{"parent": "null"},
+			want:  map[string]interface{}{"parent": nil},
+			valid: true,
+		},
+		{
+			name:  "string",
+			input: map[string]interface{}{"name": "42"},
+			want:  map[string]interface{}{"name": "42"},
+			valid: true,
+		},
+		{
+			name:  "single value to array",
+			input: map[string]interface{}{"tags": "a", "ids": "1"},
+			want:  map[string]interface{}{"tags": []interface{}{"a"}, "ids": []interface{}{json.Number("1")}},
+			valid: true,
+		},
+		{
+			name:  "array items",
+			input: map[string]interface{}{"ids": []interface{}{"1", "2"}},
+			want:  map[string]interface{}{"ids": []interface{}{json.Number("1"), json.Number("2")}},
+			valid: true,
+		},
+		{
+			name:  "no type",
+			input: map[string]interface{}{"any": "1"},
+			want:  map[string]interface{}{"any": "1"},
+			valid: true,
+		},
+		{
+			name:  "anyOf",
+			input: map[string]interface{}{"x": "true", "y": "7"},
+			want:  map[string]interface{}{"x": true, "y": json.Number("7")},
+			valid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := sch.ValidateAndCoerce(test.input)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+			if test.valid && err != nil {
+				t.Errorf("want valid, got %v", err)
+			} else if !test.valid && err == nil {
+				t.Error("want invalid")
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateAndCoerceUnmodified(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{"properties": {"a": {"type": "integer"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := map[string]interface{}{"a": "1"}
+	if _, err := sch.ValidateAndCoerce(input); err != nil {
+		t.Fatal(err)
+	}
+	if input["a"] != "1" {
+		t.Fatal("input is modified")
+	}
+}
+
+func TestSchema_ValidateAndCoerceNumbers(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"properties": {
+			"id": {"type": "integer"},
+			"active": {"type": "boolean"},
+			"tags": {"type": "array"}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := map[string]interface{}{"id": "9007199254740993", "active": "true", "tags": "a"}
+	got, err := sch.ValidateAndCoerceNumbers(input)
+	want := map[string]interface{}{"id": json.Number("9007199254740993"), "active": "true", "tags": "a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+	if err == nil {
+		t.Error("want invalid")
+	}
+}
@@ -0,0 +1,564 @@
+// Package compat compares two versions of a compiled json-schema, and
+// reports whether the new version is compatible with the old one, similar
+// to the compatibility rules of schema registries. It can be used in CI to
+// gate schema changes.
+//
+// Typical usage:
+//
+//	report := compat.Compare(oldSchema, newSchema)
+//	if breaking := report.Breaking(compat.Backward); len(breaking) > 0 {
+//		fmt.Print(breaking)
+//		os.Exit(1)
+//	}
+//
+// Schemas are compared keyword by keyword, through "properties",
+// "additionalProperties", "items", "prefixItems" and "$ref". Keywords like
+// "pattern" or "anyOf" are compared for equality only, and any change in
+// them is reported as Changed. Hence the report is conservative: a change
+// may be reported even if it is actually compatible.
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/jsonvalue"
+)
+
+// Effect tells how a change affects the set of valid instances.
+type Effect int
+
+const (
+	// Narrowed means the new schema rejects some instances which are
+	// valid as per old schema, e.g. new required property. It breaks
+	// backward compatibility.
+	Narrowed Effect = iota + 1
+
+	// Widened means the new schema allows some instances which are
+	// invalid as per old schema, e.g. new enum value. It breaks
+	// forward compatibility.
+	Widened
+
+	// Changed means both Narrowed and Widened. It breaks both backward
+	// and forward compatibility.
+	Changed
+)
+
+func (e Effect) String() string {
+	switch e {
+	case Narrowed:
+		return "narrowed"
+	case Widened:
+		return "widened"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("Effect(%d)", int(e))
+}
+
+// Mode is the kind of compatibility to check.
+type Mode int
+
+const (
+	// Backward compatibility means instances valid as per old schema are
+	// valid as per new schema, i.e. readers using new schema can read data
+	// written using old schema.
+	Backward Mode = iota
+
+	// Forward compatibility means instances valid as per new schema are
+	// valid as per old schema, i.e. readers using old schema can read data
+	// written using new schema.
+	Forward
+
+	// Full compatibility means both Backward and Forward compatibility.
+	Full
+)
+
+// Change is a difference found between old and new schema.
+type Change struct {
+	// Path is json-pointer to instance location affected by the change.
+	// "*" matches any array item or any additional property.
+	Path    string
+	Keyword string // keyword that is changed.
+	Effect  Effect
+	Message string
+}
+
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Effect, path, c.Message)
+}
+
+// Report is the list of changes found, sorted by path.
+type Report []Change
+
+// Breaking returns the changes which break compatibility of given mode.
+func (r Report) Breaking(mode Mode) Report {
+	var breaking Report
+	for _, c := range r {
+		switch {
+		case c.Effect == Changed, mode == Full,
+			mode == Backward && c.Effect == Narrowed,
+			mode == Forward && c.Effect == Widened:
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// Compatible tells whether the new schema is compatible with old schema
+// as per given mode.
+func (r Report) Compatible(mode Mode) bool {
+	return len(r.Breaking(mode)) == 0
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	for _, c := range r {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Compare compares old and new versions of schema.
+func Compare(old, new *jsonschema.Schema) Report {
+	cmp := &comparer{visited: make(map[[2]*jsonschema.Schema]bool)}
+	cmp.compare("", old, new)
+	sort.SliceStable(cmp.report, func(i, j int) bool {
+		return cmp.report[i].Path < cmp.report[j].Path
+	})
+	return cmp.report
+}
+
+type comparer struct {
+	visited map[[2]*jsonschema.Schema]bool
+	report  Report
+}
+
+func (cmp *comparer) add(path, keyword string, effect Effect, format string, a ...interface{}) {
+	cmp.report = append(cmp.report, Change{
+		Path:    path,
+		Keyword: keyword,
+		Effect:  effect,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (cmp *comparer) compare(path string, old, new *jsonschema.Schema) {
+	if old == nil || new == nil {
+		return
+	}
+	pair := [2]*jsonschema.Schema{old, new}
+	if cmp.visited[pair] {
+		return
+	}
+	cmp.visited[pair] = true
+
+	switch {
+	case old.Ref != nil && new.Ref == nil:
+		cmp.compare(path, old.Ref, new)
+		return
+	case old.Ref == nil && new.Ref != nil:
+		cmp.compare(path, old, new.Ref)
+		return
+	case old.Ref != nil && new.Ref != nil:
+		cmp.compare(path, old.Ref, new.Ref)
+	}
+
+	switch {
+	case isFalse(old) && isFalse(new):
+		return
+	case isFalse(old):
+		cmp.add(path, "", Widened, "false schema is replaced")
+		return
+	case isFalse(new):
+		cmp.add(path, "", Narrowed, "schema is replaced with false schema")
+		return
+	}
+	if old.Always != nil {
+		old = anySchema
+	}
+	if new.Always != nil {
+		new = anySchema
+	}
+
+	cmp.compareTypes(path, old, new)
+	cmp.compareValues(path, "enum", old.Enum, new.Enum)
+	var oldConst, newConst []interface{}
+	if len(old.Constant) > 0 {
+		oldConst = old.Constant[:1]
+	}
+	if len(new.Constant) > 0 {
+		newConst = new.Constant[:1]
+	}
+	cmp.compareValues(path, "const", oldConst, newConst)
+	cmp.compareLimits(path, old, new)
+	cmp.compareObject(path, old, new)
+	cmp.compareArray(path, old, new)
+
+	// keywords compared for equality only
+	if old.Format != new.Format {
+		cmp.add(path, "format", Changed, "format changed from %q to %q", old.Format, new.Format)
+	}
+	if regexString(old.Pattern) != regexString(new.Pattern) {
+		cmp.add(path, "pattern", Changed, "pattern changed from %q to %q", regexString(old.Pattern), regexString(new.Pattern))
+	}
+	for _, applicator := range []struct {
+		kw       string
+		old, new []*jsonschema.Schema
+	}{
+		{"allOf", old.AllOf, new.AllOf},
+		{"anyOf", old.AnyOf, new.AnyOf},
+		{"oneOf", old.OneOf, new.OneOf},
+	} {
+		if len(applicator.old) != len(applicator.new) {
+			cmp.add(path, applicator.kw, Changed, "%s changed from %d to %d subschemas", applicator.kw, len(applicator.old), len(applicator.new))
+			continue
+		}
+		for i := range applicator.old {
+			cmp.compare(path, applicator.old[i], applicator.new[i])
+		}
+	}
+	if (old.Not == nil) != (new.Not == nil) {
+		cmp.add(path, "not", Changed, "not is added or removed")
+	}
+	if (old.If == nil) != (new.If == nil) {
+		cmp.add(path, "if", Changed, "if is added or removed")
+	}
+}
+
+// types returns set of types allowed by sch. nil means all types.
+func types(sch *jsonschema.Schema) map[string]bool {
+	if len(sch.Types) == 0 {
+		return nil
+	}
+	m := make(map[string]bool)
+	for _, t := range sch.Types {
+		m[t] = true
+	}
+	return m
+}
+
+func (cmp *comparer) compareTypes(path string, old, new *jsonschema.Schema) {
+	oldTypes, newTypes := types(old), types(new)
+	allows := func(set map[string]bool, t string) bool {
+		return set == nil || set[t] || (t == "integer" && set["number"])
+	}
+	var removed, added bool
+	for t := range oldTypes {
+		removed = removed || !allows(newTypes, t)
+	}
+	for t := range newTypes {
+		added = added || !allows(oldTypes, t)
+	}
+	switch {
+	case oldTypes != nil && newTypes == nil:
+		cmp.add(path, "type", Widened, "type %v is removed", old.Types)
+		return
+	case oldTypes == nil && newTypes != nil:
+		cmp.add(path, "type", Narrowed, "type %v is added", new.Types)
+		return
+	}
+	switch {
+	case removed && added:
+		cmp.add(path, "type", Changed, "type changed from %v to %v", old.Types, new.Types)
+	case removed:
+		cmp.add(path, "type", Narrowed, "type changed from %v to %v", old.Types, new.Types)
+	case added:
+		cmp.add(path, "type", Widened, "type changed from %v to %v", old.Types, new.Types)
+	}
+}
+
+// compareValues compares values of enum or const. nil means any value.
+func (cmp *comparer) compareValues(path, kw string, old, new []interface{}) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		cmp.add(path, kw, Narrowed, "%s is added", kw)
+		return
+	case new == nil:
+		cmp.add(path, kw, Widened, "%s is removed", kw)
+		return
+	}
+	contains := func(values []interface{}, v interface{}) bool {
+		for _, e := range values {
+			if jsonvalue.Equal(e, v) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range old {
+		if !contains(new, v) {
+			cmp.add(path, kw, Narrowed, "%s value %s is removed", kw, jsonString(v))
+		}
+	}
+	for _, v := range new {
+		if !contains(old, v) {
+			cmp.add(path, kw, Widened, "%s value %s is added", kw, jsonString(v))
+		}
+	}
+}
+
+func (cmp *comparer) compareLimits(path string, old, new *jsonschema.Schema) {
+	// -1 means not specified
+	intLimit := func(kw string, old, new int, lower bool) {
+		if old == new {
+			return
+		}
+		narrowed := old == -1 || (new != -1 && (new > old) == lower)
+		switch {
+		case old == -1:
+			cmp.add(path, kw, Narrowed, "%s %d is added", kw, new)
+		case new == -1:
+			cmp.add(path, kw, Widened, "%s %d is removed", kw, old)
+		case narrowed:
+			cmp.add(path, kw, Narrowed, "%s changed from %d to %d", kw, old, new)
+		default:
+			cmp.add(path, kw, Widened, "%s changed from %d to %d", kw, old, new)
+		}
+	}
+	intLimit("minLength", old.MinLength, new.MinLength, true)
+	intLimit("maxLength", old.MaxLength, new.MaxLength, false)
+	intLimit("minItems", old.MinItems, new.MinItems, true)
+	intLimit("maxItems", old.MaxItems, new.MaxItems, false)
+	intLimit("minProperties", old.MinProperties, new.MinProperties, true)
+	intLimit("maxProperties", old.MaxProperties, new.MaxProperties, false)
+
+	ratLimit := func(kw string, old, new *big.Rat, lower bool) {
+		switch {
+		case old == nil && new == nil:
+		case old == nil:
+			cmp.add(path, kw, Narrowed, "%s %s is added", kw, new.RatString())
+		case new == nil:
+			cmp.add(path, kw, Widened, "%s %s is removed", kw, old.RatString())
+		case old.Cmp(new) != 0:
+			if (new.Cmp(old) > 0) == lower {
+				cmp.add(path, kw, Narrowed, "%s changed from %s to %s", kw, old.RatString(), new.RatString())
+			} else {
+				cmp.add(path, kw, Widened, "%s changed from %s to %s", kw, old.RatString(), new.RatString())
+			}
+		}
+	}
+	ratLimit("minimum", old.Minimum, new.Minimum, true)
+	ratLimit("exclusiveMinimum", old.ExclusiveMinimum, new.ExclusiveMinimum, true)
+	ratLimit("maximum", old.Maximum, new.Maximum, false)
+	ratLimit("exclusiveMaximum", old.ExclusiveMaximum, new.ExclusiveMaximum, false)
+
+	if old.MultipleOf != nil && new.MultipleOf != nil {
+		if old.MultipleOf.Cmp(new.MultipleOf) != 0 {
+			// new is narrower, if it is multiple of old
+			switch {
+			case quo(new.MultipleOf, old.MultipleOf).IsInt():
+				cmp.add(path, "multipleOf", Narrowed, "multipleOf changed from %s to %s", old.MultipleOf.RatString(), new.MultipleOf.RatString())
+			case quo(old.MultipleOf, new.MultipleOf).IsInt():
+				cmp.add(path, "multipleOf", Widened, "multipleOf changed from %s to %s", old.MultipleOf.RatString(), new.MultipleOf.RatString())
+			default:
+				cmp.add(path, "multipleOf", Changed, "multipleOf changed from %s to %s", old.MultipleOf.RatString(), new.MultipleOf.RatString())
+			}
+		}
+	} else {
+		ratLimit("multipleOf", old.MultipleOf, new.MultipleOf, true)
+	}
+}
+
+func (cmp *comparer) compareObject(path string, old, new *jsonschema.Schema) {
+	oldRequired, newRequired := stringSet(old.Required), stringSet(new.Required)
+	for _, pname := range new.Required {
+		if !oldRequired[pname] {
+			cmp.add(path, "required", Narrowed, "property %q is now required", pname)
+		}
+	}
+	for _, pname := range old.Required {
+		if !newRequired[pname] {
+			cmp.add(path, "required", Widened, "property %q is no longer required", pname)
+		}
+	}
+
+	for _, pname := range propertyNames(old, new) {
+		ppath := path + "/" + escape(pname)
+		oldProp, newProp := additional(old, pname), additional(new, pname)
+		switch {
+		case oldProp == nil && newProp == nil:
+			continue
+		case oldProp == nil:
+			cmp.add(ppath, "properties", Widened, "property %q is now allowed", pname)
+		case newProp == nil:
+			cmp.add(ppath, "properties", Narrowed, "property %q is no longer allowed", pname)
+		default:
+			cmp.compare(ppath, oldProp, newProp)
+		}
+	}
+
+	oldAdditional, newAdditional := additional(old, ""), additional(new, "")
+	switch {
+	case oldAdditional != nil && newAdditional == nil:
+		cmp.add(path+"/*", "additionalProperties", Narrowed, "additional properties are no longer allowed")
+	case oldAdditional == nil && newAdditional != nil:
+		cmp.add(path+"/*", "additionalProperties", Widened, "additional properties are now allowed")
+	default:
+		cmp.compare(path+"/*", oldAdditional, newAdditional)
+	}
+}
+
+// additional returns the schema applicable to property pname of sch.
+// if pname is empty, it returns schema for additional properties.
+// returns nil if the property is not allowed.
+func additional(sch *jsonschema.Schema, pname string) *jsonschema.Schema {
+	if pname != "" {
+		if prop, ok := sch.Properties[pname]; ok {
+			if isFalse(prop) {
+				return nil
+			}
+			return prop
+		}
+		for pattern, prop := range sch.PatternProperties {
+			if pattern.MatchString(pname) {
+				return prop
+			}
+		}
+	}
+	switch v := sch.AdditionalProperties.(type) {
+	case bool:
+		if !v {
+			return nil
+		}
+	case *jsonschema.Schema:
+		if isFalse(v) {
+			return nil
+		}
+		return v
+	}
+	return anySchema
+}
+
+var anySchema = &jsonschema.Schema{MinLength: -1, MaxLength: -1, MinItems: -1, MaxItems: -1, MinProperties: -1, MaxProperties: -1}
+
+func (cmp *comparer) compareArray(path string, old, new *jsonschema.Schema) {
+	oldPrefix, newPrefix := prefixItems(old), prefixItems(new)
+	n := len(oldPrefix)
+	if len(newPrefix) > n {
+		n = len(newPrefix)
+	}
+	for i := 0; i < n; i++ {
+		cmp.compareItem(fmt.Sprintf("%s/%d", path, i), itemSchema(old, i), itemSchema(new, i))
+	}
+	cmp.compareItem(path+"/*", itemSchema(old, -1), itemSchema(new, -1))
+	if old.UniqueItems != new.UniqueItems {
+		if new.UniqueItems {
+			cmp.add(path, "uniqueItems", Narrowed, "items must now be unique")
+		} else {
+			cmp.add(path, "uniqueItems", Widened, "items need no longer be unique")
+		}
+	}
+}
+
+func (cmp *comparer) compareItem(path string, old, new *jsonschema.Schema) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		cmp.add(path, "items", Widened, "items are now allowed")
+	case new == nil:
+		cmp.add(path, "items", Narrowed, "items are no longer allowed")
+	default:
+		cmp.compare(path, old, new)
+	}
+}
+
+func prefixItems(sch *jsonschema.Schema) []*jsonschema.Schema {
+	if sch.PrefixItems != nil {
+		return sch.PrefixItems
+	}
+	items, _ := sch.Items.([]*jsonschema.Schema)
+	return items
+}
+
+// itemSchema returns the schema for array item at index i of sch.
+// if i is -1, it returns schema for items after prefix items.
+// returns nil if the item is not allowed.
+func itemSchema(sch *jsonschema.Schema, i int) *jsonschema.Schema {
+	if prefix := prefixItems(sch); i >= 0 && i < len(prefix) {
+		return prefix[i]
+	}
+	var rest interface{}
+	switch {
+	case sch.Items2020 != nil:
+		rest = sch.Items2020
+	case sch.PrefixItems == nil:
+		if items, ok := sch.Items.(*jsonschema.Schema); ok {
+			rest = items
+		} else if sch.Items != nil {
+			rest = sch.AdditionalItems
+		}
+	}
+	switch v := rest.(type) {
+	case bool:
+		if !v {
+			return nil
+		}
+	case *jsonschema.Schema:
+		if isFalse(v) {
+			return nil
+		}
+		return v
+	}
+	return anySchema
+}
+
+// propertyNames returns sorted names of properties in old or new.
+func propertyNames(old, new *jsonschema.Schema) []string {
+	set := make(map[string]bool)
+	for pname := range old.Properties {
+		set[pname] = true
+	}
+	for pname := range new.Properties {
+		set[pname] = true
+	}
+	names := make([]string, 0, len(set))
+	for pname := range set {
+		names = append(names, pname)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func quo(x, y *big.Rat) *big.Rat {
+	return new(big.Rat).Quo(x, y)
+}
+
+func isFalse(sch *jsonschema.Schema) bool {
+	return sch.Always != nil && !*sch.Always
+}
+
+func regexString(re jsonschema.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
+
+func stringSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		set[s] = true
+	}
+	return set
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
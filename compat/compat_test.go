@@ -0,0 +1,132 @@
+package compat_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/compat"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{
+			name: "same",
+			old:  `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`,
+			new:  `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`,
+		},
+		{
+			name: "new required property",
+			old:  `{"properties": {"a": {}, "b": {}}, "required": ["a"]}`,
+			new:  `{"properties": {"a": {}, "b": {}}, "required": ["a", "b"]}`,
+			want: []string{`narrowed: /: property "b" is now required`},
+		},
+		{
+			name: "removed required property",
+			old:  `{"required": ["a"]}`,
+			new:  `{}`,
+			want: []string{`widened: /: property "a" is no longer required`},
+		},
+		{
+			name: "narrowed type",
+			old:  `{"properties": {"a": {"type": ["string", "number"]}}}`,
+			new:  `{"properties": {"a": {"type": "string"}}}`,
+			want: []string{`narrowed: /a: type changed from [string number] to [string]`},
+		},
+		{
+			name: "integer to number",
+			old:  `{"type": "integer"}`,
+			new:  `{"type": "number"}`,
+			want: []string{`widened: /: type changed from [integer] to [number]`},
+		},
+		{
+			name: "enum",
+			old:  `{"enum": ["a", "b"]}`,
+			new:  `{"enum": ["b", "c"]}`,
+			want: []string{
+				`narrowed: /: enum value "a" is removed`,
+				`widened: /: enum value "c" is added`,
+			},
+		},
+		{
+			name: "limits",
+			old:  `{"items": {"maxLength": 10, "minimum": 1}}`,
+			new:  `{"items": {"maxLength": 5, "minimum": 0}}`,
+			want: []string{
+				`narrowed: /*: maxLength changed from 10 to 5`,
+				`widened: /*: minimum changed from 1 to 0`,
+			},
+		},
+		{
+			name: "additionalProperties",
+			old:  `{"properties": {"a": {}}}`,
+			new:  `{"properties": {"a": {}}, "additionalProperties": false}`,
+			want: []string{`narrowed: /*: additional properties are no longer allowed`},
+		},
+		{
+			name: "property removed with additionalProperties false",
+			old:  `{"properties": {"a": {}, "b": {}}, "additionalProperties": false}`,
+			new:  `{"properties": {"a": {}}, "additionalProperties": false}`,
+			want: []string{`narrowed: /b: property "b" is no longer allowed`},
+		},
+		{
+			name: "property added",
+			old:  `{"properties": {"a": {}}}`,
+			new:  `{"properties": {"a": {}, "b": {"type": "string"}}}`,
+			want: []string{`narrowed: /b: type [string] is added`},
+		},
+		{
+			name: "ref",
+			old:  `{"properties": {"a": {"$ref": "#/$defs/a"}}, "$defs": {"a": {"type": "string"}}}`,
+			new:  `{"properties": {"a": {"type": "string", "pattern": "^x"}}}`,
+			want: []string{`changed: /a: pattern changed from "" to "^x"`},
+		},
+		{
+			name: "recursive",
+			old:  `{"$defs": {"node": {"properties": {"next": {"$ref": "#/$defs/node"}}}}, "$ref": "#/$defs/node"}`,
+			new:  `{"$defs": {"node": {"properties": {"next": {"$ref": "#/$defs/node"}}, "required": ["next"]}}, "$ref": "#/$defs/node"}`,
+			want: []string{`narrowed: /: property "next" is now required`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			old, err := jsonschema.CompileString("https://example.com/old.json", test.old)
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			new, err := jsonschema.CompileString("https://example.com/new.json", test.new)
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			var got []string
+			for _, c := range compat.Compare(old, new) {
+				got = append(got, c.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestReport_Compatible(t *testing.T) {
+	old := jsonschema.MustCompileString("https://example.com/old.json", `{"enum": ["a", "b"]}`)
+	new := jsonschema.MustCompileString("https://example.com/new.json", `{"enum": ["a", "b", "c"]}`)
+	report := compat.Compare(old, new)
+	if !report.Compatible(compat.Backward) {
+		t.Error("adding enum value must be backward compatible")
+	}
+	if report.Compatible(compat.Forward) {
+		t.Error("adding enum value must not be forward compatible")
+	}
+	if report.Compatible(compat.Full) {
+		t.Error("adding enum value must not be fully compatible")
+	}
+	if got := len(report.Breaking(compat.Forward)); got != 1 {
+		t.Errorf("got %d forward breaking changes, want 1", got)
+	}
+}
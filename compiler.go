@@ -1,17 +1,36 @@
 package jsonschema
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/big"
-	"regexp"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // A Compiler represents a json-schema compiler.
+//
+// A Compiler is safe for concurrent use by multiple goroutines: resources
+// can be added, and schemas can be compiled concurrently. Compiled schemas
+// are cached in compiler, so compiling the same url again returns the same
+// *Schema without recompiling, and without waiting for other compilations.
+// Compilations do not wait for documents being loaded by other
+// compilations, and concurrent loads of the same url are shared; only the
+// compilation steps in between loads are serialized. The exported fields
+// must be configured before the compiler is used concurrently.
 type Compiler struct {
+	mu       sync.Mutex              // guards resources, extensions, regexps, fetches, loads and ongoing compilation
+	compiled sync.Map                // schemas compiled by Compile, keyed by absolute url. read without mu
+	loads    map[string]*pendingLoad // loads in progress, keyed by url
+
 	// Draft represents the draft used when '$schema' attribute is missing.
 	//
 	// This defaults to latest supported draft (currently 2020-12).
@@ -27,22 +46,98 @@ type Compiler struct {
 
 	// LoadURL loads the document at given absolute URL.
 	//
-	// If nil, package global LoadURL is used.
+	// If nil, package global LoadURL is used. Setting this restricts
+	// the urls this compiler can load, irrespective of the loaders
+	// registered in package global Loaders (e.g. by httploader).
 	LoadURL func(s string) (io.ReadCloser, error)
 
+	// LoadURLContext is like LoadURL, but is passed the context of ongoing
+	// compilation, so that loading can be cancelled by CompileContext.
+	// If set, it is used instead of LoadURL.
+	LoadURLContext func(ctx context.Context, s string) (io.ReadCloser, error)
+
+	// Offline tells compiler to never load http or https urls. Referring
+	// remote url which is neither added as resource nor found in fs added
+	// by AddRemoteFS, results in compilation error.
+	Offline bool
+	remotes []fs.FS
+
 	// CompileRegex comples given regular expression.
-	// Defaults to golang's regexp implementation.
+	// Defaults to golang's regexp implementation, with ECMA-262 syntax
+	// "\uXXXX" and "(?<name>...)" translated to go syntax.
+	//
+	// JSON Schema uses ECMA-262 regular expressions. For lookarounds and
+	// backreferences, which golang's regexp does not support, an ECMA-262
+	// engine like github.com/dlclark/regexp2 can be plugged in:
+	//
+	//	type ecmaRegexp regexp2.Regexp
+	//
+	//	func (re *ecmaRegexp) MatchString(s string) bool {
+	//		matched, err := (*regexp2.Regexp)(re).MatchString(s)
+	//		return err == nil && matched
+	//	}
+	//
+	//	func (re *ecmaRegexp) String() string {
+	//		return (*regexp2.Regexp)(re).String()
+	//	}
+	//
+	//	compiler.CompileRegex = func(s string) (jsonschema.Regexp, error) {
+	//		re, err := regexp2.Compile(s, regexp2.ECMAScript)
+	//		return (*ecmaRegexp)(re), err
+	//	}
 	//
 	// NOTE: If you are overriding this, also ensure to override "regex" Format.
+	//
+	// Compiled regular expressions are shared by all schemas compiled by
+	// this compiler, so each distinct pattern is compiled only once. Hence
+	// this must not be changed after the compiler is used.
 	CompileRegex func(s string) (Regexp, error)
+	regexps      map[string]Regexp // compiled regular expressions, keyed by pattern
+
+	// StrictRegex tells whether to fail compilation if "pattern" or
+	// "patternProperties" uses syntax which is not valid ECMA-262,
+	// such as inline flags, "\A" or POSIX character classes.
+	StrictRegex bool
+
+	// Strict tells compiler to fail on schemas which are valid, but are
+	// likely mistakes: unknown keywords, keywords next to $ref which are
+	// ignored before draft2019, keywords like "then" without "if", enum or
+	// const values not allowed by "type", and lower limits like "minimum"
+	// greater than upper limits like "maximum".
+	Strict bool
+
+	// BackportKeywords tells compiler to honor "if", "then" and "else" in
+	// draft4 and draft6, and "dependentSchemas" and "dependentRequired"
+	// before draft2019, where they are otherwise ignored as unknown keywords.
+	// This lets schemas which must stay on older drafts, use these keywords.
+	//
+	// Since meta-schemas of these drafts do not describe the backported
+	// keywords, their values are checked by compiler. Note that "$id" and
+	// anchors within backported subschemas are not recognized.
+	BackportKeywords bool
 
 	// Formats can be registered by adding to this map. Key is format name,
 	// value is function that knows how to validate that format.
 	Formats map[string]func(interface{}) bool
 
-	// AssertFormat for specifications >= draft2019-09.
+	// AssertFormat tells whether to assert "format" in draft2019-09 or
+	// above, where it is only an annotation, unless the format-assertion
+	// vocabulary is enabled by $vocabulary of meta-schema. It can be used
+	// for compatibility with earlier drafts, where "format" is asserted.
 	AssertFormat bool
 
+	// DisallowUnknownFormats tells whether to fail compilation if schema
+	// uses format which is neither in Formats nor in package global Formats.
+	// This is applicable only when format is asserted.
+	DisallowUnknownFormats bool
+
+	// LenientFormats tells which built-in formats are validated with the
+	// relaxed checks of earlier versions, instead of spec-accurate ones.
+	// Key is format name. Applicable formats are "hostname", "email",
+	// "uri", "iri", "uri-reference", "iri-reference" and "uri-template".
+	// For example, lenient "uri" allows non-ascii characters.
+	LenientFormats map[string]bool
+
 	// Decoders can be registered by adding to this map. Key is encoding name,
 	// value is function that knows how to decode string in that format.
 	Decoders map[string]func(string) ([]byte, error)
@@ -53,6 +148,91 @@ type Compiler struct {
 
 	// AssertContent for specifications >= draft2019-09.
 	AssertContent bool
+
+	// AllowData enables $data references for specifications >= draft6.
+	//
+	// With this, value of keywords const, enum, minimum, maximum,
+	// exclusiveMinimum, exclusiveMaximum, multipleOf, minLength, maxLength,
+	// minItems, maxItems, minProperties and maxProperties can be of form
+	// {"$data": "pointer"}, where pointer is json-pointer or relative json-pointer
+	// into the instance being validated. If the pointer does not refer to any
+	// value, the keyword is ignored.
+	AllowData bool
+
+	// AllowErrorMessage enables "errorMessage" keyword, to override the
+	// messages of validation errors reported by the schema.
+	//
+	// Value of errorMessage is either string, which replaces all errors of
+	// the schema with single error, or object mapping keyword to message.
+	// In object form, "properties" and "required" can be object mapping
+	// property name to message, and "_" is the message for all other errors.
+	// Messages can use placeholders ${value} and ${limit}, which are replaced
+	// by the offending value and the keyword value respectively.
+	AllowErrorMessage bool
+
+	// AllowDiscriminator enables OpenAPI style "discriminator" keyword, which
+	// selects the oneOf subschema to validate with, using value of a property.
+	//
+	// For example {"discriminator": {"propertyName": "kind", "mapping": {"dog": "#/$defs/dog"}}}.
+	// If mapping is missing for a value, it is mapped to the oneOf subschema
+	// which allows that value via "const" or "enum" of the property, or to
+	// the $ref subschema whose name is that value. If the instance is an
+	// object without the property or with unmapped value, validation fails.
+	AllowDiscriminator bool
+
+	// Translator, if set, renders the messages of validation errors reported
+	// by schemas compiled with this compiler. For example to localize them,
+	// or to use product-specific phrasing.
+	//
+	// It is called for each ValidationError in the error tree, with Message
+	// set to the default message, and returns the message to be used.
+	// ValidationError.KeywordError tells the keyword that failed, along with
+	// its values.
+	Translator func(ve *ValidationError) string
+
+	// ErrorValue, if set, captures the offending instance value of each
+	// validation error into ValidationError.Value. It is called with the
+	// error, whose locations and Message are populated, and the instance value
+	// at its InstanceLocation; the returned value is captured. It can be used
+	// to truncate large values, or to redact sensitive values.
+	//
+	// TruncateValue returns ErrorValue which captures values as json text,
+	// truncated to given length.
+	ErrorValue func(ve *ValidationError, v interface{}) interface{}
+
+	// Severities changes the severity of failures of keywords. Failures of
+	// keywords with SeverityWarning do not fail validation, they are
+	// reported as warnings by Schema.ValidateWithWarnings. This lets new
+	// constraints be advisory, during migrations.
+	//
+	// Key is "format", "deprecated", or name of extension registered using
+	// RegisterExtension. Other keys fail compilation. "format" and
+	// "deprecated" are asserted if they are in this map, even in drafts
+	// where they are annotations: "deprecated": true reports any value it
+	// applies to.
+	Severities map[string]Severity
+
+	// Limits bounds the resources consumed in compiling and validating
+	// schemas. See Limits.
+	Limits  Limits
+	fetches int // number of documents loaded using LoadURL
+
+	// CollectErrors tells compiler to continue after a problem in schema,
+	// such as invalid regex, unknown format or unresolved $ref, so that all
+	// problems are reported at once. If more than one problem is found, the
+	// returned *SchemaError wraps CompileErrors.
+	//
+	// Note that a schema that is not valid against its meta-schema is not
+	// compiled any further, but the ValidationError lists all its violations.
+	CollectErrors bool
+	errs          []error          // errors collected during ongoing compilation
+	created       []*resource      // resources whose schema is created by ongoing compilation
+	pending       []string         // urls to be loaded, before ongoing compilation is retried
+	failed        map[string]error // errors in loading urls, by ongoing compilation
+	patterns      int              // number of regular expressions compiled by ongoing compilation
+	warnings      []Warning        // see Warnings
+
+	ctx context.Context // context of ongoing compilation. nil if not compiling.
 }
 
 // Compile parses json-schema at given url returns, if successful,
@@ -93,12 +273,12 @@ func MustCompileString(url, schema string) *Schema {
 // behavior change Compiler.Draft value
 func NewCompiler() *Compiler {
 	return &Compiler{
-		Draft:     latest,
-		resources: make(map[string]*resource),
-		Formats:   make(map[string]func(interface{}) bool),
+		Draft:          latest,
+		resources:      make(map[string]*resource),
+		Formats:        make(map[string]func(interface{}) bool),
+		LenientFormats: make(map[string]bool),
 		CompileRegex: func(s string) (Regexp, error) {
-			re, err := regexp.Compile(s)
-			return (*goRegexp)(re), err
+			return compileGoRegexp(s)
 		},
 		Decoders:   make(map[string]func(string) ([]byte, error)),
 		MediaTypes: make(map[string]func([]byte) error),
@@ -110,8 +290,11 @@ func NewCompiler() *Compiler {
 //
 // Note that url must not have fragment
 func (c *Compiler) AddResource(url string, r io.Reader) error {
-	doc, err := unmarshal(r)
+	doc, err := unmarshal(c.limitReader(url, r))
 	if err != nil {
+		if le, ok := err.(*LimitExceededError); ok {
+			return le
+		}
 		return fmt.Errorf("jsonschema: invalid json %s: %v", url, err)
 	}
 	return c.AddResourceJSON(url, doc)
@@ -119,11 +302,199 @@ func (c *Compiler) AddResource(url string, r io.Reader) error {
 
 // AddResourceJSON adds in-memory resource from given json value.
 func (c *Compiler) AddResourceJSON(url string, doc interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addResource(url, doc)
+}
+
+// AddResources adds given in-memory resources, keyed by url. This
+// can be used to register pre-fetched remote schemas.
+func (c *Compiler) AddResources(resources map[string][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for url, b := range resources {
+		if err := c.checkSchemaSize(url, b); err != nil {
+			return err
+		}
+		doc, err := unmarshal(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("jsonschema: invalid json %s: %v", url, err)
+		}
+		if err := c.addResource(url, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddResourceFS adds the files in fsys whose path matches glob, as
+// in-memory resources. glob is a pattern as in path.Match, where "**"
+// matches zero or more directories. For example "schemas/**/*.json".
+// If glob is empty, all files with ".json" extension are added.
+//
+// The file at path "schemas/person.json" is added with the url
+// "schemas/person.json", which is resolved against current directory as
+// in Compile. Hence the relative references between files work, and the
+// file can be compiled using its path:
+//
+//	//go:embed schemas
+//	var schemas embed.FS
+//
+//	if err := compiler.AddResourceFS(schemas, "schemas/**/*.json"); err != nil {
+//		return err
+//	}
+//	sch, err := compiler.Compile("schemas/person.json")
+//
+// Files whose root schema has absolute "$id", are also added with that url,
+// so that they can be referred by "$id" from other files.
+func (c *Compiler) AddResourceFS(fsys fs.FS, glob string) error {
+	if glob == "" {
+		glob = "**/*.json"
+	}
+	if _, err := path.Match(strings.ReplaceAll(glob, "**", "*"), ""); err != nil {
+		return fmt.Errorf("jsonschema: invalid glob %q: %v", glob, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !matchGlob(strings.Split(glob, "/"), strings.Split(p, "/")) {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		if err := c.checkSchemaSize(p, b); err != nil {
+			return err
+		}
+		doc, err := unmarshal(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("jsonschema: invalid json %s: %v", p, err)
+		}
+		if err := c.addResource(p, doc); err != nil {
+			return err
+		}
+		if m, ok := doc.(map[string]interface{}); ok {
+			for _, kw := range []string{"$id", "id"} {
+				if id, ok := m[kw].(string); ok {
+					if isURILenient(id) {
+						id, _ = split(id)
+						if _, ok := c.resources[id]; !ok {
+							if err := c.addResource(id, doc); err != nil {
+								return err
+							}
+						}
+					}
+					break
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// matchGlob tells whether path segments match glob segments, where "**"
+// matches zero or more segments.
+func matchGlob(glob, segments []string) bool {
+	if len(glob) == 0 {
+		return len(segments) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(glob[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(glob[0], segments[0]); !ok {
+		return false
+	}
+	return matchGlob(glob[1:], segments[1:])
+}
+
+// AddRemoteFS registers fsys containing pre-fetched remote schemas.
+// The file at path "example.com/schemas/a.json" in fsys is used when
+// "https://example.com/schemas/a.json" or "http://example.com/schemas/a.json"
+// is referred. The query string of url is ignored.
+//
+// This can be used with embed.FS, to compile schemas without network access:
+//
+//	//go:embed remote
+//	var remote embed.FS
+//
+//	sub, _ := fs.Sub(remote, "remote")
+//	compiler.AddRemoteFS(sub)
+//	compiler.Offline = true
+func (c *Compiler) AddRemoteFS(fsys fs.FS) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remotes = append(c.remotes, fsys)
+}
+
+// ResourceInfo describes a resource known to Compiler. See Compiler.Resources.
+type ResourceInfo struct {
+	// URL is the absolute url, the resource is added or loaded with.
+	// relative file paths and file urls with "../" are resolved.
+	URL string
+
+	// ID is the base uri of the resource, i.e. URL resolved with its "$id".
+	// It is empty if the resource is not yet used by any compilation.
+	ID string
+
+	// Draft of the resource. It is nil if ID is empty.
+	Draft *Draft
+
+	// Embedded lists the base uris established by "$id" of subschemas
+	// in the resource found so far, in sorted order.
+	Embedded []string
+}
+
+// Resources returns all resources added to or loaded by the compiler,
+// sorted by URL. This is useful in debugging reference resolution
+// problems, by showing the absolute url each relative reference must
+// resolve to.
+func (c *Compiler) Resources() []ResourceInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var infos []ResourceInfo
+	for url, r := range c.resources {
+		info := ResourceInfo{URL: url}
+		if r.draft != nil {
+			info.ID, info.Draft = r.url, r.draft
+			for _, sr := range r.subresources {
+				if sr.url != "" && sr.url != r.url {
+					info.Embedded = append(info.Embedded, sr.url)
+				}
+			}
+			sort.Strings(info.Embedded)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].URL < infos[j].URL })
+	return infos
+}
+
+func (c *Compiler) addResource(url string, doc interface{}) error {
 	res, err := newResource(url, doc)
 	if err != nil {
 		return err
 	}
+	_, replaced := c.resources[res.url]
 	c.resources[res.url] = res
+	if replaced {
+		// schemas compiled earlier may refer to the resource replaced
+		c.compiled.Range(func(url, _ interface{}) bool {
+			c.compiled.Delete(url)
+			return true
+		})
+	}
 	return nil
 }
 
@@ -142,6 +513,57 @@ func (c *Compiler) MustCompile(url string) *Schema {
 //
 // error returned will be of type *SchemaError
 func (c *Compiler) Compile(url string) (*Schema, error) {
+	return c.CompileContext(context.Background(), url)
+}
+
+// CompileRef compiles the subschema addressed by ref, which is resolved
+// against the schema at url, like "$ref" in that schema. ref is typically
+// a fragment such as "#/properties/address" or "#address", so that a
+// subtree of an instance, like the one changed by a PATCH request, can be
+// validated on its own. The instance locations in validation errors are
+// then relative to that subtree.
+//
+// error returned will be of type *SchemaError
+func (c *Compiler) CompileRef(url, ref string) (*Schema, error) {
+	return c.compileContext(context.Background(), url, func(url string) (*Schema, error) {
+		b, _ := split(url)
+		r, err := c.findResource(b)
+		if err != nil {
+			return nil, err
+		}
+		return c.compileRef(r, nil, "#", r, ref)
+	})
+}
+
+// CompileContext is like Compile, but compilation is aborted when ctx
+// is done. The ctx is checked before loading each resource and while
+// validating schemas against meta-schema. Resources being loaded using
+// LoadURLContext are cancelled, by passing ctx to it.
+//
+// On abort, returned *SchemaError wraps ctx.Err().
+func (c *Compiler) CompileContext(ctx context.Context, url string) (*Schema, error) {
+	u, err := toAbs(url)
+	if err == nil {
+		if sch, ok := c.compiled.Load(u); ok {
+			return sch.(*Schema), nil
+		}
+	}
+	sch, err := c.compileContext(ctx, url, func(url string) (*Schema, error) {
+		return c.compileURL(url, nil, "#")
+	})
+	if err == nil {
+		c.compiled.Store(u, sch)
+	}
+	return sch, err
+}
+
+// compileContext calls compile with absolute url, and reports errors.
+//
+// compile does not load resources. It records the urls to be loaded in
+// c.pending and fails, so that they are loaded without holding c.mu, and
+// compile is retried. Thus a compilation never observes schemas partially
+// compiled by another compilation.
+func (c *Compiler) compileContext(ctx context.Context, url string, compile func(url string) (*Schema, error)) (*Schema, error) {
 	// make url absolute
 	u, err := toAbs(url)
 	if err != nil {
@@ -149,32 +571,85 @@ func (c *Compiler) Compile(url string) (*Schema, error) {
 	}
 	url = u
 
-	sch, err := c.compileURL(url, nil, "#")
-	if err != nil {
-		err = &SchemaError{url, err}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for kw := range c.Severities {
+		if _, ok := c.extensions[kw]; !ok && kw != "format" && kw != "deprecated" {
+			return nil, &SchemaError{url, fmt.Errorf("jsonschema: severity of keyword %q cannot be changed", kw)}
+		}
+	}
+
+	defer func() { c.failed, c.patterns = nil, 0 }()
+	for {
+		sch, err := c.compileOnce(ctx, url, compile)
+		pending := c.pending
+		if len(pending) == 0 {
+			return sch, err
+		}
+		c.pending = nil
+		for _, u := range pending {
+			if err := c.loadResource(ctx, u); err != nil {
+				if c.failed == nil {
+					c.failed = make(map[string]error)
+				}
+				c.failed[u] = err
+			}
+		}
 	}
-	return sch, err
+}
+
+// compileOnce calls compile with absolute url, and reports errors.
+func (c *Compiler) compileOnce(ctx context.Context, url string, compile func(url string) (*Schema, error)) (*Schema, error) {
+	c.ctx = ctx
+	defer func() { c.ctx, c.errs, c.created = nil, nil, nil }()
+	nwarnings := len(c.warnings)
+	sch, err := compile(url)
+	if errs := c.errs; len(errs) > 0 {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(errs) == 1 {
+			err = errs[0]
+		} else {
+			sort.SliceStable(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+			err = CompileErrors(errs)
+		}
+	}
+	if err != nil || len(c.pending) > 0 {
+		// discard partially compiled schemas, so that they are not
+		// returned by subsequent compilations.
+		for _, r := range c.created {
+			r.schema = nil
+		}
+		c.warnings = c.warnings[:nwarnings]
+		return nil, &SchemaError{url, err}
+	}
+	if !sch.untracked && !sch.needsTracking() {
+		sch.untracked = true
+	}
+	return sch, nil
 }
 
 func (c *Compiler) findResource(url string) (*resource, error) {
 	if _, ok := c.resources[url]; !ok {
-		// load resource
-		var rdr io.Reader
 		if sch, ok := vocabSchemas[url]; ok {
-			rdr = strings.NewReader(sch)
-		} else {
-			loadURL := LoadURL
-			if c.LoadURL != nil {
-				loadURL = c.LoadURL
-			}
-			r, err := loadURL(url)
+			doc, err := unmarshal(strings.NewReader(sch))
 			if err != nil {
 				return nil, err
 			}
-			defer r.Close()
-			rdr = r
-		}
-		if err := c.AddResource(url, rdr); err != nil {
+			if err := c.addResource(url, doc); err != nil {
+				return nil, err
+			}
+		} else if err := c.failed[url]; err != nil {
+			return nil, err
+		} else if c.ctx != nil {
+			// load after this attempt of ongoing compilation, see compileContext
+			if err := c.ctx.Err(); err != nil {
+				return nil, err
+			}
+			c.pending = append(c.pending, url)
+			return nil, errPending
+		} else if err := c.loadResource(c.context(), url); err != nil {
 			return nil, err
 		}
 	}
@@ -185,7 +660,7 @@ func (c *Compiler) findResource(url string) (*resource, error) {
 	}
 
 	// set draft
-	r.draft = c.Draft
+	draft := c.Draft
 	if m, ok := r.doc.(map[string]interface{}); ok {
 		if sch, ok := m["$schema"]; ok {
 			sch, ok := sch.(string)
@@ -195,8 +670,8 @@ func (c *Compiler) findResource(url string) (*resource, error) {
 			if !isURI(sch) {
 				return nil, fmt.Errorf("jsonschema: $schema must be uri in %s", url)
 			}
-			r.draft = findDraft(sch)
-			if r.draft == nil {
+			draft = findDraft(sch)
+			if draft == nil {
 				sch, _ := split(sch)
 				if sch == url {
 					return nil, fmt.Errorf("jsonschema: unsupported draft in %s", url)
@@ -205,10 +680,11 @@ func (c *Compiler) findResource(url string) (*resource, error) {
 				if err != nil {
 					return nil, err
 				}
-				r.draft = mr.draft
+				draft = mr.draft
 			}
 		}
 	}
+	r.draft = draft
 
 	id, err := r.draft.resolveID(r.url, r.doc)
 	if err != nil {
@@ -225,6 +701,87 @@ func (c *Compiler) findResource(url string) (*resource, error) {
 	return r, nil
 }
 
+// errPending is returned by findResource, if resource is to be loaded
+// before ongoing compilation can proceed. see compileContext.
+var errPending = errors.New("jsonschema: resource is not loaded yet")
+
+// pendingLoad is a load in progress, shared by compilations loading same url.
+type pendingLoad struct {
+	done     chan struct{} // closed when load is completed
+	err      error
+	canceled bool // whether context of compilation loading it is done
+}
+
+// loadResource loads the resource at url and adds it to c.resources.
+// c.mu must be held. It is released while loading and parsing the
+// resource. If url is being loaded by another compilation, it waits
+// for that load instead.
+func (c *Compiler) loadResource(ctx context.Context, url string) error {
+	for {
+		if _, ok := c.resources[url]; ok {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if l, ok := c.loads[url]; ok {
+			c.mu.Unlock()
+			select {
+			case <-l.done:
+			case <-ctx.Done():
+			}
+			c.mu.Lock()
+			if l.canceled {
+				continue // cancelled by other compilation. load again, with ctx
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if l.err != nil {
+				return l.err
+			}
+			continue
+		}
+
+		l := &pendingLoad{done: make(chan struct{})}
+		if c.loads == nil {
+			c.loads = make(map[string]*pendingLoad)
+		}
+		c.loads[url] = l
+		l.err = c.loadResourceDoc(ctx, url)
+		l.canceled = l.err != nil && ctx.Err() != nil
+		delete(c.loads, url)
+		close(l.done)
+		return l.err
+	}
+}
+
+// loadResourceDoc is used by loadResource to load the resource at url.
+func (c *Compiler) loadResourceDoc(ctx context.Context, url string) error {
+	r, err := c.load(ctx, url)
+	if err != nil {
+		return &loadError{url, err}
+	}
+	c.mu.Unlock()
+	doc, err := unmarshal(c.limitReader(url, r))
+	r.Close()
+	c.mu.Lock()
+	if err != nil {
+		if le, ok := err.(*LimitExceededError); ok {
+			return le
+		}
+		return fmt.Errorf("jsonschema: invalid json %s: %v", url, err)
+	}
+	if _, ok := c.resources[url]; ok {
+		return nil // added meanwhile
+	}
+	if err := c.addResource(url, doc); err != nil {
+		return err
+	}
+	c.resources[url].loaded = true
+	return nil
+}
+
 func (c *Compiler) compileURL(url string, stack []schemaRef, ptr string) (*Schema, error) {
 	// if url points to a draft, return Draft.meta
 	if d := findDraft(url); d != nil && d.meta != nil {
@@ -241,48 +798,76 @@ func (c *Compiler) compileURL(url string, stack []schemaRef, ptr string) (*Schem
 
 func (c *Compiler) compileRef(r *resource, stack []schemaRef, refPtr string, res *resource, ref string) (*Schema, error) {
 	base := r.baseURL(res.floc)
+	rawRef := ref
 	ref, err := resolveURL(base, ref)
 	if err != nil {
 		return nil, err
 	}
+	refR, refRes := r, res // r and res are changed for external resource
+	refErr := func(err error, searched []string, suggestion string) error {
+		re := &RefError{
+			URL:        ref,
+			Searched:   searched,
+			Suggestion: suggestion,
+			Err:        err,
+		}
+		if refPtr != "#" {
+			re.Keyword, re.Ref, re.Location, re.BaseURIs = refPtr, rawRef, refR.url+refRes.floc, refR.baseURIs(refRes.floc)
+		}
+		return re
+	}
 
 	u, f := split(ref)
 	sr := r.findResource(u)
 	if sr == nil {
 		// external resource
-		return c.compileURL(ref, stack, refPtr)
+		if d := findDraft(ref); d != nil && d.meta != nil {
+			return d.meta, nil
+		}
+		er, err := c.findResource(u)
+		if err != nil {
+			if le, ok := err.(*loadError); ok && le.url == u {
+				return nil, refErr(le.err, []string{u}, c.suggestURL(u))
+			}
+			return nil, err
+		}
+		r, sr = er, er
 	}
 
 	// ensure root resource is always compiled first.
 	// this is required to get schema.meta from root resource
 	if r.schema == nil {
 		r.schema = newSchema(r.url, r.floc, r.draft, r.doc)
-		if _, err := c.compile(r, nil, schemaRef{"#", r.schema, false}, r); err != nil {
+		c.created = append(c.created, r)
+		if _, err := c.compile(r, nil, schemaRef{path: "#", schema: r.schema}, r); err != nil {
 			return nil, err
 		}
 	}
 
+	fsr := sr
 	sr, err = r.resolveFragment(c, sr, f)
 	if err != nil {
 		return nil, err
 	}
 	if sr == nil {
-		return nil, fmt.Errorf("jsonschema: %s not found", ref)
+		searched, suggestion := r.lookupFailure(fsr, f)
+		return nil, refErr(nil, searched, suggestion)
 	}
 
 	if sr.schema != nil {
-		if err := checkLoop(stack, schemaRef{refPtr, sr.schema, false}); err != nil {
+		if err := checkLoop(stack, schemaRef{path: refPtr, schema: sr.schema}); err != nil {
 			return nil, err
 		}
 		return sr.schema, nil
 	}
 
 	sr.schema = newSchema(r.url, sr.floc, r.draft, sr.doc)
-	return c.compile(r, stack, schemaRef{refPtr, sr.schema, false}, sr)
+	c.created = append(c.created, sr)
+	return c.compile(r, stack, schemaRef{path: refPtr, schema: sr.schema}, sr)
 }
 
 func (c *Compiler) compileDynamicAnchors(r *resource, res *resource) error {
-	if r.draft.version < 2020 {
+	if r.draft.version < 2020 || !r.hasDynamicAnchors() {
 		return nil
 	}
 
@@ -303,6 +888,10 @@ func (c *Compiler) compileDynamicAnchors(r *resource, res *resource) error {
 }
 
 func (c *Compiler) compile(r *resource, stack []schemaRef, sref schemaRef, res *resource) (*Schema, error) {
+	res.schema.translator = c.Translator
+	res.schema.errorValue = c.ErrorValue
+	res.schema.maxDepth = c.Limits.MaxValidationDepth
+	res.schema.severities = c.Severities
 	if err := c.compileDynamicAnchors(r, res); err != nil {
 		return nil, err
 	}
@@ -312,7 +901,11 @@ func (c *Compiler) compile(r *resource, stack []schemaRef, sref schemaRef, res *
 		res.schema.Always = &v
 		return res.schema, nil
 	default:
-		return res.schema, c.compileMap(r, stack, sref, res)
+		if err := c.compileMap(r, stack, sref, res); err != nil {
+			return res.schema, err
+		}
+		res.schema.empty = len(v.(map[string]interface{})) == 0 && len(res.schema.Extensions) == 0
+		return res.schema, nil
 	}
 }
 
@@ -322,27 +915,62 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 	if err := checkLoop(stack, sref); err != nil {
 		return err
 	}
+	if len(stack) >= maxNesting {
+		return fmt.Errorf("jsonschema: schema nesting exceeds %d levels in %s", maxNesting, res)
+	}
+	if max := c.Limits.MaxRefDepth; max > 0 && isRefKeyword(sref.path) && refDepth(stack) >= max {
+		return &LimitExceededError{Limit: "MaxRefDepth", Max: max, URL: res.schema.Location}
+	}
 	stack = append(stack, sref)
 
 	var s = res.schema
 	var err error
 
+	if c.AllowData && r.draft.version >= 6 {
+		m, s.data = extractData(m)
+	}
+
+	if c.Strict {
+		if err := c.checkStrict(r, res, m); err != nil {
+			return err
+		}
+	}
+	c.checkDeprecated(r, res, m)
+	if c.BackportKeywords {
+		if err := checkBackported(r, res, m); err != nil {
+			if c.abort(err) {
+				return err
+			}
+			m = withoutBackported(r.draft, m)
+		}
+	}
+
 	if r == res { // root schema
 		if sch, ok := m["$schema"]; ok {
 			sch := sch.(string)
 			if d := findDraft(sch); d != nil {
 				s.meta = d.meta
 			} else {
-				if s.meta, err = c.compileRef(r, stack, "$schema", res, sch); err != nil {
+				if s.meta, err = c.compileRef(r, stack, "$schema", res, sch); c.abort(err) {
 					return err
 				}
+				// custom meta-schema, such as one extending a draft
+				// using $recursiveAnchor or $dynamicAnchor
+				if s.meta != nil {
+					if err := c.validateMeta(r, s.meta); c.abort(err) {
+						return err
+					}
+				}
 			}
+		} else {
+			// vocabularies of draft are enabled
+			s.meta = r.draft.meta
 		}
 	}
 
 	if ref, ok := m["$ref"]; ok {
 		s.Ref, err = c.compileRef(r, stack, "$ref", res, ref.(string))
-		if err != nil {
+		if c.abort(err) {
 			return err
 		}
 		if r.draft.version < 2019 {
@@ -355,12 +983,16 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 		if r == res { // root schema
 			if vocab, ok := m["$vocabulary"]; ok {
 				for url, reqd := range vocab.(map[string]interface{}) {
-					if reqd, ok := reqd.(bool); ok && !reqd {
+					if !r.draft.isVocab(url) && !c.isVocab(url) {
+						if reqd, ok := reqd.(bool); ok && !reqd {
+							// optional vocab, which is not supported
+							continue
+						}
+						if err := fmt.Errorf("jsonschema: unsupported vocab %q in %s", url, res); c.abort(err) {
+							return err
+						}
 						continue
 					}
-					if !r.draft.isVocab(url) {
-						return fmt.Errorf("jsonschema: unsupported vocab %q in %s", url, res)
-					}
 					s.vocab = append(s.vocab, url)
 				}
 			} else {
@@ -368,9 +1000,10 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 			}
 		}
 
-		if ref, ok := m["$recursiveRef"]; ok {
+		// $recursiveRef is replaced by $dynamicRef in draft2020
+		if ref, ok := m["$recursiveRef"]; ok && r.draft.version == 2019 {
 			s.RecursiveRef, err = c.compileRef(r, stack, "$recursiveRef", res, ref.(string))
-			if err != nil {
+			if c.abort(err) {
 				return err
 			}
 		}
@@ -378,7 +1011,7 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 	if r.draft.version >= 2020 {
 		if dref, ok := m["$dynamicRef"]; ok {
 			s.DynamicRef, err = c.compileRef(r, stack, "$dynamicRef", res, dref.(string))
-			if err != nil {
+			if c.abort(err) {
 				return err
 			}
 			if dref, ok := dref.(string); ok {
@@ -416,28 +1049,15 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 				s.Types = toStrings(t)
 			}
 		}
+		if r.draft == OpenAPI30 && len(s.Types) > 0 {
+			if nullable, ok := m["nullable"].(bool); ok && nullable && !contains(s.Types, "null") {
+				s.Types = append(s.Types, "null")
+			}
+		}
 
 		if e, ok := m["enum"]; ok {
 			s.Enum = e.([]interface{})
-			allPrimitives := true
-			for _, item := range s.Enum {
-				switch jsonType(item) {
-				case "object", "array":
-					allPrimitives = false
-				}
-			}
-			s.enumError = "enum failed"
-			if allPrimitives {
-				if len(s.Enum) == 1 {
-					s.enumError = fmt.Sprintf("value must be %#v", s.Enum[0])
-				} else {
-					strEnum := make([]string, len(s.Enum))
-					for i, item := range s.Enum {
-						strEnum[i] = fmt.Sprintf("%#v", item)
-					}
-					s.enumError = fmt.Sprintf("value must be one of %s", strings.Join(strEnum, ", "))
-				}
-			}
+			s.enumError = enumError(s.Enum)
 		}
 
 		s.Minimum = loadRat("minimum")
@@ -479,10 +1099,8 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 		s.MinLength, s.MaxLength = loadInt("minLength"), loadInt("maxLength")
 
 		if pattern, ok := m["pattern"]; ok {
-			var err error
-			s.Pattern, err = c.CompileRegex(pattern.(string))
-			if err != nil {
-				panic("regex Format and compiler.CompileRegex are incompatible")
+			if s.Pattern, err = c.compileRegex(res, pattern.(string)); c.abort(err) {
+				return err
 			}
 		}
 
@@ -491,7 +1109,8 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 			if s.MinContains == -1 {
 				s.MinContains = 1
 			}
-
+		}
+		if r.draft.version >= 2019 || c.BackportKeywords {
 			if deps, ok := m["dependentRequired"]; ok {
 				deps := deps.(map[string]interface{})
 				s.DependentRequired = make(map[string][]string, len(deps))
@@ -519,7 +1138,7 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 			schemas := make([]*Schema, len(pvalue))
 			for i := range pvalue {
 				sch, err := compile(stack, escape(pname)+"/"+strconv.Itoa(i))
-				if err != nil {
+				if c.abort(err) {
 					return nil, err
 				}
 				schemas[i] = sch
@@ -530,25 +1149,32 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 	}
 
 	if r.draft.version < 2019 || r.schema.meta.hasVocab("applicator") {
-		if s.Not, err = loadSchema("not", stack); err != nil {
+		if s.Not, err = loadSchema("not", stack); c.abort(err) {
 			return err
 		}
-		if s.AllOf, err = loadSchemas("allOf", stack); err != nil {
+		if s.AllOf, err = loadSchemas("allOf", stack); c.abort(err) {
 			return err
 		}
-		if s.AnyOf, err = loadSchemas("anyOf", stack); err != nil {
+		if s.AnyOf, err = loadSchemas("anyOf", stack); c.abort(err) {
 			return err
 		}
-		if s.OneOf, err = loadSchemas("oneOf", stack); err != nil {
+		if s.OneOf, err = loadSchemas("oneOf", stack); c.abort(err) {
 			return err
 		}
+		if c.AllowDiscriminator || r.draft.openapi {
+			if d, ok := m["discriminator"]; ok {
+				if s.discriminator, err = c.compileDiscriminator(r, res, s, d); c.abort(err) {
+					return err
+				}
+			}
+		}
 
 		if props, ok := m["properties"]; ok {
 			props := props.(map[string]interface{})
 			s.Properties = make(map[string]*Schema, len(props))
 			for pname := range props {
 				s.Properties[pname], err = compile(nil, "properties/"+escape(pname))
-				if err != nil {
+				if c.abort(err) {
 					return err
 				}
 			}
@@ -562,8 +1188,15 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 			patternProps := patternProps.(map[string]interface{})
 			s.PatternProperties = make(map[Regexp]*Schema, len(patternProps))
 			for pattern := range patternProps {
-				s.PatternProperties[regexp.MustCompile(pattern)], err = compile(nil, "patternProperties/"+escape(pattern))
+				re, err := c.compileRegex(res, pattern)
 				if err != nil {
+					if c.abort(err) {
+						return err
+					}
+					continue
+				}
+				s.PatternProperties[re], err = compile(nil, "patternProperties/"+escape(pattern))
+				if c.abort(err) {
 					return err
 				}
 			}
@@ -575,7 +1208,7 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 				s.AdditionalProperties = additionalProps
 			case map[string]interface{}:
 				s.AdditionalProperties, err = compile(nil, "additionalProperties")
-				if err != nil {
+				if c.abort(err) {
 					return err
 				}
 			}
@@ -590,7 +1223,7 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 					s.Dependencies[pname] = toStrings(pvalue)
 				default:
 					s.Dependencies[pname], err = compile(stack, "dependencies/"+escape(pname))
-					if err != nil {
+					if c.abort(err) {
 						return err
 					}
 				}
@@ -598,34 +1231,34 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 		}
 
 		if r.draft.version >= 6 {
-			if s.PropertyNames, err = loadSchema("propertyNames", nil); err != nil {
+			if s.PropertyNames, err = loadSchema("propertyNames", nil); c.abort(err) {
 				return err
 			}
-			if s.Contains, err = loadSchema("contains", nil); err != nil {
+			if s.Contains, err = loadSchema("contains", nil); c.abort(err) {
 				return err
 			}
 		}
 
-		if r.draft.version >= 7 {
+		if r.draft.version >= 7 || c.BackportKeywords {
 			if m["if"] != nil {
-				if s.If, err = loadSchema("if", stack); err != nil {
+				if s.If, err = loadSchema("if", stack); c.abort(err) {
 					return err
 				}
-				if s.Then, err = loadSchema("then", stack); err != nil {
+				if s.Then, err = loadSchema("then", stack); c.abort(err) {
 					return err
 				}
-				if s.Else, err = loadSchema("else", stack); err != nil {
+				if s.Else, err = loadSchema("else", stack); c.abort(err) {
 					return err
 				}
 			}
 		}
-		if r.draft.version >= 2019 {
+		if r.draft.version >= 2019 || c.BackportKeywords {
 			if deps, ok := m["dependentSchemas"]; ok {
 				deps := deps.(map[string]interface{})
 				s.DependentSchemas = make(map[string]*Schema, len(deps))
 				for pname := range deps {
 					s.DependentSchemas[pname], err = compile(stack, "dependentSchemas/"+escape(pname))
-					if err != nil {
+					if c.abort(err) {
 						return err
 					}
 				}
@@ -633,10 +1266,10 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 		}
 
 		if r.draft.version >= 2020 {
-			if s.PrefixItems, err = loadSchemas("prefixItems", nil); err != nil {
+			if s.PrefixItems, err = loadSchemas("prefixItems", nil); c.abort(err) {
 				return err
 			}
-			if s.Items2020, err = loadSchema("items", nil); err != nil {
+			if s.Items2020, err = loadSchema("items", nil); c.abort(err) {
 				return err
 			}
 		} else {
@@ -644,7 +1277,7 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 				switch items.(type) {
 				case []interface{}:
 					s.Items, err = loadSchemas("items", nil)
-					if err != nil {
+					if c.abort(err) {
 						return err
 					}
 					if additionalItems, ok := m["additionalItems"]; ok {
@@ -653,14 +1286,14 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 							s.AdditionalItems = additionalItems
 						case map[string]interface{}:
 							s.AdditionalItems, err = compile(nil, "additionalItems")
-							if err != nil {
+							if c.abort(err) {
 								return err
 							}
 						}
 					}
 				default:
 					s.Items, err = compile(nil, "items")
-					if err != nil {
+					if c.abort(err) {
 						return err
 					}
 				}
@@ -671,10 +1304,10 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 
 	// unevaluatedXXX keywords were in "applicator" vocab in 2019, but moved to new vocab "unevaluated" in 2020
 	if (r.draft.version == 2019 && r.schema.meta.hasVocab("applicator")) || (r.draft.version >= 2020 && r.schema.meta.hasVocab("unevaluated")) {
-		if s.UnevaluatedProperties, err = loadSchema("unevaluatedProperties", nil); err != nil {
+		if s.UnevaluatedProperties, err = loadSchema("unevaluatedProperties", nil); c.abort(err) {
 			return err
 		}
-		if s.UnevaluatedItems, err = loadSchema("unevaluatedItems", nil); err != nil {
+		if s.UnevaluatedItems, err = loadSchema("unevaluatedItems", nil); c.abort(err) {
 			return err
 		}
 		if r.draft.version >= 2020 {
@@ -685,11 +1318,13 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 
 	if format, ok := m["format"]; ok {
 		s.Format = format.(string)
-		if r.draft.version < 2019 || c.AssertFormat || r.schema.meta.hasVocab("format-assertion") {
-			if format, ok := c.Formats[s.Format]; ok {
-				s.format = format
-			} else {
-				s.format = Formats[s.Format]
+		_, severity := c.Severities["format"]
+		if r.draft.version < 2019 || c.AssertFormat || severity || r.schema.meta.hasVocab("format-assertion") {
+			s.format = c.lookupFormat(s.Format)
+			if s.format == nil && c.DisallowUnknownFormats {
+				if err := fmt.Errorf("jsonschema: unknown format %q in %s", s.Format, res); c.abort(err) {
+					return err
+				}
 			}
 		}
 	}
@@ -702,6 +1337,14 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 			s.Description = description.(string)
 		}
 		s.Default = m["default"]
+		for kw, v := range m {
+			if !r.draft.keywords[kw] && !(c.AllowErrorMessage && kw == "errorMessage") && !(c.AllowDiscriminator && kw == "discriminator") && !c.backported(r.draft, kw) {
+				if s.unknown == nil {
+					s.unknown = make(map[string]interface{})
+				}
+				s.unknown[kw] = v
+			}
+		}
 	}
 
 	if r.draft.version >= 6 {
@@ -713,20 +1356,12 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 	if r.draft.version >= 7 {
 		if encoding, ok := m["contentEncoding"]; ok {
 			s.ContentEncoding = encoding.(string)
-			if decoder, ok := c.Decoders[s.ContentEncoding]; ok {
-				s.decoder = decoder
-			} else {
-				s.decoder = Decoders[s.ContentEncoding]
-			}
+			s.decoder = c.lookupDecoder(s.ContentEncoding)
 		}
 		if mediaType, ok := m["contentMediaType"]; ok {
 			s.ContentMediaType = mediaType.(string)
-			if mediaType, ok := c.MediaTypes[s.ContentMediaType]; ok {
-				s.mediaType = mediaType
-			} else {
-				s.mediaType = MediaTypes[s.ContentMediaType]
-			}
-			if s.ContentSchema, err = loadSchema("contentSchema", stack); err != nil {
+			s.mediaType = c.lookupMediaType(s.ContentMediaType)
+			if s.ContentSchema, err = loadSchema("contentSchema", stack); c.abort(err) {
 				return err
 			}
 		}
@@ -752,16 +1387,59 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 			s.mediaType = nil
 			s.ContentSchema = nil
 		}
-		if c.ExtractAnnotations {
+		if _, severity := c.Severities["deprecated"]; c.ExtractAnnotations || severity {
 			if deprecated, ok := m["deprecated"]; ok {
 				s.Deprecated = deprecated.(bool)
 			}
 		}
 	}
 
+	if r.draft.openapi && c.ExtractAnnotations {
+		if example, ok := m["example"]; ok {
+			s.Examples = append(s.Examples, example)
+		}
+		if r.draft.version < 7 {
+			if readOnly, ok := m["readOnly"].(bool); ok {
+				s.ReadOnly = readOnly
+			}
+			if writeOnly, ok := m["writeOnly"].(bool); ok {
+				s.WriteOnly = writeOnly
+			}
+			if deprecated, ok := m["deprecated"].(bool); ok {
+				s.Deprecated = deprecated
+			}
+		}
+	}
+
+	if c.AllowErrorMessage {
+		if em, ok := m["errorMessage"]; ok {
+			if s.errorMessage, err = compileErrorMessage(em); err != nil {
+				if err := fmt.Errorf("jsonschema: invalid %v in %s", err, res); c.abort(err) {
+					return err
+				}
+			}
+		}
+	}
+
 	for name, ext := range c.extensions {
+		if ext.vocab != "" {
+			if r.draft.version < 2019 || !r.schema.meta.hasVocabURL(ext.vocab) {
+				continue
+			}
+			if ext.meta != nil {
+				vd := newValidator(c.context())
+				err := ext.meta.validateValue(vd, m, res.floc[1:])
+				vd.release()
+				if c.abort(err) {
+					return err
+				}
+				if err != nil {
+					continue
+				}
+			}
+		}
 		es, err := ext.compiler.Compile(CompilerContext{c, r, stack, res}, m)
-		if err != nil {
+		if c.abort(err) {
 			return err
 		}
 		if es != nil {
@@ -772,6 +1450,7 @@ func (c *Compiler) compileMap(r *resource, stack []schemaRef, sref schemaRef, re
 		}
 	}
 
+	s.sortKeys()
 	return nil
 }
 
@@ -780,13 +1459,29 @@ func (c *Compiler) validateSchema(r *resource, v interface{}, vloc string) error
 		if meta == nil {
 			return nil
 		}
-		return meta.validateValue(v, vloc)
+		vd := newValidator(c.context())
+		defer vd.release()
+		return meta.validateValue(vd, v, vloc)
+	}
+
+	if c.AllowData && r.draft.version >= 6 {
+		v = stripData(v)
 	}
 
+	if !r.draft.boolSchema {
+		// report clearly, rather than as meta-schema failure
+		if ptr, ok := r.draft.findBoolSchema(v, vloc); ok {
+			return fmt.Errorf("jsonschema: boolean schema is not allowed in %s, at %s#%s", r.draft, r.url, ptr)
+		}
+	}
 	if err := validate(r.draft.meta); err != nil {
 		return err
 	}
 	for _, ext := range c.extensions {
+		if ext.vocab != "" {
+			// validated in compileMap, if vocab is enabled
+			continue
+		}
 		if err := validate(ext.meta); err != nil {
 			return err
 		}
@@ -794,6 +1489,91 @@ func (c *Compiler) validateSchema(r *resource, v interface{}, vloc string) error
 	return nil
 }
 
+// validateMeta validates the document of root resource r against its
+// custom meta-schema meta. validation against the draft meta-schema
+// is done by validateSchema when the resource is loaded.
+func (c *Compiler) validateMeta(r *resource, meta *Schema) error {
+	var v = r.doc
+	if c.AllowData && r.draft.version >= 6 {
+		v = stripData(v)
+	}
+	vd := newValidator(c.context())
+	defer vd.release()
+	return meta.validateValue(vd, v, "")
+}
+
+// context returns the context of ongoing compilation.
+func (c *Compiler) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// suggestURL returns the url of resource known to compiler, which is
+// nearest to u. returns empty string if there is none.
+func (c *Compiler) suggestURL(u string) string {
+	var candidates []string
+	for url, r := range c.resources {
+		candidates = append(candidates, url)
+		for _, sr := range r.subresources {
+			if sr.url != "" {
+				candidates = append(candidates, sr.url)
+			}
+		}
+	}
+	return nearest(u, candidates)
+}
+
+// loadError is the error returned by findResource, when resource
+// cannot be loaded.
+type loadError struct {
+	url string
+	err error
+}
+
+func (e *loadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *loadError) Unwrap() error {
+	return e.err
+}
+
+// abort tells whether compilation must stop due to err.
+//
+// If CollectErrors is set, err is recorded and false is returned, so that
+// compilation continues with rest of the schema. Errors due to cancellation
+// of context are never recorded.
+func (c *Compiler) abort(err error) bool {
+	if err == nil {
+		return false
+	}
+	if !c.CollectErrors || c.context().Err() != nil {
+		return true
+	}
+	c.errs = append(c.errs, err)
+	return false
+}
+
+// enumError returns error message for enum failure.
+func enumError(enum []interface{}) string {
+	for _, item := range enum {
+		switch jsonType(item) {
+		case "object", "array":
+			return "enum failed"
+		}
+	}
+	if len(enum) == 1 {
+		return fmt.Sprintf("value must be %#v", enum[0])
+	}
+	strEnum := make([]string, len(enum))
+	for i, item := range enum {
+		strEnum[i] = fmt.Sprintf("%#v", item)
+	}
+	return fmt.Sprintf("value must be one of %s", strings.Join(strEnum, ", "))
+}
+
 func toStrings(arr []interface{}) []string {
 	s := make([]string, len(arr))
 	for i, v := range arr {
@@ -802,17 +1582,44 @@ func toStrings(arr []interface{}) []string {
 	return s
 }
 
+func contains(arr []string, s string) bool {
+	for _, item := range arr {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // SchemaRef captures schema and the path referring to it.
 type schemaRef struct {
 	path    string  // relative-json-pointer to schema
+	token   string  // appended to path, if not empty
 	schema  *Schema // target schema
 	discard bool    // true when scope left
+	vpath   string  // relative-json-pointer to value, used only in validation
+}
+
+// relPath returns relative-json-pointer to schema.
+//
+// path and token are kept separate, so that validation does not allocate
+// unless it needs the location.
+func (sr schemaRef) relPath() string {
+	if sr.token == "" {
+		return sr.path
+	}
+	return sr.path + "/" + sr.token
 }
 
 func (sr schemaRef) String() string {
-	return fmt.Sprintf("(%s)%v", sr.path, sr.schema)
+	return fmt.Sprintf("(%s)%v", sr.relPath(), sr.schema)
 }
 
+// maxNesting is the maximum depth of subschemas compiled for a schema.
+// it guards against stack overflow and quadratic validation cost of
+// pathological schemas.
+const maxNesting = 1000
+
 func checkLoop(stack []schemaRef, sref schemaRef) error {
 	for _, ref := range stack {
 		if ref.schema == sref.schema {
@@ -825,7 +1632,7 @@ func checkLoop(stack []schemaRef, sref schemaRef) error {
 func keywordLocation(stack []schemaRef, path string) string {
 	var loc string
 	for _, ref := range stack[1:] {
-		loc += "/" + ref.path
+		loc += "/" + ref.relPath()
 	}
 	if path != "" {
 		loc = loc + "/" + path
@@ -833,6 +1640,60 @@ func keywordLocation(stack []schemaRef, path string) string {
 	return loc
 }
 
+// lookupFormat returns the function validating format with given name.
+// nil if format is unknown.
+func (c *Compiler) lookupFormat(name string) func(interface{}) bool {
+	if format, ok := c.Formats[name]; ok {
+		return format
+	}
+	if format, ok := lenientFormats[name]; ok && c.LenientFormats[name] {
+		return format
+	}
+	return Formats[name]
+}
+
+// lookupDecoder returns the decoder of content encoding with given name.
+// nil if encoding is unknown.
+func (c *Compiler) lookupDecoder(name string) func(string) ([]byte, error) {
+	if decoder, ok := c.Decoders[name]; ok {
+		return decoder
+	}
+	return Decoders[name]
+}
+
+// lookupMediaType returns the function validating media type with given name.
+// nil if media type is unknown.
+func (c *Compiler) lookupMediaType(name string) func([]byte) error {
+	if mediaType, ok := c.MediaTypes[name]; ok {
+		return mediaType
+	}
+	return MediaTypes[name]
+}
+
+func (c *Compiler) compileRegex(res *resource, pattern string) (Regexp, error) {
+	if c.StrictRegex {
+		if err := checkECMARegex(pattern); err != nil {
+			return nil, fmt.Errorf("jsonschema: invalid regex %q in %s: %v", pattern, res, err)
+		}
+	}
+	if re, ok := c.regexps[pattern]; ok {
+		return re, nil
+	}
+	if max := c.Limits.MaxPatterns; max > 0 && c.patterns >= max {
+		return nil, &LimitExceededError{Limit: "MaxPatterns", Max: max, URL: res.String()}
+	}
+	c.patterns++
+	re, err := c.CompileRegex(pattern)
+	if err != nil {
+		panic("regex Format and compiler.CompileRegex are incompatible")
+	}
+	if c.regexps == nil {
+		c.regexps = make(map[string]Regexp)
+	}
+	c.regexps[pattern] = re
+	return re, nil
+}
+
 // Regexp --
 
 // Regexp is the representation of a compiled regular expression.
@@ -844,13 +1705,3 @@ type Regexp interface {
 	// String returns the source text used to compile the regular expression.
 	String() string
 }
-
-type goRegexp regexp.Regexp
-
-func (re *goRegexp) MatchString(s string) bool {
-	return (*regexp.Regexp)(re).MatchString(s)
-}
-
-func (re *goRegexp) String() string {
-	return (*regexp.Regexp)(re).String()
-}
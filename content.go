@@ -1,7 +1,9 @@
 package jsonschema
 
 import (
+	"encoding/base32"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 )
 
@@ -11,6 +13,8 @@ import (
 // New Decoders can be registered by adding to this map. Key is encoding name,
 // value is function that knows how to decode string in that format.
 var Decoders = map[string]func(string) ([]byte, error){
+	"base16": hex.DecodeString,
+	"base32": base32.StdEncoding.DecodeString,
 	"base64": base64.StdEncoding.DecodeString,
 }
 
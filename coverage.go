@@ -0,0 +1,135 @@
+package jsonschema
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Coverage records which branches of a schema are exercised by instances,
+// to find the parts of schema not covered by a corpus of test instances.
+//
+// The branches recorded are:
+//   - each subschema of oneOf and anyOf, covered when an instance is valid against it
+//   - then and else, covered when they are evaluated
+//   - each value of enum, covered when an instance equal to it is valid against the schema
+//
+// Coverage implements Hook, and is safe for concurrent use.
+type Coverage struct {
+	schema *Schema
+
+	mu       sync.Mutex
+	branches map[coverageKey]string // branch to its location
+	covered  map[string]bool        // location to whether covered
+}
+
+type coverageKey struct {
+	keyword string
+	schema  *Schema
+}
+
+// NewCoverage returns Coverage for the branches of s, and the schemas
+// reachable from s.
+func NewCoverage(s *Schema) *Coverage {
+	c := &Coverage{
+		schema:   s,
+		branches: make(map[coverageKey]string),
+		covered:  make(map[string]bool),
+	}
+	add := func(kw string, sch *Schema, loc string) {
+		if sch == nil {
+			return
+		}
+		if _, ok := c.branches[coverageKey{kw, sch}]; !ok {
+			c.branches[coverageKey{kw, sch}] = loc
+			c.covered[loc] = false
+		}
+	}
+	s.Walk(func(sch *Schema) bool {
+		for i, sub := range sch.OneOf {
+			add("oneOf", sub, sch.Location+"/oneOf/"+strconv.Itoa(i))
+		}
+		for i, sub := range sch.AnyOf {
+			add("anyOf", sub, sch.Location+"/anyOf/"+strconv.Itoa(i))
+		}
+		if sch.If != nil {
+			add("then", sch.Then, sch.Location+"/then")
+			add("else", sch.Else, sch.Location+"/else")
+		}
+		for i := range sch.Enum {
+			c.covered[sch.Location+"/enum/"+strconv.Itoa(i)] = false
+		}
+		return true
+	})
+	return c
+}
+
+// Validate validates v against the schema, recording the branches exercised.
+func (c *Coverage) Validate(v interface{}) error {
+	return c.schema.ValidateWithHook(v, c)
+}
+
+// OnKeywordEnter implements Hook.
+func (c *Coverage) OnKeywordEnter(e *HookEvent) {
+	switch e.Keyword {
+	case "then", "else":
+		c.cover(c.branches[coverageKey{e.Keyword, e.Schema}])
+	}
+}
+
+// OnKeywordExit implements Hook.
+func (c *Coverage) OnKeywordExit(e *HookEvent, err error) {
+	if err != nil {
+		return
+	}
+	switch e.Keyword {
+	case "oneOf", "anyOf":
+		c.cover(c.branches[coverageKey{e.Keyword, e.Schema}])
+	}
+	for i, item := range e.Schema.Enum {
+		if equals(e.Instance, item) {
+			c.cover(e.Schema.Location + "/enum/" + strconv.Itoa(i))
+			break
+		}
+	}
+}
+
+func (c *Coverage) cover(loc string) {
+	if loc == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.covered[loc]; ok {
+		c.covered[loc] = true
+	}
+}
+
+// Uncovered returns the absolute locations of branches not yet
+// exercised, in sorted order. for example "schema.json#/oneOf/1"
+// or "schema.json#/properties/kind/enum/2".
+func (c *Coverage) Uncovered() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var locs []string
+	for loc, covered := range c.covered {
+		if !covered {
+			locs = append(locs, loc)
+		}
+	}
+	sort.Strings(locs)
+	return locs
+}
+
+// Stats returns the number of branches exercised, and the total number
+// of branches.
+func (c *Coverage) Stats() (covered, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ok := range c.covered {
+		if ok {
+			covered++
+		}
+	}
+	return covered, len(c.covered)
+}
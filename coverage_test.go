@@ -0,0 +1,41 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCoverage(t *testing.T) {
+	sch, err := jsonschema.CompileString("https://example.com/schema.json", `{
+		"properties": {
+			"kind": {"enum": ["a", "b", "c"]},
+			"value": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+		},
+		"if": {"required": ["kind"]},
+		"then": {"required": ["value"]},
+		"else": {"maxProperties": 0}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := jsonschema.NewCoverage(sch)
+	if covered, total := c.Stats(); covered != 0 || total != 7 {
+		t.Fatalf("stats: got %d/%d, want 0/7", covered, total)
+	}
+	for _, doc := range []string{`{"kind": "a", "value": "x"}`, `{"kind": "b", "value": true}`} {
+		_ = c.Validate(decodeString(t, doc))
+	}
+	want := []string{
+		"https://example.com/schema.json#/else",
+		"https://example.com/schema.json#/properties/kind/enum/2",
+		"https://example.com/schema.json#/properties/value/oneOf/1",
+	}
+	if got := c.Uncovered(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("uncovered: got %v, want %v", got, want)
+	}
+	if covered, _ := c.Stats(); covered != 4 {
+		t.Fatalf("covered: got %d, want 4", covered)
+	}
+}
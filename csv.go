@@ -0,0 +1,147 @@
+package jsonschema
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RowResult is the result of validating a row of csv.
+type RowResult struct {
+	Line  int                    // line number of the row, starting from 1
+	Value map[string]interface{} // row as object, after coercion. nil if row is malformed
+	Err   error                  // nil if valid. *ValidationError if invalid, or *csv.ParseError if row is malformed
+
+	columns map[string]int // header to column number
+}
+
+// CellError is a validation error, located at a cell of csv.
+type CellError struct {
+	Line   int    // line number of the row, starting from 1
+	Column int    // column number, starting from 1. zero if error is about the row as whole
+	Header string // header of the column. empty if Column is zero
+	Err    *ValidationError
+}
+
+func (ce CellError) Error() string {
+	if ce.Column == 0 {
+		return fmt.Sprintf("line %d: %s", ce.Line, ce.Err.Message)
+	}
+	return fmt.Sprintf("line %d, column %d (%s): %s", ce.Line, ce.Column, ce.Header, ce.Err.Message)
+}
+
+// Cells returns the leaf errors of r.Err, located at the cells of the row.
+// Returns nil, if r.Err is not *ValidationError.
+func (r RowResult) Cells() []CellError {
+	var ve *ValidationError
+	if !errors.As(r.Err, &ve) {
+		return nil
+	}
+	var cells []CellError
+	for _, leaf := range ve.Leaves() {
+		ce := CellError{Line: r.Line, Err: leaf}
+		if loc := strings.TrimPrefix(leaf.InstanceLocation, "/"); loc != leaf.InstanceLocation {
+			header := loc
+			if slash := strings.IndexByte(loc, '/'); slash != -1 {
+				header = loc[:slash]
+			}
+			header = strings.ReplaceAll(strings.ReplaceAll(header, "~1", "/"), "~0", "~")
+			if col, ok := r.columns[header]; ok {
+				ce.Column, ce.Header = col, header
+			}
+		}
+		cells = append(cells, ce)
+	}
+	return cells
+}
+
+// CSVOptions configures Schema.ValidateCSV. The zero value is ready to use.
+type CSVOptions struct {
+	// Comma is the field delimiter. defaults to ',' if zero.
+	Comma rune
+
+	// Header is the list of column names. If nil, the first row of
+	// input is used as header.
+	Header []string
+
+	// Result, if not nil, is called with the result of each row, in order.
+	// Returning false stops the validation. When Result is set,
+	// ValidateCSV does not collect the results.
+	Result func(r RowResult) bool
+}
+
+// ValidateCSV validates each row of csv read from r, against the schema s.
+// Each row is converted to an object with the column headers as property
+// names. Empty cells are omitted, so that they are reported by "required".
+// The cell values are then coerced to the types expected by s, as in
+// Schema.ValidateAndCoerce.
+//
+// Returns the results of rows which are either malformed or not valid
+// against s, unless opts.Result is set. opts can be nil. Use
+// RowResult.Cells to locate the errors at cells.
+//
+// The returned error is non-nil only if reading from r fails, or header
+// is missing or has duplicate columns.
+func (s *Schema) ValidateCSV(r io.Reader, opts *CSVOptions) ([]RowResult, error) {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	header := opts.Header
+	if header == nil {
+		var err error
+		if header, err = cr.Read(); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("jsonschema: csv header is missing")
+			}
+			return nil, err
+		}
+	}
+	cr.FieldsPerRecord = len(header)
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		if _, ok := columns[h]; ok {
+			return nil, fmt.Errorf("jsonschema: duplicate column %q in csv header", h)
+		}
+		columns[h] = i + 1
+	}
+
+	var results []RowResult
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		result := RowResult{columns: columns}
+		var perr *csv.ParseError
+		switch {
+		case errors.As(err, &perr):
+			result.Line, result.Err = perr.StartLine, err
+		case err != nil:
+			return nil, err
+		default:
+			result.Line, _ = cr.FieldPos(0)
+			row := make(map[string]interface{}, len(record))
+			for i, cell := range record {
+				if cell != "" {
+					row[header[i]] = cell
+				}
+			}
+			v := coerce(row, []*Schema{s}, false) // cells are coerced in place
+			result.Value, result.Err = row, s.Validate(v)
+		}
+		if opts.Result != nil {
+			if !opts.Result(result) {
+				return nil, nil
+			}
+		} else if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
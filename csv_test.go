@@ -0,0 +1,102 @@
+package jsonschema_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_ValidateCSV(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"name": {"type": "string"},
+			"active": {"type": "boolean"}
+		},
+		"required": ["id", "name"]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		`id,name,active`,
+		`1,john,true`,
+		`x,jane,false`,
+		`0,,yes`,
+		`2,"bob`,
+		`smith",true`,
+		`3,alice`,
+		`4,eve,`,
+	}, "\n")
+
+	results, err := sch.ValidateCSV(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, r := range results {
+		lines = append(lines, r.Line)
+	}
+	if want := []int{3, 4, 7}; !reflect.DeepEqual(lines, want) {
+		t.Fatalf("invalid lines: got %v, want %v", lines, want)
+	}
+
+	cells := results[0].Cells()
+	if len(cells) != 1 || cells[0].Column != 1 || cells[0].Header != "id" {
+		t.Errorf("line 3: got cells %v", cells)
+	}
+
+	var got []string
+	for _, cell := range results[1].Cells() {
+		got = append(got, cell.Header)
+	}
+	sort.Strings(got)
+	if want := []string{"", "active", "id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("line 4: got headers %q, want %q", got, want)
+	}
+
+	var perr *csv.ParseError
+	if !errors.As(results[2].Err, &perr) || results[2].Value != nil || results[2].Cells() != nil {
+		t.Errorf("line 7: must be csv parse error, got %v", results[2].Err)
+	}
+
+	t.Run("result", func(t *testing.T) {
+		var values []map[string]interface{}
+		_, err := sch.ValidateCSV(strings.NewReader(input), &jsonschema.CSVOptions{
+			Result: func(r jsonschema.RowResult) bool {
+				values = append(values, r.Value)
+				return r.Line < 3
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{{"id": json.Number("1"), "name": "john", "active": true}, {"id": "x", "name": "jane", "active": false}}
+		if !reflect.DeepEqual(values, want) {
+			t.Fatalf("got %v, want %v", values, want)
+		}
+	})
+
+	t.Run("header", func(t *testing.T) {
+		results, err := sch.ValidateCSV(strings.NewReader("1;john\n2;jane"), &jsonschema.CSVOptions{
+			Comma:  ';',
+			Header: []string{"id", "name"},
+		})
+		if err != nil || len(results) != 0 {
+			t.Fatalf("got %v, %v", results, err)
+		}
+	})
+
+	t.Run("duplicate header", func(t *testing.T) {
+		if _, err := sch.ValidateCSV(strings.NewReader("id,id\n1,2"), nil); err == nil {
+			t.Fatal("error expected")
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// dataKeywords lists keywords whose value can be a $data reference.
+var dataKeywords = map[string]bool{
+	"const":            true,
+	"enum":             true,
+	"minimum":          true,
+	"maximum":          true,
+	"exclusiveMinimum": true,
+	"exclusiveMaximum": true,
+	"multipleOf":       true,
+	"minLength":        true,
+	"maxLength":        true,
+	"minItems":         true,
+	"maxItems":         true,
+	"minProperties":    true,
+	"maxProperties":    true,
+}
+
+// dataRef returns the json-pointer of $data reference, if v is
+// of form {"$data": "pointer"}.
+func dataRef(v interface{}) (string, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return "", false
+	}
+	ptr, ok := m["$data"].(string)
+	return ptr, ok
+}
+
+// extractData returns copy of schema m, without keywords using $data references.
+// the removed keywords are returned with their json-pointers.
+func extractData(m map[string]interface{}) (map[string]interface{}, map[string]string) {
+	var data map[string]string
+	for kw, v := range m {
+		if !dataKeywords[kw] {
+			continue
+		}
+		if ptr, ok := dataRef(v); ok {
+			if data == nil {
+				data = make(map[string]string)
+			}
+			data[kw] = ptr
+		}
+	}
+	if data == nil {
+		return m, nil
+	}
+	mm := make(map[string]interface{}, len(m))
+	for kw, v := range m {
+		if _, ok := data[kw]; !ok {
+			mm[kw] = v
+		}
+	}
+	return mm, data
+}
+
+// stripData returns copy of schema doc v, with all keywords using $data
+// references removed. it is used to validate schema against the metaschema.
+func stripData(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		v, _ = extractData(v)
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = stripData(item)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = stripData(item)
+		}
+		return arr
+	}
+	return v
+}
+
+// resolveData returns copy of s, with $data references resolved against
+// the instance being validated. keywords whose $data reference does not
+// resolve to a value are ignored.
+//
+// if $data reference resolves to a value which is invalid for the keyword,
+// the keyword along with error is returned.
+func (s *Schema) resolveData(vd *validator, vloc string) (*Schema, string, error) {
+	sch := *s
+	for kw, ptr := range s.data {
+		v, ok, err := vd.lookup(ptr, vloc)
+		if err != nil {
+			return nil, kw, err
+		}
+		if !ok {
+			continue
+		}
+		invalid := func() error {
+			return fmt.Errorf("$data %s must not be %s", quote(ptr), jsonType(v))
+		}
+		switch kw {
+		case "const":
+			sch.Constant = []interface{}{v}
+		case "enum":
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, kw, invalid()
+			}
+			sch.Enum, sch.enumError = arr, enumError(arr)
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			if jsonType(v) != "number" {
+				return nil, kw, invalid()
+			}
+			num := ratValue(v)
+			if num == nil {
+				return nil, kw, fmt.Errorf("$data %s is out of supported range", quote(ptr))
+			}
+			switch kw {
+			case "minimum":
+				sch.Minimum = num
+			case "maximum":
+				sch.Maximum = num
+			case "exclusiveMinimum":
+				sch.ExclusiveMinimum = num
+			case "exclusiveMaximum":
+				sch.ExclusiveMaximum = num
+			case "multipleOf":
+				if num.Sign() <= 0 {
+					return nil, kw, fmt.Errorf("$data %s must be > 0, but got %v", quote(ptr), v)
+				}
+				sch.MultipleOf = num
+			}
+		default:
+			var num *big.Rat
+			if jsonType(v) == "number" {
+				num = ratValue(v)
+			}
+			if num == nil || !num.IsInt() || num.Sign() < 0 {
+				return nil, kw, fmt.Errorf("$data %s must be non-negative integer, but got %v", quote(ptr), v)
+			}
+			n := int(num.Num().Int64())
+			switch kw {
+			case "minLength":
+				sch.MinLength = n
+			case "maxLength":
+				sch.MaxLength = n
+			case "minItems":
+				sch.MinItems = n
+			case "maxItems":
+				sch.MaxItems = n
+			case "minProperties":
+				sch.MinProperties = n
+			case "maxProperties":
+				sch.MaxProperties = n
+			}
+		}
+	}
+	return &sch, "", nil
+}
+
+// lookup returns the value referred by $data pointer ptr, evaluated at
+// instance location vloc. ptr is either json-pointer or relative json-pointer.
+//
+// returns false, if ptr does not refer to any value.
+func (vd *validator) lookup(ptr, vloc string) (interface{}, bool, error) {
+	// tokens of vloc, relative to root instance
+	var loc []string
+	if rel := strings.TrimPrefix(vloc, vd.rootLoc); rel != "" {
+		for _, tok := range strings.Split(rel[1:], "/") {
+			loc = append(loc, jsonpointer.Unescape(tok))
+		}
+	}
+
+	if ptr != "" && ptr[0] != '/' {
+		// relative json-pointer
+		i := 0
+		for i < len(ptr) && ptr[i] >= '0' && ptr[i] <= '9' {
+			i++
+		}
+		up, err := strconv.Atoi(ptr[:i])
+		if err != nil || (i > 1 && ptr[0] == '0') {
+			return nil, false, fmt.Errorf("invalid $data pointer %s", quote(ptr))
+		}
+		if up > len(loc) {
+			return nil, false, nil
+		}
+		loc = loc[:len(loc)-up]
+		switch rest := ptr[i:]; {
+		case rest == "#":
+			if len(loc) == 0 {
+				return nil, false, nil
+			}
+			name := loc[len(loc)-1]
+			if _, ok := vd.value(loc[:len(loc)-1]).([]interface{}); ok {
+				return json.Number(name), true, nil
+			}
+			return name, true, nil
+		case rest == "" || rest[0] == '/':
+			ptr = rest
+		default:
+			return nil, false, fmt.Errorf("invalid $data pointer %s", quote(ptr))
+		}
+	} else {
+		loc = nil
+	}
+
+	if ptr != "" {
+		if ptr[0] != '/' {
+			return nil, false, fmt.Errorf("invalid $data pointer %s", quote(ptr))
+		}
+		for _, tok := range strings.Split(ptr[1:], "/") {
+			loc = append(loc, jsonpointer.Unescape(tok))
+		}
+	}
+	v := vd.value(loc)
+	return v, v != notFound, nil
+}
+
+// notFound is returned by validator.value, if there is no such value.
+var notFound = &struct{}{}
+
+// value returns the value at given tokens in root instance.
+func (vd *validator) value(tokens []string) interface{} {
+	v := vd.root
+	for _, tok := range tokens {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			item, ok := vv[tok]
+			if !ok {
+				return notFound
+			}
+			v = item
+		case []interface{}:
+			index, err := strconv.Atoi(tok)
+			if err != nil || index < 0 || index >= len(vv) {
+				return notFound
+			}
+			v = vv[index]
+		default:
+			return notFound
+		}
+	}
+	return v
+}
@@ -0,0 +1,79 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestAllowData(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"limits": {
+				"properties": {
+					"max": {"type": "number"}
+				}
+			},
+			"value": {"maximum": {"$data": "/limits/max"}},
+			"password": {"type": "string"},
+			"confirm": {"const": {"$data": "1/password"}},
+			"items": {
+				"type": "array",
+				"items": {"minLength": {"$data": "2/minLength"}}
+			},
+			"minLength": {"type": "integer"}
+		}
+	}`
+
+	// without AllowData, $data is not valid for maximum
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Compile("schema.json"); err == nil {
+		t.Fatal("compile must fail without AllowData")
+	}
+
+	c = jsonschema.NewCompiler()
+	c.AllowData = true
+	if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	tests := []struct {
+		name  string
+		doc   string
+		valid bool
+	}{
+		{"maximum valid", `{"limits": {"max": 10}, "value": 10}`, true},
+		{"maximum invalid", `{"limits": {"max": 10}, "value": 11}`, false},
+		{"maximum unresolved", `{"value": 11}`, true},
+		{"maximum not number", `{"limits": {"max": "10"}, "value": 1}`, false},
+		{"const valid", `{"password": "secret", "confirm": "secret"}`, true},
+		{"const invalid", `{"password": "secret", "confirm": "Secret"}`, false},
+		{"nested valid", `{"minLength": 2, "items": ["ab", "abc"]}`, true},
+		{"nested invalid", `{"minLength": 2, "items": ["ab", "a"]}`, false},
+		{"nested negative", `{"minLength": -1, "items": ["a"]}`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sch.Validate(decodeString(t, test.doc))
+			if test.valid && err != nil {
+				t.Fatalf("%#v", err)
+			}
+			if !test.valid {
+				var ve *jsonschema.ValidationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("want ValidationError, got %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// InstanceDecoders is a registry of functions, which know how to decode
+// instance documents of specific media type, to be validated using
+// Schema.Validate.
+//
+// New decoders can be registered by adding to this map. Key is media type,
+// value is function that decodes single document from r. For example, to
+// validate yaml documents:
+//
+//	jsonschema.InstanceDecoders["application/yaml"] = func(r io.Reader) (interface{}, error) {
+//		var v interface{}
+//		err := yaml.NewDecoder(r).Decode(&v)
+//		return v, err
+//	}
+//
+// The decoded value need not be strictly json: maps with non-string keys are
+// converted to map[string]interface{} and go integer types are accepted as
+// numbers. Decoders should preserve numbers beyond float64 precision, by
+// returning them as json.Number, *big.Int or *big.Rat.
+var InstanceDecoders = map[string]func(r io.Reader) (interface{}, error){
+	"application/json": DecodeJSON,
+}
+
+// Decode decodes single document of given mediaType from r, using the
+// decoder registered in InstanceDecoders. Parameters in mediaType such as
+// charset are ignored, and structured syntax suffixes like
+// "application/geo+json" fall back to the decoder for "application/json".
+func Decode(r io.Reader, mediaType string) (interface{}, error) {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid media type %q: %v", mediaType, err)
+	}
+	decode, ok := InstanceDecoders[mt]
+	if !ok {
+		if plus := strings.LastIndexByte(mt, '+'); plus != -1 {
+			decode, ok = InstanceDecoders["application/"+mt[plus+1:]]
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: no decoder registered for media type %q", mt)
+	}
+	v, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeInstance(v), nil
+}
+
+// ValidateBytes decodes b as document of given mediaType using Decode,
+// and validates it. Errors in decoding are returned as is. returns
+// InvalidJSONTypeError if decoder returned value that is not json.
+func (s *Schema) ValidateBytes(b []byte, mediaType string) error {
+	v, err := Decode(bytes.NewReader(b), mediaType)
+	if err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+// normalizeInstance converts maps with non-string keys and go integer
+// types not known to jsonType in v, returned by decoder.
+func normalizeInstance(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeInstance(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalizeInstance(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeInstance(item)
+		}
+		return v
+	case int16:
+		return int64(v)
+	case uint16:
+		return uint64(v)
+	}
+	return v
+}
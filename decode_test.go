@@ -0,0 +1,58 @@
+package jsonschema_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestValidateBytes(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"properties": {"id": {"type": "integer", "maximum": 18446744073709551615}, "port": {"type": "integer"}}
+	}`)
+	if err := sch.ValidateBytes([]byte(`{"id": 18446744073709551615}`), "application/json; charset=utf-8"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.ValidateBytes([]byte(`{"id": 18446744073709551616}`), "application/json"); err == nil {
+		t.Fatal("number precision must be preserved")
+	}
+	if err := sch.ValidateBytes([]byte(`{"id": 1}`), "application/geo+json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.ValidateBytes([]byte(`{"id": 1}`), "text/plain"); err == nil || !strings.Contains(err.Error(), "no decoder") {
+		t.Fatalf("got %v, want no decoder error", err)
+	}
+	if err := sch.ValidateBytes([]byte(`{`), "application/json"); err == nil {
+		t.Fatal("decode error expected")
+	}
+
+	// decoder of "key=value" lines, with int16 values and non-string map keys
+	jsonschema.InstanceDecoders["text/x-properties"] = func(r io.Reader) (interface{}, error) {
+		m := map[interface{}]interface{}{}
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			k, v, _ := strings.Cut(sc.Text(), "=")
+			if n, err := strconv.ParseInt(v, 10, 16); err == nil {
+				m[k] = int16(n)
+			} else {
+				m[k] = v
+			}
+		}
+		return m, sc.Err()
+	}
+	defer delete(jsonschema.InstanceDecoders, "text/x-properties")
+	if err := sch.ValidateBytes([]byte("port=8080\nhost=x"), "text/x-properties"); err != nil {
+		t.Fatal(err)
+	}
+	err := sch.ValidateBytes([]byte("port=http"), "text/x-properties")
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+}
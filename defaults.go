@@ -0,0 +1,108 @@
+package jsonschema
+
+// ValidateAndFill fills in "default" values for missing object properties
+// and array items in v, and validates the resulting instance.
+//
+// v is not modified. The instance with defaults filled in is returned
+// even when validation fails.
+//
+// Defaults are taken from "properties" for objects, and from "prefixItems"
+// or "items" array for arrays. Defaults are collected through "$ref" and
+// "allOf", but not from "anyOf", "oneOf" or conditional keywords, since
+// it is not possible to tell which of them apply before validation.
+// A "default": null is treated as if default is not specified.
+//
+// NOTE: defaults are available only if Compiler.ExtractAnnotations is true.
+func (s *Schema) ValidateAndFill(v interface{}) (interface{}, error) {
+	v = deepCopy(v)
+	v = s.fillDefaults(v, nil)
+	return v, s.Validate(v)
+}
+
+// fillDefaults fills defaults into v in place and returns it.
+//
+// stack holds schemas applied on same v, to avoid infinite loop.
+func (s *Schema) fillDefaults(v interface{}, stack []*Schema) interface{} {
+	if s == nil {
+		return v
+	}
+	for _, sch := range stack {
+		if sch == s {
+			return v
+		}
+	}
+	stack = append(stack, s)
+
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for pname, sch := range s.Properties {
+			if pvalue, ok := vv[pname]; ok {
+				vv[pname] = sch.fillDefaults(pvalue, nil)
+			} else if dv := sch.defaultValue(); dv != nil {
+				vv[pname] = sch.fillDefaults(dv, nil)
+			}
+		}
+	case []interface{}:
+		items := s.PrefixItems
+		if items == nil {
+			items, _ = s.Items.([]*Schema)
+		}
+		for i, sch := range items {
+			if i < len(vv) {
+				vv[i] = sch.fillDefaults(vv[i], nil)
+				continue
+			}
+			dv := sch.defaultValue()
+			if dv == nil {
+				break
+			}
+			vv = append(vv, sch.fillDefaults(dv, nil))
+		}
+		rest := s.Items2020
+		if sch, ok := s.Items.(*Schema); ok {
+			rest = sch
+		} else if sch, ok := s.AdditionalItems.(*Schema); ok {
+			rest = sch
+		}
+		if rest != nil {
+			for i := len(items); i < len(vv); i++ {
+				vv[i] = rest.fillDefaults(vv[i], nil)
+			}
+		}
+		v = vv
+	}
+
+	// subschemas applied on same instance
+	v = s.Ref.fillDefaults(v, stack)
+	for _, sch := range s.AllOf {
+		v = sch.fillDefaults(v, stack)
+	}
+	return v
+}
+
+// defaultValue returns copy of default value, to avoid instance sharing
+// values with schema.
+func (s *Schema) defaultValue() interface{} {
+	if s.Default == nil && s.Ref != nil {
+		return s.Ref.defaultValue()
+	}
+	return deepCopy(s.Default)
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, pvalue := range v {
+			m[k] = deepCopy(pvalue)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = deepCopy(item)
+		}
+		return arr
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestValidateAndFill(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"type": "object",
+		"allOf": [{"$ref": "#/$defs/base"}],
+		"properties": {
+			"port": {"type": "integer", "default": 8080},
+			"tls": {
+				"type": "object",
+				"default": {},
+				"properties": {
+					"enabled": {"type": "boolean", "default": false}
+				}
+			},
+			"hosts": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"weight": {"type": "integer", "default": 1}
+					}
+				}
+			},
+			"pair": {
+				"type": "array",
+				"prefixItems": [{"default": "a"}, {"default": "b"}]
+			}
+		},
+		"$defs": {
+			"base": {
+				"properties": {
+					"name": {"type": "string", "default": "server"}
+				}
+			}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	v := decodeString(t, `{"port": 9090, "hosts": [{}, {"weight": 5}], "pair": ["x"]}`)
+	filled, err := sch.ValidateAndFill(v)
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	want := decodeString(t, `{
+		"name": "server",
+		"port": 9090,
+		"tls": {"enabled": false},
+		"hosts": [{"weight": 1}, {"weight": 5}],
+		"pair": ["x", "b"]
+	}`)
+	if !reflect.DeepEqual(filled, want) {
+		t.Errorf("got %v, want %v", filled, want)
+	}
+	if _, ok := v.(map[string]interface{})["name"]; ok {
+		t.Error("given instance must not be modified")
+	}
+
+	if _, err := sch.ValidateAndFill(decodeString(t, `{"port": "80"}`)); err == nil {
+		t.Error("validation error expected")
+	}
+}
@@ -0,0 +1,73 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A Warning reports usage of deprecated keyword in schema. Unlike
+// errors, warnings do not fail compilation. See Compiler.Warnings.
+type Warning struct {
+	SchemaURL string // absolute url of the schema, with json-pointer fragment.
+	Keyword   string // deprecated keyword.
+	Message   string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.SchemaURL, w.Message)
+}
+
+// Warnings returns the warnings about deprecated usage in schemas
+// compiled so far by this compiler, such as "definitions" in place of
+// "$defs" in draft2019-09 and above. This can be used for linting.
+//
+// Warnings of a failed compilation are discarded, and schemas returned
+// from cache are not checked again.
+func (c *Compiler) Warnings() []Warning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Warning(nil), c.warnings...)
+}
+
+// checkDeprecated records warnings for deprecated keywords used in
+// schema m of res, as per draft of r.
+func (c *Compiler) checkDeprecated(r *resource, res *resource, m map[string]interface{}) {
+	keywords := make([]string, 0, len(m))
+	for kw := range m {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+
+	warn := func(kw, format string, a ...interface{}) {
+		c.warnings = append(c.warnings, Warning{
+			SchemaURL: res.schema.Location,
+			Keyword:   kw,
+			Message:   fmt.Sprintf(format, a...),
+		})
+	}
+	version := r.draft.version
+	for _, kw := range keywords {
+		switch kw {
+		case "definitions":
+			if version >= 2019 {
+				warn(kw, `"definitions" is replaced by "$defs" in %s`, r.draft)
+			}
+		case "dependencies":
+			if version >= 2019 {
+				warn(kw, `"dependencies" is replaced by "dependentRequired" and "dependentSchemas" in %s`, r.draft)
+			}
+		case "id":
+			if _, ok := m["id"].(string); ok && version >= 6 && !r.draft.openapi {
+				warn(kw, `"id" is replaced by "$id" in %s`, r.draft)
+			}
+		case "exclusiveMinimum", "exclusiveMaximum":
+			if _, ok := m[kw].(bool); ok && version == 4 && !r.draft.openapi {
+				warn(kw, "boolean %q is replaced by number in draft6 and above", kw)
+			}
+		case "$recursiveRef", "$recursiveAnchor":
+			if version >= 2020 {
+				warn(kw, "%q is replaced by %q in %s", kw, "$dynamic"+kw[len("$recursive"):], r.draft)
+			}
+		}
+	}
+}
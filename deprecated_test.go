@@ -0,0 +1,106 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Warnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []jsonschema.Warning
+	}{
+		{
+			name: "definitions in 2020",
+			schema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$ref": "#/definitions/a",
+				"definitions": {"a": {"type": "string"}}
+			}`,
+			want: []jsonschema.Warning{{
+				SchemaURL: "http://example.com/schema.json#",
+				Keyword:   "definitions",
+				Message:   `"definitions" is replaced by "$defs" in Draft2020`,
+			}},
+		},
+		{
+			name: "definitions in draft7",
+			schema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"$ref": "#/definitions/a",
+				"definitions": {"a": {"type": "string"}}
+			}`,
+		},
+		{
+			name: "id in draft7",
+			schema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"properties": {"a": {"id": "a.json"}}
+			}`,
+			want: []jsonschema.Warning{{
+				SchemaURL: "http://example.com/schema.json#/properties/a",
+				Keyword:   "id",
+				Message:   `"id" is replaced by "$id" in Draft7`,
+			}},
+		},
+		{
+			name: "boolean exclusiveMinimum in draft4",
+			schema: `{
+				"$schema": "http://json-schema.org/draft-04/schema#",
+				"minimum": 1,
+				"exclusiveMinimum": true
+			}`,
+			want: []jsonschema.Warning{{
+				SchemaURL: "http://example.com/schema.json#",
+				Keyword:   "exclusiveMinimum",
+				Message:   `boolean "exclusiveMinimum" is replaced by number in draft6 and above`,
+			}},
+		},
+		{
+			name: "dependencies in 2019",
+			schema: `{
+				"$schema": "https://json-schema.org/draft/2019-09/schema",
+				"dependencies": {"a": ["b"]}
+			}`,
+			want: []jsonschema.Warning{{
+				SchemaURL: "http://example.com/schema.json#",
+				Keyword:   "dependencies",
+				Message:   `"dependencies" is replaced by "dependentRequired" and "dependentSchemas" in Draft2019`,
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			if err := c.AddResource("http://example.com/schema.json", strings.NewReader(test.schema)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Compile("http://example.com/schema.json"); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Warnings(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompiler_WarningsFailedCompile(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(`{
+		"definitions": {"a": {}},
+		"$ref": "#/$defs/missing"
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Compile("http://example.com/schema.json"); err == nil {
+		t.Fatal("error expected")
+	}
+	if got := c.Warnings(); len(got) != 0 {
+		t.Fatalf("warnings of failed compilation must be discarded: %v", got)
+	}
+}
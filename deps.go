@@ -0,0 +1,153 @@
+package jsonschema
+
+import (
+	"sort"
+)
+
+// DependencyGraph is the graph of schema resources referenced, directly or
+// indirectly, by a schema. Resources are identified by the url they are
+// added to or loaded by the Compiler with, without fragment. The graph may
+// have cycles, for example when two resources refer to each other.
+//
+// Meta-schemas of drafts and their vocabularies are not included, as they
+// are built into the package.
+type DependencyGraph struct {
+	// Root is the resource of the schema, whose dependencies are computed.
+	Root string
+
+	// Edges maps each resource in the graph to the resources it refers to
+	// using "$ref", "$dynamicRef", "$recursiveRef" or "$schema", in sorted
+	// order. References within a resource are not included.
+	Edges map[string][]string
+}
+
+// Dependencies compiles the schema at given url, and returns the graph of
+// resources it depends on. Build systems can use it to invalidate cached
+// schemas when any of their resources changes, and vendoring tools to fetch
+// the complete closure of a schema.
+//
+// Any compilation error is returned as *SchemaError.
+func (c *Compiler) Dependencies(url string) (*DependencyGraph, error) {
+	sch, err := c.Compile(url)
+	if err != nil {
+		return nil, err
+	}
+	// Location of schema uses "$id" of its resource, if any
+	c.mu.Lock()
+	urls := make(map[string]string, len(c.resources))
+	for u, r := range c.resources {
+		urls[r.url] = u
+	}
+	c.mu.Unlock()
+	resourceOf := func(s *Schema) string {
+		u, _ := split(s.Location)
+		if key, ok := urls[u]; ok {
+			return key
+		}
+		return u
+	}
+
+	root := resourceOf(sch)
+	edges := make(map[string]map[string]bool)
+	node := func(u string) map[string]bool {
+		m, ok := edges[u]
+		if !ok {
+			m = make(map[string]bool)
+			edges[u] = m
+		}
+		return m
+	}
+	node(root)
+
+	seen := make(map[*Schema]bool)
+	var visit func(s *Schema)
+	visit = func(s *Schema) {
+		from := resourceOf(s)
+		if seen[s] || (isBuiltin(from) && from != root) {
+			return
+		}
+		seen[s] = true
+		for _, ref := range []*Schema{s.Ref, s.DynamicRef, s.RecursiveRef, s.meta} {
+			if ref == nil {
+				continue
+			}
+			to := resourceOf(ref)
+			if isBuiltin(to) {
+				continue
+			}
+			if to != from {
+				node(from)[to] = true
+				node(to)
+			}
+		}
+		for _, sub := range s.Subschemas() {
+			visit(sub)
+		}
+		if s.meta != nil {
+			visit(s.meta)
+		}
+	}
+	visit(sch)
+
+	g := &DependencyGraph{Root: root, Edges: make(map[string][]string, len(edges))}
+	for from, m := range edges {
+		to := make([]string, 0, len(m))
+		for u := range m {
+			to = append(to, u)
+		}
+		sort.Strings(to)
+		g.Edges[from] = to
+	}
+	return g, nil
+}
+
+// Resources returns all resources in the graph, including Root, in sorted
+// order.
+func (g *DependencyGraph) Resources() []string {
+	urls := make([]string, 0, len(g.Edges))
+	for u := range g.Edges {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// Dependents returns the resources in the graph, which refer to resource u
+// directly or indirectly, in sorted order. If a cache of compiled schemas
+// is keyed by resource, these are the entries to invalidate when u changes.
+func (g *DependencyGraph) Dependents(u string) []string {
+	reverse := make(map[string][]string)
+	for from, list := range g.Edges {
+		for _, to := range list {
+			reverse[to] = append(reverse[to], from)
+		}
+	}
+	seen := make(map[string]bool)
+	queue := []string{u}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[cur] {
+			if !seen[from] {
+				seen[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+	urls := make([]string, 0, len(seen))
+	for from := range seen {
+		urls = append(urls, from)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// isBuiltin tells whether u is meta-schema of a draft or one of its
+// vocabularies.
+func isBuiltin(u string) bool {
+	if findDraft(u) != nil {
+		return true
+	}
+	_, ok := vocabSchemas[u]
+	return ok
+}
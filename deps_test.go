@@ -0,0 +1,99 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Dependencies(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	resources := map[string]string{
+		"http://example.com/person.json": `{
+			"properties": {
+				"name": {"$ref": "#/$defs/name"},
+				"home": {"$ref": "address.json"},
+				"pet": {"$ref": "pet.json"}
+			},
+			"$defs": {
+				"name": {"type": "string"}
+			}
+		}`,
+		"http://example.com/address.json": `{
+			"properties": {
+				"zip": {"$ref": "common.json#/$defs/zip"},
+				"owner": {"$ref": "person.json"}
+			}
+		}`,
+		"http://example.com/pet.json": `{
+			"$schema": "http://example.com/meta.json",
+			"properties": {
+				"kind": {"$ref": "https://json-schema.org/draft/2020-12/meta/core"}
+			}
+		}`,
+		"http://example.com/meta.json": `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "http://example.com/meta.json",
+			"$vocabulary": {"https://json-schema.org/draft/2020-12/vocab/core": true},
+			"allOf": [{"$ref": "https://json-schema.org/draft/2020-12/schema"}]
+		}`,
+		"http://example.com/common.json": `{
+			"$id": "http://example.com/schemas/common.json",
+			"$defs": {"zip": {"type": "string"}}
+		}`,
+		"http://example.com/unused.json": `{}`,
+	}
+	for url, schema := range resources {
+		if err := c.AddResource(url, strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := c.Dependencies("http://example.com/person.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if want := "http://example.com/person.json"; g.Root != want {
+		t.Errorf("Root: got %q, want %q", g.Root, want)
+	}
+	wantEdges := map[string][]string{
+		"http://example.com/person.json":  {"http://example.com/address.json", "http://example.com/pet.json"},
+		"http://example.com/address.json": {"http://example.com/common.json", "http://example.com/person.json"},
+		"http://example.com/pet.json":     {"http://example.com/meta.json"},
+		"http://example.com/meta.json":    {},
+		"http://example.com/common.json":  {},
+	}
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("Edges:\n got %v\nwant %v", g.Edges, wantEdges)
+	}
+	wantResources := []string{
+		"http://example.com/address.json",
+		"http://example.com/common.json",
+		"http://example.com/meta.json",
+		"http://example.com/person.json",
+		"http://example.com/pet.json",
+	}
+	if got := g.Resources(); !reflect.DeepEqual(got, wantResources) {
+		t.Errorf("Resources:\n got %v\nwant %v", got, wantResources)
+	}
+	wantDependents := []string{"http://example.com/address.json", "http://example.com/person.json"}
+	if got := g.Dependents("http://example.com/common.json"); !reflect.DeepEqual(got, wantDependents) {
+		t.Errorf("Dependents:\n got %v\nwant %v", got, wantDependents)
+	}
+	if got := g.Dependents("http://example.com/unused.json"); len(got) != 0 {
+		t.Errorf("Dependents of unused: got %v, want none", got)
+	}
+}
+
+func TestCompiler_Dependencies_error(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("http://example.com/a.json", strings.NewReader(`{"$ref": "b.json"}`)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.Dependencies("http://example.com/a.json")
+	if _, ok := err.(*jsonschema.SchemaError); !ok {
+		t.Fatalf("got %#v, want *SchemaError", err)
+	}
+}
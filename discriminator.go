@@ -0,0 +1,134 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// discriminator is the compiled form of OpenAPI style "discriminator" keyword.
+type discriminator struct {
+	property string         // name of the discriminator property
+	mapping  map[string]int // discriminator value to index of oneOf subschema
+}
+
+// values returns the allowed values of discriminator property, in sorted order.
+func (d *discriminator) values() []string {
+	values := make([]string, 0, len(d.mapping))
+	for value := range d.mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// compileDiscriminator compiles the discriminator keyword of schema s.
+//
+// value of discriminator property is mapped to oneOf subschema using
+// "mapping", by "const" or "enum" of the discriminator property in the
+// subschema, and by name of the subschema referenced via $ref.
+func (c *Compiler) compileDiscriminator(r *resource, res *resource, s *Schema, v interface{}) (*discriminator, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: invalid discriminator in %s: must be object", res)
+	}
+	pname, ok := m["propertyName"].(string)
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: invalid discriminator in %s: propertyName must be string", res)
+	}
+	if len(s.OneOf) == 0 {
+		return nil, fmt.Errorf("jsonschema: invalid discriminator in %s: oneOf is required", res)
+	}
+	d := &discriminator{property: pname, mapping: make(map[string]int)}
+
+	if mapping, ok := m["mapping"]; ok {
+		mapping, ok := mapping.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("jsonschema: invalid discriminator in %s: mapping must be object", res)
+		}
+		for _, value := range sortedKeys(mapping) {
+			ref, ok := mapping[value].(string)
+			if !ok {
+				return nil, fmt.Errorf("jsonschema: invalid discriminator in %s: mapping/%s must be string", res, escape(value))
+			}
+			sch, err := c.compileRef(r, nil, "discriminator/mapping/"+escape(value), res, ref)
+			if err != nil {
+				return nil, err
+			}
+			index := -1
+			for i, branch := range s.OneOf {
+				if branch == sch || branch.Ref == sch {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				return nil, fmt.Errorf("jsonschema: invalid discriminator in %s: mapping/%s is not one of oneOf subschemas", res, escape(value))
+			}
+			d.mapping[value] = index
+		}
+	}
+
+	for i, branch := range s.OneOf {
+		for _, value := range discriminatorValues(branch, pname) {
+			if _, ok := d.mapping[value]; !ok {
+				d.mapping[value] = i
+			}
+		}
+	}
+	for i, branch := range s.OneOf {
+		if branch.Ref != nil {
+			loc := branch.Ref.Location
+			name := unescape(loc[strings.LastIndexAny(loc, "/#")+1:])
+			if _, ok := d.mapping[name]; !ok && name != "" {
+				d.mapping[name] = i
+			}
+		}
+	}
+	return d, nil
+}
+
+// discriminatorValues returns the values allowed for property pname by
+// const or enum in sch, including via $ref and allOf.
+func discriminatorValues(sch *Schema, pname string) []string {
+	var values []string
+	visited := make(map[*Schema]bool)
+	var collect func(sch *Schema)
+	collect = func(sch *Schema) {
+		if sch == nil || visited[sch] {
+			return
+		}
+		visited[sch] = true
+		if psch, ok := sch.Properties[pname]; ok {
+			for psch.Ref != nil && len(psch.Constant) == 0 && len(psch.Enum) == 0 {
+				psch = psch.Ref
+			}
+			if len(psch.Constant) > 0 {
+				if value, ok := psch.Constant[0].(string); ok {
+					values = append(values, value)
+				}
+			}
+			for _, item := range psch.Enum {
+				if value, ok := item.(string); ok {
+					values = append(values, value)
+				}
+			}
+		}
+		collect(sch.Ref)
+		for _, s := range sch.AllOf {
+			collect(s)
+		}
+	}
+	collect(sch)
+	return values
+}
+
+// sortedKeys returns keys of m in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,95 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestDiscriminator(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.AllowDiscriminator = true
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"oneOf": [
+			{"$ref": "#/$defs/cat"},
+			{"$ref": "#/$defs/dog"},
+			{"$ref": "#/$defs/Bird"}
+		],
+		"discriminator": {
+			"propertyName": "kind",
+			"mapping": {"puppy": "#/$defs/dog"}
+		},
+		"$defs": {
+			"cat": {
+				"required": ["kind", "lives"],
+				"properties": {"kind": {"const": "cat"}, "lives": {"type": "integer"}}
+			},
+			"dog": {
+				"required": ["kind", "bark"],
+				"properties": {"kind": {"enum": ["dog", "puppy"]}, "bark": {"type": "boolean"}}
+			},
+			"Bird": {
+				"required": ["kind", "wings"],
+				"properties": {"kind": {"type": "string"}, "wings": {"type": "integer"}}
+			}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	tests := []struct {
+		name  string
+		doc   string
+		valid bool
+		want  string // substring of error
+	}{
+		{"const", `{"kind": "cat", "lives": 9}`, true, ""},
+		{"enum", `{"kind": "dog", "bark": true}`, true, ""},
+		{"mapping", `{"kind": "puppy", "bark": true}`, true, ""},
+		{"ref name", `{"kind": "Bird", "wings": 2}`, true, ""},
+		{"invalid branch", `{"kind": "cat", "lives": "nine"}`, false, "/$defs/cat/properties/lives/type"},
+		{"missing property", `{"lives": 9}`, false, "missing discriminator property 'kind'"},
+		{"unmapped value", `{"kind": "fish"}`, false, "must be one of Bird, cat, dog, puppy"},
+		{"not object", `"cat"`, false, "oneOf"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sch.Validate(decodeString(t, test.doc))
+			if test.valid {
+				if err != nil {
+					t.Fatalf("%#v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validation must fail")
+			}
+			if ve, ok := err.(*jsonschema.ValidationError); !ok || !strings.Contains(ve.GoString(), test.want) {
+				t.Fatalf("error must contain %q, got %#v", test.want, err)
+			}
+			if test.name == "invalid branch" && strings.Contains(err.(*jsonschema.ValidationError).GoString(), "/$defs/dog") {
+				t.Fatalf("must validate only with selected branch, got %#v", err)
+			}
+		})
+	}
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.AllowDiscriminator = true
+		if err := c.AddResource("schema.json", strings.NewReader(`{
+			"oneOf": [{"$ref": "#/$defs/a"}],
+			"discriminator": {"propertyName": "kind", "mapping": {"b": "#/$defs/b"}},
+			"$defs": {"a": {}, "b": {}}
+		}`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Compile("schema.json"); err == nil {
+			t.Fatal("compile must fail")
+		}
+	})
+}
@@ -5,29 +5,107 @@ Features:
   - implements draft 2020-12, 2019-09, draft-7, draft-6, draft-4
   - fully compliant with JSON-Schema-Test-Suite, (excluding some optional)
   - list of optional tests that are excluded can be found in schema_test.go(variable skipTests)
-  - validates schemas against meta-schema
+  - validates schemas against meta-schema, including custom meta-schemas extending a draft via $recursiveAnchor or $dynamicAnchor
   - full support of remote references
   - support of recursive references between schemas
+  - supports $anchor and plain-name fragments via "$id": "#name" in older drafts, reporting duplicate $anchor in a resource as compile error
   - detects infinite loop in schemas
-  - thread safe validation
+  - boolean schemas true and false in every applicator from draft6, reported by Schema.Boolean, and rejected with their location in draft4
+  - thread safe compilation and validation
+  - validates go structs/maps/slices directly using Schema.ValidateStruct, and reports errors with go field paths such as Items[0].Address.City using ValidationError.MapInstanceLocations and FieldPaths
+  - validates raw json and decodes it into go value in a single parse, using Schema.Unmarshal
+  - generates schema from go types using Reflect, honoring jsonschema and validate struct tags
+  - fills default values of missing properties and items using Schema.ValidateAndFill
+  - enforces readOnly and writeOnly for requests and responses using ValidateOptions.Mode, or strips such properties using Schema.ValidateAndStrip
+  - removes properties not described by schema, from untrusted input using Schema.Prune
+  - coerces string values such as query parameters to expected types using Schema.ValidateAndCoerce
+  - reports usage of deprecated keywords as warnings using Compiler.Warnings
+  - analyzes schemas for smells like impossible constraints and unreachable subschemas, using package lint
+  - checks backward and forward compatibility of schema changes, using package compat
+  - generates valid sample instances and invalid mutants from schemas, using package sample
+  - configurable limits on $ref depth, schema size, patterns, validation depth and remote fetches, using Compiler.Limits
+  - per validation limits on instance depth, array length checked by uniqueItems and string length matched by pattern, using ValidateOptions
+  - compiled schemas can be serialized with Schema.MarshalBinary and loaded with Compiler.UnmarshalSchema, without recompiling
+  - generates go validation functions from schemas for hot paths, using package validgen
+  - runs in browsers and javascript runtimes when compiled with GOOS=js GOARCH=wasm, using package wasm
+  - http middleware validating request and response bodies, with RFC 7807 problem details, using package httpvalidate
+  - validates protobuf Struct, ListValue, Value and proto-JSON encoded messages, using package protoval
+  - converts schemas to Avro schemas and protobuf messages, reporting lossy cases, using package schemaconv
+  - validates JSON and JSONB database columns on write and read, using package sqljson
+  - fetches schemas with references from Confluent compatible schema registries, and verifies framed Kafka payloads, using package schemaregistry
+  - builds schemas programmatically, and folds allOf subschemas into their parent, using package builder
+  - validates documents patched by JSON Patch or JSON Merge Patch, reporting the patch operation causing each violation, using package jsonpatch
+  - validates CBOR and MessagePack payloads without converting to JSON, preserving integer/float distinctions and byte strings, using packages cbor and msgpack
+  - benchmarks with Kubernetes CRD, OpenAPI and GeoJSON schemas in package benchmarks, with benchmarks/compare.sh to compare performance against a previous release
+  - runs JSON-Schema-Test-Suite against custom keywords and dialects, with per-draft selection and skipping of optional tests, using package testsuite
+  - opt-in memoization of subschema results within validation using ValidateOptions.Memoize, and caching of validity across validations by content hash using ResultCache
+  - deterministic order of validation errors, independent of map iteration, so that error output can be compared in tests and golden files
+  - warning severity for format, deprecated and custom keywords using Compiler.Severities, reported separately from errors by Schema.ValidateWithWarnings
+  - validation against multiple schema versions, selected by version in instance such as "/apiVersion" or given out of band, using SchemaSet
+  - process-wide registry of named schemas compiled lazily and concurrently-safely, using Register and Get
+  - grouping of leaf errors by failing keyword, with counts and sample instance locations, using ValidationError.Aggregate
+  - additionalProperties false errors report each offending property as its own cause, with "did you mean" suggestions from properties
+  - enum and const errors for strings suggest the nearest allowed values, in message and in KeywordError.Suggestions
+  - typed limit, actual value and exclusiveness of failed numeric, length and count constraints using KeywordError.Bound
+  - generates Markdown and HTML documentation with property tables, types, constraints, descriptions and examples, using package docs
+  - generates typescript interfaces and types from schemas, for frontend consumers, using package tsgen
+  - collects annotations such as readOnly, deprecated using Schema.ValidateWithAnnotations
+  - validation can be traced, for coverage reports or profiling, using Schema.ValidateWithHook
+  - reports evaluation counts and time spent per schema location, to find expensive anyOf fan-out or regexes, using ValidateOptions.Profile
+  - reports oneOf/anyOf, then/else and enum branches not exercised by test instances using Coverage
+  - fast validity check without building errors using Schema.Valid
+  - numbers beyond float64 precision are validated exactly as json.Number, *big.Int, *big.Float or *big.Rat. DecodeJSON decodes instances preserving precision
+  - validates raw bytes of any media type using Schema.ValidateBytes, with decoders registered per media type in InstanceDecoders, such as yaml, toml, cbor or messagepack
+  - limits the number of errors reported, or stops at first error, using Schema.ValidateWithOptions
+  - validates newline-delimited json (NDJSON, JSON Lines) streams line by line using Schema.ValidateLines
+  - validates csv rows as objects, with cells coerced to expected types and errors located at row and column, using Schema.ValidateCSV
   - rich, intuitive hierarchial error messages with json-pointers to exact location
-  - supports output formats flag, basic and detailed
-  - supports enabling format and content Assertions in draft2019-09 or above
+  - instance locations are RFC 6901 json-pointers, which can be evaluated using package jsonpointer
+  - line, column and byte offset of invalid values in raw json, using Schema.ValidateJSON
+  - picks the most relevant error of oneOf/anyOf failures using ValidationError.BestMatch
+  - error messages can be localized or rephrased using Compiler.Translator
+  - renders errors as sentences for end users, with per keyword templates, using package render
+  - offending instance values can be captured into errors, truncated or redacted, using Compiler.ErrorValue
+  - supports custom error messages in schema via errorMessage keyword, by setting Compiler.AllowErrorMessage to true
+  - supports OpenAPI style discriminator keyword for oneOf, by setting Compiler.AllowDiscriminator to true
+  - supports OpenAPI 3.0 and 3.1 schema dialects using OpenAPI30 and OpenAPI31
+  - regex engine is pluggable using Compiler.CompileRegex, and non ECMA-262 regex syntax can be rejected using Compiler.StrictRegex
+  - reports all problems in schema at once, instead of stopping at first, by setting Compiler.CollectErrors to true
+  - backports if/then/else to draft4 and draft6, and dependentSchemas/dependentRequired to draft7 and below, by setting Compiler.BackportKeywords to true
+  - strict mode rejects unknown keywords, ignored keywords and contradicting limits, by setting Compiler.Strict to true
+  - unresolved references are reported as RefError with base uri chain, locations searched and "did you mean" suggestion
+  - resolves windows and UNC file paths and "../" in file urls consistently, and lists the absolute url of every loaded resource using Compiler.Resources
+  - propertyNames failures are reported at the offending property name, with ValidationError.PropertyName set
+  - supports output formats flag, basic, detailed and verbose
+  - ValidationError and SchemaError can be marshalled to json directly. ValidationError.Leaves gives flat list of errors, and ValidationError.Flatten gives them as issues with keyword, params and severity
+  - supports enabling format and content Assertions in draft2019-09 or above, where format is asserted only if format-assertion vocabulary is enabled
   - change Compiler.AssertFormat, Compiler.AssertContent to true
-  - compiled schema can be introspected. easier to develop tools like generating go structs given schema
+  - compiled schema can be introspected using Schema.Walk, Schema.Subschemas. easier to develop tools like generating go structs given schema
+  - validates instance fragments, such as the subtree changed by PATCH request, against subschema addressed by json-pointer or anchor using Compiler.CompileRef
+  - Schema.Resolve looks up subschema by json-pointer, anchor or absolute location. useful to map ValidationError.KeywordLocation back to the schema
+  - bundles schema with all external references into single self-contained document using Compiler.Bundle, or inlines all references using Compiler.Deref
+  - exposes graph of schema resources a schema depends on, for cache invalidation and vendoring, using Compiler.Dependencies
+  - downloads remote schemas referenced by a schema into local directory with manifest, for offline builds, using Compiler.Vendor
+  - supports $data references for cross-field constraints, by setting Compiler.AllowData to true
   - supports user-defined keywords via extensions
+  - supports custom vocabularies via Compiler.RegisterVocabulary, enabled by $vocabulary of meta-schema
   - implements following formats (supports user-defined)
   - date-time, date, time, duration (supports leap-second)
-  - uuid, hostname, email
+  - uuid, hostname, email, idn-hostname, idn-email
   - ip-address, ipv4, ipv6
-  - uri, uriref, uri-template(limited validation)
+  - uri, uriref, uri-reference, iri, iri-reference, uri-template
   - json-pointer, relative-json-pointer
   - regex, format
+  - relaxed checks of earlier versions can be enabled per format using Compiler.LenientFormats
   - implements following contentEncoding (supports user-defined)
   - base64
+  - base32
+  - base16
   - implements following contentMediaType (supports user-defined)
   - application/json
   - can load from files/http/https/string/[]byte/io.Reader (supports user-defined)
+  - adds directory trees of schemas from fs.FS (e.g. embed.FS) matching glob, where "**" matches any directories, using Compiler.AddResourceFS
+  - can compile without network access, using pre-fetched remote schemas from Compiler.AddRemoteFS (e.g. embed.FS) or Compiler.AddResources, and Compiler.Offline
 
 The schema is compiled against the version specified in "$schema" property.
 If "$schema" property is missing, it uses latest draft which currently implemented
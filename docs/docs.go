@@ -0,0 +1,475 @@
+// Package docs generates Markdown and HTML documentation from compiled
+// json-schemas, with a table of properties for each object schema.
+//
+// Typical usage:
+//
+//	compiler := jsonschema.NewCompiler()
+//	compiler.ExtractAnnotations = true // for titles, descriptions, defaults and examples
+//	sch, err := compiler.Compile("config.json")
+//	if err != nil {
+//		return err
+//	}
+//	g := &docs.Generator{MaxDepth: 3}
+//	md := g.Markdown(sch)
+//
+// The documentation has a section for the schema, and for each object
+// schema nested in its properties and items. Each section lists the
+// properties with their type, whether they are required, their constraints
+// and description. Subschemas in "$ref" and "allOf" are merged, so that
+// properties from referenced schemas are documented in place. A schema
+// reachable from several properties, such as $defs referenced many times,
+// has single section that is linked from each of them.
+//
+// Since compiled schema does not preserve the order of properties, they
+// are listed in sorted order of names.
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+// Generator generates documentation of schemas.
+type Generator struct {
+	// MaxDepth is the nesting depth of object schemas, beyond which
+	// sections are not generated. The schema itself is at depth 0.
+	// Properties beyond MaxDepth are still listed in the table of their
+	// parent, without link. <= 0 means no limit.
+	MaxDepth int
+}
+
+// Section documents an object schema.
+type Section struct {
+	Anchor      string // unique id of section, to link to it
+	Title       string
+	Description string
+	Location    string // absolute location of schema
+	Rows        []Row
+	Examples    []string // json text of examples
+}
+
+// Row documents a property of object schema.
+type Row struct {
+	Name        string
+	Type        string // e.g. "string", "array of address", "integer | null"
+	Link        string // Anchor of section documenting the property value. empty if none
+	Required    bool
+	Deprecated  bool
+	Constraints []string // e.g. "minLength: 3", "format: email"
+	Description string
+}
+
+// Sections returns the sections documenting sch, with the section of sch
+// first, followed by nested object schemas in depth-first order.
+func (g *Generator) Sections(sch *jsonschema.Schema) []*Section {
+	w := &walker{g: g, sections: make(map[*jsonschema.Schema]*Section), anchors: make(map[string]bool)}
+	name := sch.Title
+	if name == "" {
+		name = schemautil.LocationName(sch.Location)
+	}
+	w.section(sch, name, 0)
+	return w.list
+}
+
+// Markdown returns the documentation of sch in Markdown.
+func (g *Generator) Markdown(sch *jsonschema.Schema) string {
+	var buf bytes.Buffer
+	for i, sec := range g.Sections(sch) {
+		level := "##"
+		if i == 0 {
+			level = "#"
+		}
+		fmt.Fprintf(&buf, "%s %s <a id=%q></a>\n\n", level, sec.Title, sec.Anchor)
+		if sec.Description != "" {
+			fmt.Fprintf(&buf, "%s\n\n", sec.Description)
+		}
+		if len(sec.Rows) > 0 {
+			buf.WriteString("| Property | Type | Required | Constraints | Description |\n")
+			buf.WriteString("|---|---|---|---|---|\n")
+			for _, row := range sec.Rows {
+				name := "`" + row.Name + "`"
+				if row.Deprecated {
+					name = "~~" + name + "~~"
+				}
+				typ := cell(row.Type)
+				if row.Link != "" {
+					typ = fmt.Sprintf("[%s](#%s)", typ, row.Link)
+				}
+				required := ""
+				if row.Required {
+					required = "yes"
+				}
+				constraints := make([]string, len(row.Constraints))
+				for i, c := range row.Constraints {
+					constraints[i] = cell(c)
+				}
+				fmt.Fprintf(&buf, "| %s | %s | %s | %s | %s |\n", name, typ, required, strings.Join(constraints, "<br>"), cell(row.Description))
+			}
+			buf.WriteString("\n")
+		}
+		for _, ex := range sec.Examples {
+			fmt.Fprintf(&buf, "Example:\n\n```json\n%s\n```\n\n", ex)
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n") + "\n"
+}
+
+// cell escapes s to be used in Markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+var htmlTemplate = template.Must(template.New("docs").Parse(`{{range $i, $sec := .}}
+{{if eq $i 0}}<h1 id="{{$sec.Anchor}}">{{$sec.Title}}</h1>{{else}}<h2 id="{{$sec.Anchor}}">{{$sec.Title}}</h2>{{end}}
+{{with $sec.Description}}<p>{{.}}</p>
+{{end}}{{if $sec.Rows}}<table>
+<thead><tr><th>Property</th><th>Type</th><th>Required</th><th>Constraints</th><th>Description</th></tr></thead>
+<tbody>
+{{range $sec.Rows}}<tr><td>{{if .Deprecated}}<del><code>{{.Name}}</code></del>{{else}}<code>{{.Name}}</code>{{end}}</td><td>{{if .Link}}<a href="#{{.Link}}">{{.Type}}</a>{{else}}{{.Type}}{{end}}</td><td>{{if .Required}}yes{{end}}</td><td>{{range $j, $c := .Constraints}}{{if $j}}<br>{{end}}{{$c}}{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{range $sec.Examples}}<p>Example:</p>
+<pre><code>{{.}}</code></pre>
+{{end}}{{end}}`))
+
+// HTML returns the documentation of sch as HTML fragment, which can be
+// embedded in a page.
+func (g *Generator) HTML(sch *jsonschema.Schema) string {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, g.Sections(sch)); err != nil {
+		panic(err) // template is constant, and data is well formed
+	}
+	return strings.TrimLeft(buf.String(), "\n")
+}
+
+type walker struct {
+	g        *Generator
+	list     []*Section
+	sections map[*jsonschema.Schema]*Section // object schemas documented so far
+	anchors  map[string]bool
+}
+
+// section returns the section documenting object schema sch with given
+// name, generating it if not done already. returns nil if sch is beyond
+// MaxDepth.
+func (w *walker) section(sch *jsonschema.Schema, name string, depth int) *Section {
+	if sec, ok := w.sections[sch]; ok {
+		return sec
+	}
+	if w.g.MaxDepth > 0 && depth > w.g.MaxDepth {
+		return nil
+	}
+	all := parts(sch)
+	sec := &Section{
+		Anchor:      w.anchor(name),
+		Title:       name,
+		Description: description(all),
+		Location:    sch.Location,
+	}
+	for _, p := range all {
+		for _, ex := range p.Examples {
+			sec.Examples = append(sec.Examples, jsonText(ex, "  "))
+		}
+	}
+	w.sections[sch] = sec
+	w.list = append(w.list, sec)
+
+	props, required := schemautil.Properties(sch)
+	names := make([]string, 0, len(props))
+	for pname := range props {
+		names = append(names, pname)
+	}
+	sort.Strings(names)
+	for _, pname := range names {
+		p := props[pname]
+		pparts := parts(p)
+		row := Row{
+			Name:        pname,
+			Type:        typeOf(pparts),
+			Required:    required[pname],
+			Constraints: constraints(pparts),
+			Description: description(pparts),
+		}
+		for _, pp := range pparts {
+			row.Deprecated = row.Deprecated || pp.Deprecated
+		}
+		if obj, array := objectOf(p); obj != nil {
+			child := w.section(obj, childName(obj, name, pname), depth+1)
+			if child != nil {
+				row.Link = child.Anchor
+				if array {
+					row.Type = "array of " + child.Title
+				} else {
+					row.Type = child.Title
+				}
+			}
+		}
+		sec.Rows = append(sec.Rows, row)
+	}
+	return sec
+}
+
+// anchor returns unique anchor for section with given name.
+func (w *walker) anchor(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ', r == '.', r == '/':
+			b.WriteRune('-')
+		}
+	}
+	anchor := b.String()
+	if anchor == "" {
+		anchor = "schema"
+	}
+	for i := 2; w.anchors[anchor]; i++ {
+		anchor = strings.TrimSuffix(anchor, "-"+strconv.Itoa(i-1)) + "-" + strconv.Itoa(i)
+	}
+	w.anchors[anchor] = true
+	return anchor
+}
+
+// childName returns title of section for object schema obj, found in
+// property pname of section with given name.
+func childName(obj *jsonschema.Schema, name, pname string) string {
+	for _, p := range parts(obj) {
+		if p.Title != "" {
+			return p.Title
+		}
+	}
+	if _, frag, _ := strings.Cut(obj.Location, "#"); strings.Contains(frag, "/$defs/") || strings.Contains(frag, "/definitions/") {
+		return schemautil.LocationName(obj.Location)
+	}
+	return name + "." + pname
+}
+
+// parts returns sch along with subschemas in its $ref and allOf, which
+// together describe the value.
+func parts(sch *jsonschema.Schema) []*jsonschema.Schema {
+	var list []*jsonschema.Schema
+	seen := make(map[*jsonschema.Schema]bool)
+	var collect func(s *jsonschema.Schema)
+	collect = func(s *jsonschema.Schema) {
+		if s == nil || seen[s] {
+			return
+		}
+		seen[s] = true
+		list = append(list, s)
+		collect(s.Ref)
+		for _, sub := range s.AllOf {
+			collect(sub)
+		}
+	}
+	collect(sch)
+	return list
+}
+
+// objectOf returns the object schema of sch, or of its items, whose
+// properties are to be documented in its own section.
+func objectOf(sch *jsonschema.Schema) (obj *jsonschema.Schema, array bool) {
+	if schemautil.HasProperties(sch) {
+		return target(sch), false
+	}
+	for _, p := range parts(sch) {
+		items := p.Items2020
+		if s, ok := p.Items.(*jsonschema.Schema); ok {
+			items = s
+		}
+		if items != nil && schemautil.HasProperties(items) {
+			return target(items), true
+		}
+	}
+	return nil, false
+}
+
+// target returns the schema referenced by sch, if sch has nothing but
+// $ref, so that a schema referenced from many places has single section.
+func target(sch *jsonschema.Schema) *jsonschema.Schema {
+	for sch.Ref != nil && len(sch.Properties) == 0 && len(sch.AllOf) == 0 && sch.Title == "" && sch.Description == "" {
+		sch = sch.Ref
+	}
+	return sch
+}
+
+func description(parts []*jsonschema.Schema) string {
+	for _, p := range parts {
+		if p.Description != "" {
+			return p.Description
+		}
+	}
+	for _, p := range parts {
+		if p.Title != "" {
+			return p.Title
+		}
+	}
+	return ""
+}
+
+// typeOf returns the type of value described by parts.
+func typeOf(parts []*jsonschema.Schema) string {
+	for _, p := range parts {
+		if len(p.Types) == 0 {
+			continue
+		}
+		types := make([]string, len(p.Types))
+		for i, t := range p.Types {
+			types[i] = t
+			if t == "array" {
+				if item := itemType(p); item != "" {
+					types[i] = "array of " + item
+				}
+			}
+		}
+		return strings.Join(types, " | ")
+	}
+	for _, p := range parts {
+		switch {
+		case len(p.Constant) > 0:
+			return jsonType(p.Constant[0])
+		case len(p.Enum) > 0:
+			return jsonType(p.Enum[0])
+		case len(p.OneOf) > 0:
+			return alternatives(p.OneOf)
+		case len(p.AnyOf) > 0:
+			return alternatives(p.AnyOf)
+		}
+	}
+	return "any"
+}
+
+// itemType returns type of items of array schema s.
+func itemType(s *jsonschema.Schema) string {
+	items := s.Items2020
+	if sch, ok := s.Items.(*jsonschema.Schema); ok {
+		items = sch
+	}
+	if items == nil {
+		return ""
+	}
+	return typeOf(parts(items))
+}
+
+func alternatives(list []*jsonschema.Schema) string {
+	var types []string
+	for _, sch := range list {
+		types = append(types, typeOf(parts(sch)))
+	}
+	return strings.Join(types, " | ")
+}
+
+// constraints returns the constraints in parts, in human readable form.
+func constraints(parts []*jsonschema.Schema) []string {
+	var list []string
+	add := func(format string, a ...interface{}) {
+		list = append(list, fmt.Sprintf(format, a...))
+	}
+	for _, p := range parts {
+		if len(p.Constant) > 0 {
+			add("const: %s", jsonText(p.Constant[0], ""))
+		}
+		if len(p.Enum) > 0 {
+			values := make([]string, len(p.Enum))
+			for i, v := range p.Enum {
+				values[i] = jsonText(v, "")
+			}
+			add("one of: %s", strings.Join(values, ", "))
+		}
+		if p.Format != "" {
+			add("format: %s", p.Format)
+		}
+		if p.Pattern != nil {
+			add("pattern: %s", p.Pattern.String())
+		}
+		for _, limit := range []struct {
+			name  string
+			value int
+		}{
+			{"minLength", p.MinLength}, {"maxLength", p.MaxLength},
+			{"minItems", p.MinItems}, {"maxItems", p.MaxItems},
+			{"minProperties", p.MinProperties}, {"maxProperties", p.MaxProperties},
+		} {
+			if limit.value != -1 {
+				add("%s: %d", limit.name, limit.value)
+			}
+		}
+		if p.UniqueItems {
+			add("uniqueItems")
+		}
+		for _, limit := range []struct {
+			name  string
+			value *big.Rat
+		}{
+			{"minimum", p.Minimum}, {"exclusiveMinimum", p.ExclusiveMinimum},
+			{"maximum", p.Maximum}, {"exclusiveMaximum", p.ExclusiveMaximum},
+			{"multipleOf", p.MultipleOf},
+		} {
+			if limit.value != nil {
+				add("%s: %s", limit.name, number(limit.value))
+			}
+		}
+		if p.Default != nil {
+			add("default: %s", jsonText(p.Default, ""))
+		}
+		if p.ReadOnly {
+			add("readOnly")
+		}
+		if p.WriteOnly {
+			add("writeOnly")
+		}
+		if p.Deprecated {
+			add("deprecated")
+		}
+	}
+	return list
+}
+
+func number(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// jsonText returns v as json text, indented if indent is not empty.
+func jsonText(v interface{}, indent string) string {
+	var b []byte
+	var err error
+	if indent == "" {
+		b, err = json.Marshal(v)
+	} else {
+		b, err = json.MarshalIndent(v, "", indent)
+	}
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "number"
+}
@@ -0,0 +1,126 @@
+package docs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/docs"
+)
+
+const schema = `{
+	"title": "Config",
+	"description": "Service configuration.",
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "description": "Service name | id"},
+		"port": {"type": "integer", "minimum": 1, "maximum": 65535, "default": 8080},
+		"mode": {"enum": ["dev", "prod"]},
+		"legacy": {"type": "boolean", "deprecated": true},
+		"home": {"$ref": "#/$defs/address"},
+		"offices": {"type": "array", "items": {"$ref": "#/$defs/address"}},
+		"tls": {
+			"type": "object",
+			"properties": {
+				"cert": {"type": "string"},
+				"client": {"type": "object", "properties": {"ca": {"type": "string"}}}
+			}
+		}
+	},
+	"examples": [{"name": "api"}],
+	"$defs": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string"}},
+			"allOf": [{"properties": {"zip": {"type": "string", "pattern": "^[0-9]+$"}}}]
+		}
+	}
+}`
+
+func compile(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("config.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func TestGenerator_Markdown(t *testing.T) {
+	got := (&docs.Generator{MaxDepth: 1}).Markdown(compile(t))
+	want := "# Config <a id=\"config\"></a>\n" +
+		"\n" +
+		"Service configuration.\n" +
+		"\n" +
+		"| Property | Type | Required | Constraints | Description |\n" +
+		"|---|---|---|---|---|\n" +
+		"| `home` | [address](#address) |  |  |  |\n" +
+		"| ~~`legacy`~~ | boolean |  | deprecated |  |\n" +
+		"| `mode` | string |  | one of: \"dev\", \"prod\" |  |\n" +
+		"| `name` | string | yes | minLength: 1 | Service name \\| id |\n" +
+		"| `offices` | [array of address](#address) |  |  |  |\n" +
+		"| `port` | integer |  | minimum: 1<br>maximum: 65535<br>default: 8080 |  |\n" +
+		"| `tls` | [Config.tls](#config-tls) |  |  |  |\n" +
+		"\n" +
+		"Example:\n" +
+		"\n" +
+		"```json\n" +
+		"{\n" +
+		"  \"name\": \"api\"\n" +
+		"}\n" +
+		"```\n" +
+		"\n" +
+		"## address <a id=\"address\"></a>\n" +
+		"\n" +
+		"| Property | Type | Required | Constraints | Description |\n" +
+		"|---|---|---|---|---|\n" +
+		"| `city` | string | yes |  |  |\n" +
+		"| `zip` | string |  | pattern: ^[0-9]+$ |  |\n" +
+		"\n" +
+		"## Config.tls <a id=\"config-tls\"></a>\n" +
+		"\n" +
+		"| Property | Type | Required | Constraints | Description |\n" +
+		"|---|---|---|---|---|\n" +
+		"| `cert` | string |  |  |  |\n" +
+		"| `client` | object |  |  |  |\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerator_Sections(t *testing.T) {
+	sections := (&docs.Generator{}).Sections(compile(t))
+	var titles []string
+	for _, sec := range sections {
+		titles = append(titles, sec.Title)
+	}
+	if got, want := strings.Join(titles, ","), "Config,address,Config.tls,Config.tls.client"; got != want {
+		t.Fatalf("titles: got %s, want %s", got, want)
+	}
+	if loc := sections[1].Location; !strings.HasSuffix(loc, "config.json#/$defs/address") {
+		t.Fatalf("location: got %s", loc)
+	}
+}
+
+func TestGenerator_HTML(t *testing.T) {
+	got := (&docs.Generator{}).HTML(compile(t))
+	for _, want := range []string{
+		`<h1 id="config">Config</h1>`,
+		`<p>Service configuration.</p>`,
+		`<tr><td><code>home</code></td><td><a href="#address">address</a></td>`,
+		`<tr><td><del><code>legacy</code></del></td>`,
+		`<td>one of: &#34;dev&#34;, &#34;prod&#34;</td>`,
+		`<h2 id="config-tls-client">Config.tls.client</h2>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in:\n%s", want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -15,9 +16,17 @@ type Draft struct {
 	vocab        []string // built-in vocab
 	defaultVocab []string // vocabs when $vocabulary is not used
 	subschemas   map[string]position
+	keywords     map[string]bool // keywords defined by draft
+	openapi      bool            // is OpenAPI dialect
 }
 
 func (d *Draft) URL() string {
+	switch d {
+	case OpenAPI30:
+		return "" // OpenAPI 3.0 has no dialect url
+	case OpenAPI31:
+		return "https://spec.openapis.org/oas/3.1/dialect/base"
+	}
 	switch d.version {
 	case 2020:
 		return "https://json-schema.org/draft/2020-12/schema"
@@ -34,6 +43,12 @@ func (d *Draft) URL() string {
 }
 
 func (d *Draft) String() string {
+	switch d {
+	case OpenAPI30:
+		return "OpenAPI30"
+	case OpenAPI31:
+		return "OpenAPI31"
+	}
 	return fmt.Sprintf("Draft%d", d.version)
 }
 
@@ -78,6 +93,17 @@ func (d *Draft) resolveID(base string, sch interface{}) (string, error) {
 }
 
 func (d *Draft) anchors(sch interface{}) []string {
+	anchors := d.plainAnchors(sch)
+	if m, ok := sch.(map[string]interface{}); ok {
+		if v, ok := m["$dynamicAnchor"]; ok && d.version >= 2020 {
+			anchors = append(anchors, v.(string))
+		}
+	}
+	return anchors
+}
+
+// plainAnchors returns anchors in sch, excluding $dynamicAnchor.
+func (d *Draft) plainAnchors(sch interface{}) []string {
 	m, ok := sch.(map[string]interface{})
 	if !ok {
 		return nil
@@ -94,9 +120,6 @@ func (d *Draft) anchors(sch interface{}) []string {
 	if v, ok := m["$anchor"]; ok && d.version >= 2019 {
 		anchors = append(anchors, v.(string))
 	}
-	if v, ok := m["$dynamicAnchor"]; ok && d.version >= 2020 {
-		anchors = append(anchors, v.(string))
-	}
 	return anchors
 }
 
@@ -107,14 +130,13 @@ func (d *Draft) listSubschemas(r *resource, base string, rr map[string]*resource
 		if err != nil {
 			return err
 		}
-		floc := r.floc + "/" + loc
-		sr := &resource{url: url, floc: floc, doc: sch}
-		rr[floc] = sr
-
 		base := base
 		if url != "" {
 			base = url
 		}
+		floc := r.floc + "/" + loc
+		sr := &resource{url: url, floc: floc, doc: sch, base: base}
+		rr[floc] = sr
 		return d.listSubschemas(sr, base, rr)
 	}
 
@@ -163,6 +185,51 @@ func (d *Draft) listSubschemas(r *resource, base string, rr map[string]*resource
 	return nil
 }
 
+// findBoolSchema returns json-pointer of first boolean schema in sch at
+// ptr, visiting keywords in sorted order. It is used to report boolean
+// schemas in drafts, where they are not valid. Note that boolean value of
+// additionalProperties and additionalItems is not a schema.
+func (d *Draft) findBoolSchema(sch interface{}, ptr string) (string, bool) {
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		_, ok := sch.(bool)
+		return ptr, ok
+	}
+	kws := make([]string, 0, len(d.subschemas))
+	for kw := range d.subschemas {
+		kws = append(kws, kw)
+	}
+	sort.Strings(kws)
+	for _, kw := range kws {
+		pos := d.subschemas[kw]
+		switch v := m[kw].(type) {
+		case map[string]interface{}:
+			if pos&prop != 0 {
+				for _, pname := range sortedKeys(v) {
+					if loc, ok := d.findBoolSchema(v[pname], ptr+"/"+kw+"/"+escape(pname)); ok {
+						return loc, true
+					}
+				}
+			} else if loc, ok := d.findBoolSchema(v, ptr+"/"+kw); ok {
+				return loc, true
+			}
+		case []interface{}:
+			if pos&item != 0 {
+				for i, item := range v {
+					if loc, ok := d.findBoolSchema(item, ptr+"/"+kw+"/"+strconv.Itoa(i)); ok {
+						return loc, true
+					}
+				}
+			}
+		case bool:
+			if pos&self != 0 && kw != "additionalProperties" && kw != "additionalItems" {
+				return ptr + "/" + kw, true
+			}
+		}
+	}
+	return "", false
+}
+
 // isVocab tells whether url is built-in vocab.
 func (d *Draft) isVocab(url string) bool {
 	for _, v := range d.vocab {
@@ -226,6 +293,20 @@ var (
 		},
 	}
 
+	// OpenAPI30 is the dialect used by schema objects in OpenAPI 3.0
+	// documents. It is based on draft4, and additionally supports
+	// "nullable" and "discriminator" keywords. Annotations "example",
+	// "readOnly", "writeOnly" and "deprecated" are also extracted.
+	//
+	// OpenAPI 3.0 has no dialect url, so this must be set in Compiler.Draft.
+	OpenAPI30 = &Draft{version: 4, boolSchema: false, openapi: true}
+
+	// OpenAPI31 is the OpenAPI 3.1 base dialect. It is based on draft2020,
+	// and additionally supports "discriminator" keyword and "example"
+	// annotation. It is used for schemas with "$schema" set to
+	// https://spec.openapis.org/oas/3.1/dialect/base.
+	OpenAPI31 = &Draft{version: 2020, id: "$id", boolSchema: true, openapi: true}
+
 	latest = Draft2020
 )
 
@@ -249,6 +330,8 @@ func findDraft(url string) *Draft {
 		return Draft6
 	case "https://json-schema.org/draft-04/schema":
 		return Draft4
+	case "https://spec.openapis.org/oas/3.1/dialect/base":
+		return OpenAPI31
 	}
 	return nil
 }
@@ -290,6 +373,48 @@ func init() {
 
 	subschemas["prefixItems"] = item
 	Draft2020.subschemas = clone(subschemas)
+	OpenAPI30.subschemas = clone(Draft4.subschemas)
+	OpenAPI31.subschemas = clone(Draft2020.subschemas)
+
+	keywords := map[string]bool{}
+	add := func(kw ...string) {
+		for _, kw := range kw {
+			keywords[kw] = true
+		}
+	}
+	add("$schema", "id", "$ref", "definitions", "title", "description", "default", "format",
+		"multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum",
+		"maxLength", "minLength", "pattern",
+		"items", "additionalItems", "maxItems", "minItems", "uniqueItems",
+		"maxProperties", "minProperties", "required", "properties", "patternProperties",
+		"additionalProperties", "dependencies", "enum", "type", "allOf", "anyOf", "oneOf", "not")
+	Draft4.keywords = cloneKeywords(keywords)
+
+	delete(keywords, "id")
+	add("$id", "examples", "contains", "propertyNames", "const")
+	Draft6.keywords = cloneKeywords(keywords)
+
+	add("$comment", "if", "then", "else", "readOnly", "writeOnly", "contentEncoding", "contentMediaType")
+	Draft7.keywords = cloneKeywords(keywords)
+
+	add("$anchor", "$defs", "$vocabulary", "$recursiveRef", "$recursiveAnchor", "deprecated",
+		"unevaluatedProperties", "unevaluatedItems", "dependentSchemas", "dependentRequired",
+		"maxContains", "minContains", "contentSchema")
+	Draft2019.keywords = cloneKeywords(keywords)
+
+	delete(keywords, "$recursiveRef")
+	delete(keywords, "$recursiveAnchor")
+	add("$dynamicRef", "$dynamicAnchor", "prefixItems")
+	Draft2020.keywords = cloneKeywords(keywords)
+
+	add("discriminator", "xml", "externalDocs", "example")
+	OpenAPI31.keywords = cloneKeywords(keywords)
+
+	OpenAPI30.keywords = cloneKeywords(Draft4.keywords)
+	delete(OpenAPI30.keywords, "id")
+	for _, kw := range []string{"nullable", "discriminator", "readOnly", "writeOnly", "xml", "externalDocs", "example", "deprecated"} {
+		OpenAPI30.keywords[kw] = true
+	}
 
 	Draft4.loadMeta("http://json-schema.org/draft-04/schema", `{
 		"$schema": "http://json-schema.org/draft-04/schema#",
@@ -867,6 +992,11 @@ func init() {
 			}
 		}
 	}`)
+
+	OpenAPI30.meta = Draft4.meta
+	OpenAPI31.meta = Draft2020.meta
+	OpenAPI31.vocab = Draft2020.vocab
+	OpenAPI31.defaultVocab = Draft2020.defaultVocab
 }
 
 var vocabSchemas = map[string]string{
@@ -1445,6 +1575,14 @@ var vocabSchemas = map[string]string{
 	}`,
 }
 
+func cloneKeywords(m map[string]bool) map[string]bool {
+	mm := make(map[string]bool, len(m))
+	for k, v := range m {
+		mm[k] = v
+	}
+	return mm
+}
+
 func clone(m map[string]position) map[string]position {
 	mm := make(map[string]position)
 	for k, v := range m {
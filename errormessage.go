@@ -0,0 +1,189 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// errorMessage is the compiled form of "errorMessage" keyword.
+type errorMessage struct {
+	all        string            // used when errorMessage is string
+	keywords   map[string]string // keyword to message
+	required   map[string]string // property to message, when required property is missing
+	properties map[string]string // property to message, when property is invalid
+	other      string            // "_": message for all other errors
+}
+
+func compileErrorMessage(v interface{}) (*errorMessage, error) {
+	em := &errorMessage{}
+	switch v := v.(type) {
+	case string:
+		em.all = v
+		return em, nil
+	case map[string]interface{}:
+		for kw, msg := range v {
+			switch kw {
+			case "properties", "required":
+				if msg, ok := msg.(string); ok && kw == "required" {
+					em.keyword(kw, msg)
+					continue
+				}
+				m, ok := msg.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("errorMessage/%s must be object", kw)
+				}
+				messages := make(map[string]string, len(m))
+				for pname, pmsg := range m {
+					pmsg, ok := pmsg.(string)
+					if !ok {
+						return nil, fmt.Errorf("errorMessage/%s/%s must be string", kw, escape(pname))
+					}
+					messages[pname] = pmsg
+				}
+				if kw == "required" {
+					em.required = messages
+				} else {
+					em.properties = messages
+				}
+			default:
+				msg, ok := msg.(string)
+				if !ok {
+					return nil, fmt.Errorf("errorMessage/%s must be string", escape(kw))
+				}
+				if kw == "_" {
+					em.other = msg
+				} else {
+					em.keyword(kw, msg)
+				}
+			}
+		}
+		return em, nil
+	}
+	return nil, fmt.Errorf("errorMessage must be string or object")
+}
+
+func (em *errorMessage) keyword(kw, msg string) {
+	if em.keywords == nil {
+		em.keywords = make(map[string]string)
+	}
+	em.keywords[kw] = msg
+}
+
+// apply replaces errors reported by schema s for value v at instance
+// location vloc, with the custom messages.
+func (em *errorMessage) apply(s *Schema, errors []error, v interface{}, vloc string, validationError func(keywordPath string, format string, a ...interface{}) *ValidationError) []error {
+	newError := func(keywordPath, msg string, limit, value interface{}, replaced ...*ValidationError) *ValidationError {
+		msg = strings.ReplaceAll(msg, "${value}", formatValue(value))
+		msg = strings.ReplaceAll(msg, "${limit}", formatValue(limit))
+		return validationError(joinPtr("errorMessage", keywordPath), "%s", msg).values(nil, replaced)
+	}
+
+	var all []*ValidationError
+	for _, err := range errors {
+		all = append(all, err.(*ValidationError))
+	}
+	if em.all != "" {
+		return []error{newError("", em.all, nil, v, all...)}
+	}
+
+	var result []error
+	var others []*ValidationError
+	for _, ve := range all {
+		// error for property
+		if strings.HasPrefix(ve.InstanceLocation, vloc+"/") {
+			if token := strings.SplitN(ve.InstanceLocation[len(vloc)+1:], "/", 2)[0]; token != "" {
+				pname := jsonpointer.Unescape(token)
+				if msg, ok := em.properties[pname]; ok {
+					var pvalue interface{}
+					if obj, ok := v.(map[string]interface{}); ok {
+						pvalue = obj[pname]
+					}
+					e := newError("properties/"+escape(pname), msg, nil, pvalue, ve)
+					e.InstanceLocation = vloc + "/" + token
+					result = append(result, e)
+					continue
+				}
+			}
+		}
+
+		// error for keyword of s
+		keyword := strings.TrimPrefix(strings.TrimPrefix(ve.AbsoluteKeywordLocation, s.Location), "/")
+		keyword, _, _ = strings.Cut(keyword, "/")
+		if keyword == "required" && ve.KeywordError != nil && len(em.required) > 0 {
+			var missing []string
+			for _, pname := range ve.KeywordError.Got.([]string) {
+				if msg, ok := em.required[pname]; ok {
+					result = append(result, newError("required/"+escape(pname), msg, pname, v, ve))
+				} else {
+					missing = append(missing, pname)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			quoted := make([]string, len(missing))
+			for i, pname := range missing {
+				quoted[i] = quote(pname)
+			}
+			ve = validationError("required", "missing properties: %s", strings.Join(quoted, ", ")).values(s.Required, missing)
+		}
+		if msg, ok := em.keywords[keyword]; ok && keyword != "" {
+			var limit interface{}
+			if ve.KeywordError != nil && ve.KeywordError.Keyword == keyword {
+				limit = ve.KeywordError.Want
+			}
+			result = append(result, newError(escape(keyword), msg, limit, v, ve))
+			continue
+		}
+		others = append(others, ve)
+	}
+
+	if len(others) > 0 {
+		if em.other != "" {
+			result = append(result, newError("_", em.other, nil, v, others...))
+		} else {
+			for _, ve := range others {
+				result = append(result, ve)
+			}
+		}
+	}
+	return result
+}
+
+// formatValue formats v to be used in error message.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case *big.Rat:
+		if v.IsInt() {
+			return v.Num().String()
+		}
+		f, _ := v.Float64()
+		return fmt.Sprint(f)
+	case []string:
+		return strings.Join(v, ", ")
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// unescape converts json-pointer token, escaped using escape, to string.
+func unescape(token string) string {
+	if t, err := url.PathUnescape(token); err == nil {
+		token = t
+	}
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
@@ -0,0 +1,100 @@
+package jsonschema_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestErrorMessage(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["name", "age", "email"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "errorMessage": "name must be at least 2 characters"},
+			"age": {
+				"type": "integer",
+				"minimum": 18,
+				"errorMessage": {"type": "age must be a number", "minimum": "age ${value} is below ${limit}"}
+			},
+			"email": {"type": "string", "format": "email"},
+			"zip": {"type": "string", "pattern": "^[0-9]{5}$"}
+		},
+		"errorMessage": {
+			"required": {"name": "name is required", "age": "age is required"},
+			"properties": {"zip": "zip ${value} is not valid"}
+		}
+	}`
+
+	compile := func(t *testing.T, allow bool) *jsonschema.Schema {
+		t.Helper()
+		c := jsonschema.NewCompiler()
+		c.AllowErrorMessage = allow
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		sch, err := c.Compile("schema.json")
+		if err != nil {
+			t.Fatalf("%#v", err)
+		}
+		return sch
+	}
+
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"string form", `{"name": "a", "age": 20, "email": "a@b.com"}`, []string{"name must be at least 2 characters"}},
+		{"keyword form", `{"name": "john", "age": "x", "email": "a@b.com"}`, []string{"age must be a number"}},
+		{"limit placeholder", `{"name": "john", "age": 10, "email": "a@b.com"}`, []string{"age 10 is below 18"}},
+		{"required", `{"email": "a@b.com"}`, []string{"age is required", "name is required"}},
+		{"required partial", `{"name": "john", "age": 20}`, []string{"missing properties: 'email'"}},
+		{"property", `{"name": "john", "age": 20, "email": "a@b.com", "zip": "1"}`, []string{"zip 1 is not valid"}},
+	}
+	sch := compile(t, true)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sch.Validate(decodeString(t, test.doc))
+			ve, ok := err.(*jsonschema.ValidationError)
+			if !ok {
+				t.Fatalf("want ValidationError, got %v", err)
+			}
+			var got []string
+			var collect func(ve *jsonschema.ValidationError)
+			collect = func(ve *jsonschema.ValidationError) {
+				if len(ve.Causes) == 0 {
+					got = append(got, ve.Message)
+				}
+				for _, c := range ve.Causes {
+					collect(c)
+				}
+			}
+			collect(ve)
+			sort.Strings(got)
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Fatalf("messages: got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		err := compile(t, false).Validate(decodeString(t, `{"name": "a", "age": 20, "email": "a@b.com"}`))
+		if err == nil || strings.Contains(err.Error(), "name must be") {
+			t.Fatalf("errorMessage must be ignored, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.AllowErrorMessage = true
+		if err := c.AddResource("schema.json", strings.NewReader(`{"errorMessage": {"type": 1}}`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Compile("schema.json"); err == nil {
+			t.Fatal("compile must fail")
+		}
+	})
+}
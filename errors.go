@@ -1,8 +1,13 @@
 package jsonschema
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
+	"unicode/utf8"
 )
 
 // InvalidJSONTypeError is the error type returned by ValidateInterface.
@@ -27,10 +32,37 @@ func infiniteLoopError(stack []schemaRef, sref schemaRef) InfiniteLoopError {
 		if path == "" {
 			path += ref.schema.Location
 		} else {
-			path += "/" + ref.path
+			path += "/" + ref.relPath()
 		}
 	}
-	return InfiniteLoopError(path + "/" + sref.path)
+	return InfiniteLoopError(path + "/" + sref.relPath())
+}
+
+// LimitExceededError is returned when a limit in Compiler.Limits or
+// ValidateOptions is exceeded. Compile returns it wrapped in *SchemaError,
+// and Validate returns it as is.
+type LimitExceededError struct {
+	// Limit is the name of the field in Limits or ValidateOptions.
+	// e.g. "MaxRefDepth".
+	Limit string
+
+	// Max is the value of the limit.
+	Max int
+
+	// URL is the location of schema or document, at which the limit
+	// is exceeded.
+	URL string
+
+	// InstanceLocation is the location of the json value exceeding
+	// the limit in ValidateOptions. empty for other limits.
+	InstanceLocation string
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.InstanceLocation != "" {
+		return fmt.Sprintf("jsonschema: %s %d exceeded at %s in %s", e.Limit, e.Max, quote(e.InstanceLocation), e.URL)
+	}
+	return fmt.Sprintf("jsonschema: %s %d exceeded in %s", e.Limit, e.Max, e.URL)
 }
 
 // SchemaError is the error type returned by Compile.
@@ -58,19 +90,257 @@ func (se *SchemaError) Error() string {
 }
 
 func (se *SchemaError) GoString() string {
-	if _, ok := se.Err.(*ValidationError); ok {
+	switch se.Err.(type) {
+	case *ValidationError, *RefError:
 		return fmt.Sprintf("jsonschema %s compilation failed\n%#v", se.SchemaURL, se.Err)
 	}
 	return se.Error()
 }
 
+// RefError is the error returned by Compile, wrapped in *SchemaError,
+// when a reference such as $ref cannot be resolved.
+type RefError struct {
+	// Keyword is the location of reference, relative to schema with it.
+	// e.g. "$ref", "$dynamicRef". Keyword, Ref, Location and BaseURIs are
+	// empty if the url passed to Compile is not found.
+	Keyword string
+
+	// Ref is the reference, as specified in schema.
+	Ref string
+
+	// Location is the absolute location of schema with the reference.
+	Location string
+
+	// BaseURIs is the chain of base uris, against which Ref is resolved.
+	// It starts with url of the document, followed by those established by
+	// $id of enclosing subschemas. The last one is used to resolve Ref.
+	BaseURIs []string
+
+	// URL is the absolute url, that Ref resolves to.
+	URL string
+
+	// Searched lists the locations searched for URL.
+	Searched []string
+
+	// Suggestion is the known location, nearest to URL. It is relative
+	// to the document if URL is not found within the document. Empty if
+	// there is no suggestion.
+	Suggestion string
+
+	// Err is the error in loading the document of URL. nil if document
+	// is loaded, but the fragment of URL is not found in it.
+	Err error
+}
+
+func (e *RefError) Error() string {
+	var msg string
+	if e.Keyword == "" {
+		msg = "jsonschema: "
+	} else {
+		msg = fmt.Sprintf("jsonschema: %s %q in %s: ", e.Keyword, e.Ref, e.Location)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf("cannot load %s: %s", e.URL, strings.TrimPrefix(e.Err.Error(), "jsonschema: "))
+	} else {
+		msg += e.URL + " not found"
+	}
+	if len(e.BaseURIs) > 1 {
+		msg += fmt.Sprintf(" (base uri %s)", strings.Join(e.BaseURIs, " -> "))
+	}
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf("; did you mean %q?", e.Suggestion)
+	}
+	return msg
+}
+
+func (e *RefError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RefError) GoString() string {
+	msg := e.Error()
+	if len(e.BaseURIs) > 0 {
+		msg += "\n  base uris: " + strings.Join(e.BaseURIs, " -> ")
+	}
+	for _, loc := range e.Searched {
+		msg += "\n  searched: " + loc
+	}
+	return msg
+}
+
+// CompileErrors is the error wrapped by SchemaError, when
+// Compiler.CollectErrors is set and more than one problem is found in schema.
+type CompileErrors []error
+
+func (e CompileErrors) Error() string {
+	msg := fmt.Sprintf("jsonschema: %d errors", len(e))
+	for _, err := range e {
+		msg += "\n  - " + strings.TrimPrefix(err.Error(), "jsonschema: ")
+	}
+	return msg
+}
+
+// Unwrap returns the errors, so that errors.Is and errors.As
+// of go1.20 or later can look into each of them.
+func (e CompileErrors) Unwrap() []error {
+	return e
+}
+
+// Is tells whether any of the errors matches target, so that
+// errors.Is can look into each of them, with any version of go.
+func (e CompileErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target, so that
+// errors.As can look into each of them, with any version of go.
+func (e CompileErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidationError is the error type returned by Validate.
+//
+// Causes are ordered deterministically, so that error output can be compared
+// across runs:
+//
+//   - keywords of a schema are in fixed order of evaluation. subschemas in
+//     arrays such as allOf, anyOf, oneOf and prefixItems are in the order
+//     they appear in schema
+//   - subschemas keyed by name, in properties, patternProperties,
+//     dependencies, dependentRequired and dependentSchemas, are in sorted
+//     order of property name or pattern
+//   - errors for properties of instance, such as from additionalProperties,
+//     propertyNames and unevaluatedProperties, are in sorted order of
+//     property name. errors for items are in order of index
 type ValidationError struct {
 	KeywordLocation         string             // validation path of validating keyword or schema
 	AbsoluteKeywordLocation string             // absolute location of validating keyword or schema
 	InstanceLocation        string             // location of the json value within the instance being validated
 	Message                 string             // describes error
+	KeywordError            *KeywordError      // keyword that failed. nil, if this error just groups causes
 	Causes                  []*ValidationError // nested validation errors
+	Position                *Position          // position of the json value in raw json. set only by Schema.ValidateJSON
+	Value                   interface{}        // offending instance value, as captured by Compiler.ErrorValue. nil if not set
+
+	// PropertyName tells that the error is about the name of property at
+	// InstanceLocation, rather than its value. It is set for errors
+	// reported by "propertyNames", which is a ValidationError with
+	// KeywordError.Got set to the invalid property name, and its causes.
+	PropertyName bool
+}
+
+// KeywordError tells which keyword failed validation.
+//
+// Use errors.As on *ValidationError to get the KeywordError of the
+// leaf error, that is reported by ValidationError.Error. errors.As
+// follows only the first cause, so KeywordError of other causes is not
+// found by it; use ValidationError.Leaves to inspect every failure.
+type KeywordError struct {
+	// Keyword is the name of keyword that failed. e.g. "required", "pattern".
+	// Keyword is "false" if validation failed due to false schema.
+	Keyword string
+
+	// Want is the value of keyword in schema. e.g. []string for "required",
+	// *big.Rat for "minimum". For "dependencies" and "dependentRequired",
+	// it is the property that is required, and Got is the property that
+	// requires it.
+	Want interface{}
+
+	// Got is the offending instance value or its measure, such as length
+	// for "minLength", number of properties for "maxProperties".
+	// For "required", it is the list of missing properties. For "uniqueItems",
+	// it is the indexes of duplicate items. For "errorMessage", it is the
+	// list of errors replaced by the custom message. For "propertyNames",
+	// it is the invalid property name. For "additionalProperties", it is
+	// the list of additional properties, and in each of its causes, the
+	// additional property.
+	Got interface{}
+
+	// Suggestions are the allowed values nearest to Got by edit distance,
+	// nearest first, to tell what may have been meant. It is set for causes
+	// of "additionalProperties" error, from names in "properties", and for
+	// "enum" and "const" errors of strings, from allowed strings.
+	Suggestions []string
+}
+
+func (ke *KeywordError) Error() string {
+	return fmt.Sprintf("jsonschema: %s failed: want %v, got %v", ke.Keyword, ke.Want, ke.Got)
+}
+
+// Bound is the limit of failed numeric, length or count constraint,
+// in typed form. see KeywordError.Bound.
+type Bound struct {
+	// Keyword is the keyword that failed, e.g. "minLength", "maximum".
+	Keyword string
+
+	// Limit is the value of the keyword. For limits on length and count,
+	// it is an integer.
+	Limit *big.Rat
+
+	// Actual is the instance value for "minimum", "maximum",
+	// "exclusiveMinimum" and "exclusiveMaximum". For others, it is the
+	// length or count found in the instance.
+	Actual *big.Rat
+
+	// Lower tells whether Limit is lower bound, as in "minItems",
+	// rather than upper bound.
+	Lower bool
+
+	// Exclusive tells whether Limit itself is out of bounds, as in
+	// "exclusiveMinimum".
+	Exclusive bool
+}
+
+// Bound returns the limit of failed constraint, for keywords "minimum",
+// "maximum", "exclusiveMinimum", "exclusiveMaximum", "minLength",
+// "maxLength", "minItems", "maxItems", "minProperties", "maxProperties",
+// "minContains" and "maxContains". returns nil for other keywords.
+//
+// This lets programs, such as form UIs, tell the limit without parsing
+// error message.
+func (ke *KeywordError) Bound() *Bound {
+	b := &Bound{Keyword: ke.Keyword}
+	switch ke.Keyword {
+	case "minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties", "minContains":
+		b.Lower = true
+	case "maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties", "maxContains":
+	default:
+		return nil
+	}
+	b.Exclusive = strings.HasPrefix(ke.Keyword, "exclusive")
+	switch want := ke.Want.(type) {
+	case *big.Rat:
+		b.Limit = want
+	case int:
+		b.Limit = new(big.Rat).SetInt64(int64(want))
+	default:
+		return nil
+	}
+	switch got := ke.Got.(type) {
+	case int:
+		b.Actual = new(big.Rat).SetInt64(int64(got))
+	default:
+		b.Actual = ratValue(got)
+	}
+	return b
+}
+
+// values sets Want and Got of ve.KeywordError.
+func (ve *ValidationError) values(want, got interface{}) *ValidationError {
+	if ve.KeywordError != nil { // nil in quick mode
+		ve.KeywordError.Want, ve.KeywordError.Got = want, got
+	}
+	return ve
 }
 
 func (ve *ValidationError) add(causes ...error) error {
@@ -80,6 +350,14 @@ func (ve *ValidationError) add(causes ...error) error {
 	return ve
 }
 
+// markPropertyName sets PropertyName of ve and its causes.
+func (ve *ValidationError) markPropertyName() {
+	ve.PropertyName = true
+	for _, c := range ve.Causes {
+		c.markPropertyName()
+	}
+}
+
 func (ve *ValidationError) causes(err error) error {
 	if err := err.(*ValidationError); err.Message == "" {
 		ve.Causes = err.Causes
@@ -89,13 +367,142 @@ func (ve *ValidationError) causes(err error) error {
 	return ve
 }
 
+// limit removes the leaf errors in the error tree beyond first n.
+// returns the number of leaf errors retained.
+func (ve *ValidationError) limit(n int) int {
+	if len(ve.Causes) == 0 {
+		return 1
+	}
+	count := 0
+	for i, c := range ve.Causes {
+		if count == n {
+			ve.Causes = ve.Causes[:i]
+			break
+		}
+		count += c.limit(n - count)
+	}
+	return count
+}
+
+// translate replaces the messages in error tree, with those returned by fn.
+func (ve *ValidationError) translate(fn func(ve *ValidationError) string) {
+	ve.Message = fn(ve)
+	for _, c := range ve.Causes {
+		c.translate(fn)
+	}
+}
+
 func (ve *ValidationError) Error() string {
 	leaf := ve
 	for len(leaf.Causes) > 0 {
 		leaf = leaf.Causes[0]
 	}
 	u, _ := split(ve.AbsoluteKeywordLocation)
-	return fmt.Sprintf("jsonschema: %s does not validate with %s: %s", quote(leaf.InstanceLocation), u+"#"+leaf.KeywordLocation, leaf.Message)
+	iloc := quote(leaf.InstanceLocation)
+	if p := leaf.Position; p != nil {
+		iloc += fmt.Sprintf(" (line %d, column %d)", p.Line, p.Column)
+	}
+	return fmt.Sprintf("jsonschema: %s does not validate with %s: %s", iloc, u+"#"+leaf.KeywordLocation, leaf.Message)
+}
+
+// Unwrap returns the first cause. If there are no causes, it returns
+// KeywordError. Thus the chain of Unwrap leads to the leaf error
+// reported by Error. Causes other than the first are not reachable by
+// errors.Is and errors.As; use Leaves for them.
+func (ve *ValidationError) Unwrap() error {
+	if len(ve.Causes) > 0 {
+		return ve.Causes[0]
+	}
+	if ve.KeywordError != nil {
+		return ve.KeywordError
+	}
+	return nil
+}
+
+// BestMatch returns the leaf error, that is most relevant to the user.
+//
+// When anyOf or oneOf fails, the error tree contains the failures of every
+// branch. BestMatch picks the branch that matched the instance best, i.e.
+// the branch with fewest errors, preferring the one whose errors are at
+// deeper instance location on tie. Branches which fail just because of type
+// mismatch are picked only as last resort. For other errors, it picks the
+// cause about the instance itself, in preference to its nested values and to
+// anyOf, oneOf failures.
+func (ve *ValidationError) BestMatch() *ValidationError {
+	best := ve
+	for len(best.Causes) > 0 {
+		causes := best.Causes
+		if ke := best.KeywordError; ke != nil && (ke.Keyword == "anyOf" || ke.Keyword == "oneOf") {
+			iloc := best.InstanceLocation
+			best = causes[0]
+			for _, c := range causes[1:] {
+				if c.betterMatch(best, iloc) {
+					best = c
+				}
+			}
+			continue
+		}
+		best = causes[0]
+		for _, c := range causes[1:] {
+			if c.lessRelevant(best) {
+				continue
+			}
+			if best.lessRelevant(c) || c.InstanceLocation+"#"+c.KeywordLocation < best.InstanceLocation+"#"+best.KeywordLocation {
+				best = c
+			}
+		}
+	}
+	return best
+}
+
+// betterMatch tells whether branch ve matched better than branch other,
+// when both are causes of anyOf or oneOf at instance location iloc.
+func (ve *ValidationError) betterMatch(other *ValidationError, iloc string) bool {
+	typeMismatch := func(ve *ValidationError) bool {
+		leaf := ve
+		for len(leaf.Causes) == 1 {
+			leaf = leaf.Causes[0]
+		}
+		return len(leaf.Causes) == 0 && leaf.KeywordError != nil && leaf.KeywordError.Keyword == "type" && leaf.InstanceLocation == iloc
+	}
+	if t1, t2 := typeMismatch(ve), typeMismatch(other); t1 != t2 {
+		return t2
+	}
+	d1, n1 := ve.leafStats()
+	d2, n2 := other.leafStats()
+	if n1 != n2 {
+		return n1 < n2
+	}
+	return d1 > d2
+}
+
+// leafStats returns the maximum instance depth and the number of
+// leaf errors in ve.
+func (ve *ValidationError) leafStats() (depth, count int) {
+	if len(ve.Causes) == 0 {
+		return strings.Count(ve.InstanceLocation, "/"), 1
+	}
+	for _, c := range ve.Causes {
+		d, n := c.leafStats()
+		if d > depth {
+			depth = d
+		}
+		count += n
+	}
+	return depth, count
+}
+
+// lessRelevant tells whether ve is less relevant than other,
+// when both are causes of same error.
+func (ve *ValidationError) lessRelevant(other *ValidationError) bool {
+	weak := func(ve *ValidationError) bool {
+		return ve.KeywordError != nil && (ve.KeywordError.Keyword == "anyOf" || ve.KeywordError.Keyword == "oneOf")
+	}
+	d1, d2 := strings.Count(ve.InstanceLocation, "/"), strings.Count(other.InstanceLocation, "/")
+	if d1 != d2 {
+		return d1 > d2
+	}
+	return weak(ve) && !weak(other)
 }
 
 func (ve *ValidationError) GoString() string {
@@ -110,6 +517,28 @@ func (ve *ValidationError) GoString() string {
 	return msg
 }
 
+// TruncateValue returns a function to be used as Compiler.ErrorValue, which
+// captures instance values as json text, truncated to n bytes. Truncated
+// values end with "...".
+func TruncateValue(n int) func(ve *ValidationError, v interface{}) interface{} {
+	return func(_ *ValidationError, v interface{}) interface{} {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return nil
+		}
+		b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		if len(b) <= n {
+			return string(b)
+		}
+		for n > 0 && !utf8.RuneStart(b[n]) {
+			n--
+		}
+		return string(b[:n]) + "..."
+	}
+}
+
 func joinPtr(ptr1, ptr2 string) string {
 	if len(ptr1) == 0 {
 		return ptr2
@@ -0,0 +1,598 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestKeywordError(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"required": ["name", "age"],
+		"properties": {
+			"name": {"type": "string", "pattern": "^[a-z]+$"},
+			"age": {"type": "integer", "minimum": 18}
+		}
+	}`)
+	tests := []struct {
+		instance string
+		keyword  string
+		want     interface{}
+		got      interface{}
+	}{
+		{`{"name": "john"}`, "required", []string{"name", "age"}, []string{"age"}},
+		{`{"name": "John", "age": 20}`, "pattern", "^[a-z]+$", "John"},
+		{`{"name": "john", "age": 10}`, "minimum", big.NewRat(18, 1), nil},
+		{`[]`, "type", []string{"object"}, "array"},
+	}
+	for _, test := range tests {
+		t.Run(test.keyword, func(t *testing.T) {
+			err := sch.Validate(decodeString(t, test.instance))
+			var kwErr *jsonschema.KeywordError
+			if !errors.As(err, &kwErr) {
+				t.Fatalf("errors.As failed for %#v", err)
+			}
+			if kwErr.Keyword != test.keyword {
+				t.Errorf("keyword: got %q, want %q", kwErr.Keyword, test.keyword)
+			}
+			switch want := test.want.(type) {
+			case *big.Rat:
+				if got, ok := kwErr.Want.(*big.Rat); !ok || got.Cmp(want) != 0 {
+					t.Errorf("want: got %v, want %v", kwErr.Want, want)
+				}
+			default:
+				if !reflect.DeepEqual(kwErr.Want, want) {
+					t.Errorf("want: got %v, want %v", kwErr.Want, want)
+				}
+			}
+			if test.got != nil && !reflect.DeepEqual(kwErr.Got, test.got) {
+				t.Errorf("got: got %v, want %v", kwErr.Got, test.got)
+			}
+		})
+	}
+
+	t.Run("second cause", func(t *testing.T) {
+		err := sch.Validate(decodeString(t, `{"name": "John", "age": 10}`))
+		var kwErr *jsonschema.KeywordError
+		if !errors.As(err, &kwErr) || kwErr.Keyword != "minimum" {
+			t.Fatalf("got %#v, want KeywordError for minimum of first cause", err)
+		}
+		var ve *jsonschema.ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("got %#v, want ValidationError", err)
+		}
+		var keywords []string
+		for _, leaf := range ve.Leaves() {
+			if !errors.As(leaf, &kwErr) {
+				t.Fatalf("got %#v, want KeywordError", leaf)
+			}
+			keywords = append(keywords, kwErr.Keyword)
+		}
+		if want := []string{"minimum", "pattern"}; !reflect.DeepEqual(keywords, want) {
+			t.Errorf("got %v, want %v", keywords, want)
+		}
+	})
+
+	t.Run("false schema", func(t *testing.T) {
+		err := jsonschema.MustCompileString("false.json", `false`).Validate(1)
+		var kwErr *jsonschema.KeywordError
+		if !errors.As(err, &kwErr) || kwErr.Keyword != "false" {
+			t.Fatalf("got %#v, want KeywordError for false", err)
+		}
+	})
+}
+
+func TestKeywordError_Bound(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {
+			"name": {"minLength": 3},
+			"qty": {"maximum": 100},
+			"price": {"exclusiveMinimum": 0},
+			"tags": {"maxItems": 2},
+			"step": {"multipleOf": 5}
+		}
+	}`)
+	tests := []struct {
+		instance string
+		want     *jsonschema.Bound
+	}{
+		{`{"name": "ab"}`, &jsonschema.Bound{Keyword: "minLength", Limit: big.NewRat(3, 1), Actual: big.NewRat(2, 1), Lower: true}},
+		{`{"qty": 100.5}`, &jsonschema.Bound{Keyword: "maximum", Limit: big.NewRat(100, 1), Actual: big.NewRat(201, 2)}},
+		{`{"price": 0}`, &jsonschema.Bound{Keyword: "exclusiveMinimum", Limit: big.NewRat(0, 1), Actual: big.NewRat(0, 1), Lower: true, Exclusive: true}},
+		{`{"tags": [1, 2, 3]}`, &jsonschema.Bound{Keyword: "maxItems", Limit: big.NewRat(2, 1), Actual: big.NewRat(3, 1)}},
+		{`{"step": 7}`, nil},
+	}
+	for _, test := range tests {
+		var kwErr *jsonschema.KeywordError
+		if err := sch.Validate(decodeString(t, test.instance)); !errors.As(err, &kwErr) {
+			t.Fatalf("%s: got %#v, want KeywordError", test.instance, err)
+		}
+		got := kwErr.Bound()
+		if (got == nil) != (test.want == nil) {
+			t.Fatalf("%s: got %+v, want %+v", test.instance, got, test.want)
+		}
+		if got == nil {
+			continue
+		}
+		if got.Keyword != test.want.Keyword || got.Limit.Cmp(test.want.Limit) != 0 || got.Actual.Cmp(test.want.Actual) != 0 ||
+			got.Lower != test.want.Lower || got.Exclusive != test.want.Exclusive {
+			t.Errorf("%s: got %+v, want %+v", test.instance, got, test.want)
+		}
+	}
+}
+
+func TestPropertyNamesError(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {
+			"labels": {"propertyNames": {"pattern": "^[a-z]+$", "maxLength": 5}}
+		}
+	}`)
+	err := sch.Validate(decodeString(t, `{"labels": {"ok": 1, "Bad": 2, "a/b": 3}}`))
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %#v, want *ValidationError", err)
+	}
+	got := map[string]string{}
+	var walk func(ve *jsonschema.ValidationError)
+	walk = func(ve *jsonschema.ValidationError) {
+		if ke := ve.KeywordError; ke != nil && ke.Keyword == "propertyNames" {
+			if !ve.PropertyName {
+				t.Errorf("%s: PropertyName not set", ve.InstanceLocation)
+			}
+			for _, c := range ve.Causes {
+				if !c.PropertyName || c.InstanceLocation != ve.InstanceLocation {
+					t.Errorf("%s: cause %s is not about property name", ve.InstanceLocation, c.InstanceLocation)
+				}
+			}
+			got[ve.InstanceLocation] = ke.Got.(string)
+			return
+		}
+		if ve.PropertyName {
+			t.Errorf("%s: PropertyName must not be set", ve.InstanceLocation)
+		}
+		for _, c := range ve.Causes {
+			walk(c)
+		}
+	}
+	walk(ve)
+	want := map[string]string{"/labels/Bad": "Bad", "/labels/a~1b": "a/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestAdditionalPropertiesError(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {"name": {}, "names": {}, "timeout": {}},
+		"additionalProperties": false
+	}`)
+	err := sch.Validate(decodeString(t, `{"nmae": 1, "timeot": 2, "zzz": 3}`))
+	ve := err.(*jsonschema.ValidationError).Causes[0]
+	if ke := ve.KeywordError; ke == nil || ke.Keyword != "additionalProperties" {
+		t.Fatalf("got %#v, want additionalProperties error", ve)
+	}
+	if ve.PropertyName {
+		t.Error("PropertyName must not be set on parent")
+	}
+	type cause struct {
+		loc         string
+		got         interface{}
+		suggestions []string
+		msg         string
+	}
+	var got []cause
+	for _, c := range ve.Causes {
+		if !c.PropertyName {
+			t.Errorf("%s: PropertyName not set", c.InstanceLocation)
+		}
+		got = append(got, cause{c.InstanceLocation, c.KeywordError.Got, c.KeywordError.Suggestions, c.Message})
+	}
+	want := []cause{
+		{"/nmae", "nmae", []string{"name"}, "additionalProperty 'nmae' not allowed; did you mean 'name'?"},
+		{"/timeot", "timeot", []string{"timeout"}, "additionalProperty 'timeot' not allowed; did you mean 'timeout'?"},
+		{"/zzz", "zzz", nil, "additionalProperty 'zzz' not allowed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestEnumSuggestions(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {
+			"env": {"enum": ["production", "staging", "development"]},
+			"mode": {"const": "strict"}
+		}
+	}`)
+	tests := []struct {
+		instance    string
+		keyword     string
+		suggestions []string
+		message     string
+	}{
+		{`{"env": "produciton"}`, "enum", []string{"production"}, `value must be one of "production", "staging", "development"; did you mean 'production'?`},
+		{`{"env": "qa"}`, "enum", nil, `value must be one of "production", "staging", "development"`},
+		{`{"env": 2}`, "enum", nil, `value must be one of "production", "staging", "development"`},
+		{`{"mode": "strcit"}`, "const", []string{"strict"}, `value must be "strict"; did you mean 'strict'?`},
+	}
+	for _, test := range tests {
+		err := sch.Validate(decodeString(t, test.instance))
+		var kwErr *jsonschema.KeywordError
+		if !errors.As(err, &kwErr) || kwErr.Keyword != test.keyword {
+			t.Fatalf("%s: got %#v, want %s error", test.instance, err, test.keyword)
+		}
+		if !reflect.DeepEqual(kwErr.Suggestions, test.suggestions) {
+			t.Errorf("%s: suggestions: got %q, want %q", test.instance, kwErr.Suggestions, test.suggestions)
+		}
+		if got := err.(*jsonschema.ValidationError).BestMatch().Message; got != test.message {
+			t.Errorf("%s: message:\n got %s\nwant %s", test.instance, got, test.message)
+		}
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.Translator = func(ve *jsonschema.ValidationError) string {
+		if ve.KeywordError == nil {
+			return "ne valide pas"
+		}
+		switch ve.KeywordError.Keyword {
+		case "required":
+			return fmt.Sprintf("propriétés manquantes: %s", strings.Join(ve.KeywordError.Got.([]string), ", "))
+		case "type":
+			return fmt.Sprintf("attendu %s, mais reçu %s", strings.Join(ve.KeywordError.Want.([]string), " ou "), ve.KeywordError.Got)
+		}
+		return ve.Message
+	}
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"required": ["name"],
+		"properties": {
+			"age": {"$ref": "#/$defs/age"}
+		},
+		"$defs": {
+			"age": {"type": "integer", "minimum": 18}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	err = sch.Validate(decodeString(t, `{"age": "ten"}`))
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("want ValidationError, got %v", err)
+	}
+	var msgs []string
+	var collect func(ve *jsonschema.ValidationError)
+	collect = func(ve *jsonschema.ValidationError) {
+		msgs = append(msgs, ve.Message)
+		for _, c := range ve.Causes {
+			collect(c)
+		}
+	}
+	collect(ve)
+	got := strings.Join(msgs, "\n")
+	for _, want := range []string{"ne valide pas", "propriétés manquantes: name", "attendu integer, mais reçu string"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("messages %q do not contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "missing properties") {
+		t.Errorf("messages %q are not translated", got)
+	}
+}
+
+func TestErrorValue(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	truncate := jsonschema.TruncateValue(10)
+	c.ErrorValue = func(ve *jsonschema.ValidationError, v interface{}) interface{} {
+		if ve.InstanceLocation == "/password" {
+			return "***"
+		}
+		return truncate(ve, v)
+	}
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"properties": {
+			"password": {"minLength": 8},
+			"bio": {"maxLength": 5},
+			"age": {"maximum": 10}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	err = sch.Validate(decodeString(t, `{"password": "secret", "bio": "<b>héééééé</b>", "age": 42}`))
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("want ValidationError, got %v", err)
+	}
+	want := map[string]interface{}{
+		"/password": "***",
+		"/bio":      `"<b>héé...`,
+		"/age":      "42",
+	}
+	for _, c := range ve.Causes {
+		if got := c.Value; got != want[c.InstanceLocation] {
+			t.Errorf("%s: got %v, want %v", c.InstanceLocation, got, want[c.InstanceLocation])
+		}
+	}
+	if len(ve.Causes) != len(want) {
+		t.Errorf("got %d causes, want %d", len(ve.Causes), len(want))
+	}
+
+	t.Run("not set", func(t *testing.T) {
+		err := jsonschema.MustCompileString("schema.json", `{"maximum": 10}`).Validate(decodeString(t, `42`))
+		if ve, ok := err.(*jsonschema.ValidationError); !ok || ve.Value != nil || ve.Causes[0].Value != nil {
+			t.Fatalf("values must not be captured, got %#v", err)
+		}
+	})
+}
+
+func TestBestMatch(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"shape": {
+				"oneOf": [
+					{"type": "string"},
+					{
+						"type": "object",
+						"required": ["kind", "radius"],
+						"properties": {
+							"kind": {"const": "circle"},
+							"radius": {"type": "number"}
+						}
+					},
+					{
+						"type": "object",
+						"required": ["kind", "side"],
+						"properties": {
+							"kind": {"const": "square"},
+							"side": {"type": "number"}
+						}
+					}
+				]
+			},
+			"name": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+		},
+		"required": ["name"]
+	}`)
+
+	tests := []struct {
+		name    string
+		doc     string
+		keyword string
+		iloc    string
+	}{
+		{"deepest branch", `{"name": "a", "shape": {"kind": "circle", "radius": "ten"}}`, "type", "/shape/radius"},
+		{"fewer errors", `{"name": "a", "shape": {"kind": "square"}}`, "required", "/shape"},
+		{"type mismatch last", `{"name": "a", "shape": 1}`, "type", "/shape"},
+		{"prefer shallow", `{"shape": {"kind": "circle", "radius": "ten"}}`, "required", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sch.Validate(decodeString(t, test.doc))
+			ve, ok := err.(*jsonschema.ValidationError)
+			if !ok {
+				t.Fatalf("want ValidationError, got %v", err)
+			}
+			best := ve.BestMatch()
+			if best.KeywordError == nil || best.KeywordError.Keyword != test.keyword || best.InstanceLocation != test.iloc {
+				t.Fatalf("got %#v", best)
+			}
+		})
+	}
+}
+
+func TestRefError(t *testing.T) {
+	compile := func(t *testing.T, schema string) *jsonschema.RefError {
+		t.Helper()
+		c := jsonschema.NewCompiler()
+		c.LoadURL = func(s string) (io.ReadCloser, error) {
+			return nil, fmt.Errorf("%s does not exist", s)
+		}
+		if err := c.AddResource("http://example.com/schemas/address.json", strings.NewReader(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.AddResource("http://example.com/schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := c.Compile("http://example.com/schema.json")
+		var re *jsonschema.RefError
+		if !errors.As(err, &re) {
+			t.Fatalf("got %v, want *RefError", err)
+		}
+		t.Log(err)
+		return re
+	}
+
+	tests := []struct {
+		name       string
+		ref        string
+		suggestion string
+	}{
+		{"typo", "#/$defs/Adress", "#/$defs/Address"},
+		{"definitions", "#/definitions/Address", "#/$defs/Address"},
+		{"anchor", "#adress", "#address"},
+		{"no suggestion", "#/$defs/Person", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			re := compile(t, `{
+				"properties": {"a": {"$ref": "`+test.ref+`"}},
+				"$defs": {"Address": {"$anchor": "address"}}
+			}`)
+			if re.Keyword != "$ref" || re.Ref != test.ref || re.Location != "http://example.com/schema.json#/properties/a" {
+				t.Errorf("got keyword %q, ref %q, location %q", re.Keyword, re.Ref, re.Location)
+			}
+			if re.URL != "http://example.com/schema.json"+test.ref {
+				t.Errorf("url: got %q", re.URL)
+			}
+			if re.Suggestion != test.suggestion {
+				t.Errorf("suggestion: got %q, want %q", re.Suggestion, test.suggestion)
+			}
+			if test.suggestion != "" && !strings.Contains(re.Error(), fmt.Sprintf("did you mean %q?", test.suggestion)) {
+				t.Errorf("error must have suggestion: %v", re)
+			}
+			if len(re.Searched) == 0 || re.Err != nil {
+				t.Errorf("got searched %v, err %v", re.Searched, re.Err)
+			}
+		})
+	}
+
+	t.Run("base uris", func(t *testing.T) {
+		re := compile(t, `{
+			"$defs": {
+				"n": {
+					"$id": "nested/n.json",
+					"properties": {"x": {"$ref": "#/$defs/y"}}
+				}
+			},
+			"$ref": "nested/n.json"
+		}`)
+		want := []string{"http://example.com/schema.json", "http://example.com/nested/n.json"}
+		if !reflect.DeepEqual(re.BaseURIs, want) {
+			t.Errorf("got %v, want %v", re.BaseURIs, want)
+		}
+		if re.URL != "http://example.com/nested/n.json#/$defs/y" {
+			t.Errorf("url: got %q", re.URL)
+		}
+	})
+
+	t.Run("external", func(t *testing.T) {
+		re := compile(t, `{"$ref": "schemas/adress.json"}`)
+		if re.Err == nil || re.URL != "http://example.com/schemas/adress.json" {
+			t.Errorf("got url %q, err %v", re.URL, re.Err)
+		}
+		if re.Suggestion != "http://example.com/schemas/address.json" {
+			t.Errorf("suggestion: got %q", re.Suggestion)
+		}
+	})
+}
+
+func TestCompileErrors(t *testing.T) {
+	re := &jsonschema.RefError{Keyword: "$ref", Ref: "#/$defs/a", Err: io.ErrUnexpectedEOF}
+	errs := jsonschema.CompileErrors{errors.New("first"), fmt.Errorf("second: %w", re)}
+
+	// methods are called directly, since errors.Is and errors.As of go1.20
+	// or later would use Unwrap instead
+	if !errs.Is(io.ErrUnexpectedEOF) {
+		t.Error("Is must find error wrapped in second error")
+	}
+	if errs.Is(io.EOF) {
+		t.Error("Is must not find io.EOF")
+	}
+	var got *jsonschema.RefError
+	if !errs.As(&got) || got != re {
+		t.Errorf("As: got %v, want %v", got, re)
+	}
+	var pe *fs.PathError
+	if errs.As(&pe) {
+		t.Errorf("As must not find *fs.PathError: %v", pe)
+	}
+}
+
+func TestValidationError_order(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {
+			"e": {"type": "string"},
+			"c": {"type": "string"},
+			"a": {"type": "string"}
+		},
+		"patternProperties": {
+			"[b-d]": {"type": "string"},
+			"[ab]": {"type": "string"}
+		},
+		"dependentRequired": {
+			"e": ["y"],
+			"a": ["x"]
+		},
+		"unevaluatedProperties": {"type": "string"}
+	}`)
+	inst := decodeString(t, `{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7}`)
+	want := []string{
+		"/properties/a/type",
+		"/properties/c/type",
+		"/properties/e/type",
+		"/patternProperties/%5Bab%5D/type",
+		"/patternProperties/%5Bab%5D/type",
+		"/patternProperties/%5Bb-d%5D/type",
+		"/patternProperties/%5Bb-d%5D/type",
+		"/patternProperties/%5Bb-d%5D/type",
+		"/dependentRequired/a/0",
+		"/dependentRequired/e/0",
+		"/unevaluatedProperties/type",
+		"/unevaluatedProperties/type",
+	}
+	wantInstances := []string{"/a", "/c", "/e", "/a", "/b", "/b", "/c", "/d", "", "", "/f", "/g"}
+	var first string
+	for i := 0; i < 20; i++ {
+		ve := sch.Validate(inst).(*jsonschema.ValidationError)
+		var got, gotInstances []string
+		for _, leaf := range ve.Leaves() {
+			got = append(got, leaf.KeywordLocation)
+			gotInstances = append(gotInstances, leaf.InstanceLocation)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("keyword locations:\n got: %q\nwant: %q", got, want)
+		}
+		if !reflect.DeepEqual(gotInstances, wantInstances) {
+			t.Fatalf("instance locations:\n got: %q\nwant: %q", gotInstances, wantInstances)
+		}
+		if i == 0 {
+			first = ve.GoString()
+		} else if s := ve.GoString(); s != first {
+			t.Fatalf("output changed across runs:\n%s\n%s", first, s)
+		}
+	}
+
+	// with MaxErrors, same errors are retained
+	for i := 0; i < 20; i++ {
+		err := sch.ValidateWithOptions(inst, jsonschema.ValidateOptions{MaxErrors: 2})
+		var got []string
+		for _, leaf := range err.(*jsonschema.ValidationError).Leaves() {
+			got = append(got, leaf.InstanceLocation)
+		}
+		if want := []string{"/a", "/c"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("MaxErrors: got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestValidationError_orderInstanceKeys(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"propertyNames": {"maxLength": 1},
+		"additionalProperties": {"type": "string"}
+	}`)
+	inst := decodeString(t, `{"e": 1, "bb": "x", "a": 2, "dd": "y", "c": 3}`)
+	for _, opts := range []jsonschema.ValidateOptions{{}, {MaxErrors: 4}} {
+		want := []string{"/bb", "/dd", "/a", "/c", "/e"}
+		if opts.MaxErrors > 0 {
+			want = want[:4]
+		}
+		for i := 0; i < 20; i++ {
+			err := sch.ValidateWithOptions(inst, opts)
+			var got []string
+			for _, leaf := range err.(*jsonschema.ValidationError).Leaves() {
+				got = append(got, leaf.InstanceLocation)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("MaxErrors %d: got %q, want %q", opts.MaxErrors, got, want)
+			}
+		}
+	}
+}
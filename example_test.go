@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"testing/fstest"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
@@ -191,3 +193,70 @@ func Example_userDefinedLoader() {
 	}
 	// Output:
 }
+
+// Example_compilerLoadURL shows how to restrict schema loading of a compiler.
+//
+// schemas are served from an fs.FS, and loading any other url, say http,
+// fails even if httploader is imported.
+func Example_compilerLoadURL() {
+	fsys := fstest.MapFS{
+		"schemas/main.json": {Data: []byte(`{"$ref":"obj.json"}`)},
+		"schemas/obj.json":  {Data: []byte(`{"type":"object"}`)},
+	}
+
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "fs" {
+			return nil, jsonschema.LoaderNotFoundError(s)
+		}
+		return fsys.Open(strings.TrimPrefix(u.Path, "/"))
+	}
+
+	sch, err := c.Compile("fs:///schemas/main.json")
+	if err != nil {
+		log.Fatalf("%#v", err)
+	}
+	if err = sch.Validate(map[string]interface{}{}); err != nil {
+		log.Fatalf("%#v", err)
+	}
+
+	_, err = c.Compile("http://example.com/schema.json")
+	fmt.Println(err != nil)
+	// Output:
+	// true
+}
+
+// Example_walk shows how to inspect compiled schema.
+func Example_walk() {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "maxLength": 20},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}}
+		},
+		"required": ["name"],
+		"$defs": {
+			"tag": {"type": "string", "enum": ["red", "green"]}
+		}
+	}`
+
+	sch, err := jsonschema.CompileString("https://example.com/schema.json", schema)
+	if err != nil {
+		log.Fatalf("%#v", err)
+	}
+
+	sch.Walk(func(s *jsonschema.Schema) bool {
+		fmt.Println(s.Location, s.Types, s.Enum)
+		return true
+	})
+	// Output:
+	// https://example.com/schema.json# [object] []
+	// https://example.com/schema.json#/properties/name [string] []
+	// https://example.com/schema.json#/properties/tags [array] []
+	// https://example.com/schema.json#/properties/tags/items [] []
+	// https://example.com/schema.json#/$defs/tag [string] [red green]
+}
@@ -11,13 +11,15 @@ type ExtCompiler interface {
 // ExtSchema is schema representation of custom keyword(s)
 type ExtSchema interface {
 	// Validate validates the json value v with this ExtSchema.
-	// Returned error must be *ValidationError.
+	// Returned error should be *ValidationError, constructed using ctx.Error.
+	// Any other error is wrapped into *ValidationError at schema location.
 	Validate(ctx ValidationContext, v interface{}) error
 }
 
 type extension struct {
 	meta     *Schema
 	compiler ExtCompiler
+	vocab    string // url of vocabulary. empty if not scoped to vocabulary
 }
 
 // RegisterExtension registers custom keyword(s) into this compiler.
@@ -26,7 +28,28 @@ type extension struct {
 // meta captures the metaschema for the new keywords.
 // This is used to validate the schema before calling ext.Compile.
 func (c *Compiler) RegisterExtension(name string, meta *Schema, ext ExtCompiler) {
-	c.extensions[name] = extension{meta, ext}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.extensions[name] = extension{meta, ext, ""}
+}
+
+// RegisterVocabulary registers custom vocabulary with given url, whose
+// keywords are compiled by ext.
+//
+// Unlike RegisterExtension, the keywords are enabled only in schemas whose
+// meta-schema lists url in its $vocabulary, which requires draft2019-09
+// or above. meta captures the metaschema for the keywords, and is used to
+// validate schemas having the vocabulary enabled, before calling ext.Compile.
+func (c *Compiler) RegisterVocabulary(url string, meta *Schema, ext ExtCompiler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.extensions[url] = extension{meta, ext, url}
+}
+
+// isVocab tells whether url is vocabulary registered by RegisterVocabulary.
+func (c *Compiler) isVocab(url string) bool {
+	ext, ok := c.extensions[url]
+	return ok && ext.vocab == url
 }
 
 // CompilerContext ---
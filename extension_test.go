@@ -1,6 +1,7 @@
 package jsonschema_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -47,3 +48,133 @@ func TestPowerOfExt(t *testing.T) {
 		})
 	})
 }
+
+type uniqueKeysCompiler struct{}
+
+func (uniqueKeysCompiler) Compile(ctx jsonschema.CompilerContext, m map[string]interface{}) (jsonschema.ExtSchema, error) {
+	if key, ok := m["uniqueKeys"]; ok {
+		return uniqueKeysSchema(key.(string)), nil
+	}
+	return nil, nil
+}
+
+type uniqueKeysSchema string
+
+func (s uniqueKeysSchema) Validate(ctx jsonschema.ValidationContext, v interface{}) error {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	seen := make(map[interface{}]bool)
+	for _, item := range arr {
+		if obj, ok := item.(map[string]interface{}); ok {
+			key := obj[string(s)]
+			if seen[key] {
+				// plain error, not constructed with ctx.Error
+				return fmt.Errorf("duplicate %s %v", string(s), key)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+func TestExtPlainError(t *testing.T) {
+	meta := jsonschema.MustCompileString("uniqueKeys.json", `{
+		"properties": {
+			"uniqueKeys": {"type": "string"}
+		}
+	}`)
+	c := jsonschema.NewCompiler()
+	c.RegisterExtension("uniqueKeys", meta, uniqueKeysCompiler{})
+	if err := c.AddResource("test.json", strings.NewReader(`{"uniqueKeys": "id"}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sch.Validate(decodeString(t, `[{"id": 1}, {"id": 1}]`))
+	if _, ok := err.(*jsonschema.ValidationError); !ok {
+		t.Fatalf("got %#v, want *jsonschema.ValidationError", err)
+	}
+	if !strings.Contains(err.Error(), "duplicate id 1") {
+		t.Errorf("got %q, want it to contain extension message", err.Error())
+	}
+}
+
+func TestCompiler_RegisterVocabulary(t *testing.T) {
+	const vocab = "https://example.com/vocab/powerOf"
+	metaSchema := func(vocabs string) string {
+		return `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://example.com/meta",
+			"$vocabulary": {
+				"https://json-schema.org/draft/2020-12/vocab/core": true,
+				"https://json-schema.org/draft/2020-12/vocab/applicator": true,
+				"https://json-schema.org/draft/2020-12/vocab/validation": true` + vocabs + `
+			},
+			"$dynamicAnchor": "meta",
+			"allOf": [{"$ref": "https://json-schema.org/draft/2020-12/schema"}]
+		}`
+	}
+	compile := func(meta, schema string) (*jsonschema.Schema, error) {
+		c := jsonschema.NewCompiler()
+		c.RegisterVocabulary(vocab, powerOfMeta, powerOfCompiler{})
+		if err := c.AddResource("https://example.com/meta", strings.NewReader(meta)); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		return c.Compile("schema.json")
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		sch, err := compile(metaSchema(`, "`+vocab+`": true`), `{"$schema": "https://example.com/meta", "powerOf": 10, "minimum": 100}`)
+		if err != nil {
+			t.Fatalf("%#v", err)
+		}
+		if err := sch.Validate(111); err == nil {
+			t.Fatal("powerOf must be validated")
+		}
+		if err := sch.Validate(10); err == nil {
+			t.Fatal("minimum must be validated")
+		}
+		if err := sch.Validate(100); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("invalid keyword value", func(t *testing.T) {
+		if _, err := compile(metaSchema(`, "`+vocab+`": true`), `{"$schema": "https://example.com/meta", "powerOf": "ten"}`); err == nil {
+			t.Fatal("compilation must fail")
+		}
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		for _, sch := range []string{
+			`{"$schema": "https://example.com/meta", "powerOf": 10}`,
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "powerOf": 10}`,
+			`{"powerOf": 10}`,
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "powerOf": "ten"}`,
+		} {
+			s, err := compile(metaSchema(""), sch)
+			if err != nil {
+				t.Fatalf("%s: %v", sch, err)
+			}
+			if err := s.Validate(111); err != nil {
+				t.Fatalf("%s: powerOf must be ignored: %v", sch, err)
+			}
+		}
+	})
+
+	t.Run("unknown vocab", func(t *testing.T) {
+		if _, err := compile(metaSchema(`, "https://example.com/vocab/unknown": true`), `{"$schema": "https://example.com/meta"}`); err == nil {
+			t.Fatal("required unknown vocab must fail")
+		}
+		if _, err := compile(metaSchema(`, "https://example.com/vocab/unknown": false`), `{"$schema": "https://example.com/meta"}`); err != nil {
+			t.Fatalf("optional unknown vocab must be ignored: %v", err)
+		}
+	})
+}
@@ -5,10 +5,10 @@ import (
 	"net"
 	"net/mail"
 	"net/url"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Formats is a registry of functions, which know how to validate
@@ -23,15 +23,17 @@ var Formats = map[string]func(interface{}) bool{
 	"duration":              isDuration,
 	"period":                isPeriod,
 	"hostname":              isHostname,
+	"idn-hostname":          isIDNHostname,
 	"email":                 isEmail,
+	"idn-email":             isIDNEmail,
 	"ip-address":            isIPV4,
 	"ipv4":                  isIPV4,
 	"ipv6":                  isIPV6,
 	"uri":                   isURI,
-	"iri":                   isURI,
+	"iri":                   isIRI,
 	"uri-reference":         isURIReference,
 	"uriref":                isURIReference,
-	"iri-reference":         isURIReference,
+	"iri-reference":         isIRIReference,
 	"uri-template":          isURITemplate,
 	"regex":                 isRegex,
 	"json-pointer":          isJSONPointer,
@@ -39,6 +41,20 @@ var Formats = map[string]func(interface{}) bool{
 	"uuid":                  isUUID,
 }
 
+// lenientFormats has the relaxed checks of formats, which are used instead
+// of Formats, if enabled in Compiler.LenientFormats. These are the checks
+// used before the formats were made spec-accurate.
+var lenientFormats = map[string]func(interface{}) bool{
+	"hostname":      isHostnameLenient,
+	"email":         isEmailLenient,
+	"uri":           isURILenient,
+	"iri":           isURILenient,
+	"uri-reference": isURIReferenceLenient,
+	"uriref":        isURIReferenceLenient,
+	"iri-reference": isURIReferenceLenient,
+	"uri-template":  isURITemplateLenient,
+}
+
 // isDateTime tells whether given string is a valid date representation
 // as defined by RFC 3339, section 5.6.
 //
@@ -259,6 +275,9 @@ func isPeriod(v interface{}) bool {
 // for an Internet host name, as defined by RFC 1034 section 3.1 and
 // RFC 1123 section 2.1.
 //
+// Labels with hyphens in 3rd and 4th positions must be valid A-labels,
+// as defined by RFC 5890 section 2.3.2.1.
+//
 // See https://en.wikipedia.org/wiki/Hostname#Restrictions_on_valid_host_names, for details.
 func isHostname(v interface{}) bool {
 	s, ok := v.(string)
@@ -271,6 +290,27 @@ func isHostname(v interface{}) bool {
 		return false
 	}
 
+	// Hostnames are composed of series of labels concatenated with dots, as are all domain names
+	for _, label := range strings.Split(s, ".") {
+		if !isHostnameLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHostnameLenient is isHostname, without A-label checks.
+func isHostnameLenient(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	// entire hostname (including the delimiting dots but not a trailing dot) has a maximum of 253 ASCII characters
+	s = strings.TrimSuffix(s, ".")
+	if len(s) > 253 {
+		return false
+	}
+
 	// Hostnames are composed of series of labels concatenated with dots, as are all domain names
 	for _, label := range strings.Split(s, ".") {
 		// Each label must be from 1 to 63 characters long
@@ -303,7 +343,7 @@ func isHostname(v interface{}) bool {
 }
 
 // isEmail tells whether given string is a valid Internet email address
-// as defined by RFC 5322, section 3.4.1.
+// as defined by RFC 5321, section 4.1.2.
 //
 // See https://en.wikipedia.org/wiki/Email_address, for details.
 func isEmail(v interface{}) bool {
@@ -311,6 +351,30 @@ func isEmail(v interface{}) bool {
 	if !ok {
 		return true
 	}
+	return isASCII(s) && isEmailAddress(s, isHostname)
+}
+
+// isIDNEmail tells whether given string is a valid Internet email address
+// as defined by RFC 6531, section 3.3, which allows unicode in local part
+// and domain.
+func isIDNEmail(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	return isEmailAddress(s, isIDNHostname)
+}
+
+// isEmailLenient is isEmail, allowing unicode in local part.
+func isEmailLenient(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	return isEmailAddress(s, isHostname)
+}
+
+func isEmailAddress(s string, isDomain func(interface{}) bool) bool {
 	// entire email address to be no more than 254 characters long
 	if len(s) > 254 {
 		return false
@@ -339,7 +403,7 @@ func isEmail(v interface{}) bool {
 	}
 
 	// domain must match the requirements for a hostname
-	if !isHostname(domain) {
+	if !isDomain(domain) {
 		return false
 	}
 
@@ -392,8 +456,100 @@ func isURI(v interface{}) bool {
 	if !ok {
 		return true
 	}
+	u, ok := parseIRI(s, false)
+	return ok && u.IsAbs()
+}
+
+// isIRI tells whether given string is valid IRI, according to RFC 3987.
+func isIRI(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	u, ok := parseIRI(s, true)
+	return ok && u.IsAbs()
+}
+
+// isURIReference tells whether given string is a valid URI Reference
+// (either a URI or a relative-reference), according to RFC 3986.
+func isURIReference(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	_, ok = parseIRI(s, false)
+	return ok
+}
+
+// isIRIReference tells whether given string is a valid IRI Reference
+// (either an IRI or a relative-reference), according to RFC 3987.
+func isIRIReference(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	_, ok = parseIRI(s, true)
+	return ok
+}
+
+// parseIRI parses s, after checking that it has only the characters
+// allowed in URI by RFC 3986 section 2, or in IRI by RFC 3987 section 2.2
+// if iri is true.
+func parseIRI(s string, iri bool) (*url.URL, bool) {
+	// ip-literal in brackets is allowed only in authority
+	authority := -1
+	if i := strings.Index(s, "//"); i != -1 && !strings.ContainsAny(s[:i], "/?#") {
+		authority = i + 2 + strings.IndexAny(s[i+2:]+"/", "/?#")
+	}
+	query, fragment := false, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if !iri || !(isUcschar(r) || (query && !fragment && isIprivate(r))) {
+				return nil, false
+			}
+			i += size - 1
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1:i+3]) {
+				return nil, false
+			}
+			i += 2
+		case c == '[' || c == ']':
+			if i >= authority {
+				return nil, false
+			}
+		case c == '?':
+			query = true
+		case c == '#':
+			if fragment {
+				return nil, false
+			}
+			fragment = true
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'):
+		case strings.IndexByte("-._~:/@!$&'()*+,;=", c) != -1:
+		default:
+			return nil, false
+		}
+	}
 	u, err := urlParse(s)
-	return err == nil && u.IsAbs()
+	return u, err == nil
+}
+
+// isUcschar tells whether r is ucschar, as defined in RFC 3987 section 2.2.
+func isUcschar(r rune) bool {
+	switch {
+	case r >= 0xa0 && r <= 0xd7ff, r >= 0xf900 && r <= 0xfdcf, r >= 0xfdf0 && r <= 0xffef:
+		return true
+	case r >= 0x10000 && r <= 0xdfffd, r >= 0xe1000 && r <= 0xefffd:
+		return r&0xffff <= 0xfffd
+	}
+	return false
+}
+
+// isIprivate tells whether r is iprivate, as defined in RFC 3987 section 2.2.
+func isIprivate(r rune) bool {
+	return (r >= 0xe000 && r <= 0xf8ff) || (r >= 0xf0000 && r <= 0xffffd) || (r >= 0x100000 && r <= 0x10fffd)
 }
 
 func urlParse(s string) (*url.URL, error) {
@@ -415,9 +571,96 @@ func urlParse(s string) (*url.URL, error) {
 	return u, nil
 }
 
-// isURIReference tells whether given string is a valid URI Reference
-// (either a URI or a relative-reference), according to RFC 3986.
-func isURIReference(v interface{}) bool {
+// isURITemplate tells whether given string is a valid URI Template
+// according to RFC6570, section 2.
+func isURITemplate(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end == -1 || !isURITemplateExpr(s[i+1:i+end]) {
+				return false
+			}
+			i += end
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1:i+3]) {
+				return false
+			}
+			i += 2
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if !isUcschar(r) && !isIprivate(r) {
+				return false
+			}
+			i += size - 1
+		case c <= ' ' || c == 0x7f || strings.IndexByte("\"'<>\\^`{|}", c) != -1:
+			return false
+		}
+	}
+	return true
+}
+
+// isURITemplateExpr tells whether s is valid expression of URI Template,
+// without the enclosing braces.
+func isURITemplateExpr(s string) bool {
+	// operator
+	if s != "" && strings.IndexByte("+#./;?&=,!@|", s[0]) != -1 {
+		s = s[1:]
+	}
+	// variable-list
+	for _, varspec := range strings.Split(s, ",") {
+		varname := varspec
+		if strings.HasSuffix(varspec, "*") {
+			varname = varspec[:len(varspec)-1]
+		} else if colon := strings.IndexByte(varspec, ':'); colon != -1 {
+			varname = varspec[:colon]
+			maxLen := varspec[colon+1:]
+			if maxLen == "" || len(maxLen) > 4 || maxLen[0] == '0' {
+				return false
+			}
+			for i := 0; i < len(maxLen); i++ {
+				if maxLen[i] < '0' || maxLen[i] > '9' {
+					return false
+				}
+			}
+		}
+		// varchar *( ["."] varchar )
+		if varname == "" || varname[0] == '.' || varname[len(varname)-1] == '.' || strings.Contains(varname, "..") {
+			return false
+		}
+		for i := 0; i < len(varname); i++ {
+			switch c := varname[i]; {
+			case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '.':
+			case c == '%':
+				if i+2 >= len(varname) || !isHex(varname[i+1:i+3]) {
+					return false
+				}
+				i += 2
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isURILenient is isURI, without checking characters allowed.
+func isURILenient(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	u, err := urlParse(s)
+	return err == nil && u.IsAbs()
+}
+
+// isURIReferenceLenient is isURIReference, without checking characters
+// allowed.
+func isURIReferenceLenient(v interface{}) bool {
 	s, ok := v.(string)
 	if !ok {
 		return true
@@ -426,11 +669,9 @@ func isURIReference(v interface{}) bool {
 	return err == nil && !strings.Contains(s, `\`)
 }
 
-// isURITemplate tells whether given string is a valid URI Template
-// according to RFC6570.
-//
-// Current implementation does minimal validation.
-func isURITemplate(v interface{}) bool {
+// isURITemplateLenient is isURITemplate, which only checks that the
+// braces are balanced.
+func isURITemplateLenient(v interface{}) bool {
 	s, ok := v.(string)
 	if !ok {
 		return true
@@ -471,7 +712,7 @@ func isRegex(v interface{}) bool {
 	if !ok {
 		return true
 	}
-	_, err := regexp.Compile(s)
+	_, err := compileGoRegexp(s)
 	return err == nil
 }
 
@@ -504,14 +745,16 @@ func isJSONPointer(v interface{}) bool {
 	return true
 }
 
-// isRelativeJSONPointer tells whether given string is a valid Relative JSON Pointer.
+// isRelativeJSONPointer tells whether given string is a valid Relative JSON Pointer,
+// including index manipulation.
 //
-// see https://tools.ietf.org/html/draft-handrews-relative-json-pointer-01#section-3
+// see https://datatracker.ietf.org/doc/html/draft-bhutton-relative-json-pointer-00#section-3
 func isRelativeJSONPointer(v interface{}) bool {
 	s, ok := v.(string)
 	if !ok {
 		return true
 	}
+	// non-negative-integer
 	if s == "" {
 		return false
 	}
@@ -524,6 +767,16 @@ func isRelativeJSONPointer(v interface{}) bool {
 	} else {
 		return false
 	}
+	// index-manipulation: ("+" / "-") positive-integer
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+		if s == "" || s[0] < '1' || s[0] > '9' {
+			return false
+		}
+		for s != "" && s[0] >= '0' && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
 	return s == "#" || isJSONPointer(s)
 }
 
@@ -565,3 +818,17 @@ func isUUID(v interface{}) bool {
 	}
 	return len(s) == 0
 }
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -267,7 +267,9 @@ func TestIsURITemplate(t *testing.T) {
 func TestIsRegex(t *testing.T) {
 	tests := []test{
 		{"([abc])+\\s+$", true},
-		{"^(abc]", false}, // unclosed parenthesis
+		{"^(abc]", false},             // unclosed parenthesis
+		{"^\\u00e9+$", true},          // unicode escape
+		{"^(?<year>[0-9]{4})$", true}, // named group
 	}
 	for i, test := range tests {
 		if test.valid != isRegex(test.str) {
@@ -367,3 +369,173 @@ func TestIsUUID(t *testing.T) {
 		}
 	}
 }
+
+func TestIsIDNHostname(t *testing.T) {
+	tests := []test{
+		{"실례.테스트", true},
+		{"xn--ihqwcrb4cv8a8dqg056pqjye", true}, // A-label
+		{"xn--4gbwdl.xn--wgbh1c", true},
+		{"ex·ample", false},          // MIDDLE DOT not between 'l'
+		{"l·l", true},                // MIDDLE DOT between 'l'
+		{"α͵S", false},               // KERAIA followed by non-greek
+		{"α͵", false},                // KERAIA at end
+		{"͵β", true},                 // KERAIA followed by greek
+		{"א׳ב", true},                // GERESH preceded by hebrew
+		{"a׳b", false},               // GERESH not preceded by hebrew
+		{"・ぁ", true},                 // KATAKANA MIDDLE DOT with hiragana
+		{"def・abc", false},           // KATAKANA MIDDLE DOT without hiragana, katakana or han
+		{"٠٠۰", false},               // arabic-indic digits mixed with extended
+		{"क्‍ष", true},               // ZERO WIDTH JOINER preceded by virama
+		{"क‍ष", false},               // ZERO WIDTH JOINER not preceded by virama
+		{"ب‌ب", true},                // ZERO WIDTH NON-JOINER between joining letters
+		{"क‌ष", false},               // ZERO WIDTH NON-JOINER not preceded by virama
+		{"ـߺ", false},                // DISALLOWED exceptions
+		{"〱〲", false},                // DISALLOWED exceptions
+		{"̀hello", false},            // begins with combining mark
+		{"-> $1.00 <-", false},       // disallowed characters
+		{"xn--X", false},             // invalid punycode
+		{"XN--aa---o47jg78q", false}, // hyphens in 3rd and 4th position of U-label
+		{"ab--cd", false},            // hyphens in 3rd and 4th position
+		{"-hello", false},            // starts with hyphen
+		{"〮ab", false},
+		{"실례。테스트", true},                     // ideographic full stop as separator
+		{strings.Repeat("실례테스트", 12), false}, // A-label longer than 63
+		{"", false},
+	}
+	for i, test := range tests {
+		if test.valid != isIDNHostname(test.str) {
+			t.Errorf("#%d: %q, valid %t, got valid %t", i, test.str, test.valid, !test.valid)
+		}
+	}
+}
+
+func TestPunycode(t *testing.T) {
+	// examples from RFC 3492 section 7.1
+	tests := []struct {
+		decoded, encoded string
+	}{
+		{"ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+		{"他们为什么不说中文", "ihqwcrb4cv8a8dqg056pqjye"},
+		{"Pročprostěnemluvíčesky", "Proprostnemluvesky-uyb24dma41a"},
+		{"3年B組金八先生", "3B-ww4c5e180e575a65lsy2b"},
+		{"MajiでKoiする5秒前", "MajiKoi5-783gue6qz075azm5e"},
+	}
+	for _, test := range tests {
+		if got, err := punyEncode(test.decoded); err != nil || got != test.encoded {
+			t.Errorf("punyEncode(%q): got %q, %v, want %q", test.decoded, got, err, test.encoded)
+		}
+		if got, err := punyDecode(test.encoded); err != nil || got != test.decoded {
+			t.Errorf("punyDecode(%q): got %q, %v, want %q", test.encoded, got, err, test.decoded)
+		}
+	}
+}
+
+func TestIsIDNEmail(t *testing.T) {
+	tests := []test{
+		{"실례@실례.테스트", true},
+		{"joe.bloggs@example.com", true},
+		{"2962", false},       // no "@" character
+		{"실례@-실례.테스트", false}, // invalid domain name
+	}
+	for i, test := range tests {
+		if test.valid != isIDNEmail(test.str) {
+			t.Errorf("#%d: %q, valid %t, got valid %t", i, test.str, test.valid, !test.valid)
+		}
+	}
+	if isEmail("실례@example.com") {
+		t.Error("email must not allow non-ascii local part")
+	}
+	if !isEmailLenient("실례@example.com") {
+		t.Error("lenient email must allow non-ascii local part")
+	}
+}
+
+func TestIsIRI(t *testing.T) {
+	tests := []test{
+		{"http://ƒøø.ßår/?∂éœ=πîx#πîüx", true},
+		{"http://ƒøø.com/blah_(wîkïpédiå)_blah#ßité-1", true},
+		{"http://ƒøø.ßår/?q=Test%20URL-encoded%20stuff", true},
+		{"http://-.~_!$&'()*+,;=:%40:80%2f::::::@example.com", true},
+		{"http://[2001:0db8:85a3:0000:0000:8a2e:0370:7334]", true},
+		{"http://2001:0db8:85a3:0000:0000:8a2e:0370:7334", false}, // ipv6 not in brackets
+		{"/abc", false},                       // relative IRI reference
+		{"\\\\WINDOWS\\filëßåré", false},      // an invalid IRI
+		{"âππ", false},                        // valid IRI reference
+		{"http://example.com/a b", false},     // space
+		{"http://example.com/a\u0001", false}, // control character
+		{"http://example.com/?", true},       // iprivate in query
+		{"http://example.com/", false},       // iprivate not in query
+	}
+	for i, test := range tests {
+		if test.valid != isIRI(test.str) {
+			t.Errorf("#%d: %q, valid %t, got valid %t", i, test.str, test.valid, !test.valid)
+		}
+	}
+	for _, s := range []string{"http://ƒøø.ßår/", "http://example.com/a b", "http://a/b#c#d", "http://a/[b]", "http://a/%zz"} {
+		if isURI(s) {
+			t.Errorf("%q must not be valid uri", s)
+		}
+	}
+	for _, s := range []string{"âππ", "//foo.bar/?baz=qux#quux", "#ƒrägmênt"} {
+		if !isIRIReference(s) {
+			t.Errorf("%q must be valid iri-reference", s)
+		}
+		if isURIReference(s) != isASCII(s) {
+			t.Errorf("%q: uri-reference must allow only ascii", s)
+		}
+	}
+	if !isURILenient("http://ƒøø.ßår/") {
+		t.Error("lenient uri must allow non-ascii")
+	}
+}
+
+func TestIsURITemplateStrict(t *testing.T) {
+	tests := []test{
+		{"{var}", true},
+		{"{+path}/here", true},
+		{"{#x,hello,y}", true},
+		{"{/list*}", true},
+		{"{;x,y,empty}", true},
+		{"{?var:3}", true},
+		{"{&keys*}", true},
+		{"{a.b}", true},
+		{"{%20x}", true},
+		{"http://example.com/{ü}", false}, // non-ascii varname
+		{"http://example.com/ü{x}", true},
+		{"{}", false},
+		{"{x:0}", false},
+		{"{x:10000}", false},
+		{"{x:}", false},
+		{"{x*:3}", false},
+		{"{.a..b}", false},
+		{"{a.}", false},
+		{"{x y}", false},
+		{"{x{y}}", false},
+		{"}", false},
+		{"a b", false},
+		{"%zz", false},
+	}
+	for i, test := range tests {
+		if test.valid != isURITemplate(test.str) {
+			t.Errorf("#%d: %q, valid %t, got valid %t", i, test.str, test.valid, !test.valid)
+		}
+	}
+}
+
+func TestRelativeJSONPointerIndexManipulation(t *testing.T) {
+	tests := []test{
+		{"0+1", true},
+		{"1-2/foo", true},
+		{"0-10#", true},
+		{"0+", false},
+		{"0+0", false},    // positive-integer
+		{"0+01", false},   // leading zero
+		{"+1/foo", false}, // explicit positive prefix
+		{"0+-1", false},
+	}
+	for i, test := range tests {
+		if test.valid != isRelativeJSONPointer(test.str) {
+			t.Errorf("#%d: %q, valid %t, got valid %t", i, test.str, test.valid, !test.valid)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package jsonschema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// addCorpus adds the schemas and instances from json test files in
+// testdata/tests to the seed corpus of f. add is called with each schema
+// and an instance to be validated against it.
+func addCorpus(f *testing.F, add func(schema, instance []byte)) {
+	err := filepath.Walk("testdata/tests", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var groups []struct {
+			Schema json.RawMessage
+			Tests  []struct {
+				Data json.RawMessage
+			}
+		}
+		if err := json.Unmarshal(b, &groups); err != nil {
+			return err
+		}
+		for _, group := range groups {
+			for _, test := range group.Tests {
+				add(group.Schema, test.Data)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+}
+
+// fuzzCompiler returns a compiler, which does not load any url.
+func fuzzCompiler() *jsonschema.Compiler {
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, errors.New("loading urls is disabled for fuzzing")
+	}
+	return c
+}
+
+func FuzzCompile(f *testing.F) {
+	addCorpus(f, func(schema, _ []byte) {
+		f.Add(schema)
+	})
+	f.Fuzz(func(t *testing.T, schema []byte) {
+		c := fuzzCompiler()
+		if err := c.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+			return
+		}
+		if _, err := c.Compile("schema.json"); err != nil {
+			if !strings.HasPrefix(err.Error(), "jsonschema") {
+				t.Fatalf("error without jsonschema prefix: %v", err)
+			}
+		}
+	})
+}
+
+func FuzzValidate(f *testing.F) {
+	addCorpus(f, func(schema, instance []byte) {
+		f.Add(schema, instance)
+	})
+	f.Fuzz(func(t *testing.T, schema, instance []byte) {
+		c := fuzzCompiler()
+		c.ExtractAnnotations = true
+		if err := c.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+			return
+		}
+		sch, err := c.Compile("schema.json")
+		if err != nil {
+			return
+		}
+		v, err := jsonschema.DecodeJSON(bytes.NewReader(instance))
+		if err != nil {
+			return
+		}
+		err1 := sch.Validate(v)
+		err2 := sch.Validate(v)
+		if (err1 == nil) != (err2 == nil) {
+			t.Fatalf("validation is not deterministic: %v, %v", err1, err2)
+		}
+		if err1 != nil {
+			var ve *jsonschema.ValidationError
+			if !errors.As(err1, &ve) {
+				t.Fatalf("got %T, want *ValidationError", err1)
+			}
+			_ = ve.DetailedOutput()
+			_ = ve.BasicOutput()
+			_ = ve.Error()
+		}
+	})
+}
+
+// crashers found by fuzzing.
+
+func TestOutOfRangeNumber(t *testing.T) {
+	for _, schema := range []string{
+		`{"minimum": 1e100000000}`,
+		`{"multipleOf": 1e-10000000}`,
+	} {
+		if _, err := jsonschema.CompileString("schema.json", schema); err == nil {
+			t.Errorf("%s: compilation must fail", schema)
+		}
+	}
+
+	sch := jsonschema.MustCompileString("schema.json", `{"multipleOf": 2, "enum": [1, 2]}`)
+	v, err := jsonschema.DecodeJSON(strings.NewReader("1e-10000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate(v); err == nil {
+		t.Error("validation must fail")
+	}
+}
+
+func TestDeepNesting(t *testing.T) {
+	nest := func(n int) string {
+		return strings.Repeat(`{"not":`, n) + "{}" + strings.Repeat("}", n)
+	}
+	if _, err := jsonschema.CompileString("schema.json", nest(500)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := jsonschema.CompileString("schema.json", nest(5000))
+	if err == nil || !strings.Contains(err.Error(), "nesting exceeds") {
+		t.Fatalf("got %v, want nesting error", err)
+	}
+}
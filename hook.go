@@ -0,0 +1,68 @@
+package jsonschema
+
+import (
+	"context"
+	"strings"
+)
+
+// Hook receives events while a schema validates an instance. It can be used
+// to trace which subschemas are evaluated, to build coverage reports of
+// schemas, or to measure time spent in keywords.
+//
+// OnKeywordEnter is called before a schema is evaluated against part of the
+// instance, and OnKeywordExit after the evaluation; err is nil if that part
+// of the instance is valid. The same *HookEvent is passed to both calls.
+// Events are nested: events of subschemas are reported between enter and
+// exit of their parent schema.
+type Hook interface {
+	OnKeywordEnter(e *HookEvent)
+	OnKeywordExit(e *HookEvent, err error)
+}
+
+// HookEvent describes the evaluation of a schema, reported to Hook.
+type HookEvent struct {
+	Keyword                 string      // keyword that applied the schema, e.g. "properties". empty for root schema
+	KeywordLocation         string      // validation path of the schema
+	AbsoluteKeywordLocation string      // absolute location of the schema
+	InstanceLocation        string      // location of the json value within the instance
+	Schema                  *Schema     // schema being evaluated
+	Instance                interface{} // json value being validated
+}
+
+// ValidateWithHook is like Validate, but reports evaluation of each
+// schema to h.
+func (s *Schema) ValidateWithHook(v interface{}, h Hook) error {
+	vd := newValidator(context.Background())
+	defer vd.release()
+	vd.hook = h
+	return s.validateValue(vd, v, "")
+}
+
+// hooks reports events to each of its hooks.
+type hooks []Hook
+
+func (hs hooks) OnKeywordEnter(e *HookEvent) {
+	for _, h := range hs {
+		h.OnKeywordEnter(e)
+	}
+}
+
+func (hs hooks) OnKeywordExit(e *HookEvent, err error) {
+	for _, h := range hs {
+		h.OnKeywordExit(e, err)
+	}
+}
+
+func (vd *validator) enter(scope []schemaRef, s *Schema, v interface{}) *HookEvent {
+	keyword, _, _ := strings.Cut(scope[len(scope)-1].path, "/")
+	e := &HookEvent{
+		Keyword:                 keyword,
+		KeywordLocation:         keywordLocation(scope, ""),
+		AbsoluteKeywordLocation: s.Location,
+		InstanceLocation:        vd.instanceLocation(scope),
+		Schema:                  s,
+		Instance:                v,
+	}
+	vd.hook.OnKeywordEnter(e)
+	return e
+}
@@ -0,0 +1,53 @@
+package jsonschema_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type traceHook struct {
+	depth int
+	trace []string
+}
+
+func (h *traceHook) OnKeywordEnter(e *jsonschema.HookEvent) {
+	h.trace = append(h.trace, fmt.Sprintf("%s> %s %q", strings.Repeat(" ", h.depth), e.KeywordLocation, e.InstanceLocation))
+	h.depth++
+}
+
+func (h *traceHook) OnKeywordExit(e *jsonschema.HookEvent, err error) {
+	h.depth--
+	h.trace = append(h.trace, fmt.Sprintf("%s< %s %v", strings.Repeat(" ", h.depth), e.Keyword, err == nil))
+}
+
+func TestValidateWithHook(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"properties": {
+			"name": {"$ref": "#/$defs/name"}
+		},
+		"$defs": {
+			"name": {"type": "string"}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &traceHook{}
+	if err := sch.ValidateWithHook(decodeString(t, `{"name": 1}`), h); err == nil {
+		t.Fatal("validation must fail")
+	}
+	want := []string{
+		`>  ""`,
+		` > /properties/name "/name"`,
+		`  > /properties/name/$ref "/name"`,
+		`  < $ref false`,
+		` < properties false`,
+		`<  false`,
+	}
+	if got := strings.Join(h.trace, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
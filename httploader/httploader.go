@@ -6,6 +6,9 @@
 // To use httploader, link this package into your program:
 //
 //	import _ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
+//
+// For caching, timeouts, response size limit and allowlist of hosts,
+// use Loader.
 package httploader
 
 import (
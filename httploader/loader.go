@@ -0,0 +1,226 @@
+package httploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loader loads resources from http(s) urls, with caching and limits.
+//
+// To use Loader for all compilers:
+//
+//	l := &httploader.Loader{Timeout: 10 * time.Second, Cache: httploader.NewMemoryCache()}
+//	jsonschema.Loaders["http"] = l.Load
+//	jsonschema.Loaders["https"] = l.Load
+//
+// or set it as Compiler.LoadURL for a specific compiler. To cancel loading
+// when context of Compiler.CompileContext is done, use LoadContext:
+//
+//	compiler.LoadURLContext = l.LoadContext
+type Loader struct {
+	// Client is used to send requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Timeout is the time limit for each request, including reading the
+	// response body. Zero means no timeout.
+	Timeout time.Duration
+
+	// MaxSize is the maximum size of response body in bytes. Zero means
+	// no limit.
+	MaxSize int64
+
+	// Cache, if set, stores the loaded resources. Cached resource is
+	// revalidated using ETag or Last-Modified from the server response.
+	Cache Cache
+
+	// AllowedHosts, if not empty, is the list of hosts from which resources
+	// can be loaded. An entry of the form "*.example.com" matches all
+	// subdomains of example.com.
+	AllowedHosts []string
+}
+
+// Load loads resource from given http(s) url.
+func (l *Loader) Load(s string) (io.ReadCloser, error) {
+	return l.LoadContext(context.Background(), s)
+}
+
+// LoadContext is like Load, but the request is cancelled when ctx is done.
+func (l *Loader) LoadContext(ctx context.Context, s string) (io.ReadCloser, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if !l.allowed(u.Hostname()) {
+		return nil, fmt.Errorf("%s: host %q is not allowed", s, u.Hostname())
+	}
+
+	if l.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached *CacheEntry
+	if l.Cache != nil {
+		if e, ok := l.Cache.Get(s); ok {
+			cached = e
+			if e.ETag != "" {
+				req.Header.Set("If-None-Match", e.ETag)
+			}
+			if e.LastModified != "" {
+				req.Header.Set("If-Modified-Since", e.LastModified)
+			}
+		}
+	}
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return io.NopCloser(bytes.NewReader(cached.Body)), nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s returned status code %d", s, resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if l.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, l.MaxSize+1)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if l.MaxSize > 0 && int64(len(b)) > l.MaxSize {
+		return nil, fmt.Errorf("%s: response exceeds %d bytes", s, l.MaxSize)
+	}
+	if l.Cache != nil {
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			l.Cache.Set(s, &CacheEntry{Body: b, ETag: etag, LastModified: lastModified})
+		}
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (l *Loader) allowed(host string) bool {
+	if len(l.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, h := range l.AllowedHosts {
+		h = strings.ToLower(h)
+		if h == host || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheEntry is a resource stored in Cache.
+type CacheEntry struct {
+	Body         []byte
+	ETag         string // ETag header of the response
+	LastModified string // Last-Modified header of the response
+}
+
+// Cache stores resources loaded by Loader, keyed by url.
+// It must be safe for concurrent use.
+type Cache interface {
+	Get(url string) (*CacheEntry, bool)
+	Set(url string, e *CacheEntry)
+}
+
+// NewMemoryCache returns Cache which stores resources in memory.
+func NewMemoryCache() Cache {
+	return &memoryCache{entries: make(map[string]*CacheEntry)}
+}
+
+type memoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CacheEntry
+}
+
+func (c *memoryCache) Get(url string) (*CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *memoryCache) Set(url string, e *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = e
+}
+
+// DirCache is Cache which stores resources as files in the directory.
+// Errors in reading or writing files are ignored, so that the resource
+// is loaded again from server.
+type DirCache string
+
+func (d DirCache) file(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(string(d), hex.EncodeToString(sum[:])+".json")
+}
+
+func (d DirCache) Get(url string) (*CacheEntry, bool) {
+	b, err := os.ReadFile(d.file(url))
+	if err != nil {
+		return nil, false
+	}
+	var e CacheEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
+func (d DirCache) Set(url string, e *CacheEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(string(d), 0o755); err != nil {
+		return
+	}
+	// write to temp file and rename, to avoid partial files on concurrent use
+	f, err := os.CreateTemp(string(d), "tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), d.file(url)); err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,84 @@
+package httploader_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/httploader"
+)
+
+func TestLoader(t *testing.T) {
+	var requests, notModified int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/schema.json":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{"type": "string"}`))
+		case "/large.json":
+			_, _ = w.Write([]byte(`{"description": "` + strings.Repeat("x", 100) + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	load := func(l *httploader.Loader, url string) (string, error) {
+		r, err := l.Load(url)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		b, err := io.ReadAll(r)
+		return string(b), err
+	}
+
+	for _, cache := range []httploader.Cache{httploader.NewMemoryCache(), httploader.DirCache(t.TempDir())} {
+		requests, notModified = 0, 0
+		l := &httploader.Loader{Cache: cache}
+		for i := 0; i < 2; i++ {
+			b, err := load(l, ts.URL+"/schema.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b != `{"type": "string"}` {
+				t.Fatalf("got %s", b)
+			}
+		}
+		if requests != 2 || notModified != 1 {
+			t.Errorf("%T: cached resource must be revalidated: requests=%d, notModified=%d", cache, requests, notModified)
+		}
+	}
+
+	l := &httploader.Loader{MaxSize: 50}
+	if _, err := load(l, ts.URL+"/large.json"); err == nil {
+		t.Error("load must fail when response exceeds MaxSize")
+	}
+	if _, err := load(l, ts.URL+"/schema.json"); err != nil {
+		t.Error(err)
+	}
+	if _, err := load(l, ts.URL+"/missing.json"); err == nil {
+		t.Error("load must fail with status code 404")
+	}
+
+	l = &httploader.Loader{AllowedHosts: []string{"*.example.com"}}
+	if _, err := load(l, ts.URL+"/schema.json"); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("load must fail for host not allowed, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (&httploader.Loader{}).LoadContext(ctx, ts.URL+"/schema.json"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,299 @@
+// Package httpvalidate provides an http middleware which validates json
+// request bodies, and optionally response bodies, against schemas selected
+// by method and path of the request.
+//
+// Typical usage:
+//
+//	mw := &httpvalidate.Middleware{
+//		Routes: []httpvalidate.Route{
+//			{Method: "POST", Path: "/users", Request: userSchema, Response: userSchema},
+//			{Method: "PUT", Path: "/users/{id}", Request: userSchema},
+//		},
+//	}
+//	http.ListenAndServe(":8080", mw.Handler(mux))
+//
+// Invalid requests are rejected with RFC 7807 problem details, without
+// calling the wrapped handler:
+//
+//	HTTP/1.1 422 Unprocessable Entity
+//	Content-Type: application/problem+json
+//
+//	{
+//		"type": "about:blank",
+//		"title": "Unprocessable Entity",
+//		"status": 422,
+//		"detail": "request body is invalid against schema",
+//		"instance": "/users",
+//		"errors": {"valid": false, "errors": [...]}
+//	}
+//
+// The "errors" member holds the validation error in output format given
+// by Middleware.Format.
+//
+// Request bodies are validated with jsonschema.ModeRequest and response
+// bodies with jsonschema.ModeResponse, so that readOnly and writeOnly
+// are enforced, if schemas are compiled with Compiler.ExtractAnnotations.
+package httpvalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Route selects the schemas used to validate the exchange with given
+// method and path.
+type Route struct {
+	// Method of the request. empty matches any method.
+	Method string
+
+	// Path of the request. A segment of the form "{name}" matches any
+	// single segment, and a trailing "/*" matches any remaining segments.
+	Path string
+
+	// Request validates the request body. nil means request body is not
+	// validated.
+	Request *jsonschema.Schema
+
+	// Response validates the response body, if response has json
+	// content-type. nil means response body is not validated.
+	Response *jsonschema.Schema
+}
+
+func (rt *Route) match(method, path string) bool {
+	if rt.Method != "" && !strings.EqualFold(rt.Method, method) {
+		return false
+	}
+	want, got := strings.Split(rt.Path, "/"), strings.Split(path, "/")
+	for i, seg := range want {
+		if seg == "*" && i == len(want)-1 {
+			return len(got) >= len(want)
+		}
+		if i >= len(got) {
+			return false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if got[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != got[i] {
+			return false
+		}
+	}
+	return len(got) == len(want)
+}
+
+// Problem is RFC 7807 problem details, written as response for invalid
+// exchanges.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Errors is the validation error, in output format given by
+	// Middleware.Format. nil if body is not valid json.
+	Errors interface{} `json:"errors,omitempty"`
+}
+
+// Middleware validates request and response bodies of the routes.
+//
+// A request which matches none of the routes is passed to wrapped
+// handler unchanged. If more than one route matches, the first one
+// is used.
+type Middleware struct {
+	Routes []Route
+
+	// Format is the output format used for Problem.Errors. It must be
+	// one of "flag", "basic", "detailed" or "verbose". empty or unsupported
+	// format means "basic". It is read once, when Handler is called.
+	Format string
+
+	// MaxBodySize is the maximum size of request body in bytes. Larger
+	// requests are rejected with 413. zero means no limit.
+	MaxBodySize int64
+
+	// OnResponseError is called when the response written by wrapped
+	// handler is invalid. If nil, such responses are replaced with
+	// 500 problem details, otherwise they are sent unchanged.
+	OnResponseError func(r *http.Request, err error)
+}
+
+// Handler returns a handler which validates the exchanges before passing
+// them to next.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	format := m.Format
+	switch format {
+	case "flag", "basic", "detailed", "verbose":
+	default:
+		format = "basic"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var route *Route
+		for i := range m.Routes {
+			if m.Routes[i].match(r.Method, r.URL.Path) {
+				route = &m.Routes[i]
+				break
+			}
+		}
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if route.Request != nil {
+			if !m.validateRequest(w, r, route.Request, format) {
+				return
+			}
+		}
+		if route.Response == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rw := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if isJSON(rw.header.Get("Content-Type")) && rw.body.Len() > 0 {
+			if out, err := validate(route.Response, rw.body.Bytes(), jsonschema.ModeResponse, format); err != nil {
+				if m.OnResponseError == nil {
+					writeProblem(w, r, http.StatusInternalServerError, "response body is invalid against schema", err, out)
+					return
+				}
+				m.OnResponseError(r, err)
+			}
+		}
+		for k, v := range rw.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rw.status)
+		_, _ = w.Write(rw.body.Bytes())
+	})
+}
+
+// validateRequest validates body of r against sch. If invalid, the
+// problem is written to w, with validation error in given output format,
+// and false is returned. Otherwise r.Body is replaced, so that next
+// handler can read it.
+func (m *Middleware) validateRequest(w http.ResponseWriter, r *http.Request, sch *jsonschema.Schema, format string) bool {
+	if ct := r.Header.Get("Content-Type"); ct != "" && !isJSON(ct) {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content-type %q", ct), nil, nil)
+		return false
+	}
+	body := io.Reader(r.Body)
+	if m.MaxBodySize > 0 {
+		body = io.LimitReader(body, m.MaxBodySize+1)
+	}
+	b, err := io.ReadAll(body)
+	_ = r.Body.Close()
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("error reading request body: %v", err), nil, nil)
+		return false
+	}
+	if m.MaxBodySize > 0 && int64(len(b)) > m.MaxBodySize {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", m.MaxBodySize), nil, nil)
+		return false
+	}
+	if out, err := validate(sch, b, jsonschema.ModeRequest, format); err != nil {
+		if _, ok := err.(*jsonschema.ValidationError); ok {
+			writeProblem(w, r, http.StatusUnprocessableEntity, "request body is invalid against schema", err, out)
+		} else {
+			writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("request body is not valid json: %v", err), nil, nil)
+		}
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	r.ContentLength = int64(len(b))
+	return true
+}
+
+// validate validates json document b against sch. If it is invalid, the
+// validation error is also returned in given output format.
+func validate(sch *jsonschema.Schema, b []byte, mode jsonschema.Mode, format string) (out interface{}, err error) {
+	v, err := jsonschema.DecodeJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	opts := jsonschema.ValidateOptions{Mode: mode}
+	err = sch.ValidateWithOptions(v, opts)
+	if ve, ok := err.(*jsonschema.ValidationError); ok {
+		if format == "verbose" {
+			// ValidationError lacks the schemas which passed
+			out, _ = sch.ValidateWithOutput(v, format, opts)
+		} else {
+			out, _ = ve.Output(format)
+		}
+	}
+	return out, err
+}
+
+// writeProblem writes problem details with given status to w. If err is
+// *jsonschema.ValidationError, out is its output, given as Problem.Errors.
+//
+// Headers already set on w, such as by outer middlewares, are retained,
+// except Content-Type, Content-Length and ETag.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string, err error, out interface{}) {
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}
+	if _, ok := err.(*jsonschema.ValidationError); ok {
+		p.Errors = out
+	} else if err != nil {
+		p.Detail = fmt.Sprintf("%s: %v", detail, err)
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Del("ETag")
+	h.Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// responseRecorder buffers the response, so that it can be validated
+// before sending.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *responseRecorder) Header() http.Header {
+	return rw.header
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status, rw.wroteHeader = status, true
+	}
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.body.Write(b)
+}
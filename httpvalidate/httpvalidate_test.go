@@ -0,0 +1,234 @@
+package httpvalidate_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/httpvalidate"
+)
+
+const userSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "readOnly": true},
+		"name": {"type": "string"}
+	},
+	"required": ["name"],
+	"additionalProperties": false
+}`
+
+func compile(t *testing.T, schema string) *jsonschema.Schema {
+	t.Helper()
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("user.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("user.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func TestMiddleware_Request(t *testing.T) {
+	sch := compile(t, userSchema)
+	var called int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	})
+	mw := &httpvalidate.Middleware{
+		Routes: []httpvalidate.Route{
+			{Method: "POST", Path: "/users", Request: sch},
+			{Method: "PUT", Path: "/users/{id}", Request: sch},
+		},
+		MaxBodySize: 64,
+	}
+	h := mw.Handler(next)
+
+	tests := []struct {
+		method, path, contentType, body string
+		status                          int
+	}{
+		{"POST", "/users", "application/json", `{"name": "john"}`, http.StatusOK},
+		{"PUT", "/users/1", "application/json; charset=utf-8", `{"name": "john"}`, http.StatusOK},
+		{"POST", "/users", "", `{"name": "john"}`, http.StatusOK},
+		{"GET", "/users", "", ``, http.StatusOK},
+		{"POST", "/others", "application/json", `{}`, http.StatusOK},
+		{"POST", "/users", "application/json", `{"name": 1}`, http.StatusUnprocessableEntity},
+		{"POST", "/users", "application/json", `{"id": 1, "name": "john"}`, http.StatusUnprocessableEntity},
+		{"PUT", "/users/1", "application/json", `{}`, http.StatusUnprocessableEntity},
+		{"POST", "/users", "application/json", `{"name": `, http.StatusBadRequest},
+		{"POST", "/users", "text/plain", `{"name": "john"}`, http.StatusUnsupportedMediaType},
+		{"POST", "/users", "application/json", `{"name": "` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		called = 0
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.contentType != "" {
+			r.Header.Set("Content-Type", test.contentType)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s %s %s: got status %d, want %d: %s", test.method, test.path, test.body, w.Code, test.status, w.Body)
+			continue
+		}
+		if test.status == http.StatusOK {
+			if called != 1 {
+				t.Errorf("%s %s %s: handler not called", test.method, test.path, test.body)
+			} else if w.Body.String() != test.body {
+				t.Errorf("%s %s %s: handler got body %q", test.method, test.path, test.body, w.Body)
+			}
+			continue
+		}
+		if called != 0 {
+			t.Errorf("%s %s %s: handler called for invalid request", test.method, test.path, test.body)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s %s %s: got content-type %q", test.method, test.path, test.body, ct)
+		}
+	}
+}
+
+func TestMiddleware_Problem(t *testing.T) {
+	sch := compile(t, userSchema)
+	mw := &httpvalidate.Middleware{
+		Routes: []httpvalidate.Route{{Path: "/users", Request: sch}},
+		Format: "detailed",
+	}
+	h := mw.Handler(http.NotFoundHandler())
+	r := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name": 1}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var p struct {
+		httpvalidate.Problem
+		Errors jsonschema.Detailed `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusUnprocessableEntity || p.Title != "Unprocessable Entity" || p.Type != "about:blank" || p.Instance != "/users" {
+		t.Errorf("got problem %+v", p.Problem)
+	}
+	if len(p.Errors.Errors) != 1 || p.Errors.Errors[0].InstanceLocation != "/name" {
+		t.Errorf("got errors %+v", p.Errors)
+	}
+}
+
+func TestMiddleware_Response(t *testing.T) {
+	sch := compile(t, userSchema)
+	body := `{"id": 1, "name": "john"}`
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Test", "1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, body)
+	})
+	mw := &httpvalidate.Middleware{
+		Routes: []httpvalidate.Route{{Path: "/users/*", Response: sch}},
+	}
+	h := mw.Handler(next)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/users/1/profile", nil))
+		return w
+	}
+
+	w := serve()
+	if w.Code != http.StatusCreated || w.Body.String() != body || w.Header().Get("X-Test") != "1" {
+		t.Fatalf("got %d %q %v", w.Code, w.Body, w.Header())
+	}
+
+	body = `{"name": 1}`
+	w = serve()
+	if w.Code != http.StatusInternalServerError || w.Header().Get("X-Test") != "" {
+		t.Fatalf("got %d %q %v", w.Code, w.Body, w.Header())
+	}
+
+	var reported error
+	mw.OnResponseError = func(r *http.Request, err error) {
+		reported = err
+	}
+	w = serve()
+	if w.Code != http.StatusCreated || w.Body.String() != body {
+		t.Fatalf("got %d %q", w.Code, w.Body)
+	}
+	if _, ok := reported.(*jsonschema.ValidationError); !ok {
+		t.Fatalf("got reported error %v", reported)
+	}
+}
+
+func TestMiddleware_ProblemFormat(t *testing.T) {
+	sch := compile(t, userSchema)
+	for _, format := range []string{"", "flag", "basic", "detailed", "verbose", "unknown"} {
+		mw := &httpvalidate.Middleware{
+			Routes: []httpvalidate.Route{{Path: "/users", Request: sch}},
+			Format: format,
+		}
+		h := mw.Handler(http.NotFoundHandler())
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/users", strings.NewReader(`{"name": 1}`)))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%q: got %d %q", format, w.Code, w.Body)
+		}
+		var p struct {
+			Errors map[string]interface{} `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Errors["valid"] != false {
+			t.Errorf("%q: got errors %s", format, w.Body)
+		}
+		if _, ok := p.Errors["absoluteKeywordLocation"]; (format == "detailed" || format == "verbose") != ok {
+			t.Errorf("%q: got errors %s", format, w.Body)
+		}
+		if _, ok := p.Errors["errors"].([]interface{}); (format == "unknown") && !ok {
+			t.Errorf("unknown format must fall back to basic: %s", w.Body)
+		}
+	}
+}
+
+func TestMiddleware_ProblemHeaders(t *testing.T) {
+	sch := compile(t, userSchema)
+	mw := &httpvalidate.Middleware{
+		Routes: []httpvalidate.Route{{Path: "/users", Request: sch}},
+	}
+	h := mw.Handler(http.NotFoundHandler())
+	outer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "42")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", "3")
+		w.Header().Set("ETag", `"v1"`)
+		h.ServeHTTP(w, r)
+	})
+	w := httptest.NewRecorder()
+	outer.ServeHTTP(w, httptest.NewRequest("POST", "/users", strings.NewReader(`{"name": 1}`)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got %d %q", w.Code, w.Body)
+	}
+	want := map[string]string{
+		"X-Request-Id":                "42",
+		"Access-Control-Allow-Origin": "*",
+		"Content-Type":                "application/problem+json",
+		"Content-Length":              "",
+		"Etag":                        "",
+	}
+	for k, v := range want {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s: got %q, want %q", k, got, v)
+		}
+	}
+}
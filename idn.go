@@ -0,0 +1,402 @@
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isIDNHostname tells whether given string is a valid representation
+// for an Internet host name, as defined by RFC 5890 section 2.3.2.3,
+// including A-labels and U-labels.
+//
+// U-labels are checked against the code point categories and contextual
+// rules of RFC 5892. Bidi rules of RFC 5893 are not checked.
+func isIDNHostname(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return true
+	}
+	// label separators as per RFC 3490 section 3.1
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '。', '．', '｡':
+			return '.'
+		}
+		return r
+	}, s)
+	s = strings.TrimSuffix(s, ".")
+	if s == "" {
+		return false
+	}
+	n := 0 // length in ascii form
+	for _, label := range strings.Split(s, ".") {
+		alabel, ok := toALabel(label)
+		if !ok {
+			return false
+		}
+		n += len(alabel) + 1
+	}
+	return n-1 <= 253
+}
+
+// toALabel returns the ascii form of given hostname label,
+// after validating it.
+func toALabel(label string) (string, bool) {
+	if isASCII(label) {
+		return label, isHostnameLabel(label)
+	}
+	if !isULabel(label) {
+		return "", false
+	}
+	encoded, err := punyEncode(label)
+	if err != nil {
+		return "", false
+	}
+	alabel := "xn--" + encoded
+	return alabel, len(alabel) <= 63
+}
+
+// isHostnameLabel tells whether given ascii label is valid hostname label,
+// as defined by RFC 1123 section 2.1 and RFC 5890 section 2.3.1.
+func isHostnameLabel(label string) bool {
+	// Each label must be from 1 to 63 characters long
+	if len(label) < 1 || len(label) > 63 {
+		return false
+	}
+	// labels must not start or end with a hyphen
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	// labels may contain only the ASCII letters 'a' through 'z' (in a case-insensitive manner),
+	// the digits '0' through '9', and the hyphen ('-')
+	for _, c := range label {
+		if valid := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || (c == '-'); !valid {
+			return false
+		}
+	}
+	// hyphens in 3rd and 4th positions are reserved for A-labels, "xn--"
+	if len(label) >= 4 && label[2:4] == "--" {
+		if !strings.EqualFold(label[:2], "xn") {
+			return false
+		}
+		ulabel, err := punyDecode(strings.ToLower(label[4:]))
+		if err != nil || isASCII(ulabel) {
+			return false
+		}
+		return isULabel(ulabel)
+	}
+	return true
+}
+
+// isULabel tells whether given label in unicode is valid U-label,
+// as per RFC 5891 section 5.4 and RFC 5892.
+func isULabel(label string) bool {
+	if !utf8.ValidString(label) || label == "" {
+		return false
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	if len(label) >= 4 && label[2:4] == "--" {
+		return false
+	}
+	runes := []rune(label)
+	if unicode.Is(unicode.M, runes[0]) {
+		return false // must not begin with combining mark
+	}
+
+	var arabicIndic, extArabicIndic bool
+	for i, r := range runes {
+		switch idnaProperty(r) {
+		case idnaDisallowed:
+			return false
+		case idnaContextJ, idnaContextO:
+			if !idnaContextAllowed(runes, i) {
+				return false
+			}
+		}
+		switch {
+		case r >= '٠' && r <= '٩':
+			arabicIndic = true
+		case r >= '۰' && r <= '۹':
+			extArabicIndic = true
+		}
+	}
+	// RFC 5892 Appendix A.8 and A.9
+	return !(arabicIndic && extArabicIndic)
+}
+
+const (
+	idnaPValid = iota
+	idnaDisallowed
+	idnaContextJ
+	idnaContextO
+)
+
+// idnaProperty returns the derived property value of code point r,
+// as per RFC 5892 section 2.
+//
+// Note that unstable code points, whose case folding or normalization
+// changes them, are approximated by disallowing uppercase and titlecase
+// letters.
+func idnaProperty(r rune) int {
+	// exceptions, section 2.6
+	switch r {
+	case 'ß', 'ς', '۽', '۾', '་', '〇':
+		return idnaPValid
+	case '·', '͵', '׳', '״', '・':
+		return idnaContextO
+	case 'ـ', 'ߺ', '〮', '〯', '〱', '〲', '〳', '〴', '〵', '〻':
+		return idnaDisallowed
+	}
+	switch {
+	case r >= '٠' && r <= '٩', r >= '۰' && r <= '۹':
+		return idnaContextO
+	case r == '‌' || r == '‍': // join controls
+		return idnaContextJ
+	case r < utf8.RuneSelf: // LDH
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return idnaPValid
+		}
+		return idnaDisallowed
+	case unicode.In(r, unicode.Ll, unicode.Lo, unicode.Lm, unicode.Mn, unicode.Mc, unicode.Nd):
+		return idnaPValid
+	}
+	return idnaDisallowed
+}
+
+// idnaContextAllowed tells whether the contextual rule of code point at
+// index i in label is satisfied, as per RFC 5892 Appendix A.
+func idnaContextAllowed(label []rune, i int) bool {
+	before := func() rune {
+		if i > 0 {
+			return label[i-1]
+		}
+		return -1
+	}
+	after := func() rune {
+		if i+1 < len(label) {
+			return label[i+1]
+		}
+		return -1
+	}
+	switch r := label[i]; r {
+	case '·': // MIDDLE DOT
+		return before() == 'l' && after() == 'l'
+	case '͵': // GREEK LOWER NUMERAL SIGN (KERAIA)
+		return after() != -1 && unicode.Is(unicode.Greek, after())
+	case '׳', '״': // HEBREW PUNCTUATION GERESH and GERSHAYIM
+		return before() != -1 && unicode.Is(unicode.Hebrew, before())
+	case '・': // KATAKANA MIDDLE DOT
+		for _, r := range label {
+			if r != '・' && unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+				return true
+			}
+		}
+		return false
+	case '‍': // ZERO WIDTH JOINER
+		return isVirama(before())
+	case '‌': // ZERO WIDTH NON-JOINER
+		if isVirama(before()) {
+			return true
+		}
+		// (Joining_Type:{L,D})(Joining_Type:T)*‌(Joining_Type:T)*(Joining_Type:{R,D})
+		joining := func(from, step int) bool {
+			for j := from; j >= 0 && j < len(label); j += step {
+				switch {
+				case unicode.Is(unicode.Mn, label[j]):
+					continue // transparent
+				case unicode.In(label[j], unicode.Arabic, unicode.Syriac, unicode.Nko, unicode.Mongolian) && unicode.IsLetter(label[j]):
+					return true
+				}
+				return false
+			}
+			return false
+		}
+		return joining(i-1, -1) && joining(i+1, 1)
+	}
+	// arabic-indic digits are checked across label by isULabel
+	return true
+}
+
+// isVirama tells whether r has canonical combining class Virama.
+func isVirama(r rune) bool {
+	switch r {
+	case '्', '্', '੍', '્', '୍', '்', '్', '್',
+		'഻', '഼', '്', '්', 'ฺ', '຺', '྄', '္',
+		'်', '᜔', '᜴', '្', '᩠', '᭄', '᮪', '᮫',
+		'᯲', '᯳', '⵿', '꠆', '꣄', '꥓', '꧀', '꫶',
+		'꯭':
+		return true
+	}
+	return false
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// punycode parameters, as per RFC 3492 section 5
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+var errPunycode = errors.New("invalid punycode")
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punyThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	}
+	return k - bias
+}
+
+// punyDecode decodes punycode s, as per RFC 3492 section 6.2.
+func punyDecode(s string) (string, error) {
+	var output []rune
+	if i := strings.LastIndexByte(s, '-'); i != -1 {
+		for _, r := range s[:i] {
+			if r >= utf8.RuneSelf {
+				return "", errPunycode
+			}
+			output = append(output, r)
+		}
+		s = s[i+1:]
+	}
+	n, i, bias := punyInitialN, 0, punyInitialBias
+	for len(s) > 0 {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if len(s) == 0 {
+				return "", errPunycode
+			}
+			var digit int
+			switch c := s[0]; {
+			case c >= 'a' && c <= 'z':
+				digit = int(c - 'a')
+			case c >= 'A' && c <= 'Z':
+				digit = int(c - 'A')
+			case c >= '0' && c <= '9':
+				digit = int(c-'0') + 26
+			default:
+				return "", errPunycode
+			}
+			s = s[1:]
+			i += digit * w
+			if i < 0 || i > utf8.MaxRune {
+				return "", errPunycode
+			}
+			t := punyThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punyBase - t
+			if w > utf8.MaxRune {
+				return "", errPunycode
+			}
+		}
+		numPoints := len(output) + 1
+		bias = punyAdapt(i-oldi, numPoints, oldi == 0)
+		n += i / numPoints
+		i %= numPoints
+		if n > utf8.MaxRune || (n >= 0xd800 && n <= 0xdfff) {
+			return "", errPunycode
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+	return string(output), nil
+}
+
+// punyEncode encodes s to punycode, as per RFC 3492 section 6.3.
+func punyEncode(s string) (string, error) {
+	runes := []rune(s)
+	var b strings.Builder
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+		}
+	}
+	basic := b.Len()
+	handled := basic
+	if basic > 0 {
+		b.WriteByte('-')
+	}
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for handled < len(runes) {
+		m := int(utf8.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		delta += (m - n) * (handled + 1)
+		if delta < 0 {
+			return "", errPunycode
+		}
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				b.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			b.WriteByte(punyDigit(q))
+			bias = punyAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return b.String(), nil
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,32 @@
+// Package bindec provides helpers shared by the decoders of binary
+// json encodings.
+package bindec
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// Capacity returns initial capacity for array or map with n items.
+// It is bounded, so that malformed length does not allocate memory.
+func Capacity(n uint64) int {
+	if n > 1024 {
+		return 0
+	}
+	return int(n)
+}
+
+// Float returns f as json.Number with shortest decimal of given bits
+// precision. NaN and infinities are returned as strings.
+func Float(f float64, bits int) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bits))
+}
@@ -0,0 +1,56 @@
+// Package ecmaregex translates ECMA-262 regular expressions to go regexp
+// syntax.
+package ecmaregex
+
+import "strings"
+
+// ToGo translates unicode escapes and named groups in ECMA-262
+// regular expression s, to go regexp syntax.
+func ToGo(s string) string {
+	if !strings.Contains(s, `\u`) && !strings.Contains(s, "(?<") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == 'u':
+			switch {
+			case i+5 < len(s) && isHex(s[i+2:i+6]):
+				b.WriteString(`\x{` + s[i+2:i+6] + `}`)
+				i += 5
+				continue
+			case i+2 < len(s) && s[i+2] == '{':
+				if end := strings.IndexByte(s[i:], '}'); end != -1 && isHex(s[i+3:i+end]) {
+					b.WriteString(`\x` + s[i+2:i+end+1])
+					i += end
+					continue
+				}
+			}
+			b.WriteString(s[i : i+2])
+			i++
+		case s[i] == '\\' && i+1 < len(s):
+			b.WriteString(s[i : i+2])
+			i++
+		case strings.HasPrefix(s[i:], "(?<") && !strings.HasPrefix(s[i:], "(?<=") && !strings.HasPrefix(s[i:], "(?<!"):
+			b.WriteString("(?P<")
+			i += 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
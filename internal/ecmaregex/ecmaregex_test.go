@@ -0,0 +1,26 @@
+package ecmaregex_test
+
+import (
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/ecmaregex"
+)
+
+func TestToGo(t *testing.T) {
+	tests := []struct {
+		ecma, goRe string
+	}{
+		{`^a+$`, `^a+$`},
+		{`\u00e9`, `\x{00e9}`},
+		{`\u{1F600}`, `\x{1F600}`},
+		{`\u00`, `\u00`},
+		{`\\u00e9`, `\\u00e9`},
+		{`(?<year>\d+)`, `(?P<year>\d+)`},
+		{`(?<=a)(?<!b)`, `(?<=a)(?<!b)`},
+	}
+	for _, test := range tests {
+		if got := ecmaregex.ToGo(test.ecma); got != test.goRe {
+			t.Errorf("ToGo(%q): got %q, want %q", test.ecma, got, test.goRe)
+		}
+	}
+}
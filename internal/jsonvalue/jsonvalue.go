@@ -0,0 +1,55 @@
+// Package jsonvalue provides helpers for decoded json values, which are
+// shared by the packages working on instances and keyword values.
+package jsonvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Rat returns the value of v, if it is a number.
+func Rat(v interface{}) (*big.Rat, bool) {
+	switch v.(type) {
+	case json.Number, float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return new(big.Rat).SetString(fmt.Sprint(v))
+	}
+	return nil, false
+}
+
+// Equal tells whether json values v1 and v2 are equal.
+// numbers are compared by value.
+func Equal(v1, v2 interface{}) bool {
+	n1, ok1 := Rat(v1)
+	n2, ok2 := Rat(v2)
+	if ok1 || ok2 {
+		return ok1 && ok2 && n1.Cmp(n2) == 0
+	}
+	switch v1 := v1.(type) {
+	case []interface{}:
+		v2, ok := v2.([]interface{})
+		if !ok || len(v1) != len(v2) {
+			return false
+		}
+		for i := range v1 {
+			if !Equal(v1[i], v2[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		v2, ok := v2.(map[string]interface{})
+		if !ok || len(v1) != len(v2) {
+			return false
+		}
+		for k, e1 := range v1 {
+			e2, ok := v2[k]
+			if !ok || !Equal(e1, e2) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(v1, v2)
+}
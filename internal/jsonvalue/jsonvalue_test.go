@@ -0,0 +1,30 @@
+package jsonvalue_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/jsonvalue"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		v1, v2 interface{}
+		want   bool
+	}{
+		{json.Number("1.0"), 1, true},
+		{float32(0.5), json.Number("5e-1"), true},
+		{uint8(1), int64(2), false},
+		{"1", 1, false},
+		{nil, nil, true},
+		{[]interface{}{1.0, "a"}, []interface{}{json.Number("1"), "a"}, true},
+		{[]interface{}{1.0}, []interface{}{1.0, 2.0}, false},
+		{map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": json.Number("1")}, true},
+		{map[string]interface{}{"a": 1.0}, map[string]interface{}{"b": 1.0}, false},
+	}
+	for i, test := range tests {
+		if got := jsonvalue.Equal(test.v1, test.v2); got != test.want {
+			t.Errorf("#%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package schemautil provides helpers to inspect compiled schemas, which
+// are shared by the code generators, schema converters and documentation
+// generator.
+package schemautil
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// RefOf returns the schema referenced by sch, if any.
+func RefOf(sch *jsonschema.Schema) *jsonschema.Schema {
+	switch {
+	case sch.Ref != nil:
+		return sch.Ref
+	case sch.DynamicRef != nil:
+		return sch.DynamicRef
+	case sch.RecursiveRef != nil:
+		return sch.RecursiveRef
+	}
+	return nil
+}
+
+// IsRefOnly tells whether sch has no type information other than
+// references and allOf.
+func IsRefOnly(sch *jsonschema.Schema) bool {
+	return len(sch.Types) == 0 && len(sch.Properties) == 0 && sch.Items == nil &&
+		sch.Items2020 == nil && len(sch.PrefixItems) == 0 && len(sch.Enum) == 0 &&
+		len(sch.Constant) == 0 && len(sch.OneOf) == 0 && len(sch.AnyOf) == 0 &&
+		sch.AdditionalProperties == nil && sch.Always == nil
+}
+
+// Properties returns properties of sch, including those from allOf and $ref.
+// properties found earlier take precedence. required contains only the
+// properties which are returned.
+func Properties(sch *jsonschema.Schema) (props map[string]*jsonschema.Schema, required map[string]bool) {
+	props = make(map[string]*jsonschema.Schema)
+	required = make(map[string]bool)
+	seen := make(map[*jsonschema.Schema]bool)
+	var collect func(sch *jsonschema.Schema)
+	collect = func(sch *jsonschema.Schema) {
+		if sch == nil || seen[sch] {
+			return
+		}
+		seen[sch] = true
+		for pname, psch := range sch.Properties {
+			if _, ok := props[pname]; !ok {
+				props[pname] = psch
+			}
+		}
+		for _, pname := range sch.Required {
+			required[pname] = true
+		}
+		collect(sch.Ref)
+		for _, s := range sch.AllOf {
+			collect(s)
+		}
+	}
+	collect(sch)
+	for pname := range required {
+		if _, ok := props[pname]; !ok {
+			delete(required, pname)
+		}
+	}
+	return props, required
+}
+
+// HasProperties tells whether sch, including its allOf and $ref, has
+// any properties.
+func HasProperties(sch *jsonschema.Schema) bool {
+	props, _ := Properties(sch)
+	return len(props) > 0
+}
+
+// LocationName returns name for the schema at given location.
+// it is the last token of the fragment, or file name without extension
+// if fragment is empty.
+func LocationName(loc string) string {
+	u, frag, _ := strings.Cut(loc, "#")
+	if frag != "" && frag != "/" {
+		name := frag[strings.LastIndexByte(frag, '/')+1:]
+		if n, err := url.PathUnescape(name); err == nil {
+			name = n
+		}
+		return jsonpointer.Unescape(name)
+	}
+	name := path.Base(u)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// UniqueName returns name, suffixed with number if it is already used.
+// the returned name is marked as used.
+func UniqueName(used map[string]bool, name string) string {
+	n := name
+	for i := 2; used[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	used[n] = true
+	return n
+}
+
+// Deref follows the references of sch, as long as sch has nothing other
+// than the reference. allOf tells whether to follow the reference of sch
+// which also has allOf.
+func Deref(sch *jsonschema.Schema, allOf bool) *jsonschema.Schema {
+	seen := make(map[*jsonschema.Schema]bool)
+	for !seen[sch] {
+		seen[sch] = true
+		ref := RefOf(sch)
+		if ref == nil || !IsRefOnly(sch) || (!allOf && len(sch.AllOf) > 0) {
+			break
+		}
+		sch = ref
+	}
+	return sch
+}
+
+// TypesOf returns the json types allowed by sch, excluding null, and
+// whether null is allowed. if sch has no types, they are inferred from
+// properties, items and string enum. additional tells whether
+// additionalProperties alone implies object. integer is dropped if
+// number is also allowed.
+func TypesOf(sch *jsonschema.Schema, additional bool) (types []string, nullable bool) {
+	for _, t := range sch.Types {
+		if t == "null" {
+			nullable = true
+		} else {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 && !nullable {
+		switch {
+		case HasProperties(sch) || (additional && sch.AdditionalProperties != nil):
+			types = []string{"object"}
+		case sch.Items != nil || sch.Items2020 != nil || len(sch.PrefixItems) > 0:
+			types = []string{"array"}
+		case len(EnumValues(sch)) > 0:
+			types = []string{"string"}
+		}
+	}
+	if len(types) == 2 && types[0] == "integer" && types[1] == "number" {
+		types = types[1:]
+	}
+	return types, nullable
+}
+
+// EnumValues returns the enum values, if all of them are strings.
+func EnumValues(sch *jsonschema.Schema) []string {
+	var values []string
+	for _, v := range sch.Enum {
+		s, ok := v.(string)
+		if !ok {
+			return nil
+		}
+		values = append(values, s)
+	}
+	return values
+}
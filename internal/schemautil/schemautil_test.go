@@ -0,0 +1,61 @@
+package schemautil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+func TestLocationName(t *testing.T) {
+	tests := []struct {
+		loc, name string
+	}{
+		{"http://example.com/person.json#", "person"},
+		{"http://example.com/person.json#/", "person"},
+		{"http://example.com/person#", "person"},
+		{"http://example.com/person.json#/$defs/address", "address"},
+		{"http://example.com/person.json#/$defs/a~1b", "a/b"},
+		{"http://example.com/person.json#/$defs/~01", "~1"},
+		{"http://example.com/person.json#/$defs/a%7E1b", "a/b"},
+		{"http://example.com/person.json#/$defs/first%20name", "first name"},
+	}
+	for _, test := range tests {
+		if got := schemautil.LocationName(test.loc); got != test.name {
+			t.Errorf("LocationName(%q): got %q, want %q", test.loc, got, test.name)
+		}
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	used := make(map[string]bool)
+	for _, want := range []string{"a", "a2", "a3"} {
+		if got := schemautil.UniqueName(used, "a"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestTypesOf(t *testing.T) {
+	tests := []struct {
+		sch        *jsonschema.Schema
+		additional bool
+		types      string
+		nullable   bool
+	}{
+		{&jsonschema.Schema{Types: []string{"string", "null"}}, false, "string", true},
+		{&jsonschema.Schema{Types: []string{"integer", "number"}}, false, "number", false},
+		{&jsonschema.Schema{Enum: []interface{}{"a", "b"}}, false, "string", false},
+		{&jsonschema.Schema{Enum: []interface{}{"a", 1.0}}, false, "", false},
+		{&jsonschema.Schema{Items2020: &jsonschema.Schema{}}, false, "array", false},
+		{&jsonschema.Schema{AdditionalProperties: false}, false, "", false},
+		{&jsonschema.Schema{AdditionalProperties: false}, true, "object", false},
+	}
+	for i, test := range tests {
+		types, nullable := schemautil.TypesOf(test.sch, test.additional)
+		if got := strings.Join(types, ","); got != test.types || nullable != test.nullable {
+			t.Errorf("#%d: got %q %v, want %q %v", i, got, nullable, test.types, test.nullable)
+		}
+	}
+}
@@ -8,3 +8,61 @@ func TestQuote(t *testing.T) {
 		t.Fatalf("got: %s want: %s", got, want)
 	}
 }
+
+func TestWindowsFileURL(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{`C:\schemas\a.json`, "file:///C:/schemas/a.json"},
+		{`c:/schemas/a.json`, "file:///C:/schemas/a.json"},
+		{`C:\schemas\sub\..\my schema.json`, "file:///C:/schemas/my%20schema.json"},
+		{`\\host\share\a.json`, "file://host/share/a.json"},
+		{`schemas\a.json`, ""},
+		{`/schemas/a.json`, ""},
+	}
+	for _, test := range tests {
+		got, ok := windowsFileURL(test.path)
+		if ok != (test.want != "") || got != test.want {
+			t.Errorf("%s: got %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestToAbs_dotSegments(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"file:///schemas/sub/../a.json", "file:///schemas/a.json"},
+		{"file:///schemas/./sub/a.json#/x", "file:///schemas/sub/a.json#/x"},
+		{"file:///schemas/my%20a.json", "file:///schemas/my%20a.json"},
+		{"http://example.com/sub/../a.json", "http://example.com/sub/../a.json"},
+	}
+	for _, test := range tests {
+		got, err := toAbs(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		s, t string
+		want int
+	}{
+		{"", "", 0},
+		{"name", "name", 0},
+		{"nmae", "name", 1},
+		{"name", "nam", 1},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 3},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.s, test.t); got != test.want {
+			t.Errorf("editDistance(%q, %q): got %d, want %d", test.s, test.t, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,510 @@
+// Package jsonpatch applies JSON Patch (RFC 6902) and JSON Merge Patch
+// (RFC 7386) to json documents, and validates the patched documents
+// reporting which patch operation caused each violation.
+//
+// Typical usage in a PATCH handler:
+//
+//	patch, err := io.ReadAll(r.Body)
+//	if err != nil {
+//		return err
+//	}
+//	updated, err := jsonpatch.Validate(sch, current, patch)
+//	if err != nil {
+//		var perr *jsonpatch.Error
+//		if errors.As(err, &perr) {
+//			for _, v := range perr.Violations {
+//				if v.Op != nil {
+//					log.Printf("op %d (%s %s): %s", v.Op.Index, v.Op.Op, v.Op.Path, v.Err.Message)
+//				}
+//			}
+//		}
+//		return err
+//	}
+//
+// Use ValidateMerge for patches of content type application/merge-patch+json.
+//
+// The current document is never modified. It must be a decoded json value
+// such as those returned by jsonschema.DecodeJSON.
+package jsonpatch
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/jsonvalue"
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// Operation is an operation of JSON Patch. For JSON Merge Patch, each
+// member of patch which is not an object, is reported as an Operation
+// with Index -1, Op "remove" for null and "add" otherwise.
+type Operation struct {
+	Index int    // index of operation in patch
+	Op    string // add, remove, replace, move, copy or test
+	Path  string
+	From  string // for move and copy
+	Value interface{}
+}
+
+// OpError is the error returned, when an operation of patch cannot be
+// applied.
+type OpError struct {
+	Op  Operation
+	Err error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("jsonpatch: operation %d (%s %q): %v", e.Op.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// Violation is a leaf error of validating patched document.
+type Violation struct {
+	Err *jsonschema.ValidationError
+
+	// Op is the last operation which changed the value at the instance
+	// location of Err, or a value containing it. If there is none, the
+	// last operation which changed a value inside it is used, preferring
+	// removals for errors like "required". nil if the violation is not
+	// caused by patch.
+	Op *Operation
+}
+
+// Error is returned by Validate and ValidateMerge, when the patched
+// document is not valid against schema.
+type Error struct {
+	Err        *jsonschema.ValidationError
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("jsonpatch: patched document is not valid")
+	for _, v := range e.Violations {
+		b.WriteString("\n  ")
+		if v.Op != nil {
+			if v.Op.Index >= 0 {
+				fmt.Fprintf(&b, "operation %d ", v.Op.Index)
+			}
+			fmt.Fprintf(&b, "(%s %q): ", v.Op.Op, v.Op.Path)
+		}
+		fmt.Fprintf(&b, "%q: %s", v.Err.InstanceLocation, v.Err.Message)
+	}
+	return b.String()
+}
+
+// Unwrap returns e.Err, so that errors.As works with *jsonschema.ValidationError.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Apply applies JSON Patch to doc and returns the patched document.
+//
+// returns *OpError, if an operation cannot be applied, including failed
+// "test" operations.
+func Apply(doc interface{}, patch []byte) (interface{}, error) {
+	ops, err := parse(patch)
+	if err != nil {
+		return nil, err
+	}
+	return apply(doc, ops)
+}
+
+// ApplyMerge applies JSON Merge Patch to doc and returns the patched document.
+func ApplyMerge(doc interface{}, patch []byte) (interface{}, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergePatch(deepCopy(doc), p), nil
+}
+
+// Validate applies JSON Patch to doc, and validates the patched document
+// against sch. Returns the patched document.
+//
+// returns *OpError, if an operation cannot be applied.
+// returns *Error, if the patched document is not valid against sch.
+func Validate(sch *jsonschema.Schema, doc interface{}, patch []byte) (interface{}, error) {
+	ops, err := parse(patch)
+	if err != nil {
+		return nil, err
+	}
+	v, err := apply(doc, ops)
+	if err != nil {
+		return nil, err
+	}
+	return v, validate(sch, v, ops)
+}
+
+// ValidateMerge applies JSON Merge Patch to doc, and validates the
+// patched document against sch. Returns the patched document.
+//
+// returns *Error, if the patched document is not valid against sch.
+func ValidateMerge(sch *jsonschema.Schema, doc interface{}, patch []byte) (interface{}, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	v := mergePatch(deepCopy(doc), p)
+	var ops []Operation
+	mergeOps(p, "", &ops)
+	return v, validate(sch, v, ops)
+}
+
+func validate(sch *jsonschema.Schema, v interface{}, ops []Operation) error {
+	err := sch.Validate(v)
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	perr := &Error{Err: ve}
+	for _, leaf := range ve.Leaves() {
+		perr.Violations = append(perr.Violations, Violation{Err: leaf, Op: cause(ops, leaf.InstanceLocation)})
+	}
+	return perr
+}
+
+// cause returns the operation which caused violation at loc.
+func cause(ops []Operation, loc string) *Operation {
+	for i := len(ops) - 1; i >= 0; i-- {
+		if op := &ops[i]; op.Op != "test" && within(loc, op.Path) {
+			return op
+		}
+	}
+	// prefer removals, which cause errors like "required"
+	removed := func(op *Operation) bool {
+		return op.Op == "remove" && within(op.Path, loc) || op.Op == "move" && within(op.From, loc)
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if op := &ops[i]; removed(op) {
+			return op
+		}
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if op := &ops[i]; op.Op != "test" && within(op.Path, loc) {
+			return op
+		}
+	}
+	return nil
+}
+
+// within tells whether ptr is same as prefix or inside it. The token "-",
+// used to append to array, matches any token.
+func within(ptr, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	tokens, prefixTokens := strings.Split(ptr, "/"), strings.Split(prefix, "/")
+	if len(tokens) < len(prefixTokens) {
+		return false
+	}
+	for i, tok := range prefixTokens {
+		if tok != tokens[i] && tok != "-" {
+			return false
+		}
+	}
+	return true
+}
+
+func decode(b []byte) (interface{}, error) {
+	v, err := jsonschema.DecodeJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("jsonpatch: invalid json: %v", err)
+	}
+	return v, nil
+}
+
+func parse(patch []byte) ([]Operation, error) {
+	v, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jsonpatch: patch must be an array")
+	}
+	ops := make([]Operation, len(arr))
+	for i, item := range arr {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("jsonpatch: operation %d must be an object", i)
+		}
+		op := Operation{Index: i}
+		str := func(name string) (string, error) {
+			s, ok := m[name].(string)
+			if !ok {
+				return "", fmt.Errorf("jsonpatch: operation %d must have string %q", i, name)
+			}
+			return s, nil
+		}
+		if op.Op, err = str("op"); err != nil {
+			return nil, err
+		}
+		if op.Path, err = str("path"); err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			var ok bool
+			if op.Value, ok = m["value"]; !ok {
+				return nil, fmt.Errorf("jsonpatch: operation %d must have \"value\"", i)
+			}
+		case "move", "copy":
+			if op.From, err = str("from"); err != nil {
+				return nil, err
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("jsonpatch: operation %d has unknown op %q", i, op.Op)
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+func apply(doc interface{}, ops []Operation) (interface{}, error) {
+	doc = deepCopy(doc)
+	for _, op := range ops {
+		var err error
+		if doc, err = applyOp(doc, op); err != nil {
+			return nil, &OpError{op, err}
+		}
+	}
+	return doc, nil
+}
+
+func applyOp(doc interface{}, op Operation) (interface{}, error) {
+	path, err := jsonpointer.Split(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		return add(doc, path, deepCopy(op.Value), false)
+	case "replace":
+		return add(doc, path, deepCopy(op.Value), true)
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "test":
+		v, err := jsonpointer.Eval(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonvalue.Equal(v, op.Value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+
+	// move, copy
+	from, err := jsonpointer.Split(op.From)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if op.Op == "move" {
+		if op.Path == op.From {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %q into its child", op.From)
+		}
+		if doc, v, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+	} else {
+		if v, err = jsonpointer.Eval(doc, op.From); err != nil {
+			return nil, err
+		}
+		v = deepCopy(v)
+	}
+	return add(doc, path, v, false)
+}
+
+// add returns doc with value set at path. if replace is true, the value
+// at path must exist.
+func add(doc interface{}, path []string, value interface{}, replace bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	tok, last := path[0], len(path) == 1
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		child, ok := doc[tok]
+		if last {
+			if replace && !ok {
+				return nil, fmt.Errorf("property %q not found", tok)
+			}
+			doc[tok] = value
+			return doc, nil
+		}
+		if !ok {
+			return nil, fmt.Errorf("property %q not found", tok)
+		}
+		child, err := add(child, path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		doc[tok] = child
+		return doc, nil
+	case []interface{}:
+		if last && !replace && tok == "-" {
+			return append(doc, value), nil
+		}
+		i, err := index(tok, len(doc), last && !replace)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			if replace {
+				doc[i] = value
+				return doc, nil
+			}
+			doc = append(doc, nil)
+			copy(doc[i+1:], doc[i:])
+			doc[i] = value
+			return doc, nil
+		}
+		child, err := add(doc[i], path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		doc[i] = child
+		return doc, nil
+	}
+	return nil, fmt.Errorf("cannot set %q of %s", tok, jsonType(doc))
+}
+
+// remove returns doc with value at path removed, along with the removed value.
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove document")
+	}
+	tok, last := path[0], len(path) == 1
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		child, ok := doc[tok]
+		if !ok {
+			return nil, nil, fmt.Errorf("property %q not found", tok)
+		}
+		if last {
+			delete(doc, tok)
+			return doc, child, nil
+		}
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		doc[tok] = child
+		return doc, removed, nil
+	case []interface{}:
+		i, err := index(tok, len(doc), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := doc[i]
+			return append(doc[:i], doc[i+1:]...), removed, nil
+		}
+		child, removed, err := remove(doc[i], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		doc[i] = child
+		return doc, removed, nil
+	}
+	return nil, nil, fmt.Errorf("cannot remove %q of %s", tok, jsonType(doc))
+}
+
+// index returns array index tok. if insert is true, it can be same as length.
+func index(tok string, length int, insert bool) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || tok != strconv.Itoa(i) {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i > length || i == length && !insert {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	pm, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]interface{})
+	if !ok {
+		tm = make(map[string]interface{})
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergePatch(tm[k], v)
+		}
+	}
+	return tm
+}
+
+// mergeOps appends the members of merge patch p at ptr as operations.
+func mergeOps(p interface{}, ptr string, ops *[]Operation) {
+	m, ok := p.(map[string]interface{})
+	if !ok {
+		op := Operation{Index: -1, Op: "add", Path: ptr, Value: p}
+		if p == nil {
+			op.Op = "remove"
+		}
+		*ops = append(*ops, op)
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		mergeOps(m[k], ptr+"/"+jsonpointer.Escape(k), ops)
+	}
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = deepCopy(item)
+		}
+		return arr
+	}
+	return v
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	if _, ok := jsonvalue.Rat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,175 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpatch"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	v, err := jsonschema.DecodeJSON(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func toJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestApply(t *testing.T) {
+	doc := `{"a": {"b": [1, 2, 3]}, "c": "x"}`
+	tests := []struct {
+		patch string
+		want  string // empty means error
+	}{
+		{`[{"op": "add", "path": "/d", "value": {"e": 1}}]`, `{"a":{"b":[1,2,3]},"c":"x","d":{"e":1}}`},
+		{`[{"op": "add", "path": "/a/b/1", "value": 9}]`, `{"a":{"b":[1,9,2,3]},"c":"x"}`},
+		{`[{"op": "add", "path": "/a/b/-", "value": 9}]`, `{"a":{"b":[1,2,3,9]},"c":"x"}`},
+		{`[{"op": "remove", "path": "/a/b/0"}]`, `{"a":{"b":[2,3]},"c":"x"}`},
+		{`[{"op": "replace", "path": "/c", "value": "y"}]`, `{"a":{"b":[1,2,3]},"c":"y"}`},
+		{`[{"op": "move", "from": "/c", "path": "/a/c"}]`, `{"a":{"b":[1,2,3],"c":"x"}}`},
+		{`[{"op": "copy", "from": "/a/b", "path": "/d"}]`, `{"a":{"b":[1,2,3]},"c":"x","d":[1,2,3]}`},
+		{`[{"op": "test", "path": "/a/b", "value": [1, 2.0, 3]}, {"op": "remove", "path": "/a"}]`, `{"c":"x"}`},
+		{`[{"op": "replace", "path": "", "value": 1}]`, `1`},
+		{`[{"op": "test", "path": "/c", "value": "y"}]`, ``},
+		{`[{"op": "replace", "path": "/d", "value": 1}]`, ``},
+		{`[{"op": "add", "path": "/a/b/4", "value": 1}]`, ``},
+		{`[{"op": "add", "path": "/x/y", "value": 1}]`, ``},
+		{`[{"op": "move", "from": "/a", "path": "/a/b/0"}]`, ``},
+		{`[{"op": "remove", "path": "/a/b/01"}]`, ``},
+		{`[{"op": "unknown", "path": "/a"}]`, ``},
+		{`[{"op": "add", "path": "/a"}]`, ``},
+		{`{}`, ``},
+	}
+	for _, test := range tests {
+		orig := decode(t, doc)
+		got, err := jsonpatch.Apply(orig, []byte(test.patch))
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s: error expected, got %s", test.patch, toJSON(got))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.patch, err)
+			continue
+		}
+		if toJSON(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.patch, toJSON(got), test.want)
+		}
+		if toJSON(orig) != toJSON(decode(t, doc)) {
+			t.Errorf("%s: doc is modified", test.patch)
+		}
+	}
+
+	var operr *jsonpatch.OpError
+	_, err := jsonpatch.Apply(decode(t, doc), []byte(`[{"op": "remove", "path": "/c"}, {"op": "remove", "path": "/c"}]`))
+	if !errors.As(err, &operr) || operr.Op.Index != 1 {
+		t.Fatalf("got %v, want OpError of operation 1", err)
+	}
+}
+
+func TestApplyMerge(t *testing.T) {
+	doc := decode(t, `{"a": "b", "c": {"d": "e", "f": "g"}}`)
+	got, err := jsonpatch.ApplyMerge(doc, []byte(`{"a": "z", "c": {"f": null}, "h": [1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":"z","c":{"d":"e"},"h":[1]}`; toJSON(got) != want {
+		t.Fatalf("got %s, want %s", toJSON(got), want)
+	}
+	if toJSON(doc) != `{"a":"b","c":{"d":"e","f":"g"}}` {
+		t.Fatal("doc is modified")
+	}
+}
+
+var schema = jsonschema.MustCompileString("user.json", `{
+	"type": "object",
+	"required": ["name", "email"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"email": {"type": "string"},
+		"age": {"type": "integer", "minimum": 18},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	}
+}`)
+
+func TestValidate(t *testing.T) {
+	doc := decode(t, `{"name": "john", "email": "john@example.com", "tags": ["a"]}`)
+	v, err := jsonpatch.Validate(schema, doc, []byte(`[{"op": "add", "path": "/age", "value": 20}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if toJSON(v) != `{"age":20,"email":"john@example.com","name":"john","tags":["a"]}` {
+		t.Fatalf("got %s", toJSON(v))
+	}
+
+	_, err = jsonpatch.Validate(schema, doc, []byte(`[
+		{"op": "add", "path": "/age", "value": 10},
+		{"op": "remove", "path": "/email"},
+		{"op": "replace", "path": "/name", "value": ""},
+		{"op": "add", "path": "/tags/-", "value": 1}
+	]`))
+	var perr *jsonpatch.Error
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %v, want *jsonpatch.Error", err)
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatal("must unwrap to *jsonschema.ValidationError")
+	}
+	causes := map[string]int{}
+	for _, v := range perr.Violations {
+		if v.Op == nil {
+			t.Fatalf("%s: no operation", v.Err.InstanceLocation)
+		}
+		causes[v.Err.InstanceLocation] = v.Op.Index
+	}
+	want := map[string]int{"/age": 0, "": 1, "/name": 2, "/tags/1": 3}
+	if toJSON(causes) != toJSON(want) {
+		t.Fatalf("got %v, want %v", causes, want)
+	}
+	if !strings.Contains(err.Error(), `operation 2 (replace "/name")`) {
+		t.Fatalf("error message: %s", err)
+	}
+}
+
+func TestValidate_preexisting(t *testing.T) {
+	doc := decode(t, `{"name": "", "email": "x"}`)
+	_, err := jsonpatch.Validate(schema, doc, []byte(`[{"op": "add", "path": "/age", "value": 30}]`))
+	var perr *jsonpatch.Error
+	if !errors.As(err, &perr) || len(perr.Violations) != 1 || perr.Violations[0].Op != nil {
+		t.Fatalf("got %v, want violation not caused by patch", err)
+	}
+}
+
+func TestValidateMerge(t *testing.T) {
+	doc := decode(t, `{"name": "john", "email": "john@example.com"}`)
+	if _, err := jsonpatch.ValidateMerge(schema, doc, []byte(`{"age": 30}`)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := jsonpatch.ValidateMerge(schema, doc, []byte(`{"age": 10, "email": null}`))
+	var perr *jsonpatch.Error
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %v, want *jsonpatch.Error", err)
+	}
+	causes := map[string]string{}
+	for _, v := range perr.Violations {
+		if v.Op == nil || v.Op.Index != -1 {
+			t.Fatalf("%s: got %v", v.Err.InstanceLocation, v.Op)
+		}
+		causes[v.Err.InstanceLocation] = v.Op.Op + " " + v.Op.Path
+	}
+	want := map[string]string{"/age": "add /age", "": "remove /email"}
+	if toJSON(causes) != toJSON(want) {
+		t.Fatalf("got %v, want %v", causes, want)
+	}
+}
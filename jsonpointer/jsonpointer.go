@@ -0,0 +1,112 @@
+// Package jsonpointer implements JSON Pointer, as defined in RFC 6901.
+//
+// The pointers used by jsonschema for instance locations, such as
+// ValidationError.InstanceLocation, can be evaluated using this package.
+package jsonpointer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is the error returned by Eval, wrapped with the pointer,
+// when the pointer does not refer to any value.
+var ErrNotFound = errors.New("jsonpointer: value not found")
+
+// Escape escapes token to be used in json-pointer, by replacing
+// "~" with "~0" and "/" with "~1".
+func Escape(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+// Unescape reverses Escape. Unlike Split, it does not report invalid
+// escape sequences.
+func Unescape(token string) string {
+	if !strings.Contains(token, "~") {
+		return token
+	}
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
+
+// Join returns json-pointer with given unescaped tokens.
+func Join(tokens ...string) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(Escape(tok))
+	}
+	return sb.String()
+}
+
+// Split returns the unescaped tokens of json-pointer ptr.
+// The empty pointer "" refers to whole document and has no tokens.
+func Split(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("jsonpointer: invalid pointer %q: must start with /", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || (tok[j+1] != '0' && tok[j+1] != '1')) {
+				return nil, fmt.Errorf("jsonpointer: invalid pointer %q: invalid escape sequence in %q", ptr, tok)
+			}
+		}
+		tokens[i] = Unescape(tok)
+	}
+	return tokens, nil
+}
+
+// Eval returns the value referred by json-pointer ptr in doc.
+//
+// doc must be json value as decoded by encoding/json into interface{}.
+// returns error wrapping ErrNotFound, if ptr does not refer to any value.
+func Eval(doc interface{}, ptr string) (interface{}, error) {
+	tokens, err := Split(ptr)
+	if err != nil {
+		return nil, err
+	}
+	v := doc
+	for _, tok := range tokens {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			item, ok := vv[tok]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrNotFound, ptr)
+			}
+			v = item
+		case []interface{}:
+			index, ok := arrayIndex(tok)
+			if !ok || index >= len(vv) {
+				return nil, fmt.Errorf("%w: %q", ErrNotFound, ptr)
+			}
+			v = vv[index]
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrNotFound, ptr)
+		}
+	}
+	return v, nil
+}
+
+// arrayIndex returns the array index in token. leading zeros are not allowed.
+func arrayIndex(tok string) (int, bool) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(tok)
+	return index, err == nil
+}
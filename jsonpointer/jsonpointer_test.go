@@ -0,0 +1,91 @@
+package jsonpointer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		token, escaped string
+	}{
+		{"foo", "foo"},
+		{"a/b", "a~1b"},
+		{"m~n", "m~0n"},
+		{"~1", "~01"},
+		{"/~", "~1~0"},
+		{"a b%", "a b%"},
+	}
+	for _, test := range tests {
+		if got := jsonpointer.Escape(test.token); got != test.escaped {
+			t.Errorf("Escape(%q): got %q, want %q", test.token, got, test.escaped)
+		}
+		if got := jsonpointer.Unescape(test.escaped); got != test.token {
+			t.Errorf("Unescape(%q): got %q, want %q", test.escaped, got, test.token)
+		}
+	}
+	if got := jsonpointer.Join("a/b", "~", "0"); got != "/a~1b/~0/0" {
+		t.Errorf("Join: got %q", got)
+	}
+}
+
+func TestEval(t *testing.T) {
+	// examples from RFC 6901
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ptr  string
+		want interface{}
+	}{
+		{"", doc},
+		{"/foo", []interface{}{"bar", "baz"}},
+		{"/foo/0", "bar"},
+		{"/", 0.0},
+		{"/a~1b", 1.0},
+		{"/c%d", 2.0},
+		{"/e^f", 3.0},
+		{"/g|h", 4.0},
+		{"/i\\j", 5.0},
+		{"/k\"l", 6.0},
+		{"/ ", 7.0},
+		{"/m~0n", 8.0},
+	}
+	for _, test := range tests {
+		got, err := jsonpointer.Eval(doc, test.ptr)
+		if err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.ptr, got, test.want)
+		}
+	}
+
+	for _, ptr := range []string{"/bar", "/foo/2", "/foo/-", "/foo/01", "/foo/0/x"} {
+		if _, err := jsonpointer.Eval(doc, ptr); !errors.Is(err, jsonpointer.ErrNotFound) {
+			t.Errorf("%q: got %v, want ErrNotFound", ptr, err)
+		}
+	}
+	for _, ptr := range []string{"foo", "/m~2n", "/m~"} {
+		if _, err := jsonpointer.Eval(doc, ptr); err == nil || errors.Is(err, jsonpointer.ErrNotFound) {
+			t.Errorf("%q: got %v, want invalid pointer error", ptr, err)
+		}
+	}
+}
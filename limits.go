@@ -0,0 +1,120 @@
+package jsonschema
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// Limits bounds the resources consumed in compiling and validating
+// schemas. This is useful when compiling user-supplied schemas.
+//
+// Zero value of a field means no limit. Exceeding a limit results in
+// *LimitExceededError.
+type Limits struct {
+	// MaxRefDepth is the maximum number of $ref, $recursiveRef and
+	// $dynamicRef, followed one after another, while compiling a schema.
+	MaxRefDepth int
+
+	// MaxSchemaSize is the maximum size in bytes of each schema document,
+	// added by AddResource or AddResources, or loaded from url.
+	MaxSchemaSize int
+
+	// MaxPatterns is the maximum number of distinct regular expressions
+	// compiled for "pattern" and "patternProperties", while compiling a
+	// schema. Patterns already compiled by earlier compilations are not
+	// counted, as compiled regular expressions are cached by the compiler.
+	MaxPatterns int
+
+	// MaxValidationDepth is the maximum number of schemas nested in
+	// dynamic scope, while validating an instance. It bounds the stack
+	// used by recursive schemas, validating deeply nested instances.
+	MaxValidationDepth int
+
+	// MaxFetches is the maximum number of documents loaded by the compiler
+	// using LoadURL. Documents found in fs added by AddRemoteFS are not
+	// counted.
+	MaxFetches int
+}
+
+// limitReader returns reader, which fails with *LimitExceededError
+// if r has more than MaxSchemaSize bytes.
+func (c *Compiler) limitReader(url string, r io.Reader) io.Reader {
+	if c.Limits.MaxSchemaSize <= 0 {
+		return r
+	}
+	return &limitedReader{url: url, r: r, max: c.Limits.MaxSchemaSize}
+}
+
+// checkSchemaSize returns *LimitExceededError if b has more
+// than MaxSchemaSize bytes.
+func (c *Compiler) checkSchemaSize(url string, b []byte) error {
+	if max := c.Limits.MaxSchemaSize; max > 0 && len(b) > max {
+		return &LimitExceededError{Limit: "MaxSchemaSize", Max: max, URL: url}
+	}
+	return nil
+}
+
+type limitedReader struct {
+	url string
+	r   io.Reader
+	max int
+	n   int // number of bytes read
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if rem := lr.max - lr.n + 1; len(p) > rem {
+		p = p[:rem] // read one byte more than max, to detect the excess
+	}
+	n, err := lr.r.Read(p)
+	if lr.n += n; lr.n > lr.max {
+		return 0, &LimitExceededError{Limit: "MaxSchemaSize", Max: lr.max, URL: lr.url}
+	}
+	return n, err
+}
+
+// refDepth returns the number of references in stack.
+func refDepth(stack []schemaRef) int {
+	n := 0
+	for _, sr := range stack {
+		if isRefKeyword(sr.path) {
+			n++
+		}
+	}
+	return n
+}
+
+func isRefKeyword(path string) bool {
+	switch path {
+	case "$ref", "$recursiveRef", "$dynamicRef":
+		return true
+	}
+	return false
+}
+
+// exceedsDepth returns the location of first value in v, at which
+// nesting of arrays and objects exceeds max.
+func exceedsDepth(v interface{}, max int) (string, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if max == 0 {
+			return "", true
+		}
+		for pname, pvalue := range v {
+			if loc, ok := exceedsDepth(pvalue, max-1); ok {
+				return "/" + jsonpointer.Escape(pname) + loc, true
+			}
+		}
+	case []interface{}:
+		if max == 0 {
+			return "", true
+		}
+		for i, item := range v {
+			if loc, ok := exceedsDepth(item, max-1); ok {
+				return "/" + strconv.Itoa(i) + loc, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,195 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits jsonschema.Limits
+		schema string
+		limit  string
+	}{
+		{
+			name:   "MaxRefDepth",
+			limits: jsonschema.Limits{MaxRefDepth: 2},
+			schema: `{
+				"$ref": "#/$defs/a",
+				"$defs": {
+					"a": {"$ref": "#/$defs/b"},
+					"b": {"$ref": "#/$defs/c"},
+					"c": {"type": "string"}
+				}
+			}`,
+			limit: "MaxRefDepth",
+		},
+		{
+			name:   "MaxSchemaSize",
+			limits: jsonschema.Limits{MaxSchemaSize: 20},
+			schema: `{"type": "string", "minLength": 1}`,
+			limit:  "MaxSchemaSize",
+		},
+		{
+			name:   "MaxPatterns",
+			limits: jsonschema.Limits{MaxPatterns: 1},
+			schema: `{"properties": {"a": {"pattern": "^a"}, "b": {"pattern": "^b"}}}`,
+			limit:  "MaxPatterns",
+		},
+		{
+			name:   "MaxFetches",
+			limits: jsonschema.Limits{MaxFetches: 1},
+			schema: `{"allOf": [{"$ref": "http://example.com/a.json"}, {"$ref": "http://example.com/b.json"}]}`,
+			limit:  "MaxFetches",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			c.LoadURL = func(s string) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(`{}`)), nil
+			}
+			c.Limits = test.limits
+			err := c.AddResource("http://example.com/schema.json", strings.NewReader(test.schema))
+			if err == nil {
+				_, err = c.Compile("http://example.com/schema.json")
+			}
+			var le *jsonschema.LimitExceededError
+			if !errors.As(err, &le) {
+				t.Fatalf("got %v, want LimitExceededError", err)
+			}
+			if le.Limit != test.limit {
+				t.Fatalf("got %s, want %s", le.Limit, test.limit)
+			}
+
+			// without limits, it must compile
+			c = jsonschema.NewCompiler()
+			c.LoadURL = func(s string) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(`{}`)), nil
+			}
+			if err := c.AddResource("http://example.com/schema.json", strings.NewReader(test.schema)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Compile("http://example.com/schema.json"); err != nil {
+				t.Fatalf("%#v", err)
+			}
+		})
+	}
+}
+
+func TestLimits_MaxPatterns(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.Limits.MaxPatterns = 2
+	schemas := map[string]string{
+		"a.json": `{"properties": {"a": {"pattern": "^a"}, "b": {"pattern": "^b"}}}`,
+		"b.json": `{"properties": {"c": {"pattern": "^c"}}, "patternProperties": {"^d": {}}}`,
+	}
+	for url, schema := range schemas {
+		if err := c.AddResource(url, strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// limit applies to each compilation
+	for _, url := range []string{"a.json", "b.json"} {
+		if _, err := c.Compile(url); err != nil {
+			t.Fatalf("%s: %#v", url, err)
+		}
+	}
+}
+
+func TestLimits_MaxSchemaSize(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.Limits.MaxSchemaSize = 20
+	err := c.AddResources(map[string][]byte{"http://example.com/schema.json": []byte(`{"type": "string", "minLength": 1}`)})
+	var le *jsonschema.LimitExceededError
+	if !errors.As(err, &le) {
+		t.Fatalf("got %v, want LimitExceededError", err)
+	}
+	if err := c.AddResource("http://example.com/small.json", strings.NewReader(`{"type": "string"}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimits_MaxValidationDepth(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.Limits.MaxValidationDepth = 10
+	schema := `{"items": {"$ref": "#"}}`
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("http://example.com/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate([]interface{}{[]interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+	var v interface{} = []interface{}{}
+	for i := 0; i < 20; i++ {
+		v = []interface{}{v}
+	}
+	err = sch.Validate(v)
+	var le *jsonschema.LimitExceededError
+	if !errors.As(err, &le) {
+		t.Fatalf("got %v, want LimitExceededError", err)
+	}
+	if le.Limit != "MaxValidationDepth" || le.Max != 10 {
+		t.Fatalf("got %v", le)
+	}
+}
+
+func TestValidateOptions_Limits(t *testing.T) {
+	sch := jsonschema.MustCompileString("http://example.com/schema.json", `{
+		"properties": {
+			"tags": {"uniqueItems": true},
+			"name": {"pattern": "^[a-z]+$"},
+			"labels": {"patternProperties": {"^x-": {"type": "string"}}}
+		}
+	}`)
+	tests := []struct {
+		name     string
+		opts     jsonschema.ValidateOptions
+		instance string
+		limit    string
+		loc      string
+	}{
+		{"MaxDepth", jsonschema.ValidateOptions{MaxDepth: 3}, `{"other": [[[1]]]}`, "MaxDepth", "/other/0/0"},
+		{"MaxUniqueItems", jsonschema.ValidateOptions{MaxUniqueItems: 2}, `{"tags": [1, 2, 3]}`, "MaxUniqueItems", "/tags"},
+		{"MaxPatternLength", jsonschema.ValidateOptions{MaxPatternLength: 5}, `{"name": "abcdefgh"}`, "MaxPatternLength", "/name"},
+		{"MaxPatternLength/patternProperties", jsonschema.ValidateOptions{MaxPatternLength: 5}, `{"labels": {"x-abcdefgh": "1"}}`, "MaxPatternLength", "/labels"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := jsonschema.DecodeJSON(strings.NewReader(test.instance))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sch.Validate(v); err != nil {
+				t.Fatalf("without limits: %v", err)
+			}
+			err = sch.ValidateWithOptions(v, test.opts)
+			var le *jsonschema.LimitExceededError
+			if !errors.As(err, &le) {
+				t.Fatalf("got %v, want LimitExceededError", err)
+			}
+			if le.Limit != test.limit || le.InstanceLocation != test.loc {
+				t.Fatalf("got %s at %q, want %s at %q", le.Limit, le.InstanceLocation, test.limit, test.loc)
+			}
+		})
+	}
+
+	// within limits
+	v, err := jsonschema.DecodeJSON(strings.NewReader(`{"tags": [1, 2], "name": "abc", "other": [[1]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := jsonschema.ValidateOptions{MaxDepth: 3, MaxUniqueItems: 2, MaxPatternLength: 5}
+	if err := sch.ValidateWithOptions(v, opts); err != nil {
+		t.Fatal(err)
+	}
+}
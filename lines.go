@@ -0,0 +1,72 @@
+package jsonschema
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// LineResult is the result of validating a line of newline-delimited json.
+type LineResult struct {
+	Line  int         // line number, starting from 1
+	Value interface{} // json value in the line. nil if line is not valid json
+	Err   error       // nil if valid. *ValidationError if invalid, or error from json decoding
+}
+
+// LinesOptions configures Schema.ValidateLines. The zero value is ready to use.
+type LinesOptions struct {
+	// MaxLineSize is the maximum length of a line, in bytes.
+	// defaults to 1MB if zero.
+	MaxLineSize int
+
+	// Result, if not nil, is called with the result of each line, in order.
+	// Returning false stops the validation. When Result is set,
+	// ValidateLines does not collect the results.
+	Result func(r LineResult) bool
+}
+
+// ValidateLines validates each line of newline-delimited json (NDJSON,
+// JSON Lines) read from r, against the schema s. Blank lines are skipped.
+//
+// Returns the results of lines which are either invalid json or not valid
+// against s, unless opts.Result is set. opts can be nil.
+//
+// The returned error is non-nil only if reading from r fails, or a line is
+// longer than opts.MaxLineSize.
+func (s *Schema) ValidateLines(r io.Reader, opts *LinesOptions) ([]LineResult, error) {
+	if opts == nil {
+		opts = &LinesOptions{}
+	}
+	maxLineSize := opts.MaxLineSize
+	if maxLineSize <= 0 {
+		maxLineSize = 1024 * 1024
+	}
+	bufSize := 4096
+	if bufSize > maxLineSize {
+		bufSize = maxLineSize
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufSize), maxLineSize)
+
+	var results []LineResult
+	for line := 1; scanner.Scan(); line++ {
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		result := LineResult{Line: line}
+		if v, err := unmarshal(bytes.NewReader(b)); err != nil {
+			result.Err = err
+		} else {
+			result.Value, result.Err = v, s.Validate(v)
+		}
+		if opts.Result != nil {
+			if !opts.Result(result) {
+				return nil, nil
+			}
+		} else if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+	return results, scanner.Err()
+}
@@ -0,0 +1,71 @@
+package jsonschema_test
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_ValidateLines(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"type": "object",
+		"properties": {"level": {"enum": ["info", "error"]}},
+		"required": ["level"]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		`{"level": "info"}`,
+		``,
+		`{"level": "debug"}`,
+		`{"level": }`,
+		"{\"level\": \"error\"}\r",
+		`{}`,
+	}, "\n")
+
+	results, err := sch.ValidateLines(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, r := range results {
+		lines = append(lines, r.Line)
+	}
+	if got, want := lines, []int{3, 4, 6}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("invalid lines: got %v, want %v", got, want)
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(results[0].Err, &ve) {
+		t.Errorf("line 3: got %T, want *ValidationError", results[0].Err)
+	}
+	if errors.As(results[1].Err, &ve) || results[1].Value != nil {
+		t.Errorf("line 4: must be json syntax error, got %v", results[1].Err)
+	}
+
+	t.Run("result", func(t *testing.T) {
+		var all []int
+		_, err := sch.ValidateLines(strings.NewReader(input), &jsonschema.LinesOptions{
+			Result: func(r jsonschema.LineResult) bool {
+				all = append(all, r.Line)
+				return r.Line < 4
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 3 || all[2] != 4 {
+			t.Fatalf("got lines %v, want [1 3 4]", all)
+		}
+	})
+
+	t.Run("maxLineSize", func(t *testing.T) {
+		_, err := sch.ValidateLines(strings.NewReader(`{"level": "info"}`), &jsonschema.LinesOptions{MaxLineSize: 8})
+		if !errors.Is(err, bufio.ErrTooLong) {
+			t.Fatalf("got %v, want %v", err, bufio.ErrTooLong)
+		}
+	})
+}
@@ -0,0 +1,399 @@
+// Package lint analyzes compiled json-schemas for smells, which are not
+// errors per specification, but are likely mistakes.
+//
+// Typical usage:
+//
+//	sch, err := jsonschema.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//	for _, issue := range lint.Lint(sch).Filter(lint.Warning) {
+//		fmt.Println(issue)
+//	}
+//
+// The following are reported:
+//   - impossible constraints, such as minLength greater than maxLength,
+//     minimum greater than maximum, or required property whose schema is false.
+//   - const value not in enum.
+//   - unreachable subschemas, such as "else" when "if" is true schema,
+//     false branches of anyOf and oneOf, or keywords like "properties"
+//     which do not apply to the types allowed by "type".
+//   - untyped schemas, which use type specific keywords like "minLength"
+//     without "type", so that values of other types are allowed.
+//   - duplicate required entries, i.e. property required by schema which
+//     is already required by its "$ref" or "allOf" subschema.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/jsonvalue"
+)
+
+// Severity tells how likely an Issue is a mistake.
+type Severity int
+
+const (
+	// Info is for issues, which are harmless, but can be cleaned up.
+	Info Severity = iota
+
+	// Warning is for issues, which are likely mistakes.
+	Warning
+
+	// Error is for issues, which make the schema or its part never match.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Issue is a smell found in schema.
+type Issue struct {
+	Severity       Severity
+	SchemaLocation string // absolute location of schema.
+	Keyword        string // keyword having issue.
+	Message        string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.SchemaLocation, i.Message)
+}
+
+// Report is the list of issues found, sorted by schema location.
+type Report []Issue
+
+// Filter returns the issues with severity min or above.
+func (r Report) Filter(min Severity) Report {
+	var filtered Report
+	for _, issue := range r {
+		if issue.Severity >= min {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// Max returns the highest severity of issues. returns -1 if r is empty.
+func (r Report) Max() Severity {
+	max := Severity(-1)
+	for _, issue := range r {
+		if issue.Severity > max {
+			max = issue.Severity
+		}
+	}
+	return max
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	for _, issue := range r {
+		b.WriteString(issue.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Lint analyzes given schemas along with all their subschemas. Each
+// schema is analyzed once, even if it is reachable more than once.
+func Lint(schemas ...*jsonschema.Schema) Report {
+	l := &linter{visited: make(map[*jsonschema.Schema]bool)}
+	for _, sch := range schemas {
+		l.walk(sch)
+	}
+	sort.SliceStable(l.report, func(i, j int) bool {
+		a, b := l.report[i], l.report[j]
+		if a.SchemaLocation != b.SchemaLocation {
+			return a.SchemaLocation < b.SchemaLocation
+		}
+		return a.Keyword < b.Keyword
+	})
+	return l.report
+}
+
+type linter struct {
+	visited map[*jsonschema.Schema]bool
+	report  Report
+	sch     *jsonschema.Schema // schema being analyzed
+}
+
+func (l *linter) add(severity Severity, keyword, format string, a ...interface{}) {
+	l.report = append(l.report, Issue{
+		Severity:       severity,
+		SchemaLocation: l.sch.Location,
+		Keyword:        keyword,
+		Message:        fmt.Sprintf(format, a...),
+	})
+}
+
+func (l *linter) walk(sch *jsonschema.Schema) {
+	if sch == nil || l.visited[sch] {
+		return
+	}
+	l.visited[sch] = true
+	l.sch = sch
+	l.checkLimits(sch)
+	l.checkConst(sch)
+	l.checkUnreachable(sch)
+	l.checkTypes(sch)
+	l.checkRequired(sch)
+	for _, child := range subschemas(sch) {
+		l.walk(child)
+	}
+}
+
+// subschemas returns the subschemas of sch.
+func subschemas(sch *jsonschema.Schema) []*jsonschema.Schema {
+	list := []*jsonschema.Schema{sch.Ref, sch.RecursiveRef, sch.DynamicRef, sch.Not, sch.If, sch.Then, sch.Else}
+	list = append(list, sch.AllOf...)
+	list = append(list, sch.AnyOf...)
+	list = append(list, sch.OneOf...)
+	for _, pname := range sortedKeys(sch.Properties) {
+		list = append(list, sch.Properties[pname])
+	}
+	list = append(list, sch.PropertyNames)
+	for _, child := range sch.PatternProperties {
+		list = append(list, child)
+	}
+	if child, ok := sch.AdditionalProperties.(*jsonschema.Schema); ok {
+		list = append(list, child)
+	}
+	for _, dep := range sch.Dependencies {
+		if child, ok := dep.(*jsonschema.Schema); ok {
+			list = append(list, child)
+		}
+	}
+	for _, pname := range sortedKeys(sch.DependentSchemas) {
+		list = append(list, sch.DependentSchemas[pname])
+	}
+	list = append(list, sch.UnevaluatedProperties)
+	switch items := sch.Items.(type) {
+	case *jsonschema.Schema:
+		list = append(list, items)
+	case []*jsonschema.Schema:
+		list = append(list, items...)
+	}
+	if child, ok := sch.AdditionalItems.(*jsonschema.Schema); ok {
+		list = append(list, child)
+	}
+	list = append(list, sch.PrefixItems...)
+	list = append(list, sch.Items2020, sch.Contains, sch.UnevaluatedItems, sch.ContentSchema)
+	return list
+}
+
+// checkLimits reports lower limits greater than upper limits.
+func (l *linter) checkLimits(sch *jsonschema.Schema) {
+	for _, limit := range []struct {
+		min, max       string
+		minVal, maxVal int
+	}{
+		{"minLength", "maxLength", sch.MinLength, sch.MaxLength},
+		{"minItems", "maxItems", sch.MinItems, sch.MaxItems},
+		{"minProperties", "maxProperties", sch.MinProperties, sch.MaxProperties},
+	} {
+		if limit.maxVal != -1 && limit.minVal > limit.maxVal {
+			l.add(Error, limit.min, "%s %d is greater than %s %d", limit.min, limit.minVal, limit.max, limit.maxVal)
+		}
+	}
+	if sch.Contains != nil && sch.MaxContains != -1 && sch.MinContains > sch.MaxContains {
+		l.add(Error, "minContains", "minContains %d is greater than maxContains %d", sch.MinContains, sch.MaxContains)
+	}
+
+	// numeric range
+	minKw, min := "minimum", sch.Minimum
+	if sch.ExclusiveMinimum != nil && (min == nil || sch.ExclusiveMinimum.Cmp(min) >= 0) {
+		minKw, min = "exclusiveMinimum", sch.ExclusiveMinimum
+	}
+	maxKw, max := "maximum", sch.Maximum
+	if sch.ExclusiveMaximum != nil && (max == nil || sch.ExclusiveMaximum.Cmp(max) <= 0) {
+		maxKw, max = "exclusiveMaximum", sch.ExclusiveMaximum
+	}
+	if min != nil && max != nil {
+		cmp := min.Cmp(max)
+		if cmp > 0 || (cmp == 0 && (minKw != "minimum" || maxKw != "maximum")) {
+			l.add(Error, minKw, "no number satisfies %s %s and %s %s", minKw, min.RatString(), maxKw, max.RatString())
+		}
+	}
+
+	if sch.MaxProperties != -1 && len(sch.Required) > sch.MaxProperties {
+		l.add(Error, "required", "%d properties are required, but maxProperties is %d", len(sch.Required), sch.MaxProperties)
+	}
+}
+
+// checkConst reports const value which is not allowed by enum.
+func (l *linter) checkConst(sch *jsonschema.Schema) {
+	if len(sch.Constant) == 0 || sch.Enum == nil {
+		return
+	}
+	for _, v := range sch.Enum {
+		if jsonvalue.Equal(sch.Constant[0], v) {
+			return
+		}
+	}
+	b, _ := json.Marshal(sch.Constant[0])
+	l.add(Error, "const", "const value %s is not in enum", b)
+}
+
+// checkUnreachable reports subschemas which are never used.
+func (l *linter) checkUnreachable(sch *jsonschema.Schema) {
+	if sch.If != nil && sch.If.Always != nil {
+		if *sch.If.Always && sch.Else != nil {
+			l.add(Warning, "else", `"else" is unreachable, since "if" is true schema`)
+		}
+		if !*sch.If.Always && sch.Then != nil {
+			l.add(Warning, "then", `"then" is unreachable, since "if" is false schema`)
+		}
+	}
+	for _, list := range []struct {
+		kw      string
+		schemas []*jsonschema.Schema
+	}{{"anyOf", sch.AnyOf}, {"oneOf", sch.OneOf}} {
+		for i, child := range list.schemas {
+			if isFalse(child) {
+				l.add(Warning, list.kw, "%s/%d is false schema, which never matches", list.kw, i)
+			}
+		}
+	}
+}
+
+// typeKeywords returns the type specific keywords used in sch,
+// keyed by the type they apply to.
+func typeKeywords(sch *jsonschema.Schema) map[string][]string {
+	kws := make(map[string][]string)
+	add := func(t, kw string, used bool) {
+		if used {
+			kws[t] = append(kws[t], kw)
+		}
+	}
+	add("object", "properties", sch.Properties != nil)
+	add("object", "patternProperties", sch.PatternProperties != nil)
+	add("object", "additionalProperties", sch.AdditionalProperties != nil)
+	add("object", "required", len(sch.Required) > 0)
+	add("object", "propertyNames", sch.PropertyNames != nil)
+	add("object", "minProperties", sch.MinProperties != -1)
+	add("object", "maxProperties", sch.MaxProperties != -1)
+	add("array", "items", sch.Items != nil || sch.Items2020 != nil)
+	add("array", "prefixItems", sch.PrefixItems != nil)
+	add("array", "contains", sch.Contains != nil)
+	add("array", "minItems", sch.MinItems != -1)
+	add("array", "maxItems", sch.MaxItems != -1)
+	add("array", "uniqueItems", sch.UniqueItems)
+	add("string", "minLength", sch.MinLength != -1)
+	add("string", "maxLength", sch.MaxLength != -1)
+	add("string", "pattern", sch.Pattern != nil)
+	add("number", "minimum", sch.Minimum != nil)
+	add("number", "maximum", sch.Maximum != nil)
+	add("number", "exclusiveMinimum", sch.ExclusiveMinimum != nil)
+	add("number", "exclusiveMaximum", sch.ExclusiveMaximum != nil)
+	add("number", "multipleOf", sch.MultipleOf != nil)
+	return kws
+}
+
+// checkTypes reports type specific keywords not applicable to "type",
+// and type specific keywords used without "type".
+func (l *linter) checkTypes(sch *jsonschema.Schema) {
+	kws := typeKeywords(sch)
+	if len(kws) == 0 {
+		return
+	}
+	if len(sch.Types) == 0 {
+		if len(sch.Constant) == 0 && sch.Enum == nil {
+			var used []string
+			for _, t := range []string{"object", "array", "string", "number"} {
+				used = append(used, kws[t]...)
+			}
+			l.add(Info, "type", "schema without type uses %s, values of other types are allowed", strings.Join(used, ", "))
+		}
+		return
+	}
+	allowed := make(map[string]bool)
+	for _, t := range sch.Types {
+		allowed[t] = true
+	}
+	if allowed["integer"] {
+		allowed["number"] = true
+	}
+	for _, t := range []string{"object", "array", "string", "number"} {
+		if allowed[t] {
+			continue
+		}
+		for _, kw := range kws[t] {
+			l.add(Warning, kw, "%q is unreachable, since type %v does not allow %s", kw, sch.Types, t)
+		}
+	}
+}
+
+// checkRequired reports required properties which are not allowed,
+// and duplicate required entries.
+func (l *linter) checkRequired(sch *jsonschema.Schema) {
+	inherited := make(map[string]string) // property => location of schema requiring it
+	for _, child := range append([]*jsonschema.Schema{sch.Ref}, sch.AllOf...) {
+		if child == nil {
+			continue
+		}
+		for _, pname := range child.Required {
+			if _, ok := inherited[pname]; !ok {
+				inherited[pname] = child.Location
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, pname := range sch.Required {
+		if seen[pname] {
+			l.add(Warning, "required", "property %q is required more than once", pname)
+			continue
+		}
+		seen[pname] = true
+		if loc, ok := inherited[pname]; ok {
+			l.add(Info, "required", "property %q is already required by %s", pname, loc)
+		}
+		if child, ok := sch.Properties[pname]; ok {
+			if isFalse(child) {
+				l.add(Error, "required", "required property %q is not allowed by its schema", pname)
+			}
+			continue
+		}
+		var matched bool
+		for pattern := range sch.PatternProperties {
+			if pattern.MatchString(pname) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		additional := sch.AdditionalProperties
+		if child, ok := additional.(*jsonschema.Schema); ok && isFalse(child) {
+			additional = false
+		}
+		if additional == false {
+			l.add(Error, "required", "required property %q is not allowed by additionalProperties", pname)
+		}
+	}
+}
+
+func isFalse(sch *jsonschema.Schema) bool {
+	return sch.Always != nil && !*sch.Always
+}
+
+func sortedKeys(m map[string]*jsonschema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package lint_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/lint"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{
+			name:   "clean",
+			schema: `{"type": "object", "properties": {"a": {"type": "string", "minLength": 1}}, "required": ["a"]}`,
+		},
+		{
+			name:   "minLength greater than maxLength",
+			schema: `{"type": "string", "minLength": 5, "maxLength": 3}`,
+			want:   []string{"error: https://example.com/schema.json#: minLength 5 is greater than maxLength 3"},
+		},
+		{
+			name:   "empty numeric range",
+			schema: `{"type": "number", "exclusiveMinimum": 5, "maximum": 5}`,
+			want:   []string{"error: https://example.com/schema.json#: no number satisfies exclusiveMinimum 5 and maximum 5"},
+		},
+		{
+			name:   "equal minimum and maximum",
+			schema: `{"type": "number", "minimum": 5, "maximum": 5}`,
+		},
+		{
+			name:   "const not in enum",
+			schema: `{"const": 1, "enum": [1.5, "a"]}`,
+			want:   []string{"error: https://example.com/schema.json#: const value 1 is not in enum"},
+		},
+		{
+			name:   "const in enum",
+			schema: `{"const": 1, "enum": [1.0, "a"]}`,
+		},
+		{
+			name:   "unreachable else",
+			schema: `{"if": true, "then": {"type": "string"}, "else": {"type": "number"}}`,
+			want:   []string{`warning: https://example.com/schema.json#: "else" is unreachable, since "if" is true schema`},
+		},
+		{
+			name:   "false anyOf branch",
+			schema: `{"anyOf": [{"type": "string"}, false]}`,
+			want:   []string{"warning: https://example.com/schema.json#: anyOf/1 is false schema, which never matches"},
+		},
+		{
+			name:   "keyword not applicable to type",
+			schema: `{"type": "string", "properties": {"a": {"type": "integer", "maximum": 3}}}`,
+			want:   []string{`warning: https://example.com/schema.json#: "properties" is unreachable, since type [string] does not allow object`},
+		},
+		{
+			name:   "untyped",
+			schema: `{"properties": {"a": {"minLength": 1}}}`,
+			want: []string{
+				"info: https://example.com/schema.json#: schema without type uses properties, values of other types are allowed",
+				"info: https://example.com/schema.json#/properties/a: schema without type uses minLength, values of other types are allowed",
+			},
+		},
+		{
+			name: "required",
+			schema: `{
+				"type": "object",
+				"$ref": "#/$defs/base",
+				"properties": {"a": false, "b": {}},
+				"additionalProperties": false,
+				"required": ["a", "b", "c"],
+				"maxProperties": 2,
+				"$defs": {"base": {"type": "object", "required": ["b"]}}
+			}`,
+			want: []string{
+				"error: https://example.com/schema.json#: 3 properties are required, but maxProperties is 2",
+				`error: https://example.com/schema.json#: required property "a" is not allowed by its schema`,
+				`info: https://example.com/schema.json#: property "b" is already required by https://example.com/schema.json#/$defs/base`,
+				`error: https://example.com/schema.json#: required property "c" is not allowed by additionalProperties`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sch, err := jsonschema.CompileString("https://example.com/schema.json", test.schema)
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			var got []string
+			for _, issue := range lint.Lint(sch) {
+				got = append(got, issue.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	sch, err := jsonschema.CompileString("https://example.com/schema.json", `{
+		"properties": {"a": {"type": "string", "minLength": 5, "maxLength": 3}}
+	}`)
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	report := lint.Lint(sch)
+	if len(report) != 2 {
+		t.Fatalf("got %d issues, want 2:\n%s", len(report), report)
+	}
+	if got := report.Max(); got != lint.Error {
+		t.Errorf("Max: got %s, want %s", got, lint.Error)
+	}
+	errs := report.Filter(lint.Warning)
+	if len(errs) != 1 || errs[0].Keyword != "minLength" || errs[0].SchemaLocation != "https://example.com/schema.json#/properties/a" {
+		t.Errorf("Filter: got %v", errs)
+	}
+	if got := lint.Report(nil).Max(); got != -1 {
+		t.Errorf("Max of empty report: got %d, want -1", got)
+	}
+}
@@ -1,36 +1,24 @@
 package jsonschema
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
-	"os"
-	"path/filepath"
-	"runtime"
 	"strings"
 )
 
-func loadFileURL(s string) (io.ReadCloser, error) {
-	u, err := url.Parse(s)
-	if err != nil {
-		return nil, err
-	}
-	f := u.Path
-	if runtime.GOOS == "windows" {
-		f = strings.TrimPrefix(f, "/")
-		f = filepath.FromSlash(f)
-	}
-	return os.Open(f)
-}
-
 // Loaders is a registry of functions, which know how to load
 // absolute url of specific schema.
 //
 // New loaders can be registered by adding to this map. Key is schema,
 // value is function that knows how to load url of that schema
-var Loaders = map[string]func(url string) (io.ReadCloser, error){
-	"file": loadFileURL,
-}
+//
+// Loader for "file" scheme is registered by default, except for js
+// platform, such as GOOS=js GOARCH=wasm, which has no file system.
+var Loaders = map[string]func(url string) (io.ReadCloser, error){}
 
 // LoaderNotFoundError is the error type returned by Load function.
 // It tells that no Loader is registered for that URL Scheme.
@@ -58,3 +46,57 @@ var LoadURL = func(s string) (io.ReadCloser, error) {
 	}
 	return loader(s)
 }
+
+// load loads document at given absolute url, using fs added by
+// AddRemoteFS, before falling back to c.LoadURLContext or c.LoadURL.
+// c.mu must be held. It is released while loading, so that other
+// compilations are not blocked.
+func (c *Compiler) load(ctx context.Context, s string) (io.ReadCloser, error) {
+	u, err := url.Parse(s)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		name := strings.TrimSuffix(u.Host+u.Path, "/")
+		if fs.ValidPath(name) {
+			remotes := c.remotes
+			c.mu.Unlock()
+			f, err := openRemote(remotes, name)
+			c.mu.Lock()
+			if f != nil || err != nil {
+				return f, err
+			}
+		}
+		if c.Offline {
+			return nil, fmt.Errorf("jsonschema: remote url %s is not registered in offline mode", s)
+		}
+	}
+	if max := c.Limits.MaxFetches; max > 0 && c.fetches >= max {
+		return nil, &LimitExceededError{Limit: "MaxFetches", Max: max, URL: s}
+	}
+	c.fetches++
+	loadURL := func(ctx context.Context, s string) (io.ReadCloser, error) {
+		if c.LoadURL != nil {
+			return c.LoadURL(s)
+		}
+		return LoadURL(s)
+	}
+	if c.LoadURLContext != nil {
+		loadURL = c.LoadURLContext
+	}
+	c.mu.Unlock()
+	defer c.mu.Lock()
+	return loadURL(ctx, s)
+}
+
+// openRemote opens the file with given name, in the first of remotes
+// having it. returns nil file, if none has it.
+func openRemote(remotes []fs.FS, name string) (fs.File, error) {
+	for _, fsys := range remotes {
+		f, err := fsys.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
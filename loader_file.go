@@ -0,0 +1,34 @@
+//go:build !js
+
+package jsonschema
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	Loaders["file"] = loadFileURL
+}
+
+func loadFileURL(s string) (io.ReadCloser, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	f := u.Path
+	if runtime.GOOS == "windows" {
+		if u.Host != "" && u.Host != "localhost" {
+			// unc path: file://host/share/a.json
+			f = `\\` + u.Host + filepath.FromSlash(f)
+		} else {
+			f = strings.TrimPrefix(f, "/")
+			f = filepath.FromSlash(f)
+		}
+	}
+	return os.Open(f)
+}
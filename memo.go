@@ -0,0 +1,233 @@
+package jsonschema
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// memoKey identifies validation of an instance fragment against a subschema,
+// within single validation. maps and slices are identified by their address,
+// since the instance is not modified during validation.
+type memoKey struct {
+	s    *Schema
+	kind byte
+	ptr  uintptr
+	len  int
+	v    interface{} // scalar value
+}
+
+// newMemoKey returns memoKey for validation of v against s. returns false
+// if v cannot be used as key.
+func newMemoKey(s *Schema, v interface{}) (memoKey, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return memoKey{s: s, kind: 'o', ptr: reflect.ValueOf(v).Pointer(), len: len(v)}, true
+	case []interface{}:
+		return memoKey{s: s, kind: 'a', ptr: reflect.ValueOf(v).Pointer(), len: len(v)}, true
+	}
+	if v != nil && !reflect.TypeOf(v).Comparable() {
+		return memoKey{}, false
+	}
+	return memoKey{s: s, v: v}, true
+}
+
+// memoizable tells whether results of validation against s, can be reused
+// for the same instance fragment. It is not so, if any schema reachable
+// from s depends on dynamic scope or instance root, i.e. uses $recursiveRef,
+// $dynamicRef, $data or user defined extensions.
+func (s *Schema) memoizable() bool {
+	switch atomic.LoadInt32(&s.memo) {
+	case 1:
+		return true
+	case 2:
+		return false
+	}
+	visited := make(map[*Schema]bool)
+	var dynamic func(sch *Schema) bool
+	dynamic = func(sch *Schema) bool {
+		if visited[sch] {
+			return false
+		}
+		visited[sch] = true
+		if sch.RecursiveRef != nil || sch.DynamicRef != nil || len(sch.data) > 0 || len(sch.Extensions) > 0 {
+			return true
+		}
+		for _, sub := range sch.Subschemas() {
+			if dynamic(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	memo := int32(1)
+	if dynamic(s) {
+		memo = 2
+	}
+	atomic.StoreInt32(&s.memo, memo)
+	return memo == 1
+}
+
+// ResultCache caches whether instances are valid, across validations,
+// keyed by schema and canonical encoding of the instance. This avoids validating
+// identical payloads, such as retried requests, again. Use it with
+// ValidateOptions.Cache.
+//
+// Only validity is cached. Invalid instances are validated again, so that
+// each call gets its own *ValidationError.
+//
+// A ResultCache is safe for concurrent use by multiple goroutines.
+type ResultCache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[cacheKey]*list.Element
+	hits    int
+	misses  int
+}
+
+type cacheKey struct {
+	s    *Schema
+	mode Mode
+	doc  string // canonical encoding of instance. see canonical
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	valid bool
+}
+
+// NewResultCache returns ResultCache holding at most size results, evicting
+// least recently used ones. size <= 0 means 1024.
+func NewResultCache(size int) *ResultCache {
+	if size <= 0 {
+		size = 1024
+	}
+	return &ResultCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+// Len returns the number of results in the cache.
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the number of lookups, that found and did not find
+// the result in the cache.
+func (c *ResultCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// key returns cache key for validation of v against s in given mode.
+// The key has the canonical encoding of v, rather than its hash, so that
+// different instances never share a result. returns InvalidJSONTypeError
+// if v is not json value.
+func (c *ResultCache) key(s *Schema, mode Mode, v interface{}) (cacheKey, error) {
+	var sb strings.Builder
+	if err := canonical(v, &sb); err != nil {
+		return cacheKey{}, err
+	}
+	return cacheKey{s: s, mode: mode, doc: sb.String()}, nil
+}
+
+// canonical writes encoding of json value v to sb, such that two values
+// have same encoding only if they are equal as per json-schema. strings,
+// arrays and objects are length prefixed, and numbers are written as
+// exact rationals.
+func canonical(v interface{}, sb *strings.Builder) error {
+	switch v := v.(type) {
+	case nil:
+		sb.WriteByte('n')
+	case bool:
+		if v {
+			sb.WriteByte('t')
+		} else {
+			sb.WriteByte('f')
+		}
+	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64, *big.Int, *big.Float, *big.Rat:
+		if r := ratValue(v); r != nil {
+			sb.WriteByte('d')
+			sb.WriteString(r.RatString())
+		} else {
+			// exponent too large for big.Rat. equal numbers in different
+			// notations just do not share a result
+			sb.WriteByte('x')
+			sb.WriteString(fmt.Sprint(v))
+		}
+		sb.WriteByte(';')
+	case string:
+		sb.WriteByte('s')
+		writeString(v, sb)
+	case []interface{}:
+		sb.WriteByte('a')
+		sb.WriteString(strconv.Itoa(len(v)))
+		sb.WriteByte(':')
+		for _, item := range v {
+			if err := canonical(item, sb); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		sb.WriteByte('o')
+		sb.WriteString(strconv.Itoa(len(v)))
+		sb.WriteByte(':')
+		for _, prop := range sortedKeys(v) {
+			writeString(prop, sb)
+			if err := canonical(v[prop], sb); err != nil {
+				return err
+			}
+		}
+	default:
+		return InvalidJSONTypeError(fmt.Sprintf("%T", v))
+	}
+	return nil
+}
+
+// writeString writes length prefixed s to sb.
+func writeString(s string, sb *strings.Builder) {
+	sb.WriteString(strconv.Itoa(len(s)))
+	sb.WriteByte(':')
+	sb.WriteString(s)
+}
+
+func (c *ResultCache) get(key cacheKey) (valid, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return false, false
+	}
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).valid, true
+}
+
+func (c *ResultCache) put(key cacheKey, valid bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).valid = valid
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key, valid})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,139 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// compileCounted compiles schema, where format "counted" counts its calls.
+func compileCounted(t *testing.T, schema string) (*jsonschema.Schema, *int) {
+	t.Helper()
+	var calls int
+	c := jsonschema.NewCompiler()
+	c.AssertFormat = true
+	c.Formats = map[string]func(interface{}) bool{
+		"counted": func(v interface{}) bool {
+			calls++
+			s, ok := v.(string)
+			return !ok || s != "bad"
+		},
+	}
+	if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	return sch, &calls
+}
+
+func TestValidateOptions_Memoize(t *testing.T) {
+	sch, calls := compileCounted(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"allOf": [{"$ref": "#/$defs/item"}, {"$ref": "#/$defs/item"}],
+		"anyOf": [{"$ref": "#/$defs/item"}, {"type": "null"}],
+		"properties": {"items": {"items": {"$ref": "#/$defs/item"}}},
+		"unevaluatedProperties": false,
+		"$defs": {
+			"item": {"properties": {"name": {"format": "counted"}, "items": true}}
+		}
+	}`)
+	doc := decodeString(t, `{"name": "x", "items": [{"name": "y"}, {"name": "y"}]}`)
+	if err := sch.ValidateWithOptions(doc, jsonschema.ValidateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	without := *calls
+
+	*calls = 0
+	if err := sch.ValidateWithOptions(doc, jsonschema.ValidateOptions{Memoize: true}); err != nil {
+		t.Fatal(err)
+	}
+	// root once, each item once; "y" is a string, memoized by value
+	if *calls >= without || *calls != 2 {
+		t.Fatalf("format called %d times with memoization, %d times without", *calls, without)
+	}
+
+	// results of failing subschemas are not memoized
+	doc = decodeString(t, `{"name": "bad", "other": 1}`)
+	err := sch.ValidateWithOptions(doc, jsonschema.ValidateOptions{Memoize: true})
+	if err == nil {
+		t.Fatal("validation must fail")
+	}
+	if got := strings.Count(err.(*jsonschema.ValidationError).GoString(), "is not valid 'counted'"); got != 3 {
+		t.Fatalf("got %d format errors, want 3:\n%#v", got, err)
+	}
+}
+
+func TestValidateOptions_Cache(t *testing.T) {
+	sch, calls := compileCounted(t, `{"properties": {"name": {"format": "counted"}}}`)
+	cache := jsonschema.NewResultCache(2)
+	opts := jsonschema.ValidateOptions{Cache: cache}
+	for i := 0; i < 3; i++ {
+		// fresh instance with same content
+		if err := sch.ValidateWithOptions(decodeString(t, `{"name": "x"}`), opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("format called %d times, want 1", *calls)
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Fatalf("hits %d misses %d", hits, misses)
+	}
+
+	// invalid instances are validated again, for fresh error
+	*calls = 0
+	for i := 0; i < 2; i++ {
+		if err := sch.ValidateWithOptions(decodeString(t, `{"name": "bad"}`), opts); err == nil {
+			t.Fatal("validation must fail")
+		}
+	}
+	if *calls != 2 {
+		t.Fatalf("format called %d times, want 2", *calls)
+	}
+
+	// least recently used is evicted
+	if err := sch.ValidateWithOptions(decodeString(t, `{"name": "z"}`), opts); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("len: got %d, want 2", cache.Len())
+	}
+	*calls = 0
+	if err := sch.ValidateWithOptions(decodeString(t, `{"name": "x"}`), opts); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 1 {
+		t.Fatal("evicted result must be validated again")
+	}
+
+	// non json values are reported
+	if err := sch.ValidateWithOptions(map[string]interface{}{"name": struct{}{}}, opts); err == nil {
+		t.Fatal("InvalidJSONTypeError expected")
+	}
+}
+
+func TestResultCache_collisions(t *testing.T) {
+	tests := []struct {
+		schema         string
+		valid, invalid interface{}
+	}{
+		{`{"maxItems": 1}`, []interface{}{"a\x03b"}, []interface{}{"a", "b"}},
+		{`{"maximum": 9007199254740992}`, json.Number("9007199254740992"), json.Number("9007199254740993")},
+		{`{"required": ["a"]}`, map[string]interface{}{"a": "b"}, map[string]interface{}{"a\x03b": nil}},
+	}
+	for _, test := range tests {
+		sch := jsonschema.MustCompileString("schema.json", test.schema)
+		opts := jsonschema.ValidateOptions{Cache: jsonschema.NewResultCache(0)}
+		if err := sch.ValidateWithOptions(test.valid, opts); err != nil {
+			t.Fatalf("%s: %v must be valid: %v", test.schema, test.valid, err)
+		}
+		if err := sch.ValidateWithOptions(test.invalid, opts); err == nil {
+			t.Errorf("%s: %v must be invalid", test.schema, test.invalid)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Package msgpack decodes MessagePack documents into values that can be
+// validated using jsonschema.Schema.Validate.
+//
+// The package is typically only imported for the side effect of
+// registering its decoder for media types "application/msgpack",
+// "application/x-msgpack" and "application/vnd.msgpack":
+//
+//	import _ "github.com/santhosh-tekuri/jsonschema/v5/msgpack"
+//
+//	err := sch.ValidateBytes(payload, "application/msgpack")
+//
+// MessagePack values map onto json values as follows:
+//
+//   - integers are decoded as int64, or uint64 if they do not fit in int64
+//   - float 32 and float 64 are decoded as json.Number holding the shortest
+//     decimal that round-trips in that precision. so float32 0.1 is
+//     validated as 0.1, not as 0.10000000149011612. floats with integral
+//     value, such as 1.0, are integers as per json-schema
+//   - NaN and infinite floats are decoded as strings "NaN", "Infinity" and
+//     "-Infinity"
+//   - bin is decoded as base64 encoded string, so that it can be validated
+//     using "contentEncoding": "base64"
+//   - timestamp extension is decoded as RFC 3339 string in UTC, so that it
+//     can be validated using "format": "date-time"
+//   - map keys must be strings or integers. integer keys are decoded as
+//     their decimal string
+//   - other extension types are reported as error
+package msgpack
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/bindec"
+)
+
+func init() {
+	for _, mt := range []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"} {
+		jsonschema.InstanceDecoders[mt] = Decode
+	}
+}
+
+// MaxDepth is the maximum nesting of arrays and maps in a document.
+var MaxDepth = 1000
+
+// Decode decodes single MessagePack value from r. returns error if r has
+// more data after the value.
+func Decode(r io.Reader) (interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(b)
+}
+
+// Unmarshal decodes single MessagePack value in b. returns error if b has
+// more data after the value.
+func Unmarshal(b []byte) (interface{}, error) {
+	d := &decoder{b: b}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("msgpack: %v at offset %d", err, d.off)
+	}
+	if d.off != len(b) {
+		return nil, fmt.Errorf("msgpack: unexpected data after top-level value at offset %d", d.off)
+	}
+	return v, nil
+}
+
+var errUnexpectedEOF = errors.New("unexpected end of data")
+
+type decoder struct {
+	b   []byte
+	off int
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)-d.off) {
+		return nil, errUnexpectedEOF
+	}
+	b := d.b[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// uint reads n bytes big-endian unsigned integer.
+func (d *decoder) uint(n uint64) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("exceeded max depth %d", MaxDepth)
+	}
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c <= 0x8f:
+		return d.decodeMap(depth, uint64(c&0x0f))
+	case c <= 0x9f:
+		return d.decodeArray(depth, uint64(c&0x0f))
+	case c <= 0xbf:
+		return d.decodeStr(uint64(c & 0x1f))
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	}
+
+	// sizeOf returns size of length field for bin, str, ext, array and map types
+	sizeOf := func(c, first byte) uint64 {
+		return 1 << (c - first)
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(sizeOf(c, 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(sizeOf(c, 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		u, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return bindec.Float(float64(math.Float32frombits(uint32(u))), 32), nil
+	case 0xcb:
+		u, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return bindec.Float(math.Float64frombits(u), 64), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(sizeOf(c, 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := sizeOf(c, 0xd0)
+		u, err := d.uint(n)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend
+		shift := 64 - 8*n
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(sizeOf(c, 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(sizeOf(c, 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeStr(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 * sizeOf(c, 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(depth, n)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 * sizeOf(c, 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(depth, n)
+	}
+	return nil, fmt.Errorf("invalid type byte 0x%02x", c)
+}
+
+func (d *decoder) decodeStr(n uint64) (interface{}, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, errors.New("invalid utf-8 in str")
+	}
+	return string(b), nil
+}
+
+func (d *decoder) decodeArray(depth int, n uint64) (interface{}, error) {
+	arr := make([]interface{}, 0, bindec.Capacity(n))
+	for i := uint64(0); i < n; i++ {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, item)
+	}
+	return arr, nil
+}
+
+func (d *decoder) decodeMap(depth int, n uint64) (interface{}, error) {
+	m := make(map[string]interface{}, bindec.Capacity(n))
+	for i := uint64(0); i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		var key string
+		switch k := k.(type) {
+		case string:
+			key = k
+		case int64:
+			key = strconv.FormatInt(k, 10)
+		case uint64:
+			key = strconv.FormatUint(k, 10)
+		default:
+			return nil, fmt.Errorf("unsupported map key %v", k)
+		}
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("duplicate map key %q", key)
+		}
+		if m[key], err = d.decode(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// decodeExt decodes extension type with n bytes of data.
+func (d *decoder) decodeExt(n uint64) (interface{}, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	typ := int8(b[0])
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != -1 {
+		return nil, fmt.Errorf("unsupported extension type %d", typ)
+	}
+
+	// timestamp extension
+	var sec int64
+	var nsec uint32
+	switch len(data) {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(data))
+	case 8:
+		u := binary.BigEndian.Uint64(data)
+		nsec, sec = uint32(u>>34), int64(u&(1<<34-1))
+	case 12:
+		nsec, sec = binary.BigEndian.Uint32(data), int64(binary.BigEndian.Uint64(data[4:]))
+	default:
+		return nil, fmt.Errorf("invalid timestamp of %d bytes", len(data))
+	}
+	if nsec > 999999999 {
+		return nil, fmt.Errorf("invalid nanoseconds %d in timestamp", nsec)
+	}
+	return time.Unix(sec, int64(nsec)).UTC().Format(time.RFC3339Nano), nil
+}
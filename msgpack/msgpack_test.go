@@ -0,0 +1,113 @@
+package msgpack_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/msgpack"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string // empty means error
+	}{
+		{"00", `0`},
+		{"7f", `127`},
+		{"ff", `-1`},
+		{"e0", `-32`},
+		{"cc80", `128`},
+		{"cdffff", `65535`},
+		{"cfffffffffffffffff", `18446744073709551615`},
+		{"d080", `-128`},
+		{"d1ff00", `-256`},
+		{"d3ffffffffffffffff", `-1`},
+		{"ca3dcccccd", `0.1`},
+		{"cb3ff199999999999a", `1.1`},
+		{"ca7fc00000", `"NaN"`},
+		{"cbfff0000000000000", `"-Infinity"`},
+		{"c0", `null`},
+		{"c2", `false`},
+		{"c3", `true`},
+		{"a3616263", `"abc"`},
+		{"d903616263", `"abc"`},
+		{"c40401020304", `"AQIDBA=="`},
+		{"93010203", `[1,2,3]`},
+		{"dc0002c0c3", `[null,true]`},
+		{"82a16101a162920203", `{"a":1,"b":[2,3]}`},
+		{"810102", `{"1":2}`},
+		{"d6ff514b67b0", `"2013-03-21T20:04:00Z"`},
+		{"d7ff00000004514b67b0", `"2013-03-21T20:04:00.000000001Z"`},
+
+		{"", ``},
+		{"c1", ``},
+		{"cd01", ``},
+		{"920102ff", ``},
+		{"0000", ``},
+		{"82a16101a16102", ``},
+		{"81c301", ``},
+		{"a2c328", ``},
+		{"d40101", ``},
+	}
+	for _, test := range tests {
+		b, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := msgpack.Unmarshal(b)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s: error expected", test.hex)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.hex, got, test.want)
+		}
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "maximum": 18446744073709551615},
+			"temp": {"type": "number", "multipleOf": 0.1},
+			"at": {"type": "string", "format": "date-time"}
+		}
+	}`)
+	tests := []struct {
+		hex   string
+		valid bool
+	}{
+		// {"id": 18446744073709551615, "temp": float32(0.1), "at": timestamp}
+		{"83a26964cfffffffffffffffffa474656d70ca3dcccccda26174d6ff514b67b0", true},
+		// {"id": float64(1.0)}
+		{"81a26964cb3ff0000000000000", true},
+		// {"id": float64(1.5)}
+		{"81a26964cb3ff8000000000000", false},
+	}
+	for _, test := range tests {
+		b, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sch.ValidateBytes(b, "application/x-msgpack")
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: must be invalid", test.hex)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// DecodeJSON decodes single json value from r, to be validated using
+// Schema.Validate.
+//
+// Numbers are decoded as json.Number, so that integers beyond float64
+// precision, such as 64-bit ids, are validated without loss of
+// precision. returns error if r has more data after the json value.
+func DecodeJSON(r io.Reader) (interface{}, error) {
+	return unmarshal(r)
+}
+
+// ratValue returns the number v as *big.Rat.
+//
+// v must be one of the number types, reported as "number" by jsonType.
+// returns nil if v is json.Number not supported by isRatSupported.
+func ratValue(v interface{}) *big.Rat {
+	switch v := v.(type) {
+	case *big.Rat:
+		return v
+	case *big.Int:
+		return new(big.Rat).SetInt(v)
+	case *big.Float:
+		r, _ := v.Rat(nil)
+		return r
+	}
+	r, _ := new(big.Rat).SetString(fmt.Sprint(v))
+	return r
+}
+
+// isRatSupported tells whether json number n can be converted to *big.Rat.
+// math/big rejects numbers with excessively large exponents, such as 1e-9999999,
+// to avoid excessive memory usage.
+func isRatSupported(n json.Number) bool {
+	if len(n) < 1000 && !strings.ContainsAny(string(n), "eE") {
+		return true
+	}
+	_, ok := new(big.Rat).SetString(string(n))
+	return ok
+}
@@ -0,0 +1,101 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestOpenAPI30(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.OpenAPI30
+	c.ExtractAnnotations = true
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "nullable": true, "example": "john"},
+			"age": {"type": "integer", "minimum": 0, "exclusiveMinimum": true},
+			"id": {"type": "string", "readOnly": true, "deprecated": true},
+			"pet": {
+				"oneOf": [{"$ref": "#/definitions/Cat"}, {"$ref": "#/definitions/Dog"}],
+				"discriminator": {"propertyName": "kind"}
+			}
+		},
+		"definitions": {
+			"Cat": {"properties": {"lives": {"type": "integer"}}},
+			"Dog": {"properties": {"bark": {"type": "boolean"}}}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if sch.Draft.String() != "OpenAPI30" {
+		t.Errorf("draft: got %s, want OpenAPI30", sch.Draft)
+	}
+
+	name := sch.Properties["name"]
+	if len(name.Examples) != 1 || name.Examples[0] != "john" {
+		t.Errorf("examples: got %v, want [john]", name.Examples)
+	}
+	if id := sch.Properties["id"]; !id.ReadOnly || !id.Deprecated {
+		t.Errorf("readOnly and deprecated must be extracted")
+	}
+
+	tests := []struct {
+		name  string
+		doc   string
+		valid bool
+	}{
+		{"nullable", `{"name": null}`, true},
+		{"nullable type", `{"name": 1}`, false},
+		{"exclusiveMinimum", `{"age": 0}`, false},
+		{"minimum", `{"age": 1}`, true},
+		{"discriminator", `{"pet": {"kind": "Dog", "bark": true}}`, true},
+		{"discriminator branch", `{"pet": {"kind": "Cat", "bark": true, "lives": "nine"}}`, false},
+		{"discriminator value", `{"pet": {"kind": "Fish"}}`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sch.Validate(decodeString(t, test.doc))
+			if test.valid && err != nil {
+				t.Fatalf("%#v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("validation must fail")
+			}
+		})
+	}
+}
+
+func TestOpenAPI31(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"$schema": "https://spec.openapis.org/oas/3.1/dialect/base",
+		"type": ["string", "null"],
+		"example": "john",
+		"examples": ["jane"]
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if sch.Draft != jsonschema.OpenAPI31 {
+		t.Errorf("draft: got %s, want OpenAPI31", sch.Draft)
+	}
+	if len(sch.Examples) != 2 {
+		t.Errorf("examples: got %v, want [jane john]", sch.Examples)
+	}
+	if err := sch.Validate(nil); err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if err := sch.Validate(1); err == nil {
+		t.Fatal("validation must fail")
+	}
+}
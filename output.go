@@ -1,5 +1,11 @@
 package jsonschema
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 // Flag is output format with simple boolean property valid.
 type Flag struct {
 	Valid bool `json:"valid"`
@@ -75,3 +81,344 @@ func (ve *ValidationError) DetailedOutput() Detailed {
 		Errors:                  errors,
 	}
 }
+
+// Verbose ---
+
+// Verbose is output format based on structure of schema, with an output
+// unit for every schema evaluated, whether it is valid or not.
+//
+// A valid unit has the units of its subschemas and its annotations in
+// Annotations. An invalid unit has the units of its subschemas and its
+// failed keywords in Errors; annotations of invalid units are dropped.
+// see Schema.ValidateWithOutput.
+type Verbose struct {
+	Valid                   bool        `json:"valid"`
+	KeywordLocation         string      `json:"keywordLocation"`
+	AbsoluteKeywordLocation string      `json:"absoluteKeywordLocation"`
+	InstanceLocation        string      `json:"instanceLocation"`
+	Error                   string      `json:"error,omitempty"`
+	Annotation              interface{} `json:"annotation,omitempty"` // value of annotation keyword
+	Errors                  []Verbose   `json:"errors,omitempty"`
+	Annotations             []Verbose   `json:"annotations,omitempty"`
+}
+
+// verboseUnit is Verbose being built, along with units of subschemas
+// evaluated so far.
+type verboseUnit struct {
+	Verbose
+	units []Verbose
+}
+
+// verboseHook is the Hook that builds Verbose output.
+type verboseHook struct {
+	stack []*verboseUnit
+	root  Verbose
+}
+
+func (h *verboseHook) OnKeywordEnter(e *HookEvent) {
+	h.stack = append(h.stack, &verboseUnit{Verbose: Verbose{
+		KeywordLocation:         e.KeywordLocation,
+		AbsoluteKeywordLocation: e.AbsoluteKeywordLocation,
+		InstanceLocation:        e.InstanceLocation,
+	}})
+}
+
+func (h *verboseHook) OnKeywordExit(e *HookEvent, err error) {
+	u := h.stack[len(h.stack)-1]
+	h.stack = h.stack[:len(h.stack)-1]
+	if err == nil {
+		u.Valid = true
+		u.Annotations = u.units
+		e.Schema.eachAnnotation(func(keyword string, value interface{}) {
+			u.Annotations = append(u.Annotations, Verbose{
+				Valid:                   true,
+				KeywordLocation:         e.KeywordLocation + "/" + escape(keyword),
+				AbsoluteKeywordLocation: joinPtr(e.AbsoluteKeywordLocation, escape(keyword)),
+				InstanceLocation:        e.InstanceLocation,
+				Annotation:              value,
+			})
+		})
+	} else {
+		u.Errors = u.units
+		if ve, ok := err.(*ValidationError); ok {
+			u.addKeywordErrors(ve)
+		}
+	}
+	if len(h.stack) == 0 {
+		h.root = u.Verbose
+	} else {
+		parent := h.stack[len(h.stack)-1]
+		parent.units = append(parent.units, u.Verbose)
+	}
+}
+
+// addKeywordErrors adds units for the keywords of u, that failed with ve.
+// keywords whose failure is due to failed subschemas, which already have
+// their units, are not added.
+func (u *verboseUnit) addKeywordErrors(ve *ValidationError) {
+	causes := []*ValidationError{ve}
+	if ve.KeywordLocation == u.KeywordLocation {
+		if len(ve.Causes) == 0 {
+			u.Error = ve.Message // false schema
+			return
+		}
+		causes = ve.Causes
+	}
+	for _, c := range causes {
+		if u.hasUnit(c.KeywordLocation) {
+			continue
+		}
+		u.Errors = append(u.Errors, Verbose{
+			KeywordLocation:         c.KeywordLocation,
+			AbsoluteKeywordLocation: c.AbsoluteKeywordLocation,
+			InstanceLocation:        c.InstanceLocation,
+			Error:                   c.Message,
+		})
+	}
+}
+
+// hasUnit tells whether kloc is within the unit of some subschema of u.
+func (u *verboseUnit) hasUnit(kloc string) bool {
+	for _, sub := range u.units {
+		if kloc == sub.KeywordLocation || strings.HasPrefix(kloc, sub.KeywordLocation+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Output returns output in given format. The returned value can be
+// marshalled to json.
+//
+// format must be one of "flag", "basic" or "detailed". "verbose" format
+// is not supported, since it needs the schemas that passed validation,
+// which ValidationError does not have; use Schema.ValidateWithOutput.
+func (ve *ValidationError) Output(format string) (interface{}, error) {
+	switch format {
+	case "flag":
+		return ve.FlagOutput(), nil
+	case "basic":
+		return ve.BasicOutput(), nil
+	case "detailed":
+		return ve.DetailedOutput(), nil
+	case "verbose":
+		return nil, fmt.Errorf("jsonschema: output format %q is not supported by ValidationError, use Schema.ValidateWithOutput", format)
+	}
+	return nil, fmt.Errorf("jsonschema: unsupported output format %q", format)
+}
+
+// ValidateWithOutput is like ValidateWithOptions, but returns the result
+// in given output format, whether v is valid or not. The returned value
+// can be marshalled to json.
+//
+// format must be one of "flag", "basic", "detailed" or "verbose". For
+// "verbose", every schema evaluated is reported, along with annotations
+// of the valid ones, if Compiler.ExtractAnnotations is true. opts.Memoize
+// and opts.Cache are ignored for "verbose", since they skip evaluation.
+//
+// returns error only if v cannot be validated, such as InvalidJSONTypeError.
+func (s *Schema) ValidateWithOutput(v interface{}, format string, opts ValidateOptions) (interface{}, error) {
+	var h *verboseHook
+	var hook Hook
+	switch format {
+	case "flag", "basic", "detailed":
+	case "verbose":
+		h = &verboseHook{}
+		hook = h
+		opts.Memoize, opts.Cache = false, nil
+	default:
+		return nil, fmt.Errorf("jsonschema: unsupported output format %q", format)
+	}
+	err := s.validateWithOptions(v, opts, hook)
+	ve, ok := err.(*ValidationError)
+	if err != nil && !ok {
+		return nil, err
+	}
+	switch format {
+	case "verbose":
+		return h.root, nil
+	case "flag":
+		if ok {
+			return ve.FlagOutput(), nil
+		}
+		return Flag{Valid: true}, nil
+	case "basic":
+		if ok {
+			return ve.BasicOutput(), nil
+		}
+		return Basic{Valid: true, Errors: []BasicError{}}, nil
+	}
+	if ok {
+		return ve.DetailedOutput(), nil
+	}
+	return Detailed{Valid: true, AbsoluteKeywordLocation: s.Location}, nil
+}
+
+// JSON ---
+
+// errorJSON is the json representation of ValidationError.
+type errorJSON struct {
+	Message                 string             `json:"message"`
+	InstanceLocation        string             `json:"instanceLocation"`
+	KeywordLocation         string             `json:"keywordLocation"`
+	AbsoluteKeywordLocation string             `json:"absoluteKeywordLocation"`
+	Causes                  []*ValidationError `json:"causes,omitempty"`
+}
+
+// MarshalJSON marshals ve as json object with fields message,
+// instanceLocation, keywordLocation, absoluteKeywordLocation and causes.
+// causes is omitted if empty.
+//
+// Use Leaves to marshal the leaf errors as flat list instead.
+func (ve *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errorJSON{
+		Message:                 ve.Message,
+		InstanceLocation:        ve.InstanceLocation,
+		KeywordLocation:         ve.KeywordLocation,
+		AbsoluteKeywordLocation: ve.AbsoluteKeywordLocation,
+		Causes:                  ve.Causes,
+	})
+}
+
+// Leaves returns the leaf errors in the cause tree of ve, in depth-first
+// order. The returned errors have no causes.
+func (ve *ValidationError) Leaves() []*ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*ValidationError{ve}
+	}
+	var leaves []*ValidationError
+	for _, c := range ve.Causes {
+		leaves = append(leaves, c.Leaves()...)
+	}
+	return leaves
+}
+
+// Flat ---
+
+// Severity tells how serious an Issue is.
+type Severity int
+
+const (
+	// SeverityError is for issues which fail validation.
+	SeverityError Severity = iota
+
+	// SeverityWarning is for issues which are reported, but do not
+	// fail validation. see Compiler.Severities.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText marshals s as its String.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Issue is a leaf error of ValidationError, with its keyword metadata.
+type Issue struct {
+	Severity                Severity `json:"severity"`
+	InstancePtr             string   `json:"instancePtr"`
+	KeywordLocation         string   `json:"keywordLocation"`
+	AbsoluteKeywordLocation string   `json:"absoluteKeywordLocation"`
+	Keyword                 string   `json:"keyword,omitempty"` // empty, if not known
+	Message                 string   `json:"message"`
+
+	// Params has the values of KeywordError, with keys "want" and "got".
+	// nil values are omitted.
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// Flatten returns an Issue for each leaf error of ve, in depth-first
+// order. It is useful for log pipelines and UIs which do not want to
+// traverse the cause tree.
+func (ve *ValidationError) Flatten() []Issue {
+	var issues []Issue
+	for _, leaf := range ve.Leaves() {
+		issue := Issue{
+			Severity:                SeverityError,
+			InstancePtr:             leaf.InstanceLocation,
+			KeywordLocation:         leaf.KeywordLocation,
+			AbsoluteKeywordLocation: leaf.AbsoluteKeywordLocation,
+			Message:                 leaf.Message,
+		}
+		if ke := leaf.KeywordError; ke != nil {
+			issue.Keyword = ke.Keyword
+			for k, v := range map[string]interface{}{"want": ke.Want, "got": ke.Got} {
+				if v != nil {
+					if issue.Params == nil {
+						issue.Params = make(map[string]interface{})
+					}
+					issue.Params[k] = v
+				}
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// ErrorGroup is a group of leaf errors of ValidationError, reported by
+// same keyword of same schema, at different instance locations.
+type ErrorGroup struct {
+	AbsoluteKeywordLocation string   `json:"absoluteKeywordLocation"`
+	Keyword                 string   `json:"keyword,omitempty"` // empty, if not known
+	Message                 string   `json:"message"`           // message of first error in the group
+	Count                   int      `json:"count"`             // number of errors in the group
+	InstanceLocations       []string `json:"instanceLocations"` // sample of instance locations, in depth-first order
+}
+
+// Aggregate groups leaf errors of ve, that are reported by the same
+// keyword of the same schema, such as the same missing property in every
+// item of a large array, and returns each group with its count and first
+// samples instance locations. samples <= 0 means all. Errors are grouped
+// regardless of their messages, which differ with the instance values.
+// Groups are in depth-first order of their first error.
+func (ve *ValidationError) Aggregate(samples int) []ErrorGroup {
+	type key struct{ loc, keyword string }
+	var groups []ErrorGroup
+	index := make(map[key]int)
+	for _, leaf := range ve.Leaves() {
+		k := key{loc: leaf.AbsoluteKeywordLocation}
+		if leaf.KeywordError != nil {
+			k.keyword = leaf.KeywordError.Keyword
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, ErrorGroup{AbsoluteKeywordLocation: k.loc, Keyword: k.keyword, Message: leaf.Message})
+		}
+		g := &groups[i]
+		g.Count++
+		if samples <= 0 || len(g.InstanceLocations) < samples {
+			g.InstanceLocations = append(g.InstanceLocations, leaf.InstanceLocation)
+		}
+	}
+	return groups
+}
+
+// MarshalJSON marshals se as json object with fields schemaURL, message
+// and causes. causes has the *ValidationError, if the schema is not valid
+// against its meta-schema.
+func (se *SchemaError) MarshalJSON() ([]byte, error) {
+	v := struct {
+		SchemaURL string             `json:"schemaURL"`
+		Message   string             `json:"message"`
+		Causes    []*ValidationError `json:"causes,omitempty"`
+	}{
+		SchemaURL: se.SchemaURL,
+		Message:   se.Error(),
+	}
+	if ve, ok := se.Err.(*ValidationError); ok {
+		v.Causes = []*ValidationError{ve}
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,325 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestOutput(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {
+			"age": {"type": "integer", "minimum": 18}
+		}
+	}`)
+	err := sch.Validate(decodeString(t, `{"age": 10}`))
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %#v, want *jsonschema.ValidationError", err)
+	}
+
+	for _, format := range []string{"flag", "basic", "detailed"} {
+		t.Run(format, func(t *testing.T) {
+			out, err := ve.Output(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal(b, &m); err != nil {
+				t.Fatal(err)
+			}
+			if m["valid"] != false {
+				t.Errorf("valid: got %v, want false", m["valid"])
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		for _, format := range []string{"xml", "verbose"} {
+			if _, err := ve.Output(format); err == nil {
+				t.Errorf("%s: error expected", format)
+			}
+		}
+	})
+}
+
+func TestSchema_ValidateWithOutput(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(`{
+		"title": "person",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "title": "name"},
+			"age": {"type": "integer", "minimum": 18}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("http://example.com/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"flag", "basic", "detailed", "verbose"} {
+		for instance, valid := range map[string]bool{`{"name": "john", "age": 20}`: true, `{"age": 10}`: false} {
+			out, err := sch.ValidateWithOutput(decodeString(t, instance), format, jsonschema.ValidateOptions{})
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			b, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal(b, &m); err != nil {
+				t.Fatal(err)
+			}
+			if m["valid"] != valid {
+				t.Errorf("%s %s: valid got %v, want %v", format, instance, m["valid"], valid)
+			}
+		}
+	}
+
+	t.Run("verbose valid", func(t *testing.T) {
+		out, err := sch.ValidateWithOutput(decodeString(t, `{"name": "john", "age": 20}`), "verbose", jsonschema.ValidateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := jsonschema.Verbose{
+			Valid:                   true,
+			AbsoluteKeywordLocation: "http://example.com/schema.json#",
+			Annotations: []jsonschema.Verbose{
+				{
+					Valid:                   true,
+					KeywordLocation:         "/properties/age",
+					AbsoluteKeywordLocation: "http://example.com/schema.json#/properties/age",
+					InstanceLocation:        "/age",
+				},
+				{
+					Valid:                   true,
+					KeywordLocation:         "/properties/name",
+					AbsoluteKeywordLocation: "http://example.com/schema.json#/properties/name",
+					InstanceLocation:        "/name",
+					Annotations: []jsonschema.Verbose{{
+						Valid:                   true,
+						KeywordLocation:         "/properties/name/title",
+						AbsoluteKeywordLocation: "http://example.com/schema.json#/properties/name/title",
+						InstanceLocation:        "/name",
+						Annotation:              "name",
+					}},
+				},
+				{
+					Valid:                   true,
+					KeywordLocation:         "/title",
+					AbsoluteKeywordLocation: "http://example.com/schema.json#/title",
+					Annotation:              "person",
+				},
+			},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Errorf("got:\n%+v\nwant:\n%+v", out, want)
+		}
+	})
+
+	t.Run("verbose invalid", func(t *testing.T) {
+		out, err := sch.ValidateWithOutput(decodeString(t, `{"age": 10}`), "verbose", jsonschema.ValidateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := jsonschema.Verbose{
+			AbsoluteKeywordLocation: "http://example.com/schema.json#",
+			Errors: []jsonschema.Verbose{
+				{
+					KeywordLocation:         "/properties/age",
+					AbsoluteKeywordLocation: "http://example.com/schema.json#/properties/age",
+					InstanceLocation:        "/age",
+					Errors: []jsonschema.Verbose{{
+						KeywordLocation:         "/properties/age/minimum",
+						AbsoluteKeywordLocation: "http://example.com/schema.json#/properties/age/minimum",
+						InstanceLocation:        "/age",
+						Error:                   "must be >= 18 but found 10",
+					}},
+				},
+				{
+					KeywordLocation:         "/required",
+					AbsoluteKeywordLocation: "http://example.com/schema.json#/required",
+					Error:                   "missing properties: 'name'",
+				},
+			},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Errorf("got:\n%+v\nwant:\n%+v", out, want)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, err := sch.ValidateWithOutput(decodeString(t, `{}`), "xml", jsonschema.ValidateOptions{}); err == nil {
+			t.Error("error expected")
+		}
+	})
+}
+
+func TestValidationError_MarshalJSON(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {
+			"age": {"type": "integer", "minimum": 18},
+			"name": {"type": "string"}
+		}
+	}`)
+	err := sch.Validate(decodeString(t, `{"age": 10, "name": 1}`))
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %#v, want *jsonschema.ValidationError", err)
+	}
+
+	b, err := json.Marshal(ve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(b, &tree); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"message", "instanceLocation", "keywordLocation", "absoluteKeywordLocation", "causes"} {
+		if _, ok := tree[field]; !ok {
+			t.Errorf("field %q missing in %s", field, b)
+		}
+	}
+	if causes := tree["causes"].([]interface{}); len(causes) != 2 {
+		t.Fatalf("got %d causes, want 2", len(causes))
+	}
+
+	b, err = json.Marshal(ve.Leaves())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []map[string]interface{}
+	if err := json.Unmarshal(b, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d leaves, want 2: %s", len(list), b)
+	}
+	for _, item := range list {
+		if _, ok := item["causes"]; ok {
+			t.Errorf("leaf must not have causes: %v", item)
+		}
+	}
+}
+
+func TestSchemaError_MarshalJSON(t *testing.T) {
+	_, err := jsonschema.CompileString("schema.json", `{"type": 1}`)
+	se, ok := err.(*jsonschema.SchemaError)
+	if !ok {
+		t.Fatalf("got %#v, want *jsonschema.SchemaError", err)
+	}
+	b, err := json.Marshal(se)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		SchemaURL string        `json:"schemaURL"`
+		Message   string        `json:"message"`
+		Causes    []interface{} `json:"causes"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.SchemaURL != se.SchemaURL || v.Message != se.Error() || len(v.Causes) != 1 {
+		t.Fatalf("got %s", b)
+	}
+}
+
+func TestValidationError_Flatten(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"properties": {"a": {"minimum": 5}, "b": {"type": "string"}},
+		"required": ["c"]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sch.Validate(map[string]interface{}{"a": 1, "b": 2})
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %v, want validation error", err)
+	}
+	issues := ve.Flatten()
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+	byKeyword := make(map[string]jsonschema.Issue)
+	for _, issue := range issues {
+		if issue.Severity != jsonschema.SeverityError {
+			t.Errorf("%s: got severity %v", issue.Keyword, issue.Severity)
+		}
+		byKeyword[issue.Keyword] = issue
+	}
+
+	min := byKeyword["minimum"]
+	if min.InstancePtr != "/a" || min.KeywordLocation != "/properties/a/minimum" || min.AbsoluteKeywordLocation == "" || min.Params["got"] != 1 {
+		t.Errorf("got %+v", min)
+	}
+	if typ := byKeyword["type"]; typ.InstancePtr != "/b" || !reflect.DeepEqual(typ.Params["want"], []string{"string"}) || typ.Params["got"] != "number" {
+		t.Errorf("got %+v", typ)
+	}
+	if req := byKeyword["required"]; req.InstancePtr != "" || !reflect.DeepEqual(req.Params["got"], []string{"c"}) {
+		t.Errorf("got %+v", req)
+	}
+
+	b, err := json.Marshal(min)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"severity":"error"`) || !strings.Contains(string(b), `"keyword":"minimum"`) {
+		t.Errorf("got %s", b)
+	}
+}
+
+func TestValidationError_Aggregate(t *testing.T) {
+	sch := jsonschema.MustCompileString("http://example.com/schema.json", `{
+		"items": {
+			"required": ["id"],
+			"properties": {"qty": {"minimum": 0}, "name": {"type": "string"}}
+		}
+	}`)
+	inst := decodeString(t, `[{}, {"id": 1}, {}, {"qty": -1}, {}, {"id": 2, "qty": -5}, {"id": 3, "name": 1}, {"id": 4, "name": true}]`)
+	ve := sch.Validate(inst).(*jsonschema.ValidationError)
+	want := []jsonschema.ErrorGroup{
+		{
+			AbsoluteKeywordLocation: "http://example.com/schema.json#/items/required",
+			Keyword:                 "required",
+			Message:                 "missing properties: 'id'",
+			Count:                   4,
+			InstanceLocations:       []string{"/0", "/2"},
+		},
+		{
+			AbsoluteKeywordLocation: "http://example.com/schema.json#/items/properties/qty/minimum",
+			Keyword:                 "minimum",
+			Message:                 "must be >= 0 but found -1",
+			Count:                   2,
+			InstanceLocations:       []string{"/3/qty", "/5/qty"},
+		},
+		{
+			AbsoluteKeywordLocation: "http://example.com/schema.json#/items/properties/name/type",
+			Keyword:                 "type",
+			Message:                 "expected string, but got number",
+			Count:                   2,
+			InstanceLocations:       []string{"/6/name", "/7/name"},
+		},
+	}
+	got := ve.Aggregate(2)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+	if got := ve.Aggregate(0); len(got[0].InstanceLocations) != 4 {
+		t.Fatalf("samples 0: got %q, want all", got[0].InstanceLocations)
+	}
+}
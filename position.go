@@ -0,0 +1,111 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// Position is the location of a json value in raw json document.
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // column number in characters, starting at 1
+}
+
+// ValidateJSON is like Validate, but takes raw json document b.
+//
+// If the document is not valid against s, the Position of each
+// *ValidationError in the error tree is set to the position of the
+// json value at its InstanceLocation within b. returns error from json
+// decoding if b is not valid json.
+func (s *Schema) ValidateJSON(b []byte) error {
+	v, err := unmarshal(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	err = s.Validate(v)
+	if ve, ok := err.(*ValidationError); ok {
+		offsets, perr := jsonOffsets(b)
+		if perr != nil {
+			return err
+		}
+		ve.setPosition(b, offsets, lineStarts(b))
+	}
+	return err
+}
+
+func (ve *ValidationError) setPosition(b []byte, offsets map[string]int, lines []int) {
+	if off, ok := offsets[ve.InstanceLocation]; ok {
+		line := sort.SearchInts(lines, off+1) - 1
+		ve.Position = &Position{
+			Offset: off,
+			Line:   line + 1,
+			Column: utf8.RuneCount(b[lines[line]:off]) + 1,
+		}
+	}
+	for _, c := range ve.Causes {
+		c.setPosition(b, offsets, lines)
+	}
+}
+
+// lineStarts returns the offsets at which lines in b start.
+func lineStarts(b []byte) []int {
+	lines := []int{0}
+	for i, c := range b {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// jsonOffsets returns the byte offset of each json value in b,
+// keyed by its json-pointer.
+func jsonOffsets(b []byte) (map[string]int, error) {
+	offsets := make(map[string]int)
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	// offset of next value, skipping separators of previous token
+	next := func() int {
+		off := int(decoder.InputOffset())
+		for off < len(b) && bytes.IndexByte([]byte(" \t\r\n:,"), b[off]) != -1 {
+			off++
+		}
+		return off
+	}
+	var walk func(ptr string) error
+	walk = func(ptr string) error {
+		offsets[ptr] = next()
+		t, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'):
+			for decoder.More() {
+				t, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				if err := walk(ptr + "/" + jsonpointer.Escape(t.(string))); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := walk(ptr + "/" + strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = decoder.Token() // closing delim
+		return err
+	}
+	return offsets, walk("")
+}
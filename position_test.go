@@ -0,0 +1,63 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_ValidateJSON(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"properties": {
+			"name": {"type": "string"},
+			"tags": {"items": {"type": "string"}}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := "{\n  \"name\": \"é\",\n  \"tags\": [\"a\", 1]\n}"
+	err = sch.ValidateJSON([]byte(doc))
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	leaf := ve
+	for len(leaf.Causes) > 0 {
+		leaf = leaf.Causes[0]
+	}
+	if leaf.InstanceLocation != "/tags/1" {
+		t.Fatalf("instance location: got %s, want /tags/1", leaf.InstanceLocation)
+	}
+	want := jsonschema.Position{Offset: strings.Index(doc, "1]"), Line: 3, Column: 17}
+	if leaf.Position == nil || *leaf.Position != want {
+		t.Fatalf("position: got %+v, want %+v", leaf.Position, want)
+	}
+	if ve.Position == nil || ve.Position.Offset != 0 {
+		t.Errorf("position of root: got %+v, want offset 0", ve.Position)
+	}
+	if !strings.Contains(err.Error(), "(line 3, column 17)") {
+		t.Errorf("error must contain position: %v", err)
+	}
+
+	t.Run("unicode column", func(t *testing.T) {
+		err := sch.ValidateJSON([]byte(`{"tags": ["é", 1]}`))
+		if !errors.As(err, &ve) {
+			t.Fatalf("got %v, want *ValidationError", err)
+		}
+		for len(ve.Causes) > 0 {
+			ve = ve.Causes[0]
+		}
+		if ve.Position == nil || ve.Position.Column != 16 || ve.Position.Offset != 16 {
+			t.Fatalf("got %+v, want column 16 and offset 16", ve.Position)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		if err := sch.ValidateJSON([]byte(`{"name": }`)); err == nil || errors.As(err, &ve) {
+			t.Fatalf("must fail with json syntax error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package jsonschema
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Profile accumulates the number of evaluations and time spent in each
+// schema, across validations using ValidateOptions.Profile. It helps schema
+// authors to find expensive patterns on production-shaped data, such as
+// anyOf with many branches or giant regexes:
+//
+//	profile := &jsonschema.Profile{}
+//	for _, v := range sample {
+//		sch.ValidateWithOptions(v, jsonschema.ValidateOptions{Profile: profile})
+//	}
+//	profile.Report(os.Stdout, 10)
+//
+// Profiling adds overhead to each evaluation, so it is better enabled only
+// for a sample of validations.
+//
+// A Profile is safe for concurrent use by multiple goroutines.
+type Profile struct {
+	mu    sync.Mutex
+	stats map[string]*ProfileEntry
+}
+
+// ProfileEntry is the profile of a schema. Time of keywords like "pattern"
+// is included in Self of the schema, containing that keyword.
+type ProfileEntry struct {
+	AbsoluteKeywordLocation string
+	Keyword                 string        // keyword that applied the schema, when first evaluated. empty for root schema
+	Count                   int           // number of evaluations
+	Failures                int           // number of evaluations, which failed
+	Total                   time.Duration // time spent, including subschemas. nested evaluations of recursive schema are counted again
+	Self                    time.Duration // time spent, excluding subschemas
+}
+
+// Entries returns profile of each evaluated schema, in descending order of
+// Self time.
+func (p *Profile) Entries() []ProfileEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := make([]ProfileEntry, 0, len(p.stats))
+	for _, e := range p.stats {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Self != entries[j].Self {
+			return entries[i].Self > entries[j].Self
+		}
+		return entries[i].AbsoluteKeywordLocation < entries[j].AbsoluteKeywordLocation
+	})
+	return entries
+}
+
+// Reset discards the profile collected so far.
+func (p *Profile) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats = nil
+}
+
+// Report writes top n entries of profile to w, as table. n <= 0 means all.
+func (p *Profile) Report(w io.Writer, n int) error {
+	entries := p.Entries()
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "SELF\tTOTAL\tCOUNT\tFAILURES\t\tLOCATION")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%v\t%v\t%d\t%d\t\t%s\n", e.Self, e.Total, e.Count, e.Failures, e.AbsoluteKeywordLocation)
+	}
+	return tw.Flush()
+}
+
+// merge adds stats of a validation into p.
+func (p *Profile) merge(stats map[string]*ProfileEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stats == nil {
+		p.stats = make(map[string]*ProfileEntry, len(stats))
+	}
+	for loc, s := range stats {
+		e, ok := p.stats[loc]
+		if !ok {
+			e = &ProfileEntry{AbsoluteKeywordLocation: loc, Keyword: s.Keyword}
+			p.stats[loc] = e
+		}
+		e.Count += s.Count
+		e.Failures += s.Failures
+		e.Total += s.Total
+		e.Self += s.Self
+	}
+}
+
+// profiler is the Hook, which profiles single validation.
+type profiler struct {
+	stats map[string]*ProfileEntry
+	stack []profileFrame
+}
+
+type profileFrame struct {
+	start    time.Time
+	children time.Duration // time spent in subschemas
+}
+
+func newProfiler() *profiler {
+	return &profiler{stats: make(map[string]*ProfileEntry)}
+}
+
+func (p *profiler) OnKeywordEnter(e *HookEvent) {
+	p.stack = append(p.stack, profileFrame{start: time.Now()})
+}
+
+func (p *profiler) OnKeywordExit(e *HookEvent, err error) {
+	f := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	total := time.Since(f.start)
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += total
+	}
+	s, ok := p.stats[e.AbsoluteKeywordLocation]
+	if !ok {
+		s = &ProfileEntry{AbsoluteKeywordLocation: e.AbsoluteKeywordLocation, Keyword: e.Keyword}
+		p.stats[e.AbsoluteKeywordLocation] = s
+	}
+	s.Count++
+	if err != nil {
+		s.Failures++
+	}
+	s.Total += total
+	s.Self += total - f.children
+}
@@ -0,0 +1,82 @@
+package jsonschema_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestProfile(t *testing.T) {
+	sch := jsonschema.MustCompileString("http://example.com/schema.json", `{
+		"items": {
+			"anyOf": [
+				{"type": "integer"},
+				{"type": "string", "pattern": "^[a-z]+$"},
+				{"type": "boolean"}
+			]
+		}
+	}`)
+	profile := &jsonschema.Profile{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts := jsonschema.ValidateOptions{Profile: profile}
+			if err := sch.ValidateWithOptions(decodeString(t, `[1, "abc", true]`), opts); err != nil {
+				t.Errorf("%#v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries := make(map[string]jsonschema.ProfileEntry)
+	for _, e := range profile.Entries() {
+		entries[e.AbsoluteKeywordLocation] = e
+		if e.Self > e.Total {
+			t.Errorf("%s: self %v exceeds total %v", e.AbsoluteKeywordLocation, e.Self, e.Total)
+		}
+	}
+	tests := []struct {
+		loc      string
+		keyword  string
+		count    int
+		failures int
+	}{
+		{"http://example.com/schema.json#", "", 4, 0},
+		{"http://example.com/schema.json#/items", "items", 12, 0},
+		{"http://example.com/schema.json#/items/anyOf/0", "anyOf", 12, 8},
+		{"http://example.com/schema.json#/items/anyOf/1", "anyOf", 12, 8},
+		{"http://example.com/schema.json#/items/anyOf/2", "anyOf", 12, 8},
+	}
+	for _, test := range tests {
+		e, ok := entries[test.loc]
+		if !ok {
+			t.Errorf("%s: not profiled", test.loc)
+			continue
+		}
+		if e.Keyword != test.keyword || e.Count != test.count || e.Failures != test.failures {
+			t.Errorf("%s: got keyword %q count %d failures %d, want %q %d %d", test.loc, e.Keyword, e.Count, e.Failures, test.keyword, test.count, test.failures)
+		}
+	}
+	if len(entries) != len(tests) {
+		t.Errorf("got %d entries, want %d", len(entries), len(tests))
+	}
+
+	var buf bytes.Buffer
+	if err := profile.Report(&buf, 2); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "SELF") || !strings.Contains(lines[0], "LOCATION") {
+		t.Errorf("report:\n%s", buf.String())
+	}
+
+	profile.Reset()
+	if entries := profile.Entries(); len(entries) != 0 {
+		t.Errorf("got %d entries after Reset", len(entries))
+	}
+}
@@ -0,0 +1,91 @@
+// Package protoval validates protobuf values against json-schema, so that
+// gRPC services and gateways can reuse the schemas of their JSON APIs.
+//
+// Typical usage:
+//
+//	// google.protobuf.Struct, ListValue or Value
+//	err := protoval.Validate(sch, req.GetAttributes())
+//
+//	// any message, in proto-JSON encoding
+//	b, err := protojson.Marshal(msg)
+//	if err != nil {
+//		return err
+//	}
+//	err = protoval.ValidateJSON(sch, b)
+//
+// The well-known types of package structpb are recognized by their methods
+// AsMap, AsSlice and AsInterface, so that this package does not depend
+// on protobuf module. They map onto json values as: Struct to object,
+// ListValue to array, and Value to the json value it holds. NaN and
+// infinite numbers are held as strings "NaN", "Infinity" and "-Infinity".
+//
+// In proto-JSON encoding, 64-bit integers are sent as strings. ValidateJSON
+// converts such strings to numbers where "integer" or "number" is expected
+// by the schema, using jsonschema.Schema.ValidateAndCoerceNumbers.
+package protoval
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// mapper is implemented by *structpb.Struct.
+type mapper interface {
+	AsMap() map[string]interface{}
+}
+
+// slicer is implemented by *structpb.ListValue.
+type slicer interface {
+	AsSlice() []interface{}
+}
+
+// valuer is implemented by *structpb.Value.
+type valuer interface {
+	AsInterface() interface{}
+}
+
+// Value converts structpb Struct, ListValue or Value to json value that
+// can be validated. Maps and slices are converted recursively, and any
+// other value is returned as is.
+func Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case mapper:
+		return Value(v.AsMap())
+	case slicer:
+		return Value(v.AsSlice())
+	case valuer:
+		return Value(v.AsInterface())
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = Value(item)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = Value(item)
+		}
+		return arr
+	}
+	return v
+}
+
+// Validate validates structpb Struct, ListValue or Value against sch.
+func Validate(sch *jsonschema.Schema, v interface{}) error {
+	return sch.Validate(Value(v))
+}
+
+// ValidateJSON validates proto-JSON encoded message b against sch.
+// Strings with json number, such as 64-bit integers, are validated as
+// numbers, if "integer" or "number" is expected by sch.
+func ValidateJSON(sch *jsonschema.Schema, b []byte) error {
+	v, err := jsonschema.DecodeJSON(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("protoval: invalid json: %v", err)
+	}
+	_, err = sch.ValidateAndCoerceNumbers(v)
+	return err
+}
@@ -0,0 +1,119 @@
+package protoval_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/protoval"
+)
+
+// fake structpb types, with the same methods.
+
+type structValue map[string]interface{}
+
+func (s structValue) AsMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(s))
+	for k, v := range s {
+		m[k] = v.(value).AsInterface()
+	}
+	return m
+}
+
+type listValue []interface{}
+
+func (l listValue) AsSlice() []interface{} {
+	arr := make([]interface{}, len(l))
+	for i, v := range l {
+		arr[i] = v.(value).AsInterface()
+	}
+	return arr
+}
+
+type value struct{ v interface{} }
+
+func (v value) AsInterface() interface{} {
+	switch x := v.v.(type) {
+	case structValue:
+		return x.AsMap()
+	case listValue:
+		return x.AsSlice()
+	}
+	return v.v
+}
+
+const schema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string"},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"score": {"type": ["number", "string"]}
+	},
+	"required": ["id", "name"]
+}`
+
+func TestValidate(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", schema)
+	valid := structValue{
+		"id":    value{float64(1)},
+		"name":  value{"john"},
+		"tags":  value{listValue{value{"a"}, value{"b"}}},
+		"score": value{"NaN"},
+	}
+	if err := protoval.Validate(sch, valid); err != nil {
+		t.Fatal(err)
+	}
+	if err := protoval.Validate(sch, value{valid}); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := structValue{
+		"id":   value{float64(1.5)},
+		"name": value{"john"},
+		"tags": value{listValue{value{true}}},
+	}
+	err := protoval.Validate(sch, invalid)
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %v, want validation error", err)
+	}
+	var locs []string
+	for _, leaf := range ve.Leaves() {
+		locs = append(locs, leaf.InstanceLocation)
+	}
+	if got := strings.Join(locs, " "); !strings.Contains(got, "/id") || !strings.Contains(got, "/tags/0") {
+		t.Fatalf("got errors at %q", got)
+	}
+
+	if err := protoval.Validate(sch, listValue{value{"a"}}); err == nil {
+		t.Fatal("list validated against object schema")
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", schema)
+	tests := []struct {
+		json  string
+		valid bool
+	}{
+		{`{"id": "9007199254740993", "name": "john"}`, true},
+		{`{"id": 1, "name": "john", "score": "Infinity"}`, true},
+		{`{"id": "0", "name": "john"}`, false},
+		{`{"id": "1.5", "name": "john"}`, false},
+		{`{"id": "abc", "name": "john"}`, false},
+		{`{"id": 1, "name": "1"}`, true},
+		{`{"id": 1, "tags": "a"}`, false},
+	}
+	for _, test := range tests {
+		err := protoval.ValidateJSON(sch, []byte(test.json))
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.json, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: must be invalid", test.json)
+		}
+	}
+	if err := protoval.ValidateJSON(sch, []byte(`{`)); err == nil || !strings.HasPrefix(err.Error(), "protoval: invalid json") {
+		t.Errorf("got %v for invalid json", err)
+	}
+}
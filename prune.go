@@ -0,0 +1,164 @@
+package jsonschema
+
+// Prune returns copy of v, with object properties not described by the
+// schema removed. This is useful to sanitize untrusted input before
+// persisting it.
+//
+// A property is described, if it is matched by "properties" or
+// "patternProperties", or allowed by "additionalProperties" or
+// "unevaluatedProperties" with true or schema value. Retained property
+// values are pruned recursively with the schemas they are matched by, and
+// array items are pruned with their item schemas.
+//
+// Schemas reachable through "$ref", "$recursiveRef", "$dynamicRef", "allOf",
+// "anyOf", "oneOf", conditional and dependent keywords are all considered,
+// so that a property is retained if any of them describes it. Objects whose
+// schemas do not use any of the property keywords are left as is.
+//
+// v is not modified, and it is not validated. Use Validate on the result
+// if required.
+func (s *Schema) Prune(v interface{}) interface{} {
+	v = deepCopy(v)
+	prune(v, []*Schema{s})
+	return v
+}
+
+// prune removes properties not described by any of schemas from v in place.
+func prune(v interface{}, schemas []*Schema) {
+	schemas = applicable(schemas)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		var constrained bool
+		for _, s := range schemas {
+			if s.Properties != nil || s.PatternProperties != nil || s.AdditionalProperties != nil || s.UnevaluatedProperties != nil {
+				constrained = true
+				break
+			}
+		}
+		if !constrained {
+			return
+		}
+		for pname, pvalue := range v {
+			var matched []*Schema
+			var described bool
+			for _, s := range schemas {
+				if sch, ok := s.propertySchemas(pname); ok {
+					matched = append(matched, sch...)
+					described = true
+				}
+			}
+			if described {
+				prune(pvalue, matched)
+			} else {
+				delete(v, pname)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			var matched []*Schema
+			for _, s := range schemas {
+				if sch := s.itemSchema(i); sch != nil {
+					matched = append(matched, sch)
+				}
+			}
+			prune(item, matched)
+		}
+	}
+}
+
+// applicable returns given schemas along with their subschemas, which
+// apply on same instance. false schemas are excluded.
+func applicable(schemas []*Schema) []*Schema {
+	var result []*Schema
+	var add func(s *Schema)
+	add = func(s *Schema) {
+		if s == nil || isFalse(s) {
+			return
+		}
+		for _, sch := range result {
+			if sch == s {
+				return
+			}
+		}
+		result = append(result, s)
+		add(s.Ref)
+		add(s.RecursiveRef)
+		add(s.DynamicRef)
+		for _, list := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf, {s.If, s.Then, s.Else}} {
+			for _, sch := range list {
+				add(sch)
+			}
+		}
+		for _, sch := range s.DependentSchemas {
+			add(sch)
+		}
+		for _, dep := range s.Dependencies {
+			if sch, ok := dep.(*Schema); ok {
+				add(sch)
+			}
+		}
+	}
+	for _, s := range schemas {
+		add(s)
+	}
+	return result
+}
+
+// propertySchemas returns the schemas for property pname as per
+// "properties", "patternProperties", "additionalProperties" and
+// "unevaluatedProperties" of s, and whether pname is described by s.
+func (s *Schema) propertySchemas(pname string) ([]*Schema, bool) {
+	var matched []*Schema
+	if sch, ok := s.Properties[pname]; ok {
+		matched = append(matched, sch)
+	}
+	for pattern, sch := range s.PatternProperties {
+		if pattern.MatchString(pname) {
+			matched = append(matched, sch)
+		}
+	}
+	if matched != nil {
+		for _, sch := range matched {
+			if isFalse(sch) {
+				return nil, false
+			}
+		}
+		return matched, true
+	}
+	switch additional := s.AdditionalProperties.(type) {
+	case bool:
+		return nil, additional
+	case *Schema:
+		return []*Schema{additional}, !isFalse(additional)
+	}
+	if s.UnevaluatedProperties != nil {
+		return []*Schema{s.UnevaluatedProperties}, !isFalse(s.UnevaluatedProperties)
+	}
+	return nil, false
+}
+
+// itemSchema returns the schema for item at index i as per
+// "prefixItems", "items" and "additionalItems" of s.
+func (s *Schema) itemSchema(i int) *Schema {
+	items := s.PrefixItems
+	if items == nil {
+		items, _ = s.Items.([]*Schema)
+	}
+	if i < len(items) {
+		return items[i]
+	}
+	if sch, ok := s.Items.(*Schema); ok {
+		return sch
+	}
+	if sch, ok := s.AdditionalItems.(*Schema); ok {
+		return sch
+	}
+	if s.Items2020 != nil {
+		return s.Items2020
+	}
+	return s.UnevaluatedItems
+}
+
+func isFalse(s *Schema) bool {
+	return s.Always != nil && !*s.Always
+}
@@ -0,0 +1,127 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_Prune(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		input  string
+		want   string
+	}{
+		{
+			name:   "properties",
+			schema: `{"properties": {"a": {}, "b": {}}}`,
+			input:  `{"a": 1, "b": 2, "c": 3}`,
+			want:   `{"a": 1, "b": 2}`,
+		},
+		{
+			name:   "patternProperties",
+			schema: `{"properties": {"a": {}}, "patternProperties": {"^x-": {}}}`,
+			input:  `{"a": 1, "x-b": 2, "c": 3}`,
+			want:   `{"a": 1, "x-b": 2}`,
+		},
+		{
+			name:   "additionalProperties true",
+			schema: `{"properties": {"a": {"properties": {"b": {}}}}, "additionalProperties": true}`,
+			input:  `{"a": {"b": 1, "c": 2}, "d": {"e": 3}}`,
+			want:   `{"a": {"b": 1}, "d": {"e": 3}}`,
+		},
+		{
+			name:   "additionalProperties schema",
+			schema: `{"properties": {"a": {}}, "additionalProperties": {"properties": {"b": {}}}}`,
+			input:  `{"a": {"x": 1}, "c": {"b": 2, "x": 3}}`,
+			want:   `{"a": {"x": 1}, "c": {"b": 2}}`,
+		},
+		{
+			name:   "false property",
+			schema: `{"properties": {"a": {}, "b": false}, "additionalProperties": true}`,
+			input:  `{"a": 1, "b": 2, "c": 3}`,
+			want:   `{"a": 1, "c": 3}`,
+		},
+		{
+			name:   "unconstrained object",
+			schema: `{"type": "object"}`,
+			input:  `{"a": 1}`,
+			want:   `{"a": 1}`,
+		},
+		{
+			name:   "items",
+			schema: `{"items": {"properties": {"a": {}}}}`,
+			input:  `[{"a": 1, "b": 2}, {"c": 3}]`,
+			want:   `[{"a": 1}, {}]`,
+		},
+		{
+			name:   "prefixItems",
+			schema: `{"prefixItems": [{"properties": {"a": {}}}], "items": {"properties": {"b": {}}}}`,
+			input:  `[{"a": 1, "b": 2}, {"a": 3, "b": 4}]`,
+			want:   `[{"a": 1}, {"b": 4}]`,
+		},
+		{
+			name: "ref and allOf",
+			schema: `{
+				"$ref": "#/$defs/base",
+				"allOf": [{"properties": {"b": {}}}],
+				"$defs": {"base": {"properties": {"a": {"$ref": "#/$defs/base"}}}}
+			}`,
+			input: `{"a": {"a": {"x": 1}, "x": 2}, "b": 3, "c": 4}`,
+			want:  `{"a": {"a": {}}, "b": 3}`,
+		},
+		{
+			name:   "anyOf",
+			schema: `{"anyOf": [{"properties": {"a": {}}}, {"properties": {"b": {}}}]}`,
+			input:  `{"a": 1, "b": 2, "c": 3}`,
+			want:   `{"a": 1, "b": 2}`,
+		},
+		{
+			name:   "conditional",
+			schema: `{"if": {"properties": {"a": {"const": 1}}}, "then": {"properties": {"b": {}}}}`,
+			input:  `{"a": 1, "b": 2, "c": 3}`,
+			want:   `{"a": 1, "b": 2}`,
+		},
+		{
+			name:   "scalar",
+			schema: `{"properties": {"a": {}}}`,
+			input:  `"abc"`,
+			want:   `"abc"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sch, err := jsonschema.CompileString("schema.json", test.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input := decodeString(t, test.input)
+			got := sch.Prune(input)
+			if want := decodeString(t, test.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(input, decodeString(t, test.input)) {
+				t.Error("input is modified")
+			}
+		})
+	}
+}
+
+func TestSchema_PruneValidates(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"properties": {"name": {"type": "string"}},
+		"additionalProperties": false
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := decodeString(t, `{"name": "john", "admin": true}`)
+	if err := sch.Validate(v); err == nil {
+		t.Fatal("validation must fail before prune")
+	}
+	if err := sch.Validate(sch.Prune(v)); err != nil {
+		t.Fatal(err)
+	}
+}
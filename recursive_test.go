@@ -0,0 +1,52 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestRecursiveMetaSchema(t *testing.T) {
+	// meta-schema extending draft2019-09, which requires lowercase property
+	// names in all subschemas, using $recursiveAnchor
+	meta := `{
+		"$schema": "https://json-schema.org/draft/2019-09/schema",
+		"$id": "https://example.com/lowercase-meta",
+		"$recursiveAnchor": true,
+		"allOf": [{"$ref": "https://json-schema.org/draft/2019-09/schema"}],
+		"properties": {
+			"properties": {"propertyNames": {"pattern": "^[a-z]+$"}}
+		}
+	}`
+	tests := []struct {
+		description string
+		schema      string
+		valid       bool
+	}{
+		{"valid", `{"properties": {"foo": {"items": {"properties": {"bar": {}}}}}}`, true},
+		{"invalid at root", `{"properties": {"Foo": {}}}`, false},
+		{"invalid in subschema", `{"properties": {"foo": {"properties": {"Bar": {}}}}}`, false},
+		{"invalid in applicator", `{"items": {"anyOf": [{"properties": {"Bar": {}}}]}}`, false},
+		{"invalid in $defs", `{"$defs": {"x": {"properties": {"Bar": {}}}}}`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			if err := c.AddResource("https://example.com/lowercase-meta", strings.NewReader(meta)); err != nil {
+				t.Fatal(err)
+			}
+			schema := `{"$schema": "https://example.com/lowercase-meta", ` + test.schema[1:]
+			if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+				t.Fatal(err)
+			}
+			_, err := c.Compile("schema.json")
+			if test.valid && err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("error expected")
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reflect returns schema generated from the go type of v, using reflection.
+//
+// the schema is generated in draft 2020-12, following the same rules as
+// encoding/json does, to encode go value into json. named struct types are
+// generated in "$defs", so that recursive types are supported. struct types
+// do not allow additional properties.
+//
+// The struct fields can be annotated with "jsonschema" tag, which is a
+// comma separated list of keywords, for example:
+//
+//	type User struct {
+//		Name  string   `json:"name" jsonschema:"required,minLength=1,description=full name"`
+//		Age   int      `json:"age" jsonschema:"minimum=18"`
+//		Role  string   `json:"role" jsonschema:"enum=admin,enum=guest"`
+//		Email string   `json:"email" validate:"required,email"`
+//		Tags  []string `json:"tags" jsonschema:"uniqueItems,maxItems=10"`
+//	}
+//
+// the supported keywords are required, title, description, format,
+// pattern, enum, default, deprecated, readOnly, writeOnly, uniqueItems,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+// minLength, maxLength, minItems, maxItems, minProperties and maxProperties.
+// to use comma in value, escape it with backslash.
+//
+// the commonly used rules of "validate" tag from go-playground/validator are
+// also supported: required, min, max, len, gt, gte, lt, lte, oneof, email,
+// url, uri, uuid, hostname, ipv4, ipv6 and datetime.
+//
+// the generated schema can be converted to json using ReflectJSON.
+func Reflect(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	b, err := ReflectJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	url := "urn:go:anonymous"
+	if t != nil && t.Name() != "" {
+		url = "urn:go:" + typeName(t)
+	}
+	c := NewCompiler()
+	if err := c.AddResource(url, bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return c.Compile(url)
+}
+
+// ReflectJSON is like Reflect, but returns the generated schema as json.
+func ReflectJSON(v interface{}) ([]byte, error) {
+	r := &reflector{
+		defs:  make(map[string]interface{}),
+		names: make(map[reflect.Type]string),
+	}
+	doc, err := r.schema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	if len(r.defs) > 0 {
+		doc["$defs"] = r.defs
+	}
+	doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return json.Marshal(doc)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// reflector generates json-schema for go types.
+type reflector struct {
+	defs  map[string]interface{}
+	names map[reflect.Type]string // name in defs for named struct types
+}
+
+// schema returns json-schema for go type t.
+func (r *reflector) schema(t reflect.Type) (map[string]interface{}, error) {
+	if t == nil {
+		return map[string]interface{}{}, nil
+	}
+	for t.Kind() == reflect.Pointer && !t.Implements(jsonMarshalerType) && !t.Implements(textMarshalerType) {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case t == jsonNumberType:
+		return map[string]interface{}{"type": "number"}, nil
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]interface{}{}, nil
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(jsonMarshalerType) {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := r.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		sch := map[string]interface{}{"type": "array", "items": items}
+		if t.Kind() == reflect.Array {
+			sch["minItems"], sch["maxItems"] = t.Len(), t.Len()
+		}
+		return sch, nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return nil, InvalidJSONTypeError(fmt.Sprintf("map key %s", t.Key()))
+			}
+		}
+		values, err := r.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name, ok := r.names[t]
+		if !ok {
+			name = t.Name()
+			for i := 2; r.defs[name] != nil; i++ {
+				name = t.Name() + strconv.Itoa(i)
+			}
+			r.names[t] = name
+			r.defs[name] = true // placeholder, for recursive types
+			sch, err := r.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			r.defs[name] = sch
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + escape(name)}, nil
+	}
+	return nil, InvalidJSONTypeError(t.String())
+}
+
+func (r *reflector) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	props := make(map[string]interface{})
+	var required []string
+	for _, f := range structFields(t) {
+		sf := t.FieldByIndex(f.index)
+		var psch map[string]interface{}
+		if f.quoted && isQuotable(sf.Type) {
+			psch = map[string]interface{}{"type": "string"}
+		} else {
+			var err error
+			if psch, err = r.schema(sf.Type); err != nil {
+				return nil, err
+			}
+		}
+		reqd, err := applyTags(psch, sf)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: invalid tag in field %s.%s: %v", t, sf.Name, err)
+		}
+		if reqd {
+			required = append(required, f.name)
+		}
+		props[f.name] = psch
+	}
+	sch := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		sch["required"] = required
+	}
+	return sch, nil
+}
+
+// isQuotable tells whether ",string" tag option is applicable for type t.
+func isQuotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// applyTags adds keywords from "jsonschema" and "validate" tags of
+// field sf into sch. returns whether the field is required.
+func applyTags(sch map[string]interface{}, sf reflect.StructField) (bool, error) {
+	ft := sf.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	required := false
+
+	// value converts tag value into json value of field type.
+	value := func(key, s string) (interface{}, error) {
+		switch ft.Kind() {
+		case reflect.String:
+			return s, nil
+		case reflect.Bool:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", key, s)
+			}
+			return b, nil
+		}
+		return number(key, s)
+	}
+
+	for _, item := range splitTag(sf.Tag.Get("jsonschema"), ',') {
+		key, val, _ := strings.Cut(item, "=")
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "deprecated", "readOnly", "writeOnly", "uniqueItems":
+			sch[key] = true
+		case "title", "description", "format", "pattern":
+			sch[key] = val
+		case "enum":
+			v, err := value(key, val)
+			if err != nil {
+				return false, err
+			}
+			enum, _ := sch["enum"].([]interface{})
+			sch["enum"] = append(enum, v)
+		case "default":
+			v, err := value(key, val)
+			if err != nil {
+				return false, err
+			}
+			sch["default"] = v
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+			"minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+			n, err := number(key, val)
+			if err != nil {
+				return false, err
+			}
+			sch[key] = n
+		default:
+			return false, fmt.Errorf("unknown keyword %q", key)
+		}
+	}
+
+	// limit returns the keyword for min/max rules of validate tag.
+	limit := func(prefix string) string {
+		switch ft.Kind() {
+		case reflect.String:
+			return prefix + "Length"
+		case reflect.Slice, reflect.Array:
+			return prefix + "Items"
+		case reflect.Map:
+			return prefix + "Properties"
+		}
+		return prefix + "imum"
+	}
+
+	for _, item := range splitTag(sf.Tag.Get("validate"), ',') {
+		key, val, _ := strings.Cut(item, "=")
+		if key == "dive" {
+			break // rules after dive apply to items
+		}
+		switch key {
+		case "required":
+			required = true
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			n, err := number(key, val)
+			if err != nil {
+				return false, err
+			}
+			switch key {
+			case "min", "gte":
+				sch[limit("min")] = n
+			case "max", "lte":
+				sch[limit("max")] = n
+			case "len":
+				sch[limit("min")], sch[limit("max")] = n, n
+			case "gt":
+				sch["exclusiveMinimum"] = n
+			case "lt":
+				sch["exclusiveMaximum"] = n
+			}
+		case "oneof":
+			var enum []interface{}
+			for _, s := range strings.Fields(val) {
+				v, err := value(key, s)
+				if err != nil {
+					return false, err
+				}
+				enum = append(enum, v)
+			}
+			sch["enum"] = enum
+		case "email", "uri", "uuid", "hostname", "ipv4", "ipv6":
+			sch["format"] = key
+		case "url":
+			sch["format"] = "uri"
+		case "datetime":
+			sch["format"] = "date-time"
+		}
+	}
+	return required, nil
+}
+
+// number parses s, the value of given tag key, as json number.
+func number(key, s string) (json.Number, error) {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", fmt.Errorf("invalid %s %q", key, s)
+	}
+	return json.Number(s), nil
+}
+
+// splitTag splits s by sep, unless it is escaped with backslash.
+func splitTag(s string, sep byte) []string {
+	var items []string
+	var item strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == sep:
+			item.WriteByte(sep)
+			i++
+		case s[i] == sep:
+			items = append(items, item.String())
+			item.Reset()
+		default:
+			item.WriteByte(s[i])
+		}
+	}
+	if s != "" {
+		items = append(items, item.String())
+	}
+	return items
+}
+
+func typeName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
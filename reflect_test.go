@@ -0,0 +1,124 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type reflectAddress struct {
+	Street string `json:"street" jsonschema:"required,minLength=1"`
+	Zip    string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{5}$"`
+}
+
+type reflectBase struct {
+	ID int64 `json:"id" validate:"required,gt=0"`
+}
+
+type reflectUser struct {
+	reflectBase
+	Name     string            `json:"name" validate:"required,min=1,max=20"`
+	Email    string            `json:"email" validate:"email"`
+	Role     string            `json:"role" jsonschema:"enum=admin,enum=guest,default=guest"`
+	Age      *uint8            `json:"age,omitempty" jsonschema:"maximum=150"`
+	Tags     []string          `json:"tags,omitempty" jsonschema:"uniqueItems,maxItems=3"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Address  *reflectAddress   `json:"address,omitempty"`
+	Friends  []*reflectUser    `json:"friends,omitempty"`
+	Created  time.Time         `json:"created"`
+	Count    int               `json:"count,string"`
+	Note     string            `json:"note" jsonschema:"description=a\\, b"`
+	Password string            `json:"-"`
+	hidden   bool
+}
+
+func TestReflect(t *testing.T) {
+	sch, err := jsonschema.Reflect(reflectUser{})
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	valid := `{
+		"id": 1, "name": "john", "email": "john@example.com", "role": "admin",
+		"age": 30, "tags": ["a", "b"], "labels": {"k": "v"},
+		"address": {"street": "main", "zip": "12345"},
+		"friends": [{"id": 2, "name": "jane", "email": "jane@example.com", "role": "guest",
+			"created": "2020-01-01T00:00:00Z", "count": "1", "note": ""}],
+		"created": "2020-01-01T00:00:00Z", "count": "10", "note": ""
+	}`
+	if err := sch.Validate(decodeString(t, valid)); err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"missing required", `{"name": null}`},
+		{"id not gt 0", `{"id": 0}`},
+		{"long name", `{"name": "abcdefghijklmnopqrstuvwxyz"}`},
+		{"invalid enum", `{"role": "root"}`},
+		{"negative age", `{"age": -1}`},
+		{"max age", `{"age": 151}`},
+		{"duplicate tags", `{"tags": ["a", "a"]}`},
+		{"too many tags", `{"tags": ["a", "b", "c", "d"]}`},
+		{"invalid label", `{"labels": {"k": 1}}`},
+		{"empty street", `{"address": {"street": ""}}`},
+		{"invalid zip", `{"address": {"street": "main", "zip": "1"}}`},
+		{"invalid friend", `{"friends": [{"id": 2}]}`},
+		{"count not string", `{"count": 10}`},
+		{"unknown property", `{"password": "secret"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := decodeString(t, valid).(map[string]interface{})
+			for k, v := range decodeString(t, test.patch).(map[string]interface{}) {
+				if v == nil {
+					delete(doc, k)
+				} else {
+					doc[k] = v
+				}
+			}
+			if err := sch.Validate(doc); err == nil {
+				t.Fatal("validation must fail")
+			}
+		})
+	}
+}
+
+func TestReflectJSON(t *testing.T) {
+	b, err := jsonschema.ReflectJSON(reflectUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := doc["$ref"], "#/$defs/reflectUser"; got != want {
+		t.Fatalf("$ref: got %v, want %v", got, want)
+	}
+	defs := doc["$defs"].(map[string]interface{})
+	user := defs["reflectUser"].(map[string]interface{})
+	props := user["properties"].(map[string]interface{})
+	note := props["note"].(map[string]interface{})
+	if got, want := note["description"], "a, b"; got != want {
+		t.Fatalf("description: got %v, want %v", got, want)
+	}
+	if _, ok := props["Password"]; ok {
+		t.Fatal(`field with json:"-" must be ignored`)
+	}
+
+	type invalid struct {
+		N int `jsonschema:"minimum=abc"`
+	}
+	if _, err := jsonschema.ReflectJSON(invalid{}); err == nil || !strings.Contains(err.Error(), "minimum") {
+		t.Fatalf("want invalid tag error, got %v", err)
+	}
+	if _, err := jsonschema.ReflectJSON(make(chan int)); err == nil {
+		t.Fatal("want error for chan")
+	}
+}
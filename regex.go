@@ -0,0 +1,77 @@
+package jsonschema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/ecmaregex"
+)
+
+// goRegexp is Regexp implemented using go regexp package.
+type goRegexp struct {
+	re  *regexp.Regexp
+	src string // source in ECMA-262 syntax
+}
+
+// compileGoRegexp compiles ECMA-262 regular expression s using go regexp
+// package. ECMA-262 syntax not supported by go, like "\uXXXX" and
+// "(?<name>...)" are translated to equivalent go syntax.
+func compileGoRegexp(s string) (*goRegexp, error) {
+	re, err := regexp.Compile(ecmaregex.ToGo(s))
+	if err != nil {
+		return nil, err
+	}
+	return &goRegexp{re, s}, nil
+}
+
+func (re *goRegexp) MatchString(s string) bool {
+	return re.re.MatchString(s)
+}
+
+func (re *goRegexp) String() string {
+	return re.src
+}
+
+// checkECMARegex reports the syntax in regular expression s, which is
+// accepted by go regexp, but is not valid ECMA-262.
+func checkECMARegex(s string) error {
+	inClass := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 == len(s) {
+				return nil
+			}
+			i++
+			switch s[i] {
+			case 'A', 'z', 'Q', 'E', 'C':
+				return fmt.Errorf(`\%c is not valid ECMA-262`, s[i])
+			}
+		case '[':
+			if !inClass {
+				inClass = true
+				if i+1 < len(s) && s[i+1] == ']' {
+					i++ // ] as first char is literal in go
+				}
+			} else if strings.HasPrefix(s[i:], "[:") && strings.Contains(s[i:], ":]") {
+				return fmt.Errorf("POSIX character class is not valid ECMA-262")
+			}
+		case ']':
+			inClass = false
+		case '(':
+			if inClass || !strings.HasPrefix(s[i:], "(?") {
+				continue
+			}
+			rest := s[i+2:]
+			switch {
+			case strings.HasPrefix(rest, ":"), strings.HasPrefix(rest, "="), strings.HasPrefix(rest, "!"), strings.HasPrefix(rest, "<"):
+			case strings.HasPrefix(rest, "P<"):
+				return fmt.Errorf("(?P<name> is not valid ECMA-262, use (?<name>")
+			default:
+				return fmt.Errorf("inline flags are not valid ECMA-262")
+			}
+		}
+	}
+	return nil
+}
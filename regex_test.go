@@ -0,0 +1,97 @@
+package jsonschema_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestRegexECMA(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"properties": {
+			"name": {"pattern": "^\\u00e9+$"}
+		},
+		"patternProperties": {
+			"^(?<prefix>x)-": {"type": "integer"}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if err := sch.Validate(decodeString(t, `{"name": "éé", "x-a": 1}`)); err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if err := sch.Validate(decodeString(t, `{"name": "e"}`)); err == nil {
+		t.Fatal("validation must fail for pattern")
+	}
+	if err := sch.Validate(decodeString(t, `{"x-a": "1"}`)); err == nil {
+		t.Fatal("validation must fail for patternProperties")
+	}
+	if got := sch.Properties["name"].Pattern.String(); got != `^\u00e9+$` {
+		t.Errorf("pattern source: got %q", got)
+	}
+}
+
+func TestCompiler_StrictRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string // substring of error, empty if valid
+	}{
+		{`^[a-z]+$`, ""},
+		{`^(?:a|b)(?<name>c)$`, ""},
+		{`(?i)abc`, "inline flags"},
+		{`\Aabc\z`, `\A is not valid ECMA-262`},
+		{`[[:alpha:]]`, "POSIX character class"},
+		{`(?P<name>a)`, "use (?<name>"},
+	}
+	for _, test := range tests {
+		c := jsonschema.NewCompiler()
+		c.StrictRegex = true
+		if err := c.AddResource("schema.json", strings.NewReader(`{"patternProperties": {"`+strings.ReplaceAll(test.pattern, `\`, `\\`)+`": {}}}`)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := c.Compile("schema.json")
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("%s: %v", test.pattern, err)
+		case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+			t.Errorf("%s: error must contain %q, got %v", test.pattern, test.want, err)
+		}
+	}
+}
+
+func TestCompiler_RegexCache(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	compiled := 0
+	c.CompileRegex = func(s string) (jsonschema.Regexp, error) {
+		compiled++
+		return regexp.Compile(s)
+	}
+	for _, url := range []string{"a.json", "b.json"} {
+		if err := c.AddResource(url, strings.NewReader(`{
+			"properties": {
+				"a": {"pattern": "^[a-z]+$"},
+				"b": {"pattern": "^[a-z]+$"}
+			},
+			"patternProperties": {"^[a-z]+$": {}}
+		}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := c.Compile("a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Compile("b.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compiled != 1 {
+		t.Errorf("pattern must be compiled once, but compiled %d times", compiled)
+	}
+	if a.Properties["a"].Pattern != b.Properties["b"].Pattern {
+		t.Error("compiled pattern must be shared across schemas")
+	}
+}
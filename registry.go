@@ -0,0 +1,112 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry holds named schemas, which are compiled lazily on first use.
+// It saves applications from writing their own cache of compiled schemas:
+//
+//	func init() {
+//		jsonschema.Register("user", "schemas/user.json")
+//	}
+//
+//	func createUser(v interface{}) error {
+//		sch, err := jsonschema.Get("user")
+//		if err != nil {
+//			return err
+//		}
+//		return sch.Validate(v)
+//	}
+//
+// Each schema is compiled only once, even if requested concurrently, and
+// the result, including any compilation error, is reused by subsequent
+// calls.
+//
+// A Registry is safe for concurrent use by multiple goroutines.
+type Registry struct {
+	// Compiler compiles the registered schemas. nil means NewCompiler().
+	// It must not be changed after first Get.
+	Compiler *Compiler
+
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+}
+
+type registryEntry struct {
+	url  string
+	once sync.Once
+	sch  *Schema
+	err  error
+}
+
+// DefaultRegistry is the Registry used by Register and Get.
+var DefaultRegistry = &Registry{}
+
+// Register registers schema at given url with name in DefaultRegistry.
+func Register(name, url string) {
+	DefaultRegistry.Register(name, url)
+}
+
+// Get returns the compiled schema, registered with name in DefaultRegistry.
+func Get(name string) (*Schema, error) {
+	return DefaultRegistry.Get(name)
+}
+
+// Register registers schema at given url with name, replacing existing
+// registration of that name, if any. The schema is not compiled until
+// first Get.
+func (r *Registry) Register(name, url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = make(map[string]*registryEntry)
+	}
+	r.entries[name] = &registryEntry{url: url}
+}
+
+// Get returns the compiled schema registered with name, compiling it if
+// this is the first call for that name.
+//
+// returns *SchemaError if the schema cannot be compiled.
+func (r *Registry) Get(name string) (*Schema, error) {
+	r.mu.Lock()
+	e, ok := r.entries[name]
+	if ok && r.Compiler == nil {
+		r.Compiler = NewCompiler()
+	}
+	c := r.Compiler
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: schema %q is not registered", name)
+	}
+	e.once.Do(func() {
+		e.sch, e.err = c.Compile(e.url)
+	})
+	return e.sch, e.err
+}
+
+// MustGet is like Get but panics if the schema is not registered or cannot
+// be compiled. It simplifies validation of instances, whose schemas are
+// known to be valid.
+func (r *Registry) MustGet(name string) *Schema {
+	sch, err := r.Get(name)
+	if err != nil {
+		panic(fmt.Sprintf("jsonschema: %#v", err))
+	}
+	return sch
+}
+
+// Names returns names of the registered schemas, in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
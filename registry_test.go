@@ -0,0 +1,93 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestRegistry(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("http://example.com/user.json", strings.NewReader(`{"required": ["name"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddResource("http://example.com/invalid.json", strings.NewReader(`{"type": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+	r := &jsonschema.Registry{Compiler: c}
+	r.Register("user", "http://example.com/user.json")
+	r.Register("invalid", "http://example.com/invalid.json")
+
+	if got, want := r.Names(), []string{"invalid", "user"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names: got %v, want %v", got, want)
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		schemas := make([]*jsonschema.Schema, 10)
+		var wg sync.WaitGroup
+		for i := range schemas {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sch, err := r.Get("user")
+				if err != nil {
+					t.Errorf("%#v", err)
+				}
+				schemas[i] = sch
+			}(i)
+		}
+		wg.Wait()
+		for _, sch := range schemas {
+			if sch == nil || sch != schemas[0] {
+				t.Fatal("all calls must return same schema")
+			}
+		}
+		if err := r.MustGet("user").Validate(decodeString(t, `{}`)); err == nil {
+			t.Fatal("validation must fail")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := r.Get("invalid")
+		if _, ok := err.(*jsonschema.SchemaError); !ok {
+			t.Fatalf("got %#v, want *SchemaError", err)
+		}
+		if _, err2 := r.Get("invalid"); err2 != err {
+			t.Fatalf("compilation error must be reused")
+		}
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		_, err := r.Get("order")
+		if err == nil || !strings.Contains(err.Error(), `"order" is not registered`) {
+			t.Fatalf("got %v", err)
+		}
+		defer func() {
+			if recover() == nil {
+				t.Fatal("MustGet must panic")
+			}
+		}()
+		r.MustGet("order")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		r.Register("invalid", "http://example.com/user.json")
+		if _, err := r.Get("invalid"); err != nil {
+			t.Fatalf("%#v", err)
+		}
+	})
+}
+
+func TestRegister(t *testing.T) {
+	jsonschema.Register("registry-test", "testdata/person_schema.json")
+	sch, err := jsonschema.Get("registry-test")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if sch == nil {
+		t.Fatal("schema must not be nil")
+	}
+}
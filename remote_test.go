@@ -0,0 +1,148 @@
+package jsonschema_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Offline(t *testing.T) {
+	loadURL := func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("network access to %s", s)
+	}
+	schema := `{
+		"properties": {
+			"name": {"$ref": "https://example.com/schemas/name.json"},
+			"age": {"$ref": "http://example.com/schemas/age.json?v=1"}
+		}
+	}`
+
+	t.Run("AddRemoteFS", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.LoadURL = loadURL
+		c.Offline = true
+		c.AddRemoteFS(fstest.MapFS{
+			"example.com/schemas/name.json": {Data: []byte(`{"type": "string"}`)},
+			"example.com/schemas/age.json":  {Data: []byte(`{"type": "integer"}`)},
+		})
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		sch, err := c.Compile("schema.json")
+		if err != nil {
+			t.Fatalf("%#v", err)
+		}
+		if err := sch.Validate(decodeString(t, `{"name": 1}`)); err == nil {
+			t.Fatal("validation must fail")
+		}
+	})
+
+	t.Run("AddResources", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.LoadURL = loadURL
+		c.Offline = true
+		if err := c.AddResources(map[string][]byte{
+			"https://example.com/schemas/name.json":   []byte(`{"type": "string"}`),
+			"http://example.com/schemas/age.json?v=1": []byte(`{"type": "integer"}`),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Compile("schema.json"); err != nil {
+			t.Fatalf("%#v", err)
+		}
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.LoadURL = func(s string) (io.ReadCloser, error) {
+			t.Fatalf("must not load %s", s)
+			return nil, nil
+		}
+		c.Offline = true
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := c.Compile("schema.json")
+		if err == nil || !strings.Contains(err.Error(), "offline") {
+			t.Fatalf("must fail in offline mode, got %v", err)
+		}
+	})
+}
+
+func TestCompiler_AddResourceFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/person.json":         {Data: []byte(`{"properties": {"address": {"$ref": "common/address.json"}, "email": {"$ref": "https://example.com/email.json"}}}`)},
+		"schemas/common/address.json": {Data: []byte(`{"required": ["city"], "properties": {"zip": {"$ref": "../zip.json"}}}`)},
+		"schemas/zip.json":            {Data: []byte(`{"type": "string"}`)},
+		"schemas/common/email.json":   {Data: []byte(`{"$id": "https://example.com/email.json", "type": "string"}`)},
+		"schemas/readme.md":           {Data: []byte(`not json`)},
+		"other/invalid.json":          {Data: []byte(`{`)},
+	}
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		t.Fatalf("must not load %s", s)
+		return nil, nil
+	}
+	if err := c.AddResourceFS(fsys, "schemas/**/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schemas/person.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	for _, doc := range []string{`{"address": {}}`, `{"address": {"city": "x", "zip": 1}}`, `{"email": 1}`} {
+		if err := sch.Validate(decodeString(t, doc)); err == nil {
+			t.Errorf("%s: validation must fail", doc)
+		}
+	}
+	if err := sch.Validate(decodeString(t, `{"address": {"city": "x", "zip": "1"}, "email": "a"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := jsonschema.NewCompiler().AddResourceFS(fsys, ""); err == nil || !strings.Contains(err.Error(), "other/invalid.json") {
+		t.Fatalf("got %v, want error for invalid json", err)
+	}
+	if err := jsonschema.NewCompiler().AddResourceFS(fsys, "schemas/[.json"); err == nil {
+		t.Fatal("error expected for invalid glob")
+	}
+}
+
+func TestCompiler_Resources(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		t.Fatalf("must not load %s", s)
+		return nil, nil
+	}
+	resources := map[string]string{
+		"file:///schemas/sub/../person.json": `{"$id": "http://example.com/person.json", "properties": {"address": {"$ref": "address.json"}}}`,
+		"file:///schemas/address.json":       `{"$defs": {"zip": {"$id": "zip.json", "type": "string"}}, "properties": {"zip": {"$ref": "zip.json"}}}`,
+		"http://example.com/address.json":    `{"$ref": "file:///schemas/sub/./../address.json"}`,
+	}
+	for url, doc := range resources {
+		if err := c.AddResource(url, strings.NewReader(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Compile("file:///schemas/person.json"); err != nil {
+		t.Fatalf("%#v", err)
+	}
+	var got []string
+	for _, r := range c.Resources() {
+		got = append(got, fmt.Sprintf("%s %s %v", r.URL, r.ID, r.Embedded))
+	}
+	want := []string{
+		"file:///schemas/address.json file:///schemas/address.json [file:///schemas/zip.json]",
+		"file:///schemas/person.json http://example.com/person.json []",
+		"http://example.com/address.json http://example.com/address.json []",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
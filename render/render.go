@@ -0,0 +1,324 @@
+// Package render renders validation errors as sentences for end users,
+// such as "'age' must be at least 18" or "field 'email' is required",
+// using a text/template for each keyword.
+//
+// Typical usage:
+//
+//	r, err := render.NewRenderer(map[string]string{
+//		"required": `please fill {{.FieldNames .Got}}`,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	if err := sch.Validate(v); err != nil {
+//		if ve, ok := err.(*jsonschema.ValidationError); ok {
+//			for _, sentence := range r.Render(ve) {
+//				fmt.Println(sentence)
+//			}
+//		}
+//	}
+//
+// The templates are executed with Data. Besides the builtin functions of
+// text/template, following functions are available:
+//
+//	quote S       S in single quotes
+//	value V       V in json
+//	values LIST   json of values in LIST, e.g. "a", "b" or "c"
+//	types LIST    json types in LIST with article, e.g. a string or null
+//	plural N S P  S if N is 1, otherwise P
+//
+// Since Renderer.Message has the signature of jsonschema.Compiler.Translator,
+// it can be used to render the messages of all errors reported by schemas:
+//
+//	compiler.Translator = r.Message
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// DefaultTemplates are the templates used for keywords, which are not
+// overridden.
+var DefaultTemplates = map[string]string{
+	"type":                 `{{.Field}} must be {{types .Want}}`,
+	"required":             `{{if eq (len .Got) 1}}field {{.FieldName (index .Got 0)}} is required{{else}}fields {{.FieldNames .Got}} are required{{end}}`,
+	"additionalProperties": `{{if eq (len .Got) 1}}field {{.FieldName (index .Got 0)}} is not allowed{{else}}fields {{.FieldNames .Got}} are not allowed{{end}}`,
+	"dependencies":         `field {{.FieldName .Want}} is required when {{.FieldName .Got}} is present`,
+	"dependentRequired":    `field {{.FieldName .Want}} is required when {{.FieldName .Got}} is present`,
+	"minProperties":        `{{.Field}} must have at least {{.Want}} {{plural .Want "field" "fields"}}`,
+	"maxProperties":        `{{.Field}} must have at most {{.Want}} {{plural .Want "field" "fields"}}`,
+	"minItems":             `{{.Field}} must have at least {{.Want}} {{plural .Want "item" "items"}}`,
+	"maxItems":             `{{.Field}} must have at most {{.Want}} {{plural .Want "item" "items"}}`,
+	"uniqueItems":          `{{.Field}} must not contain duplicate items`,
+	"contains":             `{{.Field}} must contain a matching item`,
+	"minContains":          `{{.Field}} must contain at least {{.Want}} matching {{plural .Want "item" "items"}}`,
+	"maxContains":          `{{.Field}} must contain at most {{.Want}} matching {{plural .Want "item" "items"}}`,
+	"minLength":            `{{.Field}} must be at least {{.Want}} {{plural .Want "character" "characters"}} long`,
+	"maxLength":            `{{.Field}} must be at most {{.Want}} {{plural .Want "character" "characters"}} long`,
+	"pattern":              `{{.Field}} must match pattern {{quote .Want}}`,
+	"format":               `{{.Field}} must be a valid {{.Want}}`,
+	"minimum":              `{{.Field}} must be at least {{.Want}}`,
+	"maximum":              `{{.Field}} must be at most {{.Want}}`,
+	"exclusiveMinimum":     `{{.Field}} must be greater than {{.Want}}`,
+	"exclusiveMaximum":     `{{.Field}} must be less than {{.Want}}`,
+	"multipleOf":           `{{.Field}} must be a multiple of {{.Want}}`,
+	"enum":                 `{{.Field}} must be one of {{values .Want}}{{with .Suggestions}}, did you mean {{values .}}?{{end}}`,
+	"const":                `{{.Field}} must be {{value .Want}}{{with .Suggestions}}, did you mean {{values .}}?{{end}}`,
+	"readOnly":             `{{.Field}} is read-only`,
+	"writeOnly":            `{{.Field}} is write-only`,
+	"false":                `{{.Field}} is not allowed`,
+	"not":                  `{{.Field}} has a value which is not allowed`,
+	"propertyNames":        `{{.Field}} is not a valid field name`,
+}
+
+// fallback is used for keywords without template.
+const fallback = `{{.Field}}: {{.Message}}`
+
+// Data is passed to the templates.
+type Data struct {
+	// Field is the display name of the instance location in single quotes,
+	// e.g. 'address.city' or 'items[0]'. It is "value" for the instance
+	// itself.
+	Field string
+
+	// Keyword is the keyword that failed.
+	Keyword string
+
+	// Want and Got are from jsonschema.KeywordError. Numbers of type
+	// *big.Rat are converted to json.Number.
+	Want, Got interface{}
+
+	// Suggestions are from jsonschema.KeywordError, the allowed values
+	// nearest to Got.
+	Suggestions []string
+
+	// Message is the default message of the error.
+	Message string
+
+	// Error is the error being rendered.
+	Error *jsonschema.ValidationError
+
+	// path is the unescaped tokens of instance location. see FieldName.
+
+	path []string
+}
+
+// Renderer renders validation errors using templates.
+type Renderer struct {
+	templates map[string]*template.Template
+	fallback  *template.Template
+}
+
+// NewRenderer returns Renderer which uses given templates, falling back to
+// DefaultTemplates for other keywords. templates can be nil.
+func NewRenderer(templates map[string]string) (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]*template.Template)}
+	parse := func(keyword, text string) (*template.Template, error) {
+		t, err := template.New(keyword).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("render: invalid template for %s: %v", keyword, err)
+		}
+		return t, nil
+	}
+	for _, m := range []map[string]string{DefaultTemplates, templates} {
+		for keyword, text := range m {
+			t, err := parse(keyword, text)
+			if err != nil {
+				return nil, err
+			}
+			r.templates[keyword] = t
+		}
+	}
+	r.fallback, _ = parse("fallback", fallback)
+	return r, nil
+}
+
+// Render returns a sentence for each leaf error in ve. For anyOf and
+// oneOf failures, only the error picked by BestMatch is rendered. For
+// propertyNames and additionalProperties failures, the error about the
+// property names is rendered, instead of its causes.
+func (r *Renderer) Render(ve *jsonschema.ValidationError) []string {
+	var sentences []string
+	var walk func(ve *jsonschema.ValidationError)
+	walk = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			sentences = append(sentences, r.Message(ve))
+			return
+		}
+		if ke := ve.KeywordError; ke != nil && (ke.Keyword == "anyOf" || ke.Keyword == "oneOf") {
+			sentences = append(sentences, r.Message(ve.BestMatch()))
+			return
+		}
+		if ke := ve.KeywordError; ke != nil && (ke.Keyword == "propertyNames" || ke.Keyword == "additionalProperties") {
+			sentences = append(sentences, r.Message(ve))
+			return
+		}
+		for _, c := range ve.Causes {
+			walk(c)
+		}
+	}
+	walk(ve)
+	return sentences
+}
+
+// Message renders the leaf error ve, or propertyNames or additionalProperties
+// error. For errors which just group their causes, ve.Message is returned
+// as is. If template execution fails, ve.Message is returned.
+func (r *Renderer) Message(ve *jsonschema.ValidationError) string {
+	ke := ve.KeywordError
+	if ke == nil || len(ve.Causes) > 0 && ke.Keyword != "propertyNames" && ke.Keyword != "additionalProperties" {
+		return ve.Message
+	}
+	t, ok := r.templates[ke.Keyword]
+	if !ok {
+		t = r.fallback
+	}
+	data := &Data{
+		Keyword:     ke.Keyword,
+		Want:        number(ke.Want),
+		Got:         number(ke.Got),
+		Suggestions: ke.Suggestions,
+		Message:     ve.Message,
+		Error:       ve,
+	}
+	data.path, _ = jsonpointer.Split(ve.InstanceLocation)
+	data.Field = fieldName(data.path)
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return ve.Message
+	}
+	return b.String()
+}
+
+func number(v interface{}) interface{} {
+	if r, ok := v.(*big.Rat); ok {
+		if r.IsInt() {
+			return json.Number(r.Num().String())
+		}
+		f, _ := r.Float64()
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return v
+}
+
+// fieldName returns display name of the instance location path.
+func fieldName(path []string) string {
+	if len(path) == 0 {
+		return "value"
+	}
+	var b strings.Builder
+	for i, tok := range path {
+		switch {
+		case isIndex(tok):
+			b.WriteString("[" + tok + "]")
+		case i > 0:
+			b.WriteString("." + tok)
+		default:
+			b.WriteString(tok)
+		}
+	}
+	return quote(b.String())
+}
+
+func isIndex(tok string) bool {
+	if tok == "" {
+		return false
+	}
+	for _, r := range tok {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func quote(s interface{}) string {
+	return "'" + fmt.Sprint(s) + "'"
+}
+
+// list converts v into slice, if it is slice.
+func list(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []interface{}{v}
+	}
+	l := make([]interface{}, rv.Len())
+	for i := range l {
+		l[i] = rv.Index(i).Interface()
+	}
+	return l
+}
+
+// join joins items as "a, b and c".
+func join(items []string, conj string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + conj + " " + items[len(items)-1]
+}
+
+func jsonText(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+var funcs = template.FuncMap{
+	"quote": quote,
+	"value": jsonText,
+	"values": func(v interface{}) string {
+		var items []string
+		for _, item := range list(v) {
+			items = append(items, jsonText(item))
+		}
+		return join(items, "or")
+	},
+	"types": func(v interface{}) string {
+		var items []string
+		for _, t := range list(v) {
+			s := fmt.Sprint(t)
+			switch {
+			case s == "null":
+			case strings.IndexByte("aeiou", s[0]) != -1:
+				s = "an " + s
+			default:
+				s = "a " + s
+			}
+			items = append(items, s)
+		}
+		return join(items, "or")
+	},
+	"plural": func(n interface{}, singular, plural string) string {
+		if fmt.Sprint(n) == "1" {
+			return singular
+		}
+		return plural
+	},
+}
+
+// FieldName returns display name of property name of the instance,
+// e.g. 'address.city'.
+func (d *Data) FieldName(name interface{}) string {
+	return fieldName(append(append([]string(nil), d.path...), fmt.Sprint(name)))
+}
+
+// FieldNames returns display names of properties in names, e.g.
+// 'a', 'b' and 'c'. names must be slice.
+func (d *Data) FieldNames(names interface{}) string {
+	var items []string
+	for _, name := range list(names) {
+		items = append(items, d.FieldName(name))
+	}
+	return join(items, "and")
+}
@@ -0,0 +1,117 @@
+package render_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/render"
+)
+
+const schema = `{
+	"type": "object",
+	"properties": {
+		"age": {"type": "integer", "minimum": 18},
+		"name": {"type": "string", "minLength": 1},
+		"email": {"type": "string", "format": "email"},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 1},
+		"role": {"enum": ["admin", "user"]},
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city", "zip"],
+			"additionalProperties": false
+		},
+		"contact": {"oneOf": [{"type": "string", "format": "email"}, {"type": "integer"}]},
+		"labels": {"propertyNames": {"maxLength": 3}}
+	},
+	"required": ["email"]
+}`
+
+func TestRenderer_Render(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.AssertFormat = true
+	if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := render.NewRenderer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		instance string
+		want     []string
+	}{
+		{`[]`, []string{"value must be an object"}},
+		{`{"age": 17}`, []string{"'age' must be at least 18", "field 'email' is required"}},
+		{`{"email": "a@b.com", "age": 1.5, "name": ""}`, []string{"'age' must be an integer", "'name' must be at least 1 character long"}},
+		{`{"email": "x"}`, []string{"'email' must be a valid email"}},
+		{`{"email": "a@b.com", "tags": ["a", 1]}`, []string{"'tags' must have at most 1 item", "'tags[1]' must be a string"}},
+		{`{"email": "a@b.com", "role": "root"}`, []string{`'role' must be one of "admin" or "user"`}},
+		{`{"email": "a@b.com", "role": "usr"}`, []string{`'role' must be one of "admin" or "user", did you mean "user"?`}},
+		{`{"email": "a@b.com", "address": {"x": 1}}`, []string{"fields 'address.city' and 'address.zip' are required", "field 'address.x' is not allowed"}},
+		{`{"email": "a@b.com", "contact": "x"}`, []string{"'contact' must be a valid email"}},
+		{`{"email": "a@b.com", "labels": {"abc": 1, "abcd": 2}}`, []string{"'labels.abcd' is not a valid field name"}},
+	}
+	for _, test := range tests {
+		v, err := jsonschema.DecodeJSON(strings.NewReader(test.instance))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sch.Validate(v)
+		ve, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			t.Errorf("%s: got %v, want validation error", test.instance, err)
+			continue
+		}
+		got := r.Render(ve)
+		sort.Strings(got)
+		sort.Strings(test.want)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %q\nwant %q", test.instance, got, test.want)
+		}
+	}
+}
+
+func TestRenderer_Templates(t *testing.T) {
+	r, err := render.NewRenderer(map[string]string{
+		"required": `please fill {{.FieldNames .Got}}`,
+		"minimum":  `{{.Field}} is too small, got {{.Got}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := jsonschema.NewCompiler()
+	c.Translator = r.Message
+	if err := c.AddResource("schema.json", strings.NewReader(`{"properties": {"n": {"minimum": 5}}, "required": ["a", "b"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sch.Validate(map[string]interface{}{"n": 1})
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %v, want validation error", err)
+	}
+	var got []string
+	for _, leaf := range ve.Leaves() {
+		got = append(got, leaf.Message)
+	}
+	sort.Strings(got)
+	if want := []string{"'n' is too small, got 1", "please fill 'a' and 'b'"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := render.NewRenderer(map[string]string{"type": "{{"}); err == nil {
+		t.Error("error expected for invalid template")
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"net/url"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
 )
 
 type resource struct {
@@ -18,6 +21,12 @@ type resource struct {
 	draft        *Draft
 	subresources map[string]*resource // key is floc. only applicable for root resource
 	schema       *Schema
+	base         string // base url in effect, as computed by baseURL. empty if not known
+	loaded       bool   // whether loaded by compiler, rather than added by user
+
+	// whether any subresource has $dynamicAnchor. nil if not computed.
+	// only applicable for root resource
+	dynamicAnchors *bool
 }
 
 func (r *resource) String() string {
@@ -52,6 +61,7 @@ func (r *resource) fillSubschemas(c *Compiler, res *resource) error {
 	if err := r.draft.listSubschemas(res, r.baseURL(res.floc), r.subresources); err != nil {
 		return err
 	}
+	r.dynamicAnchors = nil
 
 	// ensure subresource.url uniqueness
 	url2floc := make(map[string]string)
@@ -64,9 +74,57 @@ func (r *resource) fillSubschemas(c *Compiler, res *resource) error {
 		}
 	}
 
+	// ensure anchor uniqueness within base uri
+	anchor2floc := make(map[string]string)
+	checkAnchors := func(res *resource) error {
+		base := r.baseURL(res.floc)
+		for _, anchor := range r.draft.plainAnchors(res.doc) {
+			key := base + "#" + anchor
+			if floc, ok := anchor2floc[key]; ok && floc != res.floc {
+				a, b := floc, res.floc
+				if a > b {
+					a, b = b, a
+				}
+				return fmt.Errorf("jsonschema: %q and %q in %s have same anchor %q", a[1:], b[1:], r.url, anchor)
+			}
+			anchor2floc[key] = res.floc
+		}
+		return nil
+	}
+	if err := checkAnchors(r); err != nil {
+		return err
+	}
+	for _, sr := range r.subresources {
+		if err := checkAnchors(sr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// hasDynamicAnchors tells whether r or any of its subresources has $dynamicAnchor.
+func (r *resource) hasDynamicAnchors() bool {
+	if r.dynamicAnchors == nil {
+		has := func(doc interface{}) bool {
+			if m, ok := doc.(map[string]interface{}); ok {
+				_, ok := m["$dynamicAnchor"]
+				return ok
+			}
+			return false
+		}
+		found := has(r.doc)
+		for _, sr := range r.subresources {
+			if found {
+				break
+			}
+			found = has(sr.doc)
+		}
+		r.dynamicAnchors = &found
+	}
+	return *r.dynamicAnchors
+}
+
 // listResources lists all subresources in res
 func (r *resource) listResources(res *resource) []*resource {
 	var result []*resource
@@ -129,12 +187,12 @@ func (r *resource) resolveFragment(c *Compiler, sr *resource, f string) (*resour
 	// non-standrad location
 	doc := r.doc
 	for _, item := range strings.Split(floc[2:], "/") {
-		item = strings.Replace(item, "~1", "/", -1)
-		item = strings.Replace(item, "~0", "~", -1)
+		// percent-decoding of uri fragment precedes json-pointer unescaping
 		item, err := url.PathUnescape(item)
 		if err != nil {
 			return nil, err
 		}
+		item = jsonpointer.Unescape(item)
 		switch d := doc.(type) {
 		case map[string]interface{}:
 			if _, ok := d[item]; !ok {
@@ -159,7 +217,11 @@ func (r *resource) resolveFragment(c *Compiler, sr *resource, f string) (*resour
 	if err != nil {
 		return nil, err
 	}
-	res := &resource{url: id, floc: floc, doc: doc}
+	base := id
+	if base == "" {
+		base = r.baseURL(floc)
+	}
+	res := &resource{url: id, floc: floc, doc: doc, base: base}
 	r.subresources[floc] = res
 	if err := r.fillSubschemas(c, res); err != nil {
 		return nil, err
@@ -167,7 +229,67 @@ func (r *resource) resolveFragment(c *Compiler, sr *resource, f string) (*resour
 	return res, nil
 }
 
+// lookupFailure is called when fragment f is not found in sr. It returns
+// the locations searched for f, and the fragment in sr nearest to f.
+func (r *resource) lookupFailure(sr *resource, f string) (searched []string, suggestion string) {
+	ptr := strings.HasPrefix(f, "#/")
+	if ptr {
+		searched = append(searched, sr.url+f)
+	} else {
+		searched = append(searched, r.url+sr.floc)
+	}
+	var candidates []string
+	addAnchors := func(res *resource) {
+		for _, anchor := range r.draft.anchors(res.doc) {
+			candidates = append(candidates, "#"+anchor)
+		}
+	}
+	addAnchors(sr)
+	prefix := sr.floc + "/"
+	for _, res := range r.subresources {
+		if !strings.HasPrefix(res.floc, prefix) {
+			continue
+		}
+		candidates = append(candidates, "#"+res.floc[len(sr.floc):])
+		if r.baseURL(res.floc) == sr.url {
+			addAnchors(res)
+			if !ptr {
+				searched = append(searched, r.url+res.floc)
+			}
+		}
+	}
+	sort.Strings(searched[1:])
+	return searched, nearest(f, candidates)
+}
+
+// baseURIs returns the chain of base uris in effect at floc, starting
+// with url of r. Each subsequent uri is established by $id of an
+// enclosing subschema.
+func (r *resource) baseURIs(floc string) []string {
+	var uris []string
+	for {
+		if sr, ok := r.subresources[floc]; ok && sr.url != "" && sr.url != r.url {
+			if len(uris) == 0 || uris[len(uris)-1] != sr.url {
+				uris = append(uris, sr.url)
+			}
+		}
+		slash := strings.LastIndexByte(floc, '/')
+		if slash == -1 {
+			break
+		}
+		floc = floc[:slash]
+	}
+	uris = append(uris, r.url)
+	for i, j := 0, len(uris)-1; i < j; i, j = i+1, j-1 {
+		uris[i], uris[j] = uris[j], uris[i]
+	}
+	return uris
+}
+
 func (r *resource) baseURL(floc string) string {
+	if sr, ok := r.subresources[floc]; ok && sr.base != "" {
+		return sr.base
+	}
 	for {
 		if sr, ok := r.subresources[floc]; ok {
 			if sr.url != "" {
@@ -188,8 +310,10 @@ func (r *resource) baseURL(floc string) string {
 func toAbs(s string) (string, error) {
 	// if windows absolute file path, convert to file url
 	// because: net/url parses driver name as scheme
-	if runtime.GOOS == "windows" && len(s) >= 3 && s[1:3] == `:\` {
-		s = "file:///" + filepath.ToSlash(s)
+	if runtime.GOOS == "windows" {
+		if u, ok := windowsFileURL(s); ok {
+			return u, nil
+		}
 	}
 
 	u, err := url.Parse(s)
@@ -197,6 +321,9 @@ func toAbs(s string) (string, error) {
 		return "", err
 	}
 	if u.IsAbs() {
+		if u.Scheme == "file" {
+			return cleanFileURL(s, u), nil
+		}
 		return s, nil
 	}
 
@@ -205,6 +332,9 @@ func toAbs(s string) (string, error) {
 		return "", err
 	}
 	if runtime.GOOS == "windows" {
+		if u, ok := windowsFileURL(s); ok {
+			return u, nil
+		}
 		s = "file:///" + filepath.ToSlash(s)
 	} else {
 		s = "file://" + s
@@ -213,6 +343,38 @@ func toAbs(s string) (string, error) {
 	return u.String(), err
 }
 
+// windowsFileURL converts absolute windows file path s, such as
+// `C:\dir\a.json`, `C:/dir/a.json` or UNC path `\\host\share\a.json`
+// into file url. Drive letter is made upper case, since windows paths are
+// case insensitive, so that a file has single url.
+func windowsFileURL(s string) (string, bool) {
+	s = strings.ReplaceAll(s, `\`, "/")
+	var u url.URL
+	switch {
+	case len(s) >= 3 && s[1] == ':' && s[2] == '/' && ('a' <= s[0] && s[0] <= 'z' || 'A' <= s[0] && s[0] <= 'Z'):
+		u = url.URL{Scheme: "file", Path: "/" + strings.ToUpper(s[:1]) + s[1:]}
+	case len(s) > 2 && strings.HasPrefix(s, "//") && s[2] != '/':
+		host, p, _ := strings.Cut(s[2:], "/")
+		u = url.URL{Scheme: "file", Host: host, Path: "/" + p}
+	default:
+		return "", false
+	}
+	return u.ResolveReference(&url.URL{}).String(), true
+}
+
+// cleanFileURL returns file url s, with dot segments like "../" in
+// its path removed, so that a file has single url. u is parsed s.
+func cleanFileURL(s string, u *url.URL) string {
+	p := u.Path
+	if runtime.GOOS == "windows" && len(p) >= 3 && p[0] == '/' && p[2] == ':' && 'a' <= p[1] && p[1] <= 'z' {
+		u.Path = "/" + strings.ToUpper(p[1:2]) + p[2:]
+		u.RawPath = ""
+	} else if !strings.Contains(p, "/./") && !strings.Contains(p, "/../") && !strings.HasSuffix(p, "/.") && !strings.HasSuffix(p, "/..") {
+		return s
+	}
+	return u.ResolveReference(&url.URL{}).String()
+}
+
 func resolveURL(base, ref string) (string, error) {
 	if ref == "" {
 		return base, nil
@@ -226,6 +388,9 @@ func resolveURL(base, ref string) (string, error) {
 		return "", err
 	}
 	if refURL.IsAbs() {
+		if refURL.Scheme == "file" {
+			return cleanFileURL(ref, refURL), nil
+		}
 		return ref, nil
 	}
 
@@ -0,0 +1,825 @@
+// Package sample generates example instances from compiled json-schemas,
+// for test fixtures and documentation examples.
+//
+// Typical usage:
+//
+//	sch, err := jsonschema.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//	g := sample.NewGenerator(1)
+//	v, err := g.Generate(sch)   // valid instance
+//	mutants, err := g.Mutants(sch) // invalid instances
+//
+// Generated instances respect "type", "const", "enum", "format", "pattern",
+// "required", "properties", "items", length, size and numeric limits,
+// including "multipleOf". Subschemas in "$ref" and "allOf" are merged, and
+// one of the subschemas in "anyOf" and "oneOf" is picked. Since not all
+// constraints are understood by the generator, every generated instance is
+// validated, and generation is retried with different random choices if it
+// fails.
+//
+// Numbers are generated as json.Number.
+package sample
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/ecmaregex"
+)
+
+// Generator generates instances from schemas.
+type Generator struct {
+	// Rand is the source of random choices.
+	Rand *rand.Rand
+
+	// MaxDepth is the nesting depth, beyond which optional properties
+	// and items are not generated. Defaults to 5.
+	MaxDepth int
+
+	// Attempts is the number of times generation is tried, before giving
+	// up on an instance that is not valid. Defaults to 20.
+	Attempts int
+
+	// Formats can be registered by adding to this map. Key is format
+	// name, and value is the sample string for that format. It takes
+	// precedence over built-in samples.
+	Formats map[string]string
+
+	optional bool // whether to generate optional properties
+}
+
+// NewGenerator returns Generator whose random choices are seeded with
+// given seed. Generators with same seed generate same instances.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		Rand:     rand.New(rand.NewSource(seed)),
+		MaxDepth: 5,
+		Attempts: 20,
+		Formats:  make(map[string]string),
+	}
+}
+
+// ErrUnsatisfiable is returned when no valid instance could be generated.
+var ErrUnsatisfiable = errors.New("sample: could not generate valid instance")
+
+// Generate returns an instance that is valid against sch.
+//
+// returns error wrapping ErrUnsatisfiable, if valid instance could not
+// be generated in Attempts.
+func (g *Generator) Generate(sch *jsonschema.Schema) (interface{}, error) {
+	for i := 0; i < g.Attempts; i++ {
+		// first half of attempts generate optional properties
+		g.optional = i < (g.Attempts+1)/2
+		v, err := g.generate(sch, 0)
+		if err != nil {
+			continue
+		}
+		if sch.Validate(v) == nil {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", ErrUnsatisfiable, sch.Location)
+}
+
+// Mutant is an invalid instance, derived from a valid instance.
+type Mutant struct {
+	Value       interface{}
+	Description string // how the valid instance is mutated.
+}
+
+// Mutants returns invalid instances against sch. They are derived by
+// mutating a valid instance, for example by changing type of value,
+// removing property or adding item. Only the mutations that make the
+// instance invalid are returned.
+func (g *Generator) Mutants(sch *jsonschema.Schema) ([]Mutant, error) {
+	v, err := g.Generate(sch)
+	if err != nil {
+		return nil, err
+	}
+	var mutants []Mutant
+	seen := make(map[string]bool)
+	mutate(v, "", func(desc string, replace func(root interface{}) interface{}) {
+		if seen[desc] {
+			return
+		}
+		seen[desc] = true
+		mutant := replace(deepCopy(v))
+		if sch.Validate(mutant) != nil {
+			mutants = append(mutants, Mutant{mutant, desc})
+		}
+	})
+	return mutants, nil
+}
+
+// mutate calls fn for each mutation of value v at json-pointer ptr.
+// replace function applies the mutation on copy of root instance.
+func mutate(v interface{}, ptr string, fn func(desc string, replace func(root interface{}) interface{})) {
+	loc := ptr
+	if loc == "" {
+		loc = "/"
+	}
+	set := func(value interface{}) func(root interface{}) interface{} {
+		return func(root interface{}) interface{} {
+			return setAt(root, ptr, value)
+		}
+	}
+	for _, other := range []interface{}{nil, true, json.Number("0"), "", []interface{}{}, map[string]interface{}{}} {
+		if jsonType(other) != jsonType(v) {
+			fn(fmt.Sprintf("changed %s to %s", loc, jsonType(other)), set(other))
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		fn(fmt.Sprintf("changed %s to empty string", loc), set(""))
+		fn(fmt.Sprintf("changed %s to long string", loc), set(v+strings.Repeat("x", 1000)))
+		fn(fmt.Sprintf("changed %s to string with unexpected characters", loc), set(v+" !#"))
+	case json.Number:
+		fn(fmt.Sprintf("changed %s to large number", loc), set(json.Number("1000000000")))
+		fn(fmt.Sprintf("changed %s to negative number", loc), set(json.Number("-1000000000")))
+		fn(fmt.Sprintf("changed %s to fraction", loc), set(json.Number("0.5")))
+	case []interface{}:
+		if len(v) > 0 {
+			fn(fmt.Sprintf("removed items of %s", loc), set([]interface{}{}))
+			more := append(deepCopy(v).([]interface{}), deepCopy(v[len(v)-1]))
+			fn(fmt.Sprintf("duplicated last item of %s", loc), set(more))
+		}
+		for i, item := range v {
+			mutate(item, fmt.Sprintf("%s/%d", ptr, i), fn)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kptr := ptr + "/" + escape(k)
+			fn("removed "+kptr, func(root interface{}) interface{} {
+				return setAt(root, kptr, deleted)
+			})
+		}
+		fn(fmt.Sprintf("added unexpected property to %s", loc), func(root interface{}) interface{} {
+			return setAt(root, ptr+"/unexpected-property", "x")
+		})
+		for _, k := range keys {
+			mutate(v[k], ptr+"/"+escape(k), fn)
+		}
+	}
+}
+
+// deleted is the value used with setAt, to delete object property.
+var deleted = new(struct{})
+
+// setAt sets value at json-pointer ptr in root, and returns root.
+func setAt(root interface{}, ptr string, value interface{}) interface{} {
+	if ptr == "" {
+		return value
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	v := root
+	for i, tok := range tokens {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		last := i == len(tokens)-1
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			if !last {
+				v = vv[tok]
+			} else if value == deleted {
+				delete(vv, tok)
+			} else {
+				vv[tok] = value
+			}
+		case []interface{}:
+			var index int
+			fmt.Sscan(tok, &index)
+			if !last {
+				v = vv[index]
+			} else {
+				vv[index] = value
+			}
+		}
+	}
+	return root
+}
+
+// merged is the view of schema with subschemas in $ref and allOf merged.
+type merged struct {
+	types      []string
+	constant   []interface{}
+	enum       []interface{}
+	format     string
+	pattern    jsonschema.Regexp
+	minLength  int
+	maxLength  int
+	minimum    *big.Rat
+	exclMin    bool
+	maximum    *big.Rat
+	exclMax    bool
+	multipleOf *big.Rat
+
+	properties map[string][]*jsonschema.Schema
+	required   map[string]bool
+	additional []*jsonschema.Schema // nil means any property allowed.
+	closed     bool                 // additional properties are not allowed
+	minProps   int
+	maxProps   int
+
+	prefixItems [][]*jsonschema.Schema
+	items       []*jsonschema.Schema
+	minItems    int
+	maxItems    int
+	unique      bool
+}
+
+func (g *Generator) merge(sch *jsonschema.Schema) (*merged, error) {
+	m := &merged{
+		minLength: -1, maxLength: -1, minProps: -1, maxProps: -1, minItems: -1, maxItems: -1,
+		properties: make(map[string][]*jsonschema.Schema),
+		required:   make(map[string]bool),
+	}
+	visited := make(map[*jsonschema.Schema]bool)
+	var add func(s *jsonschema.Schema) error
+	add = func(s *jsonschema.Schema) error {
+		if s == nil || visited[s] {
+			return nil
+		}
+		visited[s] = true
+		if s.Always != nil {
+			if !*s.Always {
+				return ErrUnsatisfiable
+			}
+			return nil
+		}
+		if len(s.Types) > 0 {
+			if m.types == nil {
+				m.types = s.Types
+			} else {
+				m.types = intersect(m.types, s.Types)
+				if len(m.types) == 0 {
+					return ErrUnsatisfiable
+				}
+			}
+		}
+		if len(s.Constant) > 0 && m.constant == nil {
+			m.constant = s.Constant[:1]
+		}
+		if s.Enum != nil && m.enum == nil {
+			m.enum = s.Enum
+		}
+		if s.Format != "" && m.format == "" {
+			m.format = s.Format
+		}
+		if s.Pattern != nil && m.pattern == nil {
+			m.pattern = s.Pattern
+		}
+		m.minLength = maxInt(m.minLength, s.MinLength)
+		m.maxLength = minInt(m.maxLength, s.MaxLength)
+		m.minProps = maxInt(m.minProps, s.MinProperties)
+		m.maxProps = minInt(m.maxProps, s.MaxProperties)
+		m.minItems = maxInt(m.minItems, s.MinItems)
+		m.maxItems = minInt(m.maxItems, s.MaxItems)
+		m.unique = m.unique || s.UniqueItems
+		if s.Minimum != nil && (m.minimum == nil || s.Minimum.Cmp(m.minimum) > 0) {
+			m.minimum, m.exclMin = s.Minimum, false
+		}
+		if s.ExclusiveMinimum != nil && (m.minimum == nil || s.ExclusiveMinimum.Cmp(m.minimum) >= 0) {
+			m.minimum, m.exclMin = s.ExclusiveMinimum, true
+		}
+		if s.Maximum != nil && (m.maximum == nil || s.Maximum.Cmp(m.maximum) < 0) {
+			m.maximum, m.exclMax = s.Maximum, false
+		}
+		if s.ExclusiveMaximum != nil && (m.maximum == nil || s.ExclusiveMaximum.Cmp(m.maximum) <= 0) {
+			m.maximum, m.exclMax = s.ExclusiveMaximum, true
+		}
+		if s.MultipleOf != nil && m.multipleOf == nil {
+			m.multipleOf = s.MultipleOf
+		}
+
+		for pname, psch := range s.Properties {
+			m.properties[pname] = append(m.properties[pname], psch)
+		}
+		for _, pname := range s.Required {
+			m.required[pname] = true
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case bool:
+			m.closed = m.closed || !additional
+		case *jsonschema.Schema:
+			m.additional = append(m.additional, additional)
+		}
+
+		prefix := s.PrefixItems
+		if prefix == nil {
+			prefix, _ = s.Items.([]*jsonschema.Schema)
+		}
+		for i, isch := range prefix {
+			if i == len(m.prefixItems) {
+				m.prefixItems = append(m.prefixItems, nil)
+			}
+			m.prefixItems[i] = append(m.prefixItems[i], isch)
+		}
+		if items, ok := s.Items.(*jsonschema.Schema); ok {
+			m.items = append(m.items, items)
+		}
+		if s.Items2020 != nil {
+			m.items = append(m.items, s.Items2020)
+		}
+		if additional, ok := s.AdditionalItems.(*jsonschema.Schema); ok && prefix != nil {
+			m.items = append(m.items, additional)
+		}
+
+		for _, list := range [][]*jsonschema.Schema{{s.Ref, s.RecursiveRef, s.DynamicRef}, s.AllOf} {
+			for _, sub := range list {
+				if err := add(sub); err != nil {
+					return err
+				}
+			}
+		}
+		for _, list := range [][]*jsonschema.Schema{s.AnyOf, s.OneOf} {
+			if len(list) > 0 {
+				if err := add(list[g.Rand.Intn(len(list))]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return m, add(sch)
+}
+
+func (g *Generator) generate(sch *jsonschema.Schema, depth int) (interface{}, error) {
+	if depth > 20*g.MaxDepth {
+		return nil, ErrUnsatisfiable
+	}
+	m, err := g.merge(sch)
+	if err != nil {
+		return nil, err
+	}
+	if m.constant != nil {
+		return m.constant[0], nil
+	}
+	if m.enum != nil {
+		return m.enum[g.Rand.Intn(len(m.enum))], nil
+	}
+
+	switch m.typ(g.Rand) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return g.Rand.Intn(2) == 0, nil
+	case "integer":
+		return m.number(g.Rand, true)
+	case "number":
+		return m.number(g.Rand, false)
+	case "string":
+		return g.string(m)
+	case "array":
+		return g.array(m, depth)
+	default:
+		return g.object(m, depth)
+	}
+}
+
+// typ returns the type of instance to be generated.
+func (m *merged) typ(r *rand.Rand) string {
+	if len(m.types) > 0 {
+		return m.types[r.Intn(len(m.types))]
+	}
+	switch {
+	case len(m.properties) > 0 || len(m.required) > 0 || m.minProps != -1 || m.additional != nil:
+		return "object"
+	case m.items != nil || m.prefixItems != nil || m.minItems != -1:
+		return "array"
+	case m.minimum != nil || m.maximum != nil || m.multipleOf != nil:
+		return "number"
+	}
+	return "string"
+}
+
+func (m *merged) number(r *rand.Rand, integer bool) (interface{}, error) {
+	step := m.multipleOf
+	if integer {
+		if step == nil {
+			step = big.NewRat(1, 1)
+		} else {
+			// least multiple of step, that is an integer
+			step = new(big.Rat).SetInt(step.Num())
+		}
+	}
+	if step == nil {
+		// any number in range
+		var n *big.Rat
+		switch {
+		case m.minimum != nil && m.maximum != nil:
+			n = new(big.Rat).Add(m.minimum, m.maximum)
+			n.Quo(n, big.NewRat(2, 1))
+		case m.minimum != nil:
+			n = new(big.Rat).Add(m.minimum, big.NewRat(int64(r.Intn(100)+1), 1))
+		case m.maximum != nil:
+			n = new(big.Rat).Sub(m.maximum, big.NewRat(int64(r.Intn(100)+1), 1))
+		default:
+			n = big.NewRat(int64(r.Intn(100)), 1)
+		}
+		return numberValue(n), nil
+	}
+
+	// n = k * step, where kmin <= k <= kmax
+	var kmin, kmax *big.Int
+	if m.minimum != nil {
+		q := new(big.Rat).Quo(m.minimum, step)
+		kmin = ceil(q)
+		if m.exclMin && q.IsInt() {
+			kmin.Add(kmin, big.NewInt(1))
+		}
+	}
+	if m.maximum != nil {
+		q := new(big.Rat).Quo(m.maximum, step)
+		kmax = floor(q)
+		if m.exclMax && q.IsInt() {
+			kmax.Sub(kmax, big.NewInt(1))
+		}
+	}
+	var k *big.Int
+	switch {
+	case kmin != nil && kmax != nil:
+		diff := new(big.Int).Sub(kmax, kmin)
+		if diff.Sign() < 0 {
+			return nil, ErrUnsatisfiable
+		}
+		if diff.Cmp(big.NewInt(100)) > 0 {
+			diff.SetInt64(100)
+		}
+		k = new(big.Int).Add(kmin, big.NewInt(r.Int63n(diff.Int64()+1)))
+	case kmin != nil:
+		k = new(big.Int).Add(kmin, big.NewInt(int64(r.Intn(10))))
+	case kmax != nil:
+		k = new(big.Int).Sub(kmax, big.NewInt(int64(r.Intn(10))))
+	default:
+		k = big.NewInt(int64(r.Intn(10) + 1))
+	}
+	n := new(big.Rat).Mul(new(big.Rat).SetInt(k), step)
+	return numberValue(n), nil
+}
+
+// numberValue returns n as json.Number.
+func numberValue(n *big.Rat) json.Number {
+	if n.IsInt() {
+		return json.Number(n.Num().String())
+	}
+	// number of decimal places, if decimal representation terminates
+	d := new(big.Int).Set(n.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	prec := 0
+	for zero := new(big.Int); ; prec++ {
+		if new(big.Int).Mod(d, two).Cmp(zero) == 0 {
+			d.Quo(d, two)
+		} else if new(big.Int).Mod(d, five).Cmp(zero) == 0 {
+			d.Quo(d, five)
+		} else {
+			break
+		}
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		prec = 16
+	}
+	return json.Number(n.FloatString(prec))
+}
+
+func floor(q *big.Rat) *big.Int {
+	z := new(big.Int)
+	z.Div(q.Num(), q.Denom()) // euclidean division rounds towards negative infinity, as denom > 0
+	return z
+}
+
+func ceil(q *big.Rat) *big.Int {
+	z := floor(q)
+	if !q.IsInt() {
+		z.Add(z, big.NewInt(1))
+	}
+	return z
+}
+
+// formats is the sample string of built-in formats.
+var formats = map[string]string{
+	"date-time":             "2006-01-02T15:04:05Z",
+	"date":                  "2006-01-02",
+	"time":                  "15:04:05Z",
+	"duration":              "P1DT2H",
+	"period":                "2006-01-02T15:04:05Z/P1D",
+	"hostname":              "example.com",
+	"idn-hostname":          "example.com",
+	"email":                 "user@example.com",
+	"idn-email":             "user@example.com",
+	"ip-address":            "192.168.0.1",
+	"ipv4":                  "192.168.0.1",
+	"ipv6":                  "2001:db8::1",
+	"uri":                   "https://example.com/path",
+	"iri":                   "https://example.com/path",
+	"uri-reference":         "/path",
+	"uriref":                "/path",
+	"iri-reference":         "/path",
+	"uri-template":          "https://example.com/{id}",
+	"regex":                 "^[a-z]+$",
+	"json-pointer":          "/path/0",
+	"relative-json-pointer": "1/path",
+	"uuid":                  "123e4567-e89b-12d3-a456-426614174000",
+}
+
+func (g *Generator) string(m *merged) (interface{}, error) {
+	if m.format != "" {
+		if s, ok := g.Formats[m.format]; ok {
+			return s, nil
+		}
+		if s, ok := formats[m.format]; ok {
+			return s, nil
+		}
+	}
+	min, max := m.minLength, m.maxLength
+	if min == -1 {
+		min = 0
+	}
+	if max != -1 && min > max {
+		return nil, ErrUnsatisfiable
+	}
+	if m.pattern != nil {
+		re, err := syntax.Parse(ecmaregex.ToGo(m.pattern.String()), syntax.Perl)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < 10; i++ {
+			var b strings.Builder
+			g.regexString(re.Simplify(), &b)
+			s := b.String()
+			if n := len([]rune(s)); n >= min && (max == -1 || n <= max) {
+				return s, nil
+			}
+		}
+		return nil, ErrUnsatisfiable
+	}
+	n := min
+	if n < 3 {
+		n = 3 + g.Rand.Intn(6)
+	}
+	if max != -1 && n > max {
+		n = max
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + g.Rand.Intn(26))
+	}
+	return string(b), nil
+}
+
+// regexString writes a string matching re to b.
+func (g *Generator) regexString(re *syntax.Regexp, b *strings.Builder) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteByte(byte('a' + g.Rand.Intn(26)))
+	case syntax.OpCapture:
+		g.regexString(re.Sub[0], b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexString(sub, b)
+		}
+	case syntax.OpAlternate:
+		g.regexString(re.Sub[g.Rand.Intn(len(re.Sub))], b)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, 3
+		case syntax.OpPlus:
+			min, max = 1, 3
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max == -1 {
+			max = min + 3
+		}
+		n := min + g.Rand.Intn(max-min+1)
+		for i := 0; i < n; i++ {
+			g.regexString(re.Sub[0], b)
+		}
+	}
+	// anchors, word boundaries and empty matches generate nothing
+}
+
+// classRune returns a rune in character class with given ranges,
+// preferring printable ascii.
+func (g *Generator) classRune(ranges []rune) rune {
+	var ascii [][2]rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			ascii = append(ascii, [2]rune{lo, hi})
+		}
+	}
+	if len(ascii) > 0 {
+		r := ascii[g.Rand.Intn(len(ascii))]
+		return r[0] + rune(g.Rand.Intn(int(r[1]-r[0]+1)))
+	}
+	if len(ranges) < 2 {
+		return 'a'
+	}
+	i := g.Rand.Intn(len(ranges)/2) * 2
+	lo, hi := ranges[i], ranges[i+1]
+	return lo + rune(g.Rand.Intn(int(hi-lo+1)))
+}
+
+func (g *Generator) array(m *merged, depth int) (interface{}, error) {
+	n := len(m.prefixItems)
+	if m.minItems > n {
+		n = m.minItems
+	}
+	if n == 0 && g.optional && depth < g.MaxDepth && m.maxItems != 0 {
+		n = 1 + g.Rand.Intn(2)
+	}
+	if m.maxItems != -1 && n > m.maxItems {
+		n = m.maxItems
+	}
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		schemas := m.items
+		if i < len(m.prefixItems) {
+			schemas = m.prefixItems[i]
+		}
+		var item interface{}
+		var err error
+		for try := 0; try < 10; try++ {
+			item, err = g.generateAll(schemas, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if !m.unique || !containsValue(arr, item) {
+				break
+			}
+		}
+		arr = append(arr, item)
+	}
+	return arr, nil
+}
+
+func (g *Generator) object(m *merged, depth int) (interface{}, error) {
+	obj := make(map[string]interface{})
+	var names []string
+	for pname := range m.properties {
+		names = append(names, pname)
+	}
+	for pname := range m.required {
+		if _, ok := m.properties[pname]; !ok {
+			names = append(names, pname)
+		}
+	}
+	sort.Strings(names)
+	optional := g.optional && depth < g.MaxDepth
+	for _, pname := range names {
+		if !m.required[pname] && !optional && len(obj) >= m.minProps {
+			continue
+		}
+		if m.maxProps != -1 && len(obj) >= m.maxProps {
+			break
+		}
+		schemas, ok := m.properties[pname]
+		if !ok {
+			schemas = m.additional
+		}
+		v, err := g.generateAll(schemas, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[pname] = v
+	}
+	for i := 1; len(obj) < m.minProps && !m.closed; i++ {
+		pname := fmt.Sprintf("property%d", i)
+		if _, ok := obj[pname]; ok {
+			continue
+		}
+		v, err := g.generateAll(m.additional, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[pname] = v
+	}
+	return obj, nil
+}
+
+// generateAll generates an instance valid against all schemas.
+func (g *Generator) generateAll(schemas []*jsonschema.Schema, depth int) (interface{}, error) {
+	switch len(schemas) {
+	case 0:
+		return "value", nil
+	case 1:
+		return g.generate(schemas[0], depth)
+	}
+	all := &jsonschema.Schema{
+		Location:      schemas[0].Location,
+		AllOf:         schemas,
+		MinLength:     -1,
+		MaxLength:     -1,
+		MinItems:      -1,
+		MaxItems:      -1,
+		MinProperties: -1,
+		MaxProperties: -1,
+	}
+	return g.generate(all, depth)
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = deepCopy(item)
+		}
+		return arr
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, pv := range v {
+			obj[k] = deepCopy(pv)
+		}
+		return obj
+	}
+	return v
+}
+
+func containsValue(arr []interface{}, v interface{}) bool {
+	b1, _ := json.Marshal(v)
+	for _, item := range arr {
+		if b2, _ := json.Marshal(item); string(b1) == string(b2) {
+			return true
+		}
+	}
+	return false
+}
+
+func intersect(a, b []string) []string {
+	var result []string
+	for _, t1 := range a {
+		for _, t2 := range b {
+			switch {
+			case t1 == t2:
+				result = append(result, t1)
+			case t1 == "number" && t2 == "integer", t1 == "integer" && t2 == "number":
+				result = append(result, "integer")
+			}
+		}
+	}
+	return result
+}
+
+func minInt(a, b int) int {
+	if a == -1 || (b != -1 && b < a) {
+		return b
+	}
+	return a
+}
+
+func maxInt(a, b int) int {
+	if b > a {
+		return b
+	}
+	return a
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
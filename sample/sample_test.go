@@ -0,0 +1,142 @@
+package sample_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/sample"
+)
+
+func TestGenerate(t *testing.T) {
+	schemas := map[string]string{
+		"types":       `{"type": ["null", "boolean", "integer", "number", "string", "array", "object"]}`,
+		"const":       `{"const": {"a": [1, 2]}}`,
+		"enum":        `{"enum": ["red", "green", 3]}`,
+		"string":      `{"type": "string", "minLength": 10, "maxLength": 12}`,
+		"pattern":     `{"type": "string", "pattern": "^[A-Z]{3}-\\d{4}(-[a-f]+)?$"}`,
+		"unicode":     `{"type": "string", "pattern": "^\\u00e9+$"}`,
+		"format":      `{"type": "string", "format": "email"}`,
+		"integer":     `{"type": "integer", "minimum": 5, "exclusiveMaximum": 8}`,
+		"multipleOf":  `{"type": "number", "multipleOf": 0.25, "exclusiveMinimum": 1, "maximum": 1.5}`,
+		"number":      `{"type": "number", "exclusiveMinimum": 0.1, "exclusiveMaximum": 0.2}`,
+		"intMultiple": `{"type": "integer", "multipleOf": 2.5, "minimum": 1}`,
+		"array":       `{"type": "array", "items": {"type": "integer", "maximum": 3, "minimum": 0}, "minItems": 3, "uniqueItems": true}`,
+		"prefixItems": `{"prefixItems": [{"type": "string"}, {"type": "boolean"}], "items": false}`,
+		"object": `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "minimum": 1},
+				"name": {"type": "string", "minLength": 1},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			},
+			"required": ["id", "name"],
+			"additionalProperties": false
+		}`,
+		"minProperties": `{"type": "object", "minProperties": 2, "additionalProperties": {"type": "boolean"}}`,
+		"allOf":         `{"allOf": [{"type": "object", "required": ["a"]}, {"properties": {"a": {"type": "string", "format": "date"}}}]}`,
+		"oneOf":         `{"oneOf": [{"type": "string", "maxLength": 2}, {"type": "integer"}]}`,
+		"recursive": `{
+			"$defs": {"node": {"type": "object", "properties": {"value": {"type": "integer"}, "next": {"$ref": "#/$defs/node"}}, "required": ["value"]}},
+			"$ref": "#/$defs/node"
+		}`,
+		"dependentRequired": `{"properties": {"a": {}, "b": {}}, "dependentRequired": {"a": ["c"]}}`,
+	}
+	for name, schema := range schemas {
+		t.Run(name, func(t *testing.T) {
+			sch, err := jsonschema.CompileString("https://example.com/schema.json", schema)
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			g := sample.NewGenerator(1)
+			for i := 0; i < 10; i++ {
+				v, err := g.Generate(sch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := sch.Validate(v); err != nil {
+					t.Fatalf("generated instance %v is invalid: %v", v, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	sch := jsonschema.MustCompileString("https://example.com/schema.json", `{
+		"type": "object",
+		"properties": {"a": {"type": "string"}, "b": {"type": "number"}}
+	}`)
+	v1, err := sample.NewGenerator(7).Generate(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := sample.NewGenerator(7).Generate(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v1, v2) {
+		t.Fatalf("generators with same seed generated %v and %v", v1, v2)
+	}
+}
+
+func TestGenerate_Unsatisfiable(t *testing.T) {
+	sch := jsonschema.MustCompileString("https://example.com/schema.json", `{"type": "string", "minLength": 5, "maxLength": 2}`)
+	if _, err := sample.NewGenerator(1).Generate(sch); !errors.Is(err, sample.ErrUnsatisfiable) {
+		t.Fatalf("got %v, want ErrUnsatisfiable", err)
+	}
+}
+
+func TestGenerate_Formats(t *testing.T) {
+	sch := jsonschema.MustCompileString("https://example.com/schema.json", `{"type": "string", "format": "sku"}`)
+	g := sample.NewGenerator(1)
+	g.Formats["sku"] = "SKU-1"
+	v, err := g.Generate(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "SKU-1" {
+		t.Fatalf("got %v, want SKU-1", v)
+	}
+}
+
+func TestMutants(t *testing.T) {
+	sch := jsonschema.MustCompileString("https://example.com/schema.json", `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "maximum": 100},
+			"name": {"type": "string", "maxLength": 20}
+		},
+		"required": ["id"],
+		"additionalProperties": false
+	}`)
+	mutants, err := sample.NewGenerator(1).Mutants(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	descs := make(map[string]bool)
+	for _, m := range mutants {
+		if err := sch.Validate(m.Value); err == nil {
+			b, _ := json.Marshal(m.Value)
+			t.Errorf("mutant %q is valid: %s", m.Description, b)
+		}
+		descs[m.Description] = true
+	}
+	for _, want := range []string{
+		"changed / to array",
+		"removed /id",
+		"added unexpected property to /",
+		"changed /id to large number",
+		"changed /id to fraction",
+		"changed /name to long string",
+	} {
+		if !descs[want] {
+			t.Errorf("mutant %q not found in %v", want, descs)
+		}
+	}
+	if descs["removed /name"] {
+		t.Error("removing optional property must not be mutant")
+	}
+}
@@ -2,15 +2,21 @@ package jsonschema
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"hash/maphash"
+	"math"
 	"math/big"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode/utf8"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
 )
 
 // A Schema represents compiled version of json-schema.
@@ -21,21 +27,23 @@ type Schema struct {
 	meta           *Schema
 	vocab          []string
 	dynamicAnchors []*Schema
+	anchors        []string
 
 	// type agnostic validations
 	Format           string
 	format           func(interface{}) bool
-	Always           *bool // always pass/fail. used when booleans are used as schemas in draft-07.
+	Always           *bool // always pass/fail. used when booleans are used as schemas, from draft6. see Boolean.
 	Ref              *Schema
 	RecursiveAnchor  bool
 	RecursiveRef     *Schema
 	DynamicAnchor    string
 	DynamicRef       *Schema
 	dynamicRefAnchor string
-	Types            []string      // allowed types.
-	Constant         []interface{} // first element in slice is constant value. note: slice is used to capture nil constant.
-	Enum             []interface{} // allowed values.
-	enumError        string        // error message for enum fail. captured here to avoid constructing error message every time.
+	Types            []string          // allowed types.
+	Constant         []interface{}     // first element in slice is constant value. note: slice is used to capture nil constant.
+	Enum             []interface{}     // allowed values.
+	enumError        string            // error message for enum fail. captured here to avoid constructing error message every time.
+	data             map[string]string // keyword to $data pointer. used only if Compiler.AllowData is true.
 	Not              *Schema
 	AllOf            []*Schema
 	AnyOf            []*Schema
@@ -98,15 +106,64 @@ type Schema struct {
 	WriteOnly   bool
 	Examples    []interface{}
 	Deprecated  bool
+	unknown     map[string]interface{} // unknown keywords
 
 	// user defined extensions
 	Extensions map[string]ExtSchema
+
+	untracked     bool                                                 // evaluated properties and items need not be tracked, when validating against this schema
+	errorMessage  *errorMessage                                        // used only if Compiler.AllowErrorMessage is true
+	discriminator *discriminator                                       // used only if Compiler.AllowDiscriminator is true
+	translator    func(ve *ValidationError) string                     // Compiler.Translator
+	errorValue    func(ve *ValidationError, v interface{}) interface{} // Compiler.ErrorValue
+	maxDepth      int                                                  // Compiler.Limits.MaxValidationDepth
+	memo          int32                                                // whether results can be memoized. 0 if not computed. see memoizable
+	severities    map[string]Severity                                  // Compiler.Severities
+	empty         bool                                                 // whether schema is {}, which is equivalent to true
+	keys          schemaKeys                                           // sorted keys of keywords keyed by name, computed at compile time
+}
+
+// schemaKeys holds keys of the keywords keyed by name, in sorted order.
+// these keywords are evaluated in this order, so that order of errors does
+// not depend on map iteration.
+type schemaKeys struct {
+	properties        []string
+	patterns          []Regexp
+	dependencies      []string
+	dependentRequired []string
+	dependentSchemas  []string
+	extensions        []string
+}
+
+// sortKeys computes s.keys. It must be called once s is compiled.
+func (s *Schema) sortKeys() {
+	s.keys = schemaKeys{
+		properties:        sortedSchemaKeys(s.Properties),
+		patterns:          sortedPatterns(s.PatternProperties),
+		dependencies:      sortedKeys(s.Dependencies),
+		dependentRequired: sortedRequiredKeys(s.DependentRequired),
+		dependentSchemas:  sortedSchemaKeys(s.DependentSchemas),
+		extensions:        sortedExtensionKeys(s.Extensions),
+	}
 }
 
 func (s *Schema) String() string {
 	return s.Location
 }
 
+// Boolean tells whether s is a boolean schema, i.e. true or false, and
+// returns its value. The empty schema {} is reported as true, since it
+// accepts every instance like true.
+//
+// Note that boolean value of AdditionalProperties and AdditionalItems
+// is not compiled into Schema.
+func (s *Schema) Boolean() (value, ok bool) {
+	if s.Always != nil {
+		return *s.Always, true
+	}
+	return s.empty, s.empty
+}
+
 func newSchema(url, floc string, draft *Draft, doc interface{}) *Schema {
 	// fill with default values
 	s := &Schema{
@@ -120,6 +177,7 @@ func newSchema(url, floc string, draft *Draft, doc interface{}) *Schema {
 		MaxContains:   -1,
 		MinLength:     -1,
 		MaxLength:     -1,
+		anchors:       draft.anchors(doc),
 	}
 
 	if doc, ok := doc.(map[string]interface{}); ok {
@@ -155,105 +213,278 @@ func (s *Schema) hasVocab(name string) bool {
 	return false
 }
 
+// hasVocabURL tells whether vocab with given url is enabled in meta-schema s.
+func (s *Schema) hasVocabURL(url string) bool {
+	return s != nil && contains(s.vocab, url)
+}
+
 // Validate validates given doc, against the json-schema s.
 //
 // the v must be the raw json value. for number precision
-// unmarshal with json.UseNumber().
+// unmarshal with json.UseNumber(), or use DecodeJSON. numbers of type
+// json.Number, *big.Int, *big.Float and *big.Rat are validated without
+// loss of precision.
 //
 // returns *ValidationError if v does not confirm with schema s.
 // returns InfiniteLoopError if it detects loop during validation.
+// returns *LimitExceededError if Compiler.Limits.MaxValidationDepth is exceeded.
 // returns InvalidJSONTypeError if it detects any non json value in v.
 func (s *Schema) Validate(v interface{}) (err error) {
-	return s.validateValue(v, "")
+	vd := newValidator(context.Background())
+	defer vd.release()
+	return s.validateValue(vd, v, "")
+}
+
+// Valid tells whether v is valid against the schema s.
+//
+// It is faster than Validate, because it skips building of error messages
+// and stops evaluation of a schema at its first failing keyword. Use
+// Validate to know why v is invalid.
+func (s *Schema) Valid(v interface{}) bool {
+	vd := newValidator(context.Background())
+	defer vd.release()
+	vd.quick = true
+	return s.validateValue(vd, v, "") == nil
+}
+
+// ValidateOptions configures Schema.ValidateWithOptions.
+type ValidateOptions struct {
+	// MaxErrors limits the number of leaf errors in the error tree to
+	// given number. Schemas stop evaluating their keywords once they reported
+	// that many errors. zero means no limit.
+	MaxErrors int
+
+	// FailFast stops evaluating a schema at its first failing keyword, and
+	// reports only the first leaf error. Unlike Schema.Valid, the error is
+	// still structured with locations and message.
+	FailFast bool
+
+	// Mode enforces readOnly and writeOnly, for instances sent in given
+	// direction. In ModeRequest values with readOnly schema are reported
+	// as errors, and in ModeResponse values with writeOnly schema. Use
+	// Schema.ValidateAndStrip to remove such properties instead.
+	//
+	// NOTE: readOnly and writeOnly are available only if
+	// Compiler.ExtractAnnotations is true.
+	Mode Mode
+
+	// MaxDepth is the maximum nesting of arrays and objects in the
+	// instance. For example, [[1]] has depth 2.
+	MaxDepth int
+
+	// MaxUniqueItems is the maximum length of array, checked
+	// by "uniqueItems".
+	MaxUniqueItems int
+
+	// MaxPatternLength is the maximum length in bytes of string, matched
+	// by "pattern", and of property name, matched by "patternProperties".
+	//
+	// These limits guard against attacker-controlled instances pinning CPU.
+	// zero means no limit. If exceeded, *LimitExceededError is returned.
+	MaxPatternLength int
+
+	// Memoize caches results of validating an instance fragment against a
+	// subschema, within the validation. This helps schemas which validate
+	// the same subtree many times, such as allOf or anyOf with branches
+	// referring to the same definitions. Schemas using $recursiveRef,
+	// $dynamicRef, $data or extensions are not memoized.
+	Memoize bool
+
+	// Cache, if not nil, caches validity of instances across calls, keyed
+	// by content of the instance. see ResultCache.
+	Cache *ResultCache
+
+	// Profile, if not nil, accumulates number of evaluations and time
+	// spent in each schema. see Profile.
+	Profile *Profile
+}
+
+// ValidateWithOptions is like Validate, but with given opts.
+//
+// This is useful to cap the size of the error tree when validating huge
+// instances, such as an array with many invalid items.
+func (s *Schema) ValidateWithOptions(v interface{}, opts ValidateOptions) error {
+	return s.validateWithOptions(v, opts, nil)
+}
+
+// validateWithOptions is ValidateWithOptions, which also reports evaluation
+// of each schema to hook, if not nil.
+func (s *Schema) validateWithOptions(v interface{}, opts ValidateOptions, hook Hook) error {
+	vd := newValidator(context.Background())
+	defer vd.release()
+	vd.failFast, vd.maxErrors, vd.mode = opts.FailFast, opts.MaxErrors, opts.Mode
+	if opts.FailFast {
+		vd.maxErrors = 1
+	}
+	vd.maxUnique, vd.maxPattern = opts.MaxUniqueItems, opts.MaxPatternLength
+	if opts.MaxDepth > 0 {
+		if loc, ok := exceedsDepth(v, opts.MaxDepth); ok {
+			return &LimitExceededError{Limit: "MaxDepth", Max: opts.MaxDepth, URL: s.Location, InstanceLocation: loc}
+		}
+	}
+	if opts.Memoize && s.memoizable() {
+		vd.memo = make(map[memoKey]validationResult)
+	}
+	var key cacheKey
+	if opts.Cache != nil {
+		var err error
+		if key, err = opts.Cache.key(s, opts.Mode, v); err != nil {
+			return err
+		}
+		if valid, ok := opts.Cache.get(key); ok && valid {
+			return nil
+		}
+	}
+	vd.hook = hook
+	var prof *profiler
+	if opts.Profile != nil {
+		prof = newProfiler()
+		if hook != nil {
+			vd.hook = hooks{prof, hook}
+		} else {
+			vd.hook = prof
+		}
+	}
+	err := s.validateValue(vd, v, "")
+	if prof != nil {
+		opts.Profile.merge(prof.stats)
+	}
+	if ve, ok := err.(*ValidationError); ok && vd.maxErrors > 0 {
+		ve.limit(vd.maxErrors)
+	}
+	if opts.Cache != nil {
+		if _, ok := err.(*ValidationError); ok || err == nil {
+			opts.Cache.put(key, err == nil)
+		}
+	}
+	return err
 }
 
-func (s *Schema) validateValue(v interface{}, vloc string) (err error) {
+// ValidateContext is like Validate, but validation is aborted when ctx
+// is done. The ctx is checked periodically during validation.
+//
+// returns ctx.Err() if validation is aborted.
+func (s *Schema) ValidateContext(ctx context.Context, v interface{}) error {
+	vd := newValidator(ctx)
+	defer vd.release()
+	return s.validateValue(vd, v, "")
+}
+
+func (s *Schema) validateValue(vd *validator, v interface{}, vloc string) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			switch r := r.(type) {
-			case InfiniteLoopError, InvalidJSONTypeError:
+			case InfiniteLoopError, InvalidJSONTypeError, *LimitExceededError:
 				err = r.(error)
+			case abortError:
+				err = r.err
 			default:
 				panic(r)
 			}
 		}
 	}()
-	if _, err := s.validate(nil, 0, "", v, vloc); err != nil {
+	vd.root, vd.rootLoc = v, vloc
+	vd.track = !s.untracked
+	if _, err := s.validate(vd, vd.scope, 0, schemaRef{}, v); err != nil {
+		if vd.quick {
+			return err
+		}
 		ve := ValidationError{
 			KeywordLocation:         "",
 			AbsoluteKeywordLocation: s.Location,
 			InstanceLocation:        vloc,
 			Message:                 fmt.Sprintf("doesn't validate with %s", s.Location),
 		}
-		return ve.causes(err)
+		if s.errorValue != nil {
+			ve.Value = s.errorValue(&ve, v)
+		}
+		err = ve.causes(err)
+		if s.translator != nil {
+			ve.translate(s.translator)
+		}
+		return err
 	}
 	return nil
 }
 
 // validate validates given value v with this schema.
-func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interface{}, vloc string) (result validationResult, err error) {
-	validationError := func(keywordPath string, format string, a ...interface{}) *ValidationError {
-		return &ValidationError{
-			KeywordLocation:         keywordLocation(scope, keywordPath),
-			AbsoluteKeywordLocation: joinPtr(s.Location, keywordPath),
-			InstanceLocation:        vloc,
-			Message:                 fmt.Sprintf(format, a...),
-		}
-	}
+//
+// sref is the reference to s from last schema in scope.
+func (s *Schema) validate(vd *validator, scope []schemaRef, vscope int, sref schemaRef, v interface{}) (res validationResult, err error) {
+	vd.checkDone()
 
-	sref := schemaRef{spath, s, false}
+	sref.schema = s
 	if err := checkLoop(scope[len(scope)-vscope:], sref); err != nil {
 		panic(err)
 	}
-	scope = append(scope, sref)
-	vscope++
-
-	// populate result
-	switch v := v.(type) {
-	case map[string]interface{}:
-		result.unevalProps = make(map[string]struct{})
-		for pname := range v {
-			result.unevalProps[pname] = struct{}{}
+	if s.maxDepth > 0 && len(scope) >= s.maxDepth {
+		panic(&LimitExceededError{Limit: "MaxValidationDepth", Max: s.maxDepth, URL: s.Location})
+	}
+	if !vd.track && vd.hook == nil {
+		// fast path for boolean schemas
+		if value, ok := s.Boolean(); ok {
+			if value {
+				return res, nil
+			}
+			e := evaluation{vd: vd, s: s, scope: append(scope, sref), vscope: vscope + 1, v: v}
+			return res, e.falseError()
 		}
-	case []interface{}:
-		result.unevalItems = make(map[int]struct{})
-		for i := range v {
-			result.unevalItems[i] = struct{}{}
+	}
+	if vd.memo != nil {
+		if key, ok := newMemoKey(s, v); ok {
+			if res, ok := vd.memo[key]; ok {
+				return res, nil
+			}
+			defer func() {
+				if err == nil {
+					vd.memo[key] = res
+				}
+			}()
 		}
 	}
+	scope = append(scope, sref)
+	vscope++
 
-	validate := func(sch *Schema, schPath string, v interface{}, vpath string) error {
-		vloc := vloc
-		if vpath != "" {
-			vloc += "/" + vpath
-		}
-		_, err := sch.validate(scope, 0, schPath, v, vloc)
-		return err
+	if vd.hook != nil {
+		e := vd.enter(scope, s, v)
+		defer func() { vd.hook.OnKeywordExit(e, err) }()
 	}
 
-	validateInplace := func(sch *Schema, schPath string) error {
-		vr, err := sch.validate(scope, vscope, schPath, v, vloc)
-		if err == nil {
-			// update result
-			for pname := range result.unevalProps {
-				if _, ok := vr.unevalProps[pname]; !ok {
-					delete(result.unevalProps, pname)
-				}
+	e := evaluation{vd: vd, s: s, scope: scope, vscope: vscope, v: v, mark: len(vd.annotations)}
+
+	// populate result
+	if vd.track {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			e.result.unevalProps = make(map[string]struct{})
+			for pname := range v {
+				e.result.unevalProps[pname] = struct{}{}
 			}
-			for i := range result.unevalItems {
-				if _, ok := vr.unevalItems[i]; !ok {
-					delete(result.unevalItems, i)
-				}
+		case []interface{}:
+			e.result.unevalItems = make(map[int]struct{})
+			for i := range v {
+				e.result.unevalItems[i] = struct{}{}
 			}
 		}
-		return err
+	}
+
+	if len(s.data) > 0 {
+		sch, kw, err := s.resolveData(vd, vd.instanceLocation(scope))
+		if err != nil {
+			return e.result, e.validationError(kw, "%v", err)
+		}
+		s, e.s = sch, sch
 	}
 
 	if s.Always != nil {
 		if !*s.Always {
-			return result, validationError("", "not allowed")
+			return e.result, e.falseError()
 		}
-		return result, nil
+		return e.result, nil
+	}
+
+	if n, ok := v.(json.Number); ok && !isRatSupported(n) {
+		return e.result, e.validationError("", "number %s is out of supported range", n)
 	}
 
 	if len(s.Types) > 0 {
@@ -264,15 +495,23 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 				matched = true
 				break
 			} else if t == "integer" && vType == "number" {
-				num, _ := new(big.Rat).SetString(fmt.Sprint(v))
-				if num.IsInt() {
+				if _, ok := int64Value(v); ok {
+					matched = true
+					break
+				}
+				if ratValue(v).IsInt() {
 					matched = true
 					break
 				}
 			}
 		}
 		if !matched {
-			return result, validationError("type", "expected %s, but got %s", strings.Join(s.Types, " or "), vType)
+			err := e.validationError("type", "expected %s, but got %s", strings.Join(s.Types, " or "), vType).values(s.Types, vType)
+			if s.errorMessage != nil && !vd.quick {
+				ec := e // copy, so that e does not escape
+				return e.result, s.errorMessage.apply(s, []error{err}, v, vd.instanceLocation(scope), ec.validationError)[0]
+			}
+			return e.result, err
 		}
 	}
 
@@ -282,9 +521,13 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 		if !equals(v, s.Constant[0]) {
 			switch jsonType(s.Constant[0]) {
 			case "object", "array":
-				errors = append(errors, validationError("const", "const failed"))
+				errors = append(errors, e.validationError("const", "const failed").values(s.Constant[0], v))
 			default:
-				errors = append(errors, validationError("const", "value must be %#v", s.Constant[0]))
+				ve := e.validationError("const", "value must be %#v", s.Constant[0]).values(s.Constant[0], v)
+				if str, ok := v.(string); ok && !vd.quick {
+					e.suggest(ve, str, s.Constant)
+				}
+				errors = append(errors, ve)
 			}
 		}
 	}
@@ -298,117 +541,208 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 			}
 		}
 		if !matched {
-			errors = append(errors, validationError("enum", s.enumError))
+			ve := e.validationError("enum", "%s", s.enumError).values(s.Enum, v)
+			if str, ok := v.(string); ok && !vd.quick {
+				e.suggest(ve, str, s.Enum)
+			}
+			errors = append(errors, ve)
 		}
 	}
 
+	switch {
+	case vd.mode == ModeRequest && s.ReadOnly:
+		errors = append(errors, e.validationError("readOnly", "readOnly value is not allowed in request").values(true, v))
+	case vd.mode == ModeResponse && s.WriteOnly:
+		errors = append(errors, e.validationError("writeOnly", "writeOnly value is not allowed in response").values(true, v))
+	}
+
 	if s.format != nil && !s.format(v) {
 		var val = v
 		if v, ok := v.(string); ok {
 			val = quote(v)
 		}
-		errors = append(errors, validationError("format", "%v is not valid %s", val, quote(s.Format)))
+		errors = e.report(errors, "format", e.validationError("format", "%v is not valid %s", val, quote(s.Format)).values(s.Format, v))
+	}
+
+	if s.Deprecated {
+		if _, ok := s.severities["deprecated"]; ok {
+			errors = e.report(errors, "deprecated", e.validationError("deprecated", "value is deprecated").values(true, v))
+		}
 	}
 
 	switch v := v.(type) {
 	case map[string]interface{}:
 		if s.MinProperties != -1 && len(v) < s.MinProperties {
-			errors = append(errors, validationError("minProperties", "minimum %d properties allowed, but found %d properties", s.MinProperties, len(v)))
+			errors = append(errors, e.validationError("minProperties", "minimum %d properties allowed, but found %d properties", s.MinProperties, len(v)).values(s.MinProperties, len(v)))
 		}
 		if s.MaxProperties != -1 && len(v) > s.MaxProperties {
-			errors = append(errors, validationError("maxProperties", "maximum %d properties allowed, but found %d properties", s.MaxProperties, len(v)))
+			errors = append(errors, e.validationError("maxProperties", "maximum %d properties allowed, but found %d properties", s.MaxProperties, len(v)).values(s.MaxProperties, len(v)))
 		}
 		if len(s.Required) > 0 {
 			var missing []string
 			for _, pname := range s.Required {
 				if _, ok := v[pname]; !ok {
-					missing = append(missing, quote(pname))
+					if vd.mode != ModeNone && s.Properties[pname].excluded(vd.mode, nil) {
+						continue // not allowed in this mode
+					}
+					missing = append(missing, pname)
 				}
 			}
 			if len(missing) > 0 {
-				errors = append(errors, validationError("required", "missing properties: %s", strings.Join(missing, ", ")))
+				quoted := make([]string, len(missing))
+				for i, pname := range missing {
+					quoted[i] = quote(pname)
+				}
+				errors = append(errors, e.validationError("required", "missing properties: %s", strings.Join(quoted, ", ")).values(s.Required, missing))
 			}
 		}
 
-		for pname, sch := range s.Properties {
+		for _, pname := range s.keys.properties {
+			if vd.enough(errors) {
+				break
+			}
 			if pvalue, ok := v[pname]; ok {
-				delete(result.unevalProps, pname)
-				if err := validate(sch, "properties/"+escape(pname), pvalue, escape(pname)); err != nil {
+				sch := s.Properties[pname]
+				delete(e.result.unevalProps, pname)
+				if err := e.validate(sch, "properties", escape(pname), pvalue, jsonpointer.Escape(pname)); err != nil {
 					errors = append(errors, err)
 				}
 			}
 		}
 
+		// properties of v are visited in map order, to avoid sorting them.
+		// errors reported are sorted instead, using sortErrors
 		if s.PropertyNames != nil {
+			start := len(errors)
 			for pname := range v {
-				if err := validate(s.PropertyNames, "propertyNames", pname, escape(pname)); err != nil {
+				if err := e.validate(s.PropertyNames, "propertyNames", "", pname, jsonpointer.Escape(pname)); err != nil {
+					if !vd.quick {
+						ve := e.validationError("propertyNames", "invalid property name %s", quote(pname)).values(nil, pname)
+						ve.InstanceLocation += "/" + jsonpointer.Escape(pname)
+						err = ve.add(err)
+						ve.markPropertyName()
+					}
 					errors = append(errors, err)
 				}
 			}
+			sortErrors(errors[start:])
 		}
 
 		if s.RegexProperties {
+			var invalid []string
 			for pname := range v {
 				if !isRegex(pname) {
-					errors = append(errors, validationError("", "patternProperty %s is not valid regex", quote(pname)))
+					invalid = append(invalid, pname)
 				}
 			}
+			sort.Strings(invalid)
+			for _, pname := range invalid {
+				errors = append(errors, e.validationError("", "patternProperty %s is not valid regex", quote(pname)))
+			}
 		}
-		for pattern, sch := range s.PatternProperties {
+		for _, pattern := range s.keys.patterns {
+			sch := s.PatternProperties[pattern]
+			start := len(errors)
 			for pname, pvalue := range v {
+				if vd.maxPattern > 0 && len(pname) > vd.maxPattern {
+					e.limitExceeded("MaxPatternLength", vd.maxPattern, "patternProperties")
+				}
 				if pattern.MatchString(pname) {
-					delete(result.unevalProps, pname)
-					if err := validate(sch, "patternProperties/"+escape(pattern.String()), pvalue, escape(pname)); err != nil {
+					delete(e.result.unevalProps, pname)
+					if err := e.validate(sch, "patternProperties", escape(pattern.String()), pvalue, jsonpointer.Escape(pname)); err != nil {
 						errors = append(errors, err)
 					}
 				}
 			}
+			sortErrors(errors[start:])
 		}
 		if s.AdditionalProperties != nil {
+			additional := func(pname string) bool {
+				if vd.track {
+					_, ok := e.result.unevalProps[pname]
+					return ok
+				}
+				return s.isAdditional(pname)
+			}
 			if allowed, ok := s.AdditionalProperties.(bool); ok {
-				if !allowed && len(result.unevalProps) > 0 {
-					errors = append(errors, validationError("additionalProperties", "additionalProperties %s not allowed", result.unevalPnames()))
+				if !allowed {
+					var pnames []string
+					for pname := range v {
+						if additional(pname) {
+							pnames = append(pnames, pname)
+						}
+					}
+					if len(pnames) > 0 {
+						sort.Strings(pnames)
+						quoted := make([]string, len(pnames))
+						for i, pname := range pnames {
+							quoted[i] = quote(pname)
+						}
+						ve := e.validationError("additionalProperties", "additionalProperties %s not allowed", strings.Join(quoted, ", ")).values(false, pnames)
+						if !vd.quick {
+							e.additionalCauses(ve, pnames)
+						}
+						errors = append(errors, ve)
+					}
 				}
 			} else {
 				schema := s.AdditionalProperties.(*Schema)
-				for pname := range result.unevalProps {
-					if pvalue, ok := v[pname]; ok {
-						if err := validate(schema, "additionalProperties", pvalue, escape(pname)); err != nil {
+				check := func(pname string, pvalue interface{}) {
+					if additional(pname) {
+						if err := e.validate(schema, "additionalProperties", "", pvalue, jsonpointer.Escape(pname)); err != nil {
 							errors = append(errors, err)
 						}
 					}
 				}
+				if vd.failFast || vd.maxErrors > 0 {
+					// evaluation stops at first errors. visit in sorted order, so that same errors are reported
+					for _, pname := range sortedKeys(v) {
+						if vd.enough(errors) {
+							break
+						}
+						check(pname, v[pname])
+					}
+				} else {
+					start := len(errors)
+					for pname, pvalue := range v {
+						if vd.enough(errors) {
+							break
+						}
+						check(pname, pvalue)
+					}
+					sortErrors(errors[start:])
+				}
 			}
-			result.unevalProps = nil
+			e.result.unevalProps = nil
 		}
-		for dname, dvalue := range s.Dependencies {
+		for _, dname := range s.keys.dependencies {
 			if _, ok := v[dname]; ok {
-				switch dvalue := dvalue.(type) {
+				switch dvalue := s.Dependencies[dname].(type) {
 				case *Schema:
-					if err := validateInplace(dvalue, "dependencies/"+escape(dname)); err != nil {
+					if err := e.validateInplace(dvalue, "dependencies", escape(dname)); err != nil {
 						errors = append(errors, err)
 					}
 				case []string:
 					for i, pname := range dvalue {
 						if _, ok := v[pname]; !ok {
-							errors = append(errors, validationError("dependencies/"+escape(dname)+"/"+strconv.Itoa(i), "property %s is required, if %s property exists", quote(pname), quote(dname)))
+							errors = append(errors, e.validationError("dependencies/"+escape(dname)+"/"+strconv.Itoa(i), "property %s is required, if %s property exists", quote(pname), quote(dname)).values(pname, dname))
 						}
 					}
 				}
 			}
 		}
-		for dname, dvalue := range s.DependentRequired {
+		for _, dname := range s.keys.dependentRequired {
 			if _, ok := v[dname]; ok {
-				for i, pname := range dvalue {
+				for i, pname := range s.DependentRequired[dname] {
 					if _, ok := v[pname]; !ok {
-						errors = append(errors, validationError("dependentRequired/"+escape(dname)+"/"+strconv.Itoa(i), "property %s is required, if %s property exists", quote(pname), quote(dname)))
+						errors = append(errors, e.validationError("dependentRequired/"+escape(dname)+"/"+strconv.Itoa(i), "property %s is required, if %s property exists", quote(pname), quote(dname)).values(pname, dname))
 					}
 				}
 			}
 		}
-		for dname, sch := range s.DependentSchemas {
+		for _, dname := range s.keys.dependentSchemas {
 			if _, ok := v[dname]; ok {
-				if err := validateInplace(sch, "dependentSchemas/"+escape(dname)); err != nil {
+				if err := e.validateInplace(s.DependentSchemas[dname], "dependentSchemas", escape(dname)); err != nil {
 					errors = append(errors, err)
 				}
 			}
@@ -416,18 +750,21 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 
 	case []interface{}:
 		if s.MinItems != -1 && len(v) < s.MinItems {
-			errors = append(errors, validationError("minItems", "minimum %d items required, but found %d items", s.MinItems, len(v)))
+			errors = append(errors, e.validationError("minItems", "minimum %d items required, but found %d items", s.MinItems, len(v)).values(s.MinItems, len(v)))
 		}
 		if s.MaxItems != -1 && len(v) > s.MaxItems {
-			errors = append(errors, validationError("maxItems", "maximum %d items required, but found %d items", s.MaxItems, len(v)))
+			errors = append(errors, e.validationError("maxItems", "maximum %d items required, but found %d items", s.MaxItems, len(v)).values(s.MaxItems, len(v)))
 		}
 		if s.UniqueItems {
+			if max := vd.maxUnique; max > 0 && len(v) > max {
+				e.limitExceeded("MaxUniqueItems", max, "uniqueItems")
+			}
 			if len(v) <= 20 {
 			outer1:
 				for i := 1; i < len(v); i++ {
 					for j := 0; j < i; j++ {
 						if equals(v[i], v[j]) {
-							errors = append(errors, validationError("uniqueItems", "items at index %d and %d are equal", j, i))
+							errors = append(errors, e.validationError("uniqueItems", "items at index %d and %d are equal", j, i).values(true, []int{j, i}))
 							break outer1
 						}
 					}
@@ -440,14 +777,11 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 					h.Reset()
 					hash(item, &h)
 					k := h.Sum64()
-					if err != nil {
-						panic(err)
-					}
 					arr, ok := m[k]
 					if ok {
 						for _, j := range arr {
 							if equals(v[j], item) {
-								errors = append(errors, validationError("uniqueItems", "items at index %d and %d are equal", j, i))
+								errors = append(errors, e.validationError("uniqueItems", "items at index %d and %d are equal", j, i).values(true, []int{j, i}))
 								break outer2
 							}
 						}
@@ -462,21 +796,27 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 		switch items := s.Items.(type) {
 		case *Schema:
 			for i, item := range v {
-				if err := validate(items, "items", item, strconv.Itoa(i)); err != nil {
+				if vd.enough(errors) {
+					break
+				}
+				if err := e.validate(items, "items", "", item, strconv.Itoa(i)); err != nil {
 					errors = append(errors, err)
 				}
 			}
-			result.unevalItems = nil
+			e.result.unevalItems = nil
 		case []*Schema:
 			for i, item := range v {
+				if vd.enough(errors) {
+					break
+				}
 				if i < len(items) {
-					delete(result.unevalItems, i)
-					if err := validate(items[i], "items/"+strconv.Itoa(i), item, strconv.Itoa(i)); err != nil {
+					delete(e.result.unevalItems, i)
+					if err := e.validate(items[i], "items", strconv.Itoa(i), item, strconv.Itoa(i)); err != nil {
 						errors = append(errors, err)
 					}
 				} else if sch, ok := s.AdditionalItems.(*Schema); ok {
-					delete(result.unevalItems, i)
-					if err := validate(sch, "additionalItems", item, strconv.Itoa(i)); err != nil {
+					delete(e.result.unevalItems, i)
+					if err := e.validate(sch, "additionalItems", "", item, strconv.Itoa(i)); err != nil {
 						errors = append(errors, err)
 					}
 				} else {
@@ -485,23 +825,26 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 			}
 			if additionalItems, ok := s.AdditionalItems.(bool); ok {
 				if additionalItems {
-					result.unevalItems = nil
+					e.result.unevalItems = nil
 				} else if len(v) > len(items) {
-					errors = append(errors, validationError("additionalItems", "only %d items are allowed, but found %d items", len(items), len(v)))
+					errors = append(errors, e.validationError("additionalItems", "only %d items are allowed, but found %d items", len(items), len(v)).values(len(items), len(v)))
 				}
 			}
 		}
 
 		// prefixItems + items
 		for i, item := range v {
+			if vd.enough(errors) {
+				break
+			}
 			if i < len(s.PrefixItems) {
-				delete(result.unevalItems, i)
-				if err := validate(s.PrefixItems[i], "prefixItems/"+strconv.Itoa(i), item, strconv.Itoa(i)); err != nil {
+				delete(e.result.unevalItems, i)
+				if err := e.validate(s.PrefixItems[i], "prefixItems", strconv.Itoa(i), item, strconv.Itoa(i)); err != nil {
 					errors = append(errors, err)
 				}
 			} else if s.Items2020 != nil {
-				delete(result.unevalItems, i)
-				if err := validate(s.Items2020, "items", item, strconv.Itoa(i)); err != nil {
+				delete(e.result.unevalItems, i)
+				if err := e.validate(s.Items2020, "items", "", item, strconv.Itoa(i)); err != nil {
 					errors = append(errors, err)
 				}
 			} else {
@@ -514,37 +857,40 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 			matched := 0
 			var causes []error
 			for i, item := range v {
-				if err := validate(s.Contains, "contains", item, strconv.Itoa(i)); err != nil {
+				if err := e.validate(s.Contains, "contains", "", item, strconv.Itoa(i)); err != nil {
 					causes = append(causes, err)
 				} else {
 					matched++
 					if s.ContainsEval {
-						delete(result.unevalItems, i)
+						delete(e.result.unevalItems, i)
 					}
 				}
 			}
 			if s.MinContains != -1 && matched < s.MinContains {
-				errors = append(errors, validationError("minContains", "valid must be >= %d, but got %d", s.MinContains, matched).add(causes...))
+				errors = append(errors, e.validationError("minContains", "valid must be >= %d, but got %d", s.MinContains, matched).values(s.MinContains, matched).add(causes...))
 			}
 			if s.MaxContains != -1 && matched > s.MaxContains {
-				errors = append(errors, validationError("maxContains", "valid must be <= %d, but got %d", s.MaxContains, matched))
+				errors = append(errors, e.validationError("maxContains", "valid must be <= %d, but got %d", s.MaxContains, matched).values(s.MaxContains, matched))
 			}
 		}
 
 	case string:
 		// minLength + maxLength
 		if s.MinLength != -1 || s.MaxLength != -1 {
-			length := utf8.RuneCount([]byte(v))
+			length := utf8.RuneCountInString(v)
 			if s.MinLength != -1 && length < s.MinLength {
-				errors = append(errors, validationError("minLength", "length must be >= %d, but got %d", s.MinLength, length))
+				errors = append(errors, e.validationError("minLength", "length must be >= %d, but got %d", s.MinLength, length).values(s.MinLength, length))
 			}
 			if s.MaxLength != -1 && length > s.MaxLength {
-				errors = append(errors, validationError("maxLength", "length must be <= %d, but got %d", s.MaxLength, length))
+				errors = append(errors, e.validationError("maxLength", "length must be <= %d, but got %d", s.MaxLength, length).values(s.MaxLength, length))
 			}
 		}
 
+		if s.Pattern != nil && vd.maxPattern > 0 && len(v) > vd.maxPattern {
+			e.limitExceeded("MaxPatternLength", vd.maxPattern, "pattern")
+		}
 		if s.Pattern != nil && !s.Pattern.MatchString(v) {
-			errors = append(errors, validationError("pattern", "does not match pattern %s", quote(s.Pattern.String())))
+			errors = append(errors, e.validationError("pattern", "does not match pattern %s", quote(s.Pattern.String())).values(s.Pattern.String(), v))
 		}
 
 		// contentEncoding + contentMediaType
@@ -554,7 +900,7 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 			if s.decoder != nil {
 				b, err := s.decoder(v)
 				if err != nil {
-					errors = append(errors, validationError("contentEncoding", "value is not %s encoded", s.ContentEncoding))
+					errors = append(errors, e.validationError("contentEncoding", "value is not %s encoded", s.ContentEncoding).values(s.ContentEncoding, v))
 				} else {
 					content, decoded = b, true
 				}
@@ -564,15 +910,15 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 					content = []byte(v)
 				}
 				if err := s.mediaType(content); err != nil {
-					errors = append(errors, validationError("contentMediaType", "value is not of mediatype %s", quote(s.ContentMediaType)))
+					errors = append(errors, e.validationError("contentMediaType", "value is not of mediatype %s", quote(s.ContentMediaType)).values(s.ContentMediaType, v))
 				}
 			}
 			if decoded && s.ContentSchema != nil {
 				contentJSON, err := unmarshal(bytes.NewReader(content))
 				if err != nil {
-					errors = append(errors, validationError("contentSchema", "value is not valid json"))
+					errors = append(errors, e.validationError("contentSchema", "value is not valid json").values("application/json", v))
 				} else {
-					err := validate(s.ContentSchema, "contentSchema", contentJSON, "")
+					err := e.validate(s.ContentSchema, "contentSchema", "", contentJSON, "")
 					if err != nil {
 						errors = append(errors, err)
 					}
@@ -580,47 +926,71 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 			}
 		}
 
-	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64:
+	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64, *big.Int, *big.Float, *big.Rat:
 		// lazy convert to *big.Rat to avoid allocation
 		var numVal *big.Rat
 		num := func() *big.Rat {
 			if numVal == nil {
-				numVal, _ = new(big.Rat).SetString(fmt.Sprint(v))
+				numVal = ratValue(v)
 			}
 			return numVal
 		}
+		// integers are compared without conversion to *big.Rat
+		i, isInt := int64Value(v)
+		cmp := func(r *big.Rat) int {
+			if n, ok := ratInt64(r); ok && isInt {
+				switch {
+				case i < n:
+					return -1
+				case i > n:
+					return 1
+				}
+				return 0
+			}
+			return num().Cmp(r)
+		}
 		f64 := func(r *big.Rat) float64 {
 			f, _ := r.Float64()
 			return f
 		}
-		if s.Minimum != nil && num().Cmp(s.Minimum) < 0 {
-			errors = append(errors, validationError("minimum", "must be >= %v but found %v", f64(s.Minimum), v))
+		if s.Minimum != nil && cmp(s.Minimum) < 0 {
+			errors = append(errors, e.validationError("minimum", "must be >= %v but found %v", f64(s.Minimum), v).values(s.Minimum, v))
 		}
-		if s.ExclusiveMinimum != nil && num().Cmp(s.ExclusiveMinimum) <= 0 {
-			errors = append(errors, validationError("exclusiveMinimum", "must be > %v but found %v", f64(s.ExclusiveMinimum), v))
+		if s.ExclusiveMinimum != nil && cmp(s.ExclusiveMinimum) <= 0 {
+			errors = append(errors, e.validationError("exclusiveMinimum", "must be > %v but found %v", f64(s.ExclusiveMinimum), v).values(s.ExclusiveMinimum, v))
 		}
-		if s.Maximum != nil && num().Cmp(s.Maximum) > 0 {
-			errors = append(errors, validationError("maximum", "must be <= %v but found %v", f64(s.Maximum), v))
+		if s.Maximum != nil && cmp(s.Maximum) > 0 {
+			errors = append(errors, e.validationError("maximum", "must be <= %v but found %v", f64(s.Maximum), v).values(s.Maximum, v))
 		}
-		if s.ExclusiveMaximum != nil && num().Cmp(s.ExclusiveMaximum) >= 0 {
-			errors = append(errors, validationError("exclusiveMaximum", "must be < %v but found %v", f64(s.ExclusiveMaximum), v))
+		if s.ExclusiveMaximum != nil && cmp(s.ExclusiveMaximum) >= 0 {
+			errors = append(errors, e.validationError("exclusiveMaximum", "must be < %v but found %v", f64(s.ExclusiveMaximum), v).values(s.ExclusiveMaximum, v))
 		}
 		if s.MultipleOf != nil {
-			if q := new(big.Rat).Quo(num(), s.MultipleOf); !q.IsInt() {
-				errors = append(errors, validationError("multipleOf", "%v not multipleOf %v", v, f64(s.MultipleOf)))
+			var multiple bool
+			if n, ok := ratInt64(s.MultipleOf); ok && isInt && n != 0 {
+				multiple = i%n == 0
+			} else {
+				multiple = new(big.Rat).Quo(num(), s.MultipleOf).IsInt()
+			}
+			if !multiple {
+				errors = append(errors, e.validationError("multipleOf", "%v not multipleOf %v", v, f64(s.MultipleOf)).values(s.MultipleOf, v))
 			}
 		}
 	}
 
+	if vd.enough(errors) {
+		return e.result, e.finish(errors)
+	}
+
 	// $ref + $recursiveRef + $dynamicRef
 	validateRef := func(sch *Schema, refPath string) error {
 		if sch != nil {
-			if err := validateInplace(sch, refPath); err != nil {
+			if err := e.validateInplace(sch, refPath, ""); err != nil {
 				var url = sch.Location
 				if s.url() == sch.url() {
 					url = sch.loc()
 				}
-				return validationError(refPath, "doesn't validate with %s", quote(url)).causes(err)
+				return e.validationError(refPath, "doesn't validate with %s", quote(url)).causes(err)
 			}
 		}
 		return nil
@@ -665,14 +1035,20 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 		}
 	}
 
-	if s.Not != nil && validateInplace(s.Not, "not") == nil {
-		errors = append(errors, validationError("not", "not failed"))
+	if vd.enough(errors) {
+		return e.result, e.finish(errors)
+	}
+
+	if s.Not != nil && e.validateInplace(s.Not, "not", "") == nil {
+		errors = append(errors, e.validationError("not", "not failed").values(nil, v))
 	}
 
 	for i, sch := range s.AllOf {
-		schPath := "allOf/" + strconv.Itoa(i)
-		if err := validateInplace(sch, schPath); err != nil {
-			errors = append(errors, validationError(schPath, "allOf failed").add(err))
+		if vd.enough(errors) {
+			break
+		}
+		if err := e.validateInplace(sch, "allOf", strconv.Itoa(i)); err != nil {
+			errors = append(errors, e.validationError("allOf/"+strconv.Itoa(i), "allOf failed").add(err))
 		}
 	}
 
@@ -680,26 +1056,37 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 		matched := false
 		var causes []error
 		for i, sch := range s.AnyOf {
-			if err := validateInplace(sch, "anyOf/"+strconv.Itoa(i)); err == nil {
+			if err := e.validateInplace(sch, "anyOf", strconv.Itoa(i)); err == nil {
 				matched = true
 			} else {
 				causes = append(causes, err)
 			}
 		}
 		if !matched {
-			errors = append(errors, validationError("anyOf", "anyOf failed").add(causes...))
+			errors = append(errors, e.validationError("anyOf", "anyOf failed").add(causes...))
 		}
 	}
 
-	if len(s.OneOf) > 0 {
+	if obj, ok := v.(map[string]interface{}); ok && s.discriminator != nil {
+		d := s.discriminator
+		if pvalue, ok := obj[d.property]; !ok {
+			errors = append(errors, e.validationError("discriminator", "missing discriminator property %s", quote(d.property)).values(d.property, nil))
+		} else if value, isString := pvalue.(string); !isString {
+			errors = append(errors, e.validationError("discriminator", "discriminator property %s must be one of %s, but got %#v", quote(d.property), strings.Join(d.values(), ", "), pvalue).values(d.values(), pvalue))
+		} else if i, ok := d.mapping[value]; !ok {
+			errors = append(errors, e.validationError("discriminator", "discriminator property %s must be one of %s, but got %#v", quote(d.property), strings.Join(d.values(), ", "), pvalue).values(d.values(), pvalue))
+		} else if err := e.validateInplace(s.OneOf[i], "oneOf", strconv.Itoa(i)); err != nil {
+			errors = append(errors, e.validationError("oneOf", "oneOf failed").add(err))
+		}
+	} else if len(s.OneOf) > 0 {
 		matched := -1
 		var causes []error
 		for i, sch := range s.OneOf {
-			if err := validateInplace(sch, "oneOf/"+strconv.Itoa(i)); err == nil {
+			if err := e.validateInplace(sch, "oneOf", strconv.Itoa(i)); err == nil {
 				if matched == -1 {
 					matched = i
 				} else {
-					errors = append(errors, validationError("oneOf", "valid against schemas at indexes %d and %d", matched, i))
+					errors = append(errors, e.validationError("oneOf", "valid against schemas at indexes %d and %d", matched, i).values(1, []int{matched, i}))
 					break
 				}
 			} else {
@@ -707,25 +1094,25 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 			}
 		}
 		if matched == -1 {
-			errors = append(errors, validationError("oneOf", "oneOf failed").add(causes...))
+			errors = append(errors, e.validationError("oneOf", "oneOf failed").add(causes...))
 		}
 	}
 
 	// if + then + else
 	if s.If != nil {
-		err := validateInplace(s.If, "if")
+		err := e.validateInplace(s.If, "if", "")
 		// "if" leaves dynamic scope
 		scope[len(scope)-1].discard = true
 		if err == nil {
 			if s.Then != nil {
-				if err := validateInplace(s.Then, "then"); err != nil {
-					errors = append(errors, validationError("then", "if-then failed").add(err))
+				if err := e.validateInplace(s.Then, "then", ""); err != nil {
+					errors = append(errors, e.validationError("then", "if-then failed").add(err))
 				}
 			}
 		} else {
 			if s.Else != nil {
-				if err := validateInplace(s.Else, "else"); err != nil {
-					errors = append(errors, validationError("else", "if-else failed").add(err))
+				if err := e.validateInplace(s.Else, "else", ""); err != nil {
+					errors = append(errors, e.validationError("else", "if-else failed").add(err))
 				}
 			}
 		}
@@ -733,44 +1120,316 @@ func (s *Schema) validate(scope []schemaRef, vscope int, spath string, v interfa
 		scope[len(scope)-1].discard = false
 	}
 
-	for _, ext := range s.Extensions {
-		if err := ext.Validate(ValidationContext{result, validate, validateInplace, validationError}, v); err != nil {
-			errors = append(errors, err)
-		}
+	if vd.enough(errors) {
+		return e.result, e.finish(errors)
+	}
+
+	if len(s.Extensions) > 0 {
+		errors = append(errors, e.validateExtensions()...)
 	}
 
 	// unevaluatedProperties + unevaluatedItems
 	switch v := v.(type) {
 	case map[string]interface{}:
 		if s.UnevaluatedProperties != nil {
-			for pname := range result.unevalProps {
-				if pvalue, ok := v[pname]; ok {
-					if err := validate(s.UnevaluatedProperties, "unevaluatedProperties", pvalue, escape(pname)); err != nil {
-						errors = append(errors, err)
-					}
+			start := len(errors)
+			for pname := range e.result.unevalProps {
+				if err := e.validate(s.UnevaluatedProperties, "unevaluatedProperties", "", v[pname], jsonpointer.Escape(pname)); err != nil {
+					errors = append(errors, err)
 				}
 			}
-			result.unevalProps = nil
+			sortErrors(errors[start:])
+			e.result.unevalProps = nil
 		}
 	case []interface{}:
 		if s.UnevaluatedItems != nil {
-			for i := range result.unevalItems {
-				if err := validate(s.UnevaluatedItems, "unevaluatedItems", v[i], strconv.Itoa(i)); err != nil {
+			for i := range v {
+				if _, ok := e.result.unevalItems[i]; !ok {
+					continue
+				}
+				if err := e.validate(s.UnevaluatedItems, "unevaluatedItems", "", v[i], strconv.Itoa(i)); err != nil {
 					errors = append(errors, err)
 				}
 			}
-			result.unevalItems = nil
+			e.result.unevalItems = nil
+		}
+	}
+
+	return e.result, e.finish(errors)
+}
+
+// evaluation captures the state of schema s validating value v.
+//
+// its methods are used instead of closures in Schema.validate, to
+// avoid allocations during validation.
+type evaluation struct {
+	vd     *validator
+	s      *Schema
+	scope  []schemaRef // dynamic scope, ending with s
+	vscope int         // number of schemas in scope, validating v
+	v      interface{}
+	result validationResult
+	mark   int // number of annotations, before evaluation
+}
+
+func (e *evaluation) validationError(keywordPath string, format string, a ...interface{}) *ValidationError {
+	if e.vd.quick {
+		// only validity matters, avoid formatting
+		return &ValidationError{}
+	}
+	ve := &ValidationError{
+		KeywordLocation:         keywordLocation(e.scope, keywordPath),
+		AbsoluteKeywordLocation: joinPtr(e.s.Location, keywordPath),
+		InstanceLocation:        e.vd.instanceLocation(e.scope),
+		Message:                 fmt.Sprintf(format, a...),
+	}
+	if keywordPath != "" {
+		keyword, _, _ := strings.Cut(keywordPath, "/")
+		ve.KeywordError = &KeywordError{Keyword: keyword}
+	}
+	if e.s.errorValue != nil {
+		ve.Value = e.s.errorValue(ve, e.v)
+	}
+	return ve
+}
+
+// falseError returns the error for false schema.
+func (e *evaluation) falseError() *ValidationError {
+	ve := e.validationError("", "not allowed")
+	ve.KeywordError = &KeywordError{Keyword: "false", Want: false, Got: e.v}
+	return ve
+}
+
+// additionalCauses adds cause to ve, for each property in pnames not
+// allowed by "additionalProperties": false, with suggestions of nearest
+// property names in "properties".
+func (e *evaluation) additionalCauses(ve *ValidationError, pnames []string) {
+	known := e.s.keys.properties
+	for _, pname := range pnames {
+		suggestions := nearestN(pname, known, 3)
+		cause := e.validationError("additionalProperties", "additionalProperty %s not allowed%s", quote(pname), didYouMean(suggestions)).values(false, pname)
+		cause.KeywordError.Suggestions = suggestions
+		cause.InstanceLocation += "/" + jsonpointer.Escape(pname)
+		cause.markPropertyName()
+		ve.add(cause)
+	}
+}
+
+// suggest sets Suggestions of ve to the strings in allowed nearest to v,
+// and mentions them in its message.
+func (e *evaluation) suggest(ve *ValidationError, v string, allowed []interface{}) {
+	var candidates []string
+	for _, item := range allowed {
+		if item, ok := item.(string); ok {
+			candidates = append(candidates, item)
+		}
+	}
+	if suggestions := nearestN(v, candidates, 3); len(suggestions) > 0 {
+		ve.KeywordError.Suggestions = suggestions
+		ve.Message += didYouMean(suggestions)
+	}
+}
+
+// didYouMean returns suffix for error message, mentioning suggestions.
+// returns empty string if there are no suggestions.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = quote(s)
+	}
+	return fmt.Sprintf("; did you mean %s?", strings.Join(quoted, " or "))
+}
+
+// report appends ve to errors, unless keyword kw has SeverityWarning, in
+// which case ve is reported as warning.
+func (e *evaluation) report(errors []error, kw string, ve *ValidationError) []error {
+	if sev, ok := e.s.severities[kw]; ok && sev == SeverityWarning {
+		if e.vd.warn {
+			e.vd.warnings = append(e.vd.warnings, ve)
+		}
+		return errors
+	}
+	return append(errors, ve)
+}
+
+// limitExceeded aborts validation with *LimitExceededError,
+// for limit in ValidateOptions, exceeded by keyword.
+func (e *evaluation) limitExceeded(limit string, max int, keyword string) {
+	panic(&LimitExceededError{
+		Limit:            limit,
+		Max:              max,
+		URL:              joinPtr(e.s.Location, keyword),
+		InstanceLocation: e.vd.instanceLocation(e.scope),
+	})
+}
+
+// finish returns the errors reported by e.s as single error,
+// after applying errorMessage. annotations are collected if there
+// are no errors.
+func (e *evaluation) finish(errors []error) error {
+	vd, s := e.vd, e.s
+	if vd.collect {
+		if len(errors) == 0 {
+			s.collectAnnotations(vd, e.scope, vd.instanceLocation(e.scope))
+		} else {
+			// annotations are dropped by failing schema
+			vd.annotations = vd.annotations[:e.mark]
 		}
 	}
 
+	if s.errorMessage != nil && len(errors) > 0 && !vd.quick {
+		ec := *e // copy, so that e does not escape
+		errors = s.errorMessage.apply(s, errors, e.v, vd.instanceLocation(e.scope), ec.validationError)
+	}
+
 	switch len(errors) {
 	case 0:
-		return result, nil
+		return nil
 	case 1:
-		return result, errors[0]
+		return errors[0]
 	default:
-		return result, validationError("", "").add(errors...) // empty message, used just for wrapping
+		return e.validationError("", "").add(errors...) // empty message, used just for wrapping
+	}
+}
+
+// validate validates v at vpath, with sch at kw/token.
+// token is passed separately, to avoid concatenation unless an error is reported.
+func (e *evaluation) validate(sch *Schema, kw, token string, v interface{}, vpath string) error {
+	_, err := sch.validate(e.vd, e.scope, 0, schemaRef{path: kw, token: token, vpath: vpath}, v)
+	return err
+}
+
+// validateInplace validates the value of e, with sch at kw/token.
+func (e *evaluation) validateInplace(sch *Schema, kw, token string) error {
+	vr, err := sch.validate(e.vd, e.scope, e.vscope, schemaRef{path: kw, token: token}, e.v)
+	if err == nil {
+		// update result
+		for pname := range e.result.unevalProps {
+			if _, ok := vr.unevalProps[pname]; !ok {
+				delete(e.result.unevalProps, pname)
+			}
+		}
+		for i := range e.result.unevalItems {
+			if _, ok := vr.unevalItems[i]; !ok {
+				delete(e.result.unevalItems, i)
+			}
+		}
+	}
+	return err
+}
+
+// validateExtensions validates the value of e, with user defined extensions.
+func (e evaluation) validateExtensions() []error {
+	validate := func(sch *Schema, schPath string, v interface{}, vpath string) error {
+		return e.validate(sch, schPath, "", v, vpath)
+	}
+	validateInplace := func(sch *Schema, schPath string) error {
+		return e.validateInplace(sch, schPath, "")
+	}
+	var errors []error
+	for _, name := range e.s.keys.extensions {
+		if err := e.s.Extensions[name].Validate(ValidationContext{e.result, validate, validateInplace, e.validationError}, e.v); err != nil {
+			ve, ok := err.(*ValidationError)
+			if !ok {
+				ve = e.validationError("", "%v", err)
+			}
+			errors = e.report(errors, name, ve)
+		}
+	}
+	return errors
+}
+
+// validator captures the state of single validation.
+type validator struct {
+	ctx         context.Context
+	done        <-chan struct{} // nil, if ctx can never be done
+	count       int             // number of schemas evaluated
+	collect     bool            // whether to collect annotations
+	annotations []Annotation
+	root        interface{}                  // instance being validated. used to resolve $data
+	rootLoc     string                       // location of root
+	hook        Hook                         // nil, if no hook
+	quick       bool                         // only validity is needed. errors are not populated
+	track       bool                         // whether to track evaluated properties and items
+	failFast    bool                         // see ValidateOptions.FailFast
+	maxErrors   int                          // see ValidateOptions.MaxErrors
+	mode        Mode                         // see ValidateOptions.Mode
+	maxUnique   int                          // see ValidateOptions.MaxUniqueItems
+	maxPattern  int                          // see ValidateOptions.MaxPatternLength
+	memo        map[memoKey]validationResult // valid results. nil if ValidateOptions.Memoize is false
+	warn        bool                         // whether to collect warnings
+	warnings    []*ValidationError           // failures of keywords with SeverityWarning
+	scope       []schemaRef                  // reused across validations, to avoid allocation
+}
+
+// enough tells whether a schema, having reported errors, can skip
+// evaluation of its remaining keywords.
+func (vd *validator) enough(errors []error) bool {
+	if len(errors) == 0 {
+		return false
+	}
+	return vd.quick || vd.failFast || (vd.maxErrors > 0 && len(errors) >= vd.maxErrors)
+}
+
+// validators are pooled, so that validation does not allocate.
+var validators = sync.Pool{
+	New: func() interface{} {
+		return &validator{scope: make([]schemaRef, 0, 32)}
+	},
+}
+
+func newValidator(ctx context.Context) *validator {
+	vd := validators.Get().(*validator)
+	vd.ctx, vd.done = ctx, ctx.Done()
+	return vd
+}
+
+// release returns vd to the pool. vd must not be used after release.
+func (vd *validator) release() {
+	scope := vd.scope[:cap(vd.scope)]
+	for i := range scope {
+		scope[i] = schemaRef{}
 	}
+	*vd = validator{scope: scope[:0]}
+	validators.Put(vd)
+}
+
+// instanceLocation returns the location of value validated by last schema in scope.
+func (vd *validator) instanceLocation(scope []schemaRef) string {
+	loc := vd.rootLoc
+	for _, sr := range scope {
+		if sr.vpath != "" {
+			loc += "/" + sr.vpath
+		}
+	}
+	return loc
+}
+
+// number of schemas evaluated between checks of ctx.Done.
+const doneCheckInterval = 64
+
+// checkDone aborts validation with ctx.Err() if ctx is done.
+func (vd *validator) checkDone() {
+	if vd.done == nil {
+		return
+	}
+	vd.count++
+	if vd.count%doneCheckInterval != 0 {
+		return
+	}
+	select {
+	case <-vd.done:
+		panic(abortError{vd.ctx.Err()})
+	default:
+	}
+}
+
+// abortError is used to abort validation by panic.
+type abortError struct {
+	err error
 }
 
 type validationResult struct {
@@ -778,12 +1437,111 @@ type validationResult struct {
 	unevalItems map[int]struct{}
 }
 
-func (vr validationResult) unevalPnames() string {
-	pnames := make([]string, 0, len(vr.unevalProps))
-	for pname := range vr.unevalProps {
-		pnames = append(pnames, quote(pname))
+// needsTracking tells whether validation against s needs tracking of
+// evaluated properties and items, i.e. whether any schema reachable from s
+// uses unevaluatedProperties, unevaluatedItems or extensions.
+func (s *Schema) needsTracking() bool {
+	visited := make(map[*Schema]bool)
+	var needs func(sch *Schema) bool
+	needs = func(sch *Schema) bool {
+		if visited[sch] {
+			return false
+		}
+		visited[sch] = true
+		if sch.UnevaluatedProperties != nil || sch.UnevaluatedItems != nil || len(sch.Extensions) > 0 {
+			return true
+		}
+		// $dynamicRef may resolve to any dynamic anchor in scope
+		for _, da := range sch.dynamicAnchors {
+			if needs(da) {
+				return true
+			}
+		}
+		for _, sub := range sch.Subschemas() {
+			if needs(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return needs(s)
+}
+
+// isAdditional tells whether property pname is matched by neither
+// properties nor patternProperties of s.
+func (s *Schema) isAdditional(pname string) bool {
+	if _, ok := s.Properties[pname]; ok {
+		return false
+	}
+	for pattern := range s.PatternProperties {
+		if pattern.MatchString(pname) {
+			return false
+		}
 	}
-	return strings.Join(pnames, ", ")
+	return true
+}
+
+// int64Value returns v as int64, if v is an integer in the range of int64.
+func int64Value(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		s := string(v)
+		if strings.HasPrefix(s, "-") {
+			s = s[1:]
+		}
+		if s == "" || len(s) > 18 {
+			return 0, false
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] < '0' || s[i] > '9' {
+				return 0, false
+			}
+		}
+		i, err := strconv.ParseInt(string(v), 10, 64)
+		return i, err == nil
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), true
+		}
+	case float32:
+		return int64Value(float64(v))
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), v <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case *big.Int:
+		return v.Int64(), v.IsInt64()
+	case *big.Rat:
+		if v.IsInt() && v.Num().IsInt64() {
+			return v.Num().Int64(), true
+		}
+	case *big.Float:
+		if v.IsInt() {
+			i, acc := v.Int64()
+			return i, acc == big.Exact
+		}
+	}
+	return 0, false
+}
+
+// ratInt64 returns r as int64, if r is an integer in the range of int64.
+func ratInt64(r *big.Rat) (int64, bool) {
+	if r.IsInt() && r.Num().IsInt64() {
+		return r.Num().Int64(), true
+	}
+	return 0, false
 }
 
 // jsonType returns the json type of given value v.
@@ -795,7 +1553,7 @@ func jsonType(v interface{}) string {
 		return "null"
 	case bool:
 		return "boolean"
-	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64:
+	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64, *big.Int, *big.Float, *big.Rat:
 		return "number"
 	case string:
 		return "string"
@@ -841,9 +1599,11 @@ func equals(v1, v2 interface{}) bool {
 		}
 		return true
 	case "number":
-		num1, _ := new(big.Rat).SetString(fmt.Sprint(v1))
-		num2, _ := new(big.Rat).SetString(fmt.Sprint(v2))
-		return num1.Cmp(num2) == 0
+		r1, r2 := ratValue(v1), ratValue(v2)
+		if r1 == nil || r2 == nil {
+			return r1 == r2 && fmt.Sprint(v1) == fmt.Sprint(v2)
+		}
+		return r1.Cmp(r2) == 0
 	default:
 		return v1 == v2
 	}
@@ -860,11 +1620,27 @@ func hash(v interface{}, h *maphash.Hash) {
 		} else {
 			h.WriteByte(0)
 		}
-	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64:
+	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64, *big.Int, *big.Float, *big.Rat:
+		// numbers equal as rationals have same float64 value. numbers with
+		// same float64 value but not equal, are resolved by equals
 		h.WriteByte(2)
-		num, _ := new(big.Rat).SetString(fmt.Sprint(v))
-		h.Write(num.Num().Bytes())
-		h.Write(num.Denom().Bytes())
+		var f float64
+		switch v := v.(type) {
+		case json.Number:
+			f, _ = strconv.ParseFloat(string(v), 64)
+		case float64:
+			f = v
+		case *big.Int, *big.Float, *big.Rat:
+			f, _ = ratValue(v).Float64()
+		default:
+			f, _ = strconv.ParseFloat(fmt.Sprint(v), 64)
+		}
+		if f == 0 {
+			f = 0 // -0 and 0 are equal
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+		h.Write(b[:])
 	case string:
 		h.WriteByte(3)
 		h.WriteString(v)
@@ -891,9 +1667,87 @@ func hash(v interface{}, h *maphash.Hash) {
 	}
 }
 
-// escape converts given token to valid json-pointer token
+// escape converts given token to valid json-pointer token, to be used in
+// uri fragment.
+//
+// instance locations are not uri fragments, and use jsonpointer.Escape.
 func escape(token string) string {
 	token = strings.ReplaceAll(token, "~", "~0")
 	token = strings.ReplaceAll(token, "/", "~1")
 	return url.PathEscape(token)
 }
+
+// sortErrors sorts errors, reported for properties of an instance, in
+// sorted order of property name.
+func sortErrors(errors []error) {
+	if len(errors) > 1 {
+		sort.Stable(byProperty(errors))
+	}
+}
+
+type byProperty []error
+
+func (a byProperty) Len() int           { return len(a) }
+func (a byProperty) Less(i, j int) bool { return propertyOf(a[i]) < propertyOf(a[j]) }
+func (a byProperty) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// propertyOf returns the property name, from instance location of err.
+func propertyOf(err error) string {
+	if ve, ok := err.(*ValidationError); ok {
+		loc := ve.InstanceLocation
+		return jsonpointer.Unescape(loc[strings.LastIndexByte(loc, '/')+1:])
+	}
+	return ""
+}
+
+// sortedSchemaKeys returns keys of m in sorted order.
+func sortedSchemaKeys(m map[string]*Schema) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedRequiredKeys returns keys of m in sorted order.
+func sortedRequiredKeys(m map[string][]string) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedExtensionKeys returns keys of m in sorted order.
+func sortedExtensionKeys(m map[string]ExtSchema) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedPatterns returns keys of m in sorted order of pattern.
+func sortedPatterns(m map[Regexp]*Schema) []Regexp {
+	if len(m) == 0 {
+		return nil
+	}
+	patterns := make([]Regexp, 0, len(m))
+	for re := range m {
+		patterns = append(patterns, re)
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].String() < patterns[j].String() })
+	return patterns
+}
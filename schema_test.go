@@ -2,30 +2,40 @@ package jsonschema_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"math"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+	"github.com/santhosh-tekuri/jsonschema/v5/testsuite"
 )
 
 var skipTests = map[string]map[string][]string{
-	"TestDraft4/optional/zeroTerminatedFloats.json": {
+	"draft4/optional/zeroTerminatedFloats.json": {
 		"some languages do not distinguish between different types of numeric value": {}, // this behavior is changed in new drafts
 	},
-	"TestDraft4/optional/ecmascript-regex.json": {
+	"draft4/optional/ecmascript-regex.json": {
 		"ECMA 262 \\s matches whitespace": {
 			"Line tabulation matches",                       // \s does not match vertical tab
 			"latin-1 non-breaking-space matches",            // \s does not match unicode whitespace
@@ -48,7 +58,7 @@ var skipTests = map[string]map[string][]string{
 		"patterns always use unicode semantics with patternProperties":   {}, // invalid regex "\\p{Letter}cole"
 	},
 	//
-	"TestDraft6/optional/ecmascript-regex.json": {
+	"draft6/optional/ecmascript-regex.json": {
 		"ECMA 262 \\s matches whitespace": {
 			"Line tabulation matches",                       // \s does not match vertical tab
 			"latin-1 non-breaking-space matches",            // \s does not match unicode whitespace
@@ -71,9 +81,7 @@ var skipTests = map[string]map[string][]string{
 		"patterns always use unicode semantics with patternProperties":   {}, // invalid regex "\\p{Letter}cole"
 	},
 	//
-	"TestDraft7/optional/format/idn-hostname.json": {}, // idn-hostname format is not implemented
-	"TestDraft7/optional/format/idn-email.json":    {}, // idn-email format is not implemented
-	"TestDraft7/optional/ecmascript-regex.json": {
+	"draft7/optional/ecmascript-regex.json": {
 		"ECMA 262 \\s matches whitespace": {
 			"Line tabulation matches",                       // \s does not match vertical tab
 			"latin-1 non-breaking-space matches",            // \s does not match unicode whitespace
@@ -96,9 +104,7 @@ var skipTests = map[string]map[string][]string{
 		"patterns always use unicode semantics with patternProperties":   {}, // invalid regex "\\p{Letter}cole"
 	},
 	//
-	"TestDraft2019/optional/format/idn-hostname.json": {}, // idn-hostname format is not implemented
-	"TestDraft2019/optional/format/idn-email.json":    {}, // idn-email format is not implemented
-	"TestDraft2019/optional/ecmascript-regex.json": {
+	"draft2019-09/optional/ecmascript-regex.json": {
 		"ECMA 262 \\s matches whitespace": {
 			"Line tabulation matches",                       // \s does not match vertical tab
 			"latin-1 non-breaking-space matches",            // \s does not match unicode whitespace
@@ -121,9 +127,7 @@ var skipTests = map[string]map[string][]string{
 		"patterns always use unicode semantics with patternProperties":   {}, // invalid regex "\\p{Letter}cole"
 	},
 	//
-	"TestDraft2020/optional/format/idn-hostname.json": {}, // idn-hostname format is not implemented
-	"TestDraft2020/optional/format/idn-email.json":    {}, // idn-email format is not implemented
-	"TestDraft2020/optional/ecmascript-regex.json": {
+	"draft2020-12/optional/ecmascript-regex.json": {
 		"ECMA 262 \\s matches whitespace": {
 			"Line tabulation matches",                       // \s does not match vertical tab
 			"latin-1 non-breaking-space matches",            // \s does not match unicode whitespace
@@ -148,36 +152,56 @@ var skipTests = map[string]map[string][]string{
 	},
 }
 
+func runSuite(t *testing.T, draft string) {
+	s := &testsuite.Suite{
+		FS:   os.DirFS("testdata/JSON-Schema-Test-Suite"),
+		Skip: skipTests,
+	}
+	s.Run(t, draft)
+}
+
 func TestDraft4(t *testing.T) {
-	testFolder(t, "testdata/JSON-Schema-Test-Suite/tests/draft4", jsonschema.Draft4)
+	runSuite(t, "draft4")
 }
 
 func TestDraft6(t *testing.T) {
-	testFolder(t, "testdata/JSON-Schema-Test-Suite/tests/draft6", jsonschema.Draft6)
+	runSuite(t, "draft6")
 }
 
 func TestDraft7(t *testing.T) {
-	testFolder(t, "testdata/JSON-Schema-Test-Suite/tests/draft7", jsonschema.Draft7)
+	runSuite(t, "draft7")
 }
 
 func TestDraft2019(t *testing.T) {
-	testFolder(t, "testdata/JSON-Schema-Test-Suite/tests/draft2019-09", jsonschema.Draft2019)
+	runSuite(t, "draft2019-09")
 }
 
 func TestDraft2020(t *testing.T) {
-	testFolder(t, "testdata/JSON-Schema-Test-Suite/tests/draft2020-12", jsonschema.Draft2020)
+	runSuite(t, "draft2020-12")
 }
 
 func TestExtra(t *testing.T) {
-	t.Run("draft7", func(t *testing.T) {
-		testFolder(t, "testdata/tests/draft7", jsonschema.Draft7)
-	})
-	t.Run("draft2020", func(t *testing.T) {
-		testFolder(t, "testdata/tests/draft2020", jsonschema.Draft2020)
-	})
+	s := &testsuite.Suite{
+		FS: os.DirFS("testdata"),
+		Drafts: map[string]*jsonschema.Draft{
+			"draft7":    jsonschema.Draft7,
+			"draft2019": jsonschema.Draft2019,
+			"draft2020": jsonschema.Draft2020,
+		},
+		Remotes: map[string]fs.FS{
+			"http://localhost:1234/": os.DirFS("testdata/JSON-Schema-Test-Suite/remotes"),
+			"http://localhost:1235/": os.DirFS("testdata/remotes"),
+		},
+	}
+	s.Run(t)
 }
 
 func TestMain(m *testing.M) {
+	// fuzz workers run in separate processes, and do not need remotes
+	flag.Parse()
+	if f := flag.Lookup("test.fuzzworker"); f != nil && f.Value.String() == "true" {
+		os.Exit(m.Run())
+	}
 	server1 := &http.Server{Addr: "localhost:1234", Handler: http.FileServer(http.Dir("testdata/JSON-Schema-Test-Suite/remotes"))}
 	go func() {
 		if err := server1.ListenAndServe(); err != http.ErrServerClosed {
@@ -193,93 +217,6 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func testFolder(t *testing.T, folder string, draft *jsonschema.Draft) {
-	des, err := os.ReadDir(folder)
-	if err != nil {
-		t.Fatal(err)
-	}
-	for _, de := range des {
-		if de.IsDir() {
-			t.Run(de.Name(), func(t *testing.T) {
-				testFolder(t, path.Join(folder, de.Name()), draft)
-			})
-			continue
-		}
-		if path.Ext(de.Name()) != ".json" {
-			continue
-		}
-		t.Run(de.Name(), func(t *testing.T) {
-			skip := skipTests[t.Name()]
-			if skip != nil && len(skip) == 0 {
-				t.Skip()
-			}
-			f, err := os.Open(path.Join(folder, de.Name()))
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
-			var tg []struct {
-				Description string
-				Schema      json.RawMessage
-				Tests       []struct {
-					Description string
-					Data        interface{}
-					Valid       bool
-				}
-			}
-			dec := json.NewDecoder(f)
-			dec.UseNumber()
-			if err = dec.Decode(&tg); err != nil {
-				t.Fatal(err)
-			}
-			for _, group := range tg {
-				t.Run(group.Description, func(t *testing.T) {
-					skip := skip[group.Description]
-					if skip != nil && len(skip) == 0 {
-						t.Skip()
-					}
-					c := jsonschema.NewCompiler()
-					c.Draft = draft
-					if strings.Contains(folder, "optional") {
-						c.AssertFormat = true
-						c.AssertContent = true
-					}
-					if err := c.AddResource("schema.json", bytes.NewReader(group.Schema)); err != nil {
-						t.Fatal(err)
-					}
-					schema, err := c.Compile("schema.json")
-					if err != nil {
-						t.Fatalf("%#v", err)
-					}
-					for _, test := range group.Tests {
-						t.Run(test.Description, func(t *testing.T) {
-							for _, desc := range skip {
-								if test.Description == desc {
-									t.Skip()
-								}
-							}
-							err = schema.Validate(test.Data)
-							valid := err == nil
-							if !valid {
-								if _, ok := err.(*jsonschema.ValidationError); ok {
-									for _, line := range strings.Split(err.(*jsonschema.ValidationError).GoString(), "\n") {
-										t.Logf("%s", line)
-									}
-								} else {
-									t.Fatalf("got: %#v, want: *jsonschema.ValidationError", err)
-								}
-							}
-							if test.Valid != valid {
-								t.Fatalf("valid: got %v, want %v", valid, test.Valid)
-							}
-						})
-					}
-				})
-			}
-		})
-	}
-}
-
 func TestMustCompile(t *testing.T) {
 	t.Run("invalid", func(t *testing.T) {
 		defer func() {
@@ -408,6 +345,58 @@ func TestCompileURL(t *testing.T) {
 	}
 }
 
+func TestCompiler_CompileRef(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("http://example.com/customer.json", strings.NewReader(`{
+		"$id": "http://example.com/schemas/customer.json",
+		"properties": {
+			"address": {"$ref": "address.json"},
+			"name": {"type": "string"}
+		},
+		"$defs": {
+			"email": {"$anchor": "email", "type": "string", "format": "email"}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddResource("http://example.com/schemas/address.json", strings.NewReader(`{
+		"required": ["city"],
+		"properties": {"city": {"type": "string"}}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	c.AssertFormat = true
+
+	tests := []struct {
+		ref     string
+		valid   interface{}
+		invalid interface{}
+	}{
+		{"#/properties/address", map[string]interface{}{"city": "x"}, map[string]interface{}{}},
+		{"#email", "a@b.com", "x"},
+		{"address.json#/properties/city", "x", 1},
+	}
+	for _, test := range tests {
+		sch, err := c.CompileRef("http://example.com/customer.json", test.ref)
+		if err != nil {
+			t.Fatalf("%s: %v", test.ref, err)
+		}
+		if err := sch.Validate(test.valid); err != nil {
+			t.Errorf("%s: %v", test.ref, err)
+		}
+		err = sch.Validate(test.invalid)
+		if ve, ok := err.(*jsonschema.ValidationError); !ok || ve.InstanceLocation != "" {
+			t.Errorf("%s: got %v, want ValidationError at root", test.ref, err)
+		}
+	}
+
+	if _, err := c.CompileRef("http://example.com/customer.json", "#/properties/missing"); err == nil {
+		t.Fatal("error expected for missing subschema")
+	} else if _, ok := err.(*jsonschema.SchemaError); !ok {
+		t.Fatalf("got %T, want *SchemaError", err)
+	}
+}
+
 func TestInvalidJsonTypeError(t *testing.T) {
 	compiler := jsonschema.NewCompiler()
 	err := compiler.AddResource("test.json", strings.NewReader(`{ "type": "string"}`))
@@ -631,6 +620,121 @@ func TestNonStringFormat(t *testing.T) {
 	}
 }
 
+func TestCompiler_AssertFormat(t *testing.T) {
+	formatAssertionMeta := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://example.com/format-assertion",
+		"$vocabulary": {
+			"https://json-schema.org/draft/2020-12/vocab/core": true,
+			"https://json-schema.org/draft/2020-12/vocab/format-assertion": true
+		},
+		"$dynamicAnchor": "meta",
+		"allOf": [
+			{"$ref": "https://json-schema.org/draft/2020-12/meta/core"},
+			{"$ref": "https://json-schema.org/draft/2020-12/meta/format-assertion"}
+		]
+	}`
+	tests := []struct {
+		description string
+		draft       *jsonschema.Draft
+		schema      string
+		assert      bool // Compiler.AssertFormat
+		asserted    bool
+	}{
+		{"draft7", jsonschema.Draft7, `{}`, false, true},
+		{"draft7 $schema", jsonschema.Draft2020, `{"$schema": "http://json-schema.org/draft-07/schema#"}`, false, true},
+		{"draft2019", jsonschema.Draft2019, `{}`, false, false},
+		{"draft2019 $schema", jsonschema.Draft7, `{"$schema": "https://json-schema.org/draft/2019-09/schema"}`, false, false},
+		{"draft2019 forced", jsonschema.Draft2019, `{}`, true, true},
+		{"draft2020", jsonschema.Draft2020, `{}`, false, false},
+		{"draft2020 $schema", jsonschema.Draft7, `{"$schema": "https://json-schema.org/draft/2020-12/schema"}`, false, false},
+		{"draft2020 forced", jsonschema.Draft2020, `{}`, true, true},
+		{"format-assertion vocab", jsonschema.Draft2020, `{"$schema": "http://example.com/format-assertion"}`, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			c.Draft = test.draft
+			c.AssertFormat = test.assert
+			if err := c.AddResource("http://example.com/format-assertion", strings.NewReader(formatAssertionMeta)); err != nil {
+				t.Fatal(err)
+			}
+			schema := strings.TrimSuffix(test.schema, "}")
+			if schema != "{" {
+				schema += ", "
+			}
+			schema += `"format": "ipv4"}`
+			if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+				t.Fatal(err)
+			}
+			sch, err := c.Compile("schema.json")
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			if asserted := sch.Validate("not-ipv4") != nil; asserted != test.asserted {
+				t.Fatalf("asserted: got %v, want %v", asserted, test.asserted)
+			}
+		})
+	}
+}
+
+func TestDisallowUnknownFormats(t *testing.T) {
+	schema := `{"type": "string", "format": "credit-card"}`
+	for _, disallow := range []bool{false, true} {
+		t.Run(fmt.Sprint(disallow), func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			c.AssertFormat = true
+			c.DisallowUnknownFormats = disallow
+			if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+				t.Fatal(err)
+			}
+			_, err := c.Compile("schema.json")
+			if disallow && err == nil {
+				t.Fatal("error expected")
+			}
+			if !disallow && err != nil {
+				t.Fatalf("%#v", err)
+			}
+		})
+	}
+	t.Run("registered", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.AssertFormat = true
+		c.DisallowUnknownFormats = true
+		c.Formats["credit-card"] = func(v interface{}) bool { return true }
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Compile("schema.json"); err != nil {
+			t.Fatalf("%#v", err)
+		}
+	})
+}
+
+func TestCompiler_LenientFormats(t *testing.T) {
+	schema := `{"type": "string", "format": "uri"}`
+	for _, lenient := range []bool{false, true} {
+		t.Run(fmt.Sprint(lenient), func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			c.AssertFormat = true
+			c.LenientFormats["uri"] = lenient
+			if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+				t.Fatal(err)
+			}
+			sch, err := c.Compile("schema.json")
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			if err := sch.Validate("http://example.com/ƒøø"); (err == nil) != lenient {
+				t.Fatalf("valid: got %v, want %v", err == nil, lenient)
+			}
+			if err := sch.Validate("http://example.com/?"); err != nil {
+				t.Fatalf("valid uri must be accepted: %v", err)
+			}
+		})
+	}
+}
+
 func TestCompiler_LoadURL(t *testing.T) {
 	const (
 		base   = `{ "type": "string" }`
@@ -660,6 +764,486 @@ func TestCompiler_LoadURL(t *testing.T) {
 	}
 }
 
+func TestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("validate", func(t *testing.T) {
+		sch := jsonschema.MustCompileString("schema.json", `{"items": {"type": "integer"}}`)
+		arr := make([]interface{}, 100)
+		for i := range arr {
+			arr[i] = i
+		}
+		if err := sch.ValidateContext(context.Background(), arr); err != nil {
+			t.Fatalf("%#v", err)
+		}
+		if err := sch.ValidateContext(ctx, arr); err != context.Canceled {
+			t.Fatalf("got %#v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("compile", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.LoadURL = func(s string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(`{"type": "string"}`)), nil
+		}
+		if _, err := c.CompileContext(ctx, "map:///schema.json"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %#v, want context.Canceled", err)
+		}
+		if _, err := c.CompileContext(context.Background(), "map:///schema.json"); err != nil {
+			t.Fatalf("%#v", err)
+		}
+	})
+
+	t.Run("load", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := jsonschema.NewCompiler()
+		c.LoadURL = func(s string) (io.ReadCloser, error) {
+			t.Error("LoadURL must not be used, if LoadURLContext is set")
+			return nil, errors.New("unsupported")
+		}
+		c.LoadURLContext = func(ctx context.Context, s string) (io.ReadCloser, error) {
+			cancel() // cancel while loading
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		if _, err := c.CompileContext(ctx, "map:///schema.json"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %#v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestCompilerConcurrency(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("base.json", strings.NewReader(`{"type": "string"}`)); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			url := fmt.Sprintf("schema%d.json", i%5)
+			if err := c.AddResource(url, strings.NewReader(`{"$ref": "base.json", "maxLength": 3}`)); err != nil {
+				t.Error(err)
+				return
+			}
+			sch, err := c.Compile(url)
+			if err != nil {
+				t.Errorf("%#v", err)
+				return
+			}
+			if err := sch.Validate("foo"); err != nil {
+				t.Errorf("%#v", err)
+			}
+			if err := sch.Validate("long"); err == nil {
+				t.Error("error expected")
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCompilerConcurrency_slowLoad(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	loads := make(map[string]int)
+	c := jsonschema.NewCompiler()
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		mu.Lock()
+		loads[s]++
+		mu.Unlock()
+		switch s {
+		case "map:///slow.json":
+			close(started)
+			<-release
+			return io.NopCloser(strings.NewReader(`{"$ref": "base.json"}`)), nil
+		case "map:///fast.json":
+			return io.NopCloser(strings.NewReader(`{"$ref": "base.json"}`)), nil
+		case "map:///base.json":
+			return io.NopCloser(strings.NewReader(`{"type": "string"}`)), nil
+		}
+		return nil, errors.New("unsupported schema")
+	}
+
+	schemas := make(chan *jsonschema.Schema, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			sch, err := c.Compile("map:///slow.json")
+			if err != nil {
+				t.Errorf("%#v", err)
+			}
+			schemas <- sch
+		}()
+	}
+	<-started
+	// compilation must not wait for the slow load
+	done := make(chan error)
+	go func() {
+		_, err := c.Compile("map:///fast.json")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("%#v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("compilation is blocked by slow load")
+	}
+	close(release)
+	if s1, s2 := <-schemas, <-schemas; s1 != s2 {
+		t.Error("concurrent compilations must return same schema")
+	}
+	if n := loads["map:///slow.json"]; n != 1 {
+		t.Errorf("slow.json loaded %d times", n)
+	}
+}
+
+// blockCompiler is extension, which blocks compilation of schema
+// with "block" keyword, until released.
+type blockCompiler struct {
+	started, release chan struct{}
+}
+
+func (b blockCompiler) Compile(ctx jsonschema.CompilerContext, m map[string]interface{}) (jsonschema.ExtSchema, error) {
+	if _, ok := m["block"]; ok {
+		close(b.started)
+		<-b.release
+	}
+	return nil, nil
+}
+
+func TestCompiler_compiledCache(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	block := blockCompiler{make(chan struct{}), make(chan struct{})}
+	c.RegisterExtension("block", jsonschema.MustCompileString("block.json", `{}`), block)
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		if s == "map:///other.json" {
+			return io.NopCloser(strings.NewReader(`{"$ref": "schema.json"}`)), nil
+		}
+		return nil, errors.New("unsupported schema")
+	}
+	if err := c.AddResource("map:///schema.json", strings.NewReader(`{"type": "string"}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch := c.MustCompile("map:///schema.json")
+
+	// loading new resource must not discard schemas compiled earlier
+	c.MustCompile("map:///other.json")
+	if err := c.AddResource("map:///blocked.json", strings.NewReader(`{"block": true}`)); err != nil {
+		t.Fatal(err)
+	}
+	go c.Compile("map:///blocked.json") // holds compiler lock, until released
+	<-block.started
+	done := make(chan *jsonschema.Schema)
+	go func() {
+		done <- c.MustCompile("map:///schema.json")
+	}()
+	select {
+	case s := <-done:
+		if s != sch {
+			t.Error("got different schema")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("schema compiled earlier is not cached")
+	}
+	close(block.release)
+
+	// replacing resource must discard schemas compiled from it
+	if err := c.AddResource("map:///schema.json", strings.NewReader(`{"type": "integer"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.MustCompile("map:///schema.json").Validate(1.0); err != nil {
+		t.Errorf("%#v", err)
+	}
+}
+
+func TestSchemaValid(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "uniqueItems": true}
+		},
+		"required": ["name"],
+		"oneOf": [{"required": ["tags"]}, {"maxProperties": 1}]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"name": "x"}`, true},
+		{`{"name": "x", "tags": ["a", "b"]}`, true},
+		{`{"name": ""}`, false},
+		{`{"tags": ["a"]}`, false},
+		{`{"name": "x", "tags": ["a", "a"]}`, false},
+		{`{"name": "x", "tags": ["c"]}`, false},
+		{`{"name": "x", "other": 1}`, false},
+		{`[]`, false},
+	}
+	for _, test := range tests {
+		v := decodeString(t, test.doc)
+		if got := sch.Valid(v); got != test.valid {
+			t.Errorf("%s: got %v, want %v", test.doc, got, test.valid)
+		}
+		if valid := sch.Validate(v) == nil; valid != test.valid {
+			t.Errorf("%s: Validate and Valid must agree", test.doc)
+		}
+	}
+}
+
+func TestValidateWithOptions(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"ids": {"items": {"type": "integer"}},
+			"name": {"type": "string"}
+		},
+		"required": ["id"]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := decodeString(t, `{"ids": ["a", "b", "c", "d", "e"], "name": 1}`)
+	leafs := func(err error) int {
+		ve, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			t.Fatalf("want ValidationError, got %v", err)
+		}
+		var count func(ve *jsonschema.ValidationError) int
+		count = func(ve *jsonschema.ValidationError) int {
+			if len(ve.Causes) == 0 {
+				return 1
+			}
+			n := 0
+			for _, c := range ve.Causes {
+				n += count(c)
+			}
+			return n
+		}
+		return count(ve)
+	}
+	tests := []struct {
+		name string
+		opts jsonschema.ValidateOptions
+		want int
+	}{
+		{"no limit", jsonschema.ValidateOptions{}, 7},
+		{"maxErrors", jsonschema.ValidateOptions{MaxErrors: 3}, 3},
+		{"maxErrors more than errors", jsonschema.ValidateOptions{MaxErrors: 100}, 7},
+		{"failFast", jsonschema.ValidateOptions{FailFast: true}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sch.ValidateWithOptions(v, test.opts)
+			if got := leafs(err); got != test.want {
+				t.Fatalf("got %d errors, want %d\n%#v", got, test.want, err)
+			}
+		})
+	}
+	if err := sch.ValidateWithOptions(decodeString(t, `{"id": 1}`), jsonschema.ValidateOptions{FailFast: true}); err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	// failing branch must not stop evaluation of other branches
+	anyOf := jsonschema.MustCompileString("anyOf.json", `{"anyOf": [{"type": "string"}, {"minLength": 1, "type": "integer"}]}`)
+	if err := anyOf.ValidateWithOptions(decodeString(t, `1`), jsonschema.ValidateOptions{FailFast: true}); err != nil {
+		t.Fatalf("%#v", err)
+	}
+}
+
+func TestInstanceLocationEscaping(t *testing.T) {
+	sch := jsonschema.MustCompileString("schema.json", `{
+		"properties": {"a/b": {"$ref": "#/$defs/%7E1x"}},
+		"additionalProperties": {"type": "string"},
+		"$defs": {"/x": {"type": "integer"}}
+	}`)
+	doc := decodeString(t, `{"a/b": "1", "m~n %": 1}`)
+	err := sch.Validate(doc)
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("want ValidationError, got %v", err)
+	}
+	var locs []string
+	for _, c := range ve.Causes {
+		locs = append(locs, c.InstanceLocation)
+		v, err := jsonpointer.Eval(doc, c.InstanceLocation)
+		if err != nil {
+			t.Errorf("%s: %v", c.InstanceLocation, err)
+		} else if v == nil {
+			t.Errorf("%s: got nil", c.InstanceLocation)
+		}
+	}
+	sort.Strings(locs)
+	if want := []string{"/a~1b", "/m~0n %"}; !reflect.DeepEqual(locs, want) {
+		t.Fatalf("got %q, want %q", locs, want)
+	}
+}
+
+func TestUniqueItemsLarge(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{"uniqueItems": true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var arr []interface{}
+	for i := 0; i < 20000; i++ {
+		arr = append(arr, json.Number(fmt.Sprint(i)), fmt.Sprint(i), map[string]interface{}{"id": json.Number(fmt.Sprint(i))})
+	}
+	if err := sch.Validate(arr); err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	for _, dup := range []interface{}{json.Number("1.0"), json.Number("1e0"), 1.0, map[string]interface{}{"id": json.Number("10")}} {
+		err := sch.Validate(append(arr[:len(arr):len(arr)], dup))
+		if err == nil {
+			t.Fatalf("validation must fail for duplicate %v", dup)
+		}
+	}
+	if err := sch.Validate(append(arr[:len(arr):len(arr)], json.Number("1.5"), -0.0, json.Number("-0"))); err == nil {
+		t.Fatal("validation must fail for -0 and 0")
+	}
+}
+
+func TestValidateGoNumbers(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{"type": "integer", "minimum": 1.5, "maximum": 100, "multipleOf": 2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		v     interface{}
+		valid bool
+	}{
+		{2, true},
+		{int8(2), true},
+		{uint64(100), true},
+		{float32(4), true},
+		{4.0, true},
+		{1, false},
+		{3, false},
+		{102, false},
+		{uint64(math.MaxUint64), false},
+		{4.5, false},
+	}
+	for _, test := range tests {
+		if valid := sch.Validate(test.v) == nil; valid != test.valid {
+			t.Errorf("%T(%v): got %v, want %v", test.v, test.v, valid, test.valid)
+		}
+	}
+}
+
+func TestMultipleOfDecimal(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{"multipleOf": 0.1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		v     interface{}
+		valid bool
+	}{
+		{0.3, true},
+		{float32(0.3), true},
+		{json.Number("0.3"), true},
+		{-0.7, true},
+		{10, true},
+		{0.30000000000000004, false}, // 0.1 + 0.2 in float64
+		{0.35, false},
+	}
+	for _, test := range tests {
+		if valid := sch.Validate(test.v) == nil; valid != test.valid {
+			t.Errorf("%T(%v): got %v, want %v", test.v, test.v, valid, test.valid)
+		}
+	}
+}
+
+func TestValidateBigNumbers(t *testing.T) {
+	// 2^53+1 is not representable as float64
+	sch, err := jsonschema.CompileString("schema.json", `{
+		"type": "integer",
+		"maximum": 9007199254740993,
+		"multipleOf": 3,
+		"not": {"const": 9007199254740990}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bigInt := func(s string) *big.Int {
+		i, _ := new(big.Int).SetString(s, 10)
+		return i
+	}
+	bigFloat := func(s string) *big.Float {
+		f, _, _ := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+		return f
+	}
+	bigRat := func(s string) *big.Rat {
+		r, _ := new(big.Rat).SetString(s)
+		return r
+	}
+	tests := []struct {
+		v     interface{}
+		valid bool
+	}{
+		{json.Number("9007199254740993"), true},
+		{json.Number("9007199254740996"), false}, // maximum
+		{json.Number("9007199254740992"), false}, // multipleOf
+		{json.Number("9007199254740990"), false}, // const
+		{bigInt("9007199254740993"), true},
+		{bigInt("9007199254740996"), false},
+		{bigInt("9007199254740992"), false},
+		{bigInt("-36893488147419103230"), true},
+		{bigInt("-36893488147419103231"), false},
+		{bigFloat("9007199254740993"), true},
+		{bigFloat("9007199254740992"), false},
+		{bigFloat("4.5"), false}, // type
+		{bigRat("9007199254740993"), true},
+		{bigRat("9/2"), false},
+		{bigRat("18/2"), true},
+	}
+	for _, test := range tests {
+		if valid := sch.Validate(test.v) == nil; valid != test.valid {
+			t.Errorf("%T(%v): got %v, want %v", test.v, test.v, valid, test.valid)
+		}
+	}
+
+	sch, err = jsonschema.CompileString("schema.json", `{"uniqueItems": true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate([]interface{}{bigInt("9007199254740993"), json.Number("9007199254740992")}); err != nil {
+		t.Errorf("numbers differing beyond float64 precision must be unique: %v", err)
+	}
+	if err := sch.Validate([]interface{}{bigInt("9007199254740993"), bigRat("9007199254740993")}); err == nil {
+		t.Error("equal numbers must not be unique")
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	sch, err := jsonschema.CompileString("schema.json", `{"properties": {"id": {"const": 9007199254740993}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := jsonschema.DecodeJSON(strings.NewReader(`{"id": 9007199254740993}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate(v); err != nil {
+		t.Errorf("decoded doc must be valid: %v", err)
+	}
+	v, err = jsonschema.DecodeJSON(strings.NewReader(`{"id": 9007199254740992}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate(v); err == nil {
+		t.Error("decoded doc must not be valid")
+	}
+	if _, err := jsonschema.DecodeJSON(strings.NewReader(`{} {}`)); err == nil {
+		t.Error("error expected for multiple json values")
+	}
+}
+
 func TestFilePathSpaces(t *testing.T) {
 	if _, err := jsonschema.Compile("testdata/person schema.json"); err != nil {
 		t.Fatal(err)
@@ -723,3 +1307,119 @@ func decodeReader(t *testing.T, r io.Reader) interface{} {
 	}
 	return doc
 }
+
+func TestCompiler_CollectErrors(t *testing.T) {
+	schema := `{
+		"properties": {
+			"a": {"$ref": "#/$defs/missing"},
+			"b": {"pattern": "(?i)abc"},
+			"c": {"format": "unknown-format"},
+			"d": {"type": "string"}
+		},
+		"items": {"$ref": "#/$defs/missing2"}
+	}`
+	newCompiler := func() *jsonschema.Compiler {
+		c := jsonschema.NewCompiler()
+		c.StrictRegex = true
+		c.AssertFormat = true
+		c.DisallowUnknownFormats = true
+		if err := c.AddResource("schema.json", strings.NewReader(schema)); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	t.Run("default", func(t *testing.T) {
+		_, err := newCompiler().Compile("schema.json")
+		var errs jsonschema.CompileErrors
+		if err == nil || errors.As(err, &errs) {
+			t.Fatalf("got %v, want single error", err)
+		}
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		c := newCompiler()
+		c.CollectErrors = true
+		_, err := c.Compile("schema.json")
+		var errs jsonschema.CompileErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("got %v, want CompileErrors", err)
+		}
+		if len(errs) != 4 {
+			t.Fatalf("got %d errors, want 4: %v", len(errs), err)
+		}
+		for _, want := range []string{"#/$defs/missing ", "#/$defs/missing2 ", `"(?i)abc"`, `"unknown-format"`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error must mention %s: %v", want, err)
+			}
+		}
+
+		// partially compiled schemas must not be cached
+		if _, err := c.Compile("schema.json"); err == nil {
+			t.Fatal("compiling again must fail")
+		}
+	})
+}
+
+func TestCompiler_anchors(t *testing.T) {
+	tests := []struct {
+		description string
+		schema      string
+		dup         bool
+	}{
+		{
+			"anchor",
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$ref": "#pos", "$defs": {"a": {"$anchor": "pos", "minimum": 0}}}`,
+			false,
+		},
+		{
+			"plain-name id",
+			`{"$schema": "http://json-schema.org/draft-07/schema#", "$ref": "#pos", "definitions": {"a": {"$id": "#pos", "minimum": 0}}}`,
+			false,
+		},
+		{
+			"plain-name id draft4",
+			`{"$schema": "http://json-schema.org/draft-04/schema#", "allOf": [{"$ref": "#pos"}], "definitions": {"a": {"id": "#pos", "minimum": 0}}}`,
+			false,
+		},
+		{
+			"same anchor in different resources",
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$ref": "#pos", "$defs": {"a": {"$anchor": "pos", "minimum": 0}, "b": {"$id": "b.json", "$anchor": "pos"}}}`,
+			false,
+		},
+		{
+			"duplicate anchor",
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$defs": {"a": {"$anchor": "pos"}, "b": {"$anchor": "pos"}}}`,
+			true,
+		},
+		{
+			"duplicate plain-name id",
+			`{"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {"a": {"$id": "#pos"}, "b": {"items": {"$id": "#pos"}}}}`,
+			true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			if err := c.AddResource("http://example.com/schema.json", strings.NewReader(test.schema)); err != nil {
+				t.Fatal(err)
+			}
+			sch, err := c.Compile("http://example.com/schema.json")
+			if test.dup {
+				if err == nil || !strings.Contains(err.Error(), `have same anchor "pos"`) {
+					t.Fatalf("got %v, want duplicate anchor error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			if err := sch.Validate(-1); err == nil {
+				t.Fatal("validation must fail")
+			}
+			if got := sch.Resolve("#pos", false); got == nil || !strings.HasSuffix(got.Location, "/a") {
+				t.Fatalf("Resolve: got %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package schemaconv
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+// Avro converts sch into Avro schema, encoded in json. name and namespace
+// are used for the record generated for sch, if it is an object with
+// properties. Records for subschemas are named after their location or
+// property.
+//
+// The mapping from json-schema to Avro is as follows:
+//   - object with properties is mapped to record. Optional properties are
+//     mapped to union with "null", and default null.
+//   - object without properties is mapped to map, with values from
+//     additionalProperties.
+//   - array is mapped to array, with items from items.
+//   - string, integer, number, boolean, null are mapped to string, long,
+//     double, boolean, null. string with format uuid has logicalType uuid.
+//   - string enum is mapped to enum, if values are valid Avro symbols.
+//   - multiple types, i.e. "type": ["T1", "T2"], "oneOf" and "anyOf" are
+//     mapped to union.
+//   - anything else is mapped to string holding json text.
+func Avro(sch *jsonschema.Schema, name, namespace string) ([]byte, Losses) {
+	a := &avroConverter{
+		names: make(map[*jsonschema.Schema]string),
+		used:  make(map[string]bool),
+	}
+	if name == "" {
+		name = typeName(schemautil.LocationName(sch.Location))
+	}
+	t := a.typ(sch, typeName(name))
+	if rec, ok := t.(*avroRecord); ok {
+		rec.Namespace = namespace
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		panic(err) // all values are json friendly
+	}
+	return buf.Bytes(), a.list
+}
+
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name    string          `json:"name"`
+	Doc     string          `json:"doc,omitempty"`
+	Type    interface{}     `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type avroEnum struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Doc     string   `json:"doc,omitempty"`
+	Symbols []string `json:"symbols"`
+}
+
+type avroConverter struct {
+	names map[*jsonschema.Schema]string // named types generated
+	used  map[string]bool               // names already used
+	losses
+}
+
+// typ returns the Avro type for sch. hint is used as name, if a named
+// type has to be generated.
+func (a *avroConverter) typ(sch *jsonschema.Schema, hint string) interface{} {
+	if ref := schemautil.RefOf(sch); ref != nil && schemautil.IsRefOnly(sch) {
+		return a.typ(ref, typeName(schemautil.LocationName(ref.Location)))
+	}
+	if len(sch.AllOf) == 1 && schemautil.IsRefOnly(sch) {
+		return a.typ(sch.AllOf[0], hint)
+	}
+	if name, ok := a.names[sch]; ok {
+		return name
+	}
+	a.assertions(sch)
+	if sch.Always != nil {
+		return a.any(sch, "type")
+	}
+	if len(sch.AllOf) > 0 && !schemautil.HasProperties(sch) {
+		a.add(sch, "allOf", "is not enforced")
+	}
+	if len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 {
+		alts, keyword := sch.AnyOf, "anyOf"
+		if len(sch.OneOf) > 0 {
+			alts, keyword = sch.OneOf, "oneOf"
+			a.add(sch, keyword, "is mapped to union; exclusivity is not enforced")
+		}
+		var list []interface{}
+		for i, alt := range alts {
+			list = append(list, a.typ(alt, hint+strconv.Itoa(i)))
+		}
+		return a.union(sch, keyword, list)
+	}
+
+	types, nullable := schemautil.TypesOf(sch, false)
+	if len(types) == 0 {
+		if nullable {
+			return "null"
+		}
+		return a.any(sch, "type")
+	}
+	var list []interface{}
+	if nullable {
+		list = append(list, "null")
+	}
+	for _, t := range types {
+		list = append(list, a.typeOf(sch, t, hint))
+	}
+	return a.union(sch, "type", list)
+}
+
+// typeOf returns the Avro type for sch, when value is of json type t.
+func (a *avroConverter) typeOf(sch *jsonschema.Schema, t, hint string) interface{} {
+	switch t {
+	case "string":
+		if values := schemautil.EnumValues(sch); len(values) > 0 {
+			return a.enum(sch, hint, values)
+		}
+		if sch.Format == "uuid" {
+			return map[string]interface{}{"type": "string", "logicalType": "uuid"}
+		}
+		return "string"
+	case "integer":
+		return "long"
+	case "number":
+		return "double"
+	case "boolean":
+		return "boolean"
+	case "array":
+		items := itemSchema(sch)
+		if items == nil {
+			if sch.Items != nil || len(sch.PrefixItems) > 0 {
+				a.add(sch, "prefixItems", "tuple is mapped to array of any type")
+			}
+			return map[string]interface{}{"type": "array", "items": a.any(sch, "items")}
+		}
+		return map[string]interface{}{"type": "array", "items": a.typ(items, hint+"Item")}
+	case "object":
+		if schemautil.HasProperties(sch) {
+			return a.record(sch, hint)
+		}
+		if ap, ok := sch.AdditionalProperties.(*jsonschema.Schema); ok {
+			return map[string]interface{}{"type": "map", "values": a.typ(ap, hint+"Value")}
+		}
+		return map[string]interface{}{"type": "map", "values": a.any(sch, "additionalProperties")}
+	}
+	return a.any(sch, "type")
+}
+
+func (a *avroConverter) record(sch *jsonschema.Schema, hint string) interface{} {
+	rec := &avroRecord{Type: "record", Name: schemautil.UniqueName(a.used, hint), Doc: description(sch)}
+	a.names[sch] = rec.Name
+	switch ap := sch.AdditionalProperties.(type) {
+	case *jsonschema.Schema:
+		a.add(sch, "additionalProperties", "additional properties are dropped")
+	case bool:
+		if ap {
+			a.add(sch, "additionalProperties", "additional properties are dropped")
+		}
+	}
+
+	props, required := schemautil.Properties(sch)
+	fieldNames := make(map[string]bool)
+	for _, pname := range sortedKeys(props) {
+		psch := props[pname]
+		fname := pname
+		if !isIdentifier(pname) {
+			fname = identifier(strings.Join(words(pname), "_"), "field")
+		}
+		fname = schemautil.UniqueName(fieldNames, fname)
+		if fname != pname {
+			a.add(sch, "properties", "property %q is renamed to %q", pname, fname)
+		}
+		f := avroField{Name: fname, Doc: description(psch), Type: a.typ(psch, rec.Name+typeName(pname))}
+		if !required[pname] {
+			f.Type = a.union(psch, "required", []interface{}{"null", f.Type})
+			f.Default = json.RawMessage("null")
+		}
+		rec.Fields = append(rec.Fields, f)
+	}
+	return rec
+}
+
+func (a *avroConverter) enum(sch *jsonschema.Schema, hint string, values []string) interface{} {
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if !isIdentifier(v) || seen[v] {
+			a.add(sch, "enum", "is mapped to string, since values are not valid avro symbols")
+			return "string"
+		}
+		seen[v] = true
+	}
+	e := &avroEnum{Type: "enum", Name: schemautil.UniqueName(a.used, hint), Doc: description(sch), Symbols: values}
+	a.names[sch] = e.Name
+	return e
+}
+
+// union returns union of given types. nested unions are flattened and
+// duplicates are removed. If the types cannot form avro union, string
+// holding json text is returned.
+func (a *avroConverter) union(sch *jsonschema.Schema, keyword string, list []interface{}) interface{} {
+	var types []interface{}
+	var flatten func(list []interface{})
+	flatten = func(list []interface{}) {
+		for _, t := range list {
+			if l, ok := t.([]interface{}); ok {
+				flatten(l)
+			} else {
+				types = append(types, t)
+			}
+		}
+	}
+	flatten(list)
+
+	var result []interface{}
+	kinds := make(map[string]bool)
+	for _, t := range types {
+		kind := avroKind(t)
+		if kinds[kind] {
+			if kind == "array" || kind == "map" {
+				a.add(sch, keyword, "union of more than one %s is not representable", kind)
+				return a.any(sch, keyword)
+			}
+			continue // same primitive or named type
+		}
+		kinds[kind] = true
+		result = append(result, t)
+	}
+	if len(result) == 1 {
+		return result[0]
+	}
+	return result
+}
+
+// avroKind returns the kind of avro type t, which must be unique
+// in a union.
+func avroKind(t interface{}) string {
+	switch t := t.(type) {
+	case string:
+		return t
+	case *avroRecord:
+		return t.Name
+	case *avroEnum:
+		return t.Name
+	case map[string]interface{}:
+		return t["type"].(string)
+	}
+	return ""
+}
+
+// any reports loss, and returns string which holds json text.
+func (a *avroConverter) any(sch *jsonschema.Schema, keyword string) interface{} {
+	a.add(sch, keyword, "value of any type is mapped to string holding json text")
+	return "string"
+}
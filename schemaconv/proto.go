@@ -0,0 +1,272 @@
+package schemaconv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+// Proto converts sch into protobuf message definitions, in proto3 syntax.
+// pkg is the protobuf package, and name is used for the message generated
+// for sch. If sch is not an object with properties, it is wrapped in a
+// message with single field named value.
+//
+// The mapping from json-schema to protobuf is as follows:
+//   - object with properties is mapped to message, with field for each
+//     property. Field numbers are assigned in sorted order of property
+//     names. Optional scalar properties are marked optional.
+//   - object without properties is mapped to map<string, T>, where T is
+//     from additionalProperties, or google.protobuf.Struct if not specified.
+//   - array is mapped to repeated field, with type from items.
+//   - string, integer, number, boolean are mapped to string, int64,
+//     double, bool.
+//   - anything else is mapped to google.protobuf.Value.
+//
+// Note that messages converted from same schema with different field
+// order are wire incompatible. Hence use the generated definitions as a
+// starting point, rather than regenerating them for each schema change.
+func Proto(sch *jsonschema.Schema, pkg, name string) ([]byte, Losses) {
+	p := &protoConverter{
+		names: make(map[*jsonschema.Schema]string),
+		used:  make(map[string]bool),
+	}
+	if name == "" {
+		name = typeName(schemautil.LocationName(sch.Location))
+	}
+	name = typeName(name)
+	if t := p.typ(sch, name); t.kind != protoMessage {
+		p.add(sch, "type", "is wrapped in message %s with field value", name)
+		var decl strings.Builder
+		fmt.Fprintf(&decl, "message %s {\n", schemautil.UniqueName(p.used, name))
+		p.writeField(&decl, sch, t, "value", "", !t.nullable, 1)
+		decl.WriteString("}\n")
+		p.decls = append(p.decls, decl.String())
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated from json-schema. DO NOT EDIT.\n\n")
+	buf.WriteString("syntax = \"proto3\";\n")
+	if pkg != "" {
+		fmt.Fprintf(&buf, "\npackage %s;\n", pkg)
+	}
+	if p.wkt {
+		buf.WriteString("\nimport \"google/protobuf/struct.proto\";\n")
+	}
+	for _, decl := range p.decls {
+		buf.WriteByte('\n')
+		buf.WriteString(decl)
+	}
+	return buf.Bytes(), p.list
+}
+
+type protoKind int
+
+const (
+	protoScalar protoKind = iota
+	protoMessage
+	protoRepeated // name is type of items
+	protoMap      // name is map<string, T>
+)
+
+type protoType struct {
+	name     string
+	kind     protoKind
+	nullable bool
+}
+
+type protoConverter struct {
+	names map[*jsonschema.Schema]string // messages generated
+	used  map[string]bool               // names already used
+	decls []string
+	wkt   bool // whether google/protobuf/struct.proto is used
+	losses
+}
+
+// typ returns the protobuf type for sch. hint is used as name, if
+// a message has to be generated.
+func (p *protoConverter) typ(sch *jsonschema.Schema, hint string) protoType {
+	if ref := schemautil.RefOf(sch); ref != nil && schemautil.IsRefOnly(sch) {
+		return p.typ(ref, typeName(schemautil.LocationName(ref.Location)))
+	}
+	if len(sch.AllOf) == 1 && schemautil.IsRefOnly(sch) {
+		return p.typ(sch.AllOf[0], hint)
+	}
+	if name, ok := p.names[sch]; ok {
+		return protoType{name: name, kind: protoMessage}
+	}
+	p.assertions(sch)
+	if sch.Always != nil {
+		return p.wellKnown("Value")
+	}
+	if len(sch.AllOf) > 0 && !schemautil.HasProperties(sch) {
+		p.add(sch, "allOf", "is not enforced")
+	}
+	if len(sch.OneOf) > 0 {
+		p.add(sch, "oneOf", "is mapped to google.protobuf.Value")
+		return p.wellKnown("Value")
+	}
+	if len(sch.AnyOf) > 0 {
+		p.add(sch, "anyOf", "is mapped to google.protobuf.Value")
+		return p.wellKnown("Value")
+	}
+
+	types, nullable := schemautil.TypesOf(sch, false)
+	if len(types) != 1 {
+		if len(types) > 1 {
+			p.add(sch, "type", "multiple types are mapped to google.protobuf.Value")
+		}
+		return p.wellKnown("Value")
+	}
+	var t protoType
+	switch types[0] {
+	case "string":
+		if len(schemautil.EnumValues(sch)) > 0 {
+			p.add(sch, "enum", "is mapped to string")
+		}
+		t = protoType{name: "string"}
+	case "integer":
+		t = protoType{name: "int64"}
+	case "number":
+		t = protoType{name: "double"}
+	case "boolean":
+		t = protoType{name: "bool"}
+	case "array":
+		items := itemSchema(sch)
+		if items == nil {
+			if sch.Items != nil || len(sch.PrefixItems) > 0 {
+				p.add(sch, "prefixItems", "tuple is mapped to repeated google.protobuf.Value")
+			}
+			t = p.wellKnown("Value")
+		} else {
+			t = p.typ(items, hint+"Item")
+			switch t.kind {
+			case protoRepeated:
+				p.add(sch, "items", "array of arrays is mapped to repeated google.protobuf.ListValue")
+				t = p.wellKnown("ListValue")
+			case protoMap:
+				p.add(sch, "items", "array of maps is mapped to repeated google.protobuf.Struct")
+				t = p.wellKnown("Struct")
+			}
+			if t.nullable {
+				p.add(items, "type", "null items are not representable")
+			}
+		}
+		t = protoType{name: t.name, kind: protoRepeated}
+	case "object":
+		if schemautil.HasProperties(sch) {
+			t = protoType{name: p.message(sch, hint), kind: protoMessage}
+			break
+		}
+		ap, ok := sch.AdditionalProperties.(*jsonschema.Schema)
+		if !ok {
+			t = p.wellKnown("Struct")
+			break
+		}
+		v := p.typ(ap, hint+"Value")
+		if v.kind == protoRepeated || v.kind == protoMap {
+			p.add(ap, "type", "map value is mapped to google.protobuf.Value")
+			v = p.wellKnown("Value")
+		}
+		t = protoType{name: "map<string, " + v.name + ">", kind: protoMap}
+	default:
+		return p.wellKnown("Value")
+	}
+	t.nullable = nullable
+	return t
+}
+
+// wellKnown returns the type google.protobuf.<name>.
+func (p *protoConverter) wellKnown(name string) protoType {
+	p.wkt = true
+	return protoType{name: "google.protobuf." + name, kind: protoMessage}
+}
+
+// message generates message for sch, and returns its name.
+func (p *protoConverter) message(sch *jsonschema.Schema, hint string) string {
+	name := schemautil.UniqueName(p.used, hint)
+	p.names[sch] = name
+	index := len(p.decls)
+	p.decls = append(p.decls, "") // reserve, so that it precedes messages it uses
+
+	var decl strings.Builder
+	writeComment(&decl, "", description(sch))
+	fmt.Fprintf(&decl, "message %s {\n", name)
+	switch ap := sch.AdditionalProperties.(type) {
+	case *jsonschema.Schema:
+		p.add(sch, "additionalProperties", "additional properties are dropped")
+	case bool:
+		if ap {
+			p.add(sch, "additionalProperties", "additional properties are dropped")
+		}
+	}
+	props, required := schemautil.Properties(sch)
+	if len(required) > 0 {
+		p.add(sch, "required", "is not enforced")
+	}
+	fieldNames := make(map[string]bool)
+	for i, pname := range sortedKeys(props) {
+		psch := props[pname]
+		fname := identifier(strings.ToLower(strings.Join(words(pname), "_")), "field")
+		fname = schemautil.UniqueName(fieldNames, fname)
+		t := p.typ(psch, name+typeName(pname))
+		writeComment(&decl, "  ", description(psch))
+		p.writeField(&decl, psch, t, fname, pname, required[pname] && !t.nullable, i+1)
+	}
+	decl.WriteString("}\n")
+	p.decls[index] = decl.String()
+	return name
+}
+
+// writeField writes field declaration with given field name and number.
+// jsonName is the property name, for which the field is generated.
+func (p *protoConverter) writeField(w *strings.Builder, sch *jsonschema.Schema, t protoType, fname, jsonName string, required bool, num int) {
+	label := ""
+	switch t.kind {
+	case protoScalar:
+		if !required {
+			label = "optional "
+		}
+	case protoRepeated:
+		label = "repeated "
+	}
+	if t.nullable && (t.kind == protoRepeated || t.kind == protoMap) {
+		p.add(sch, "type", "null is treated as empty")
+	}
+	fmt.Fprintf(w, "  %s%s %s = %d", label, t.name, fname, num)
+	if jsonName != "" && jsonName != protoJSONName(fname) {
+		fmt.Fprintf(w, " [json_name = %s]", strconv.Quote(jsonName))
+	}
+	w.WriteString(";\n")
+}
+
+// protoJSONName returns the json name, protoc uses by default for
+// given field name.
+func protoJSONName(fname string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range fname {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func writeComment(w *strings.Builder, indent, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(w, "%s// %s\n", indent, line)
+	}
+}
@@ -0,0 +1,224 @@
+// Package schemaconv converts compiled json-schemas into Avro schemas and
+// protobuf message definitions, so that json-schema can be the source of
+// truth for Kafka and gRPC toolchains.
+//
+// Typical usage:
+//
+//	sch, err := jsonschema.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//	avsc, losses := schemaconv.Avro(sch, "Person", "com.example")
+//	if len(losses) > 0 {
+//		fmt.Print(losses)
+//	}
+//	proto, _ := schemaconv.Proto(sch, "example.v1", "Person")
+//
+// The conversion is lossy: json-schema can describe constraints that
+// have no equivalent in Avro or protobuf. Each such construct is reported
+// as Loss. The common lossy cases are:
+//   - assertions like "pattern", "minimum", "maxItems", "const" and "not"
+//     are dropped; the converted schema accepts values which violate them.
+//   - additional properties of objects with "properties" are dropped.
+//   - property names which are not valid identifiers are renamed. In
+//     protobuf, json_name option preserves the original name in proto-JSON.
+//   - values which may be of any type are mapped to string holding json
+//     text in Avro, and google.protobuf.Value in protobuf.
+//   - "oneOf" and "anyOf" are mapped to union in Avro, where exclusivity
+//     of oneOf is not enforced. In protobuf they are mapped to
+//     google.protobuf.Value.
+//   - string enums are mapped to string in protobuf, because proto-JSON
+//     encodes enum values by their identifiers.
+//
+// Like codegen, objects with properties and string enums referenced via
+// $ref are converted to named types, and properties are converted in
+// sorted order of their names, since compiled schema does not preserve
+// their order.
+package schemaconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+// Loss describes a json-schema construct which is not represented
+// exactly in the converted schema.
+type Loss struct {
+	Location string // location of the schema
+	Keyword  string // keyword which is not represented
+	Message  string
+}
+
+func (l Loss) String() string {
+	return fmt.Sprintf("%s: %s: %s", l.Location, l.Keyword, l.Message)
+}
+
+// Losses is the list of losses reported by a conversion.
+type Losses []Loss
+
+func (ls Losses) String() string {
+	var b strings.Builder
+	for _, l := range ls {
+		b.WriteString(l.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// losses collects losses, reporting each schema atmost once.
+type losses struct {
+	list Losses
+	seen map[*jsonschema.Schema]bool
+}
+
+func (ls *losses) add(sch *jsonschema.Schema, keyword, format string, a ...interface{}) {
+	ls.list = append(ls.list, Loss{sch.Location, keyword, fmt.Sprintf(format, a...)})
+}
+
+// assertions reports the assertions in sch, which are not converted.
+func (ls *losses) assertions(sch *jsonschema.Schema) {
+	if ls.seen == nil {
+		ls.seen = make(map[*jsonschema.Schema]bool)
+	}
+	if ls.seen[sch] {
+		return
+	}
+	ls.seen[sch] = true
+	add := func(keyword string, present bool) {
+		if present {
+			ls.add(sch, keyword, "is not enforced")
+		}
+	}
+	add("format", sch.Format != "")
+	add("pattern", sch.Pattern != nil)
+	add("minLength", sch.MinLength != -1)
+	add("maxLength", sch.MaxLength != -1)
+	add("minimum", sch.Minimum != nil)
+	add("maximum", sch.Maximum != nil)
+	add("exclusiveMinimum", sch.ExclusiveMinimum != nil)
+	add("exclusiveMaximum", sch.ExclusiveMaximum != nil)
+	add("multipleOf", sch.MultipleOf != nil)
+	add("minItems", sch.MinItems != -1)
+	add("maxItems", sch.MaxItems != -1)
+	add("uniqueItems", sch.UniqueItems)
+	add("contains", sch.Contains != nil)
+	add("minProperties", sch.MinProperties != -1)
+	add("maxProperties", sch.MaxProperties != -1)
+	add("propertyNames", sch.PropertyNames != nil)
+	add("patternProperties", len(sch.PatternProperties) > 0)
+	add("dependencies", len(sch.Dependencies) > 0)
+	add("dependentRequired", len(sch.DependentRequired) > 0)
+	add("dependentSchemas", len(sch.DependentSchemas) > 0)
+	add("const", len(sch.Constant) > 0)
+	add("not", sch.Not != nil)
+	add("if", sch.If != nil)
+	add("unevaluatedProperties", sch.UnevaluatedProperties != nil)
+	add("unevaluatedItems", sch.UnevaluatedItems != nil)
+	if len(sch.Enum) > 0 && len(schemautil.EnumValues(sch)) == 0 {
+		ls.add(sch, "enum", "is not enforced")
+	}
+	if sch.Always != nil && !*sch.Always {
+		ls.add(sch, "false", "schema which rejects everything is not representable")
+	}
+}
+
+func sortedKeys(m map[string]*jsonschema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// itemSchema returns the schema of array items, if all items have
+// same schema.
+func itemSchema(sch *jsonschema.Schema) *jsonschema.Schema {
+	switch items := sch.Items.(type) {
+	case *jsonschema.Schema:
+		return items
+	case []*jsonschema.Schema:
+		return nil
+	}
+	if len(sch.PrefixItems) == 0 {
+		return sch.Items2020
+	}
+	return nil
+}
+
+func description(sch *jsonschema.Schema) string {
+	if desc := strings.TrimSpace(sch.Description); desc != "" {
+		return desc
+	}
+	return strings.TrimSpace(sch.Title)
+}
+
+// words splits s into words, at non alphanumeric characters and
+// lower to upper case transitions.
+func words(s string) []string {
+	var list []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			list = append(list, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return list
+}
+
+// typeName converts s into CamelCase identifier, valid in both Avro
+// and protobuf.
+func typeName(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return identifier(b.String(), "Value")
+}
+
+// identifier returns s, prefixed with underscore if it starts with digit.
+// def is returned if s is empty.
+func identifier(s, def string) string {
+	if s == "" {
+		return def
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		return "_" + s
+	}
+	return s
+}
+
+// isIdentifier tells whether s matches [A-Za-z_][A-Za-z0-9_]*.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package schemaconv_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/schemaconv"
+)
+
+const personSchema = `{
+	"description": "a person",
+	"type": "object",
+	"properties": {
+		"id": {"type": "string", "format": "uuid"},
+		"firstName": {"type": "string", "minLength": 1},
+		"age": {"type": ["integer", "null"]},
+		"color": {"enum": ["RED", "GREEN"]},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"attrs": {"type": "object", "additionalProperties": {"type": "number"}},
+		"address": {"$ref": "#/$defs/address"},
+		"friends": {"type": "array", "items": {"$ref": "#"}},
+		"contact": {"oneOf": [{"type": "string"}, {"$ref": "#/$defs/address"}]},
+		"x-y": {"type": "boolean"}
+	},
+	"required": ["id", "firstName"],
+	"$defs": {
+		"address": {"type": "object", "properties": {"street": {"type": "string"}}}
+	}
+}`
+
+func compile(t *testing.T, schema string) *jsonschema.Schema {
+	t.Helper()
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("https://example.com/person.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("https://example.com/person.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func lossStrings(losses schemaconv.Losses) []string {
+	var list []string
+	for _, l := range losses {
+		list = append(list, strings.TrimPrefix(l.String(), "https://example.com/person.json"))
+	}
+	return list
+}
+
+func TestAvro(t *testing.T) {
+	sch := compile(t, personSchema)
+	got, losses := schemaconv.Avro(sch, "Person", "com.example")
+	want := `{
+		"type": "record", "name": "Person", "namespace": "com.example", "doc": "a person",
+		"fields": [
+			{"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [
+				{"name": "street", "type": ["null", "string"], "default": null}
+			]}], "default": null},
+			{"name": "age", "type": ["null", "long"], "default": null},
+			{"name": "attrs", "type": ["null", {"type": "map", "values": "double"}], "default": null},
+			{"name": "color", "type": ["null", {"type": "enum", "name": "PersonColor", "symbols": ["RED", "GREEN"]}], "default": null},
+			{"name": "contact", "type": ["null", "string", "Address"], "default": null},
+			{"name": "firstName", "type": "string"},
+			{"name": "friends", "type": ["null", {"type": "array", "items": "Person"}], "default": null},
+			{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+			{"name": "tags", "type": ["null", {"type": "array", "items": "string"}], "default": null},
+			{"name": "x_y", "type": ["null", "boolean"], "default": null}
+		]
+	}`
+	var gotv, wantv interface{}
+	if err := json.Unmarshal(got, &gotv); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantv); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotv, wantv) {
+		t.Errorf("got:\n%s", got)
+	}
+
+	wantLosses := []string{
+		"#/properties/contact: oneOf: is mapped to union; exclusivity is not enforced",
+		"#/properties/firstName: minLength: is not enforced",
+		"#/properties/id: format: is not enforced",
+		`#: properties: property "x-y" is renamed to "x_y"`,
+	}
+	if got := lossStrings(losses); !reflect.DeepEqual(got, wantLosses) {
+		t.Errorf("got losses:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestAvro_Any(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+		loss   string
+	}{
+		{`{}`, `"string"`, "#: type: value of any type is mapped to string holding json text"},
+		{`{"type": ["string", "null"]}`, `["null", "string"]`, ""},
+		{`{"oneOf": [{"type": "array"}, {"type": "array", "items": {"type": "string"}}]}`, `"string"`, "#: oneOf: union of more than one array is not representable"},
+		{`{"enum": ["a-b", "c"]}`, `"string"`, "#: enum: is mapped to string, since values are not valid avro symbols"},
+	}
+	for _, test := range tests {
+		got, losses := schemaconv.Avro(compile(t, test.schema), "", "")
+		var gotv, wantv interface{}
+		_ = json.Unmarshal(got, &gotv)
+		_ = json.Unmarshal([]byte(test.want), &wantv)
+		if !reflect.DeepEqual(gotv, wantv) {
+			t.Errorf("%s: got %s, want %s", test.schema, got, test.want)
+		}
+		if test.loss != "" && !strings.Contains(strings.Join(lossStrings(losses), "\n"), test.loss) {
+			t.Errorf("%s: got losses %v, want %q", test.schema, losses, test.loss)
+		}
+	}
+}
+
+func TestProto(t *testing.T) {
+	sch := compile(t, personSchema)
+	got, losses := schemaconv.Proto(sch, "example.v1", "Person")
+	want := `// Code generated from json-schema. DO NOT EDIT.
+
+syntax = "proto3";
+
+package example.v1;
+
+import "google/protobuf/struct.proto";
+
+// a person
+message Person {
+  Address address = 1;
+  optional int64 age = 2;
+  map<string, double> attrs = 3;
+  optional string color = 4;
+  google.protobuf.Value contact = 5;
+  string first_name = 6;
+  repeated Person friends = 7;
+  string id = 8;
+  repeated string tags = 9;
+  optional bool x_y = 10 [json_name = "x-y"];
+}
+
+message Address {
+  optional string street = 1;
+}
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s", got)
+	}
+
+	wantLosses := []string{
+		"#: required: is not enforced",
+		"#/properties/color: enum: is mapped to string",
+		"#/properties/contact: oneOf: is mapped to google.protobuf.Value",
+		"#/properties/firstName: minLength: is not enforced",
+		"#/properties/id: format: is not enforced",
+	}
+	if got := lossStrings(losses); !reflect.DeepEqual(got, wantLosses) {
+		t.Errorf("got losses:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestProto_Wrapped(t *testing.T) {
+	sch := compile(t, `{"type": "array", "items": {"type": "array", "items": {"type": "integer"}}}`)
+	got, losses := schemaconv.Proto(sch, "", "Matrix")
+	want := `// Code generated from json-schema. DO NOT EDIT.
+
+syntax = "proto3";
+
+import "google/protobuf/struct.proto";
+
+message Matrix {
+  repeated google.protobuf.ListValue value = 1;
+}
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s", got)
+	}
+	wantLosses := []string{
+		"#: items: array of arrays is mapped to repeated google.protobuf.ListValue",
+		"#: type: is wrapped in message Matrix with field value",
+	}
+	if got := lossStrings(losses); !reflect.DeepEqual(got, wantLosses) {
+		t.Errorf("got losses:\n%s", strings.Join(got, "\n"))
+	}
+}
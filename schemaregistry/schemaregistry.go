@@ -0,0 +1,297 @@
+// Package schemaregistry is a client for Confluent compatible schema
+// registries, which fetches json-schemas with their references, and
+// verifies Kafka payloads framed with schema id.
+//
+// Typical usage:
+//
+//	client := &schemaregistry.Client{URL: "http://localhost:8081"}
+//
+//	// consumer
+//	v, err := client.Verify(msg.Value)
+//
+//	// producer
+//	sch, id, err := client.Compile("orders-value", "latest")
+//	if err != nil {
+//		return err
+//	}
+//	if err := sch.Validate(order); err != nil {
+//		return err
+//	}
+//	msg.Value = schemaregistry.Frame(id, orderJSON)
+//
+// The references of a schema are fetched recursively, and added to the
+// compiler under their names, resolved against the base uri of the
+// referencing schema. Hence a reference named "address.json" satisfies
+// {"$ref": "address.json"}.
+//
+// Compiled schemas are cached by schema id, since registry never changes
+// the schema of an id.
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Reference is a reference from a schema to schema of other subject.
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// SchemaInfo is a schema registered in registry.
+type SchemaInfo struct {
+	Subject    string      `json:"subject,omitempty"`
+	Version    int         `json:"version,omitempty"`
+	ID         int         `json:"id"`
+	SchemaType string      `json:"schemaType,omitempty"` // empty means AVRO
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// Client fetches schemas from registry. It is safe for concurrent use,
+// once configured.
+type Client struct {
+	// URL is the base url of registry.
+	URL string
+
+	// HTTPClient is used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// NewCompiler returns the compiler used to compile the schemas.
+	// If nil, jsonschema.NewCompiler is used.
+	NewCompiler func() *jsonschema.Compiler
+
+	mu   sync.Mutex
+	byID map[int]*jsonschema.Schema
+}
+
+// Subject fetches the schema registered under given subject and version.
+// version is either a version number or "latest".
+func (c *Client) Subject(subject, version string) (*SchemaInfo, error) {
+	info := &SchemaInfo{}
+	if err := c.get("/subjects/"+url.PathEscape(subject)+"/versions/"+url.PathEscape(version), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// SchemaByID fetches the schema with given id.
+func (c *Client) SchemaByID(id int) (*SchemaInfo, error) {
+	info := &SchemaInfo{}
+	if err := c.get("/schemas/ids/"+strconv.Itoa(id), info); err != nil {
+		return nil, err
+	}
+	info.ID = id
+	return info, nil
+}
+
+// Compile fetches and compiles the schema registered under given subject
+// and version. Returns the compiled schema along with its id.
+func (c *Client) Compile(subject, version string) (*jsonschema.Schema, int, error) {
+	info, err := c.Subject(subject, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	if sch := c.cached(info.ID); sch != nil {
+		return sch, info.ID, nil
+	}
+	sch, err := c.compile(info)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sch, info.ID, nil
+}
+
+// CompileID fetches and compiles the schema with given id.
+func (c *Client) CompileID(id int) (*jsonschema.Schema, error) {
+	if sch := c.cached(id); sch != nil {
+		return sch, nil
+	}
+	info, err := c.SchemaByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.compile(info)
+}
+
+// Verify validates the framed payload against the schema whose id is
+// in the frame. Returns the decoded json value of payload.
+//
+// returns *jsonschema.ValidationError, if payload is not valid.
+func (c *Client) Verify(payload []byte) (interface{}, error) {
+	id, b, err := Unframe(payload)
+	if err != nil {
+		return nil, err
+	}
+	sch, err := c.CompileID(id)
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsonschema.DecodeJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("schemaregistry: invalid json payload: %v", err)
+	}
+	return v, sch.Validate(v)
+}
+
+func (c *Client) cached(id int) *jsonschema.Schema {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.byID[id]
+}
+
+func (c *Client) compile(info *SchemaInfo) (*jsonschema.Schema, error) {
+	var compiler *jsonschema.Compiler
+	if c.NewCompiler != nil {
+		compiler = c.NewCompiler()
+	} else {
+		compiler = jsonschema.NewCompiler()
+	}
+	loc := strings.TrimSuffix(c.URL, "/") + "/schemas/ids/" + strconv.Itoa(info.ID)
+	if err := c.addResources(compiler, loc, info, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	sch, err := compiler.Compile(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byID == nil {
+		c.byID = make(map[int]*jsonschema.Schema)
+	}
+	c.byID[info.ID] = sch
+	return sch, nil
+}
+
+// addResources adds schema of info at loc, along with its references
+// into compiler.
+func (c *Client) addResources(compiler *jsonschema.Compiler, loc string, info *SchemaInfo, seen map[string]bool) error {
+	if seen[loc] {
+		return nil
+	}
+	seen[loc] = true
+	if info.SchemaType != "JSON" {
+		typ := info.SchemaType
+		if typ == "" {
+			typ = "AVRO"
+		}
+		return fmt.Errorf("schemaregistry: schema %d is of type %s, not JSON", info.ID, typ)
+	}
+	if err := compiler.AddResource(loc, strings.NewReader(info.Schema)); err != nil {
+		return err
+	}
+	base, err := baseURI(loc, info.Schema)
+	if err != nil {
+		return err
+	}
+	for _, ref := range info.References {
+		refInfo, err := c.Subject(ref.Subject, strconv.Itoa(ref.Version))
+		if err != nil {
+			return err
+		}
+		refLoc, err := base.Parse(ref.Name)
+		if err != nil {
+			return fmt.Errorf("schemaregistry: invalid reference name %q in schema %d: %v", ref.Name, info.ID, err)
+		}
+		if err := c.addResources(compiler, refLoc.String(), refInfo, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseURI returns the base uri of schema at loc, which is its $id if any.
+func baseURI(loc, schema string) (*url.URL, error) {
+	base, err := url.Parse(loc)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		ID  interface{} `json:"$id"`
+		ID4 interface{} `json:"id"`
+	}
+	_ = json.Unmarshal([]byte(schema), &doc) // syntax errors are reported by compiler
+	for _, id := range []interface{}{doc.ID, doc.ID4} {
+		if s, ok := id.(string); ok {
+			if u, err := base.Parse(s); err == nil {
+				return u, nil
+			}
+		}
+	}
+	return base, nil
+}
+
+func (c *Client) get(path string, v interface{}) error {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	u := strings.TrimSuffix(c.URL, "/") + path
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json, application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var rerr struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(b, &rerr) == nil && rerr.Message != "" {
+			return fmt.Errorf("schemaregistry: %s returned status code %d: %s", u, resp.StatusCode, rerr.Message)
+		}
+		return fmt.Errorf("schemaregistry: %s returned status code %d", u, resp.StatusCode)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("schemaregistry: invalid response from %s: %v", u, err)
+	}
+	return nil
+}
+
+// framing ---
+
+const magicByte = 0
+
+// Frame returns payload prefixed with the magic byte and schema id, as
+// expected by Kafka deserializers.
+func Frame(id int, payload []byte) []byte {
+	b := make([]byte, 5+len(payload))
+	b[0] = magicByte
+	binary.BigEndian.PutUint32(b[1:5], uint32(id))
+	copy(b[5:], payload)
+	return b
+}
+
+// Unframe returns the schema id and payload, of b framed by Frame.
+func Unframe(b []byte) (id int, payload []byte, err error) {
+	if len(b) < 5 {
+		return 0, nil, fmt.Errorf("schemaregistry: payload too short for framing")
+	}
+	if b[0] != magicByte {
+		return 0, nil, fmt.Errorf("schemaregistry: unknown magic byte %d", b[0])
+	}
+	return int(binary.BigEndian.Uint32(b[1:5])), b[5:], nil
+}
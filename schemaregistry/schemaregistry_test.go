@@ -0,0 +1,118 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/schemaregistry"
+)
+
+func newRegistry(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	address := schemaregistry.SchemaInfo{
+		Subject: "address", Version: 1, ID: 1, SchemaType: "JSON",
+		Schema: `{"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]}`,
+	}
+	person := schemaregistry.SchemaInfo{
+		Subject: "person-value", Version: 2, ID: 2, SchemaType: "JSON",
+		Schema:     `{"$id": "https://example.com/person.json", "type": "object", "properties": {"name": {"type": "string"}, "address": {"$ref": "address.json"}}, "required": ["name"]}`,
+		References: []schemaregistry.Reference{{Name: "address.json", Subject: "address", Version: 1}},
+	}
+	avro := schemaregistry.SchemaInfo{Subject: "avro-value", Version: 1, ID: 3, Schema: `"string"`}
+
+	var requests int32
+	mux := http.NewServeMux()
+	handle := func(path string, info schemaregistry.SchemaInfo) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+			if strings.HasPrefix(path, "/schemas/ids/") {
+				info = schemaregistry.SchemaInfo{SchemaType: info.SchemaType, Schema: info.Schema, References: info.References}
+			}
+			_ = json.NewEncoder(w).Encode(info)
+		})
+	}
+	handle("/subjects/address/versions/1", address)
+	handle("/subjects/person-value/versions/latest", person)
+	handle("/subjects/person-value/versions/2", person)
+	handle("/subjects/avro-value/versions/latest", avro)
+	handle("/schemas/ids/2", person)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code": 40403, "message": "Schema not found"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestClient_Compile(t *testing.T) {
+	srv, _ := newRegistry(t)
+	client := &schemaregistry.Client{URL: srv.URL}
+	sch, id, err := client.Compile("person-value", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 2 {
+		t.Fatalf("got id %d", id)
+	}
+	if err := sch.Validate(map[string]interface{}{"name": "john", "address": map[string]interface{}{"city": "x"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate(map[string]interface{}{"name": "john", "address": map[string]interface{}{}}); err == nil {
+		t.Fatal("referenced schema is not enforced")
+	}
+
+	if _, _, err := client.Compile("avro-value", "latest"); err == nil || !strings.Contains(err.Error(), "is of type AVRO") {
+		t.Fatalf("got %v for avro schema", err)
+	}
+	if _, _, err := client.Compile("unknown", "latest"); err == nil || !strings.Contains(err.Error(), "Schema not found") {
+		t.Fatalf("got %v for unknown subject", err)
+	}
+}
+
+func TestClient_Verify(t *testing.T) {
+	srv, requests := newRegistry(t)
+	client := &schemaregistry.Client{URL: srv.URL + "/"}
+
+	v, err := client.Verify(schemaregistry.Frame(2, []byte(`{"name": "john"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := v.(map[string]interface{}); !ok || m["name"] != "john" {
+		t.Fatalf("got %v", v)
+	}
+	n := atomic.LoadInt32(requests)
+
+	_, err = client.Verify(schemaregistry.Frame(2, []byte(`{"name": 1}`)))
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("got %v, want validation error", err)
+	}
+	if got := atomic.LoadInt32(requests); got != n {
+		t.Fatalf("compiled schema is not cached: %d requests", got-n)
+	}
+
+	for _, payload := range [][]byte{nil, {1, 0, 0, 0, 2, '{', '}'}, schemaregistry.Frame(2, []byte(`{`)), schemaregistry.Frame(9, []byte(`{}`))} {
+		if _, err := client.Verify(payload); err == nil {
+			t.Errorf("%v: error expected", payload)
+		}
+	}
+}
+
+func TestFrame(t *testing.T) {
+	b := schemaregistry.Frame(258, []byte("{}"))
+	if string(b) != "\x00\x00\x00\x01\x02{}" {
+		t.Fatalf("got %q", b)
+	}
+	id, payload, err := schemaregistry.Unframe(b)
+	if err != nil || id != 258 || string(payload) != "{}" {
+		t.Fatalf("got %d, %q, %v", id, payload, err)
+	}
+}
@@ -0,0 +1,187 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// SchemaSet holds multiple versions of a schema, and validates each instance
+// against the version it declares. This is useful for APIs and resources
+// which evolve over time, such as kubernetes style objects with "apiVersion".
+//
+//	set, err := jsonschema.NewSchemaSet(compiler, "/apiVersion", map[string]string{
+//		"v1": "schemas/v1/widget.json",
+//		"v2": "schemas/v2/widget.json",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = set.Validate(widget)
+//
+// When the version is known from elsewhere, such as http header, use
+// ValidateVersion.
+//
+// A SchemaSet is safe for concurrent use by multiple goroutines.
+type SchemaSet struct {
+	// Selector is json-pointer to the version in instance, such as
+	// "/apiVersion". Value at Selector must be string or number.
+	Selector string
+
+	// Default is the version used for instances, which have no value at
+	// Selector. empty means such instances are rejected.
+	Default string
+
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// NewSchemaSet compiles the schema of each version using c, and returns
+// SchemaSet selecting version at json-pointer selector. Key of urls is
+// version, and value is url of its schema. returns *SchemaError of
+// first version, in sorted order, that failed to compile.
+func NewSchemaSet(c *Compiler, selector string, urls map[string]string) (*SchemaSet, error) {
+	if _, err := jsonpointer.Split(selector); err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(urls))
+	for version := range urls {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	set := &SchemaSet{Selector: selector, schemas: make(map[string]*Schema, len(urls))}
+	for _, version := range versions {
+		sch, err := c.Compile(urls[version])
+		if err != nil {
+			return nil, err
+		}
+		set.schemas[version] = sch
+	}
+	return set, nil
+}
+
+// Add adds schema of given version to the set, replacing existing
+// schema of that version, if any.
+func (set *SchemaSet) Add(version string, sch *Schema) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.schemas == nil {
+		set.schemas = make(map[string]*Schema)
+	}
+	set.schemas[version] = sch
+}
+
+// Schema returns the schema of given version. returns nil if no such
+// version is in the set.
+func (set *SchemaSet) Schema(version string) *Schema {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.schemas[version]
+}
+
+// Versions returns versions in the set, in sorted order.
+func (set *SchemaSet) Versions() []string {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	versions := make([]string, 0, len(set.schemas))
+	for version := range set.schemas {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Select returns the version declared by instance v, and its schema.
+// returns *VersionError if v does not declare version, or its version
+// is not in the set.
+func (set *SchemaSet) Select(v interface{}) (string, *Schema, error) {
+	version, err := set.version(v)
+	if err != nil {
+		return "", nil, err
+	}
+	sch := set.Schema(version)
+	if sch == nil {
+		return "", nil, &VersionError{Selector: set.Selector, Version: version, Known: set.Versions()}
+	}
+	return version, sch, nil
+}
+
+// Validate validates v against the schema of version declared by v.
+// returns *VersionError if version cannot be selected. Otherwise returns
+// error as by Schema.Validate.
+func (set *SchemaSet) Validate(v interface{}) error {
+	_, sch, err := set.Select(v)
+	if err != nil {
+		return err
+	}
+	return sch.Validate(v)
+}
+
+// ValidateVersion validates v against schema of given version, instead of
+// the version declared by v. empty version means Default. It can be used
+// when version is conveyed out of band, such as in http header. returns
+// *VersionError if version is not in the set.
+func (set *SchemaSet) ValidateVersion(version string, v interface{}) error {
+	if version == "" {
+		version = set.Default
+	}
+	sch := set.Schema(version)
+	if sch == nil {
+		return &VersionError{Version: version, Known: set.Versions()}
+	}
+	return sch.Validate(v)
+}
+
+// version returns the version at Selector in v, which must be
+// string or number.
+func (set *SchemaSet) version(v interface{}) (string, error) {
+	val, err := jsonpointer.Eval(v, set.Selector)
+	if err != nil {
+		if errors.Is(err, jsonpointer.ErrNotFound) && set.Default != "" {
+			return set.Default, nil
+		}
+		return "", &VersionError{Selector: set.Selector, Known: set.Versions()}
+	}
+	switch val := val.(type) {
+	case string:
+		return val, nil
+	case nil, bool, []interface{}, map[string]interface{}:
+		return "", &VersionError{Selector: set.Selector, Known: set.Versions()}
+	default:
+		return fmt.Sprint(val), nil
+	}
+}
+
+// VersionError is returned by SchemaSet, if version of instance cannot
+// be selected.
+type VersionError struct {
+	// Selector is json-pointer to version in instance. empty if version
+	// is given to SchemaSet.ValidateVersion.
+	Selector string
+
+	// Version is the version not in the set. empty if instance has no
+	// version, or its version is neither string nor number.
+	Version string
+
+	// Known are the versions in the set, in sorted order.
+	Known []string
+}
+
+func (e *VersionError) Error() string {
+	known := make([]string, len(e.Known))
+	for i, version := range e.Known {
+		known[i] = quote(version)
+	}
+	switch {
+	case e.Version == "" && e.Selector == "":
+		return fmt.Sprintf("jsonschema: missing version, want one of %s", strings.Join(known, ", "))
+	case e.Version == "":
+		return fmt.Sprintf("jsonschema: missing version at %s, want one of %s", quote(e.Selector), strings.Join(known, ", "))
+	}
+	return fmt.Sprintf("jsonschema: unknown version %s, want one of %s", quote(e.Version), strings.Join(known, ", "))
+}
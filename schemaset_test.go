@@ -0,0 +1,79 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchemaSet(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	for url, sch := range map[string]string{
+		"v1.json": `{"required": ["name"]}`,
+		"v2.json": `{"required": ["name", "owner"]}`,
+	} {
+		if err := c.AddResource(url, strings.NewReader(sch)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set, err := jsonschema.NewSchemaSet(c, "/apiVersion", map[string]string{"v1": "v1.json", "v2": "v2.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.Versions(); strings.Join(got, ",") != "v1,v2" {
+		t.Fatalf("Versions: got %q", got)
+	}
+
+	tests := []struct {
+		instance string
+		valid    bool
+	}{
+		{`{"apiVersion": "v1", "name": "a"}`, true},
+		{`{"apiVersion": "v2", "name": "a"}`, false},
+		{`{"apiVersion": "v2", "name": "a", "owner": "b"}`, true},
+	}
+	for _, test := range tests {
+		err := set.Validate(decodeString(t, test.instance))
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("%s: got %v, want valid %v", test.instance, err, test.valid)
+		}
+		if err != nil {
+			if _, ok := err.(*jsonschema.ValidationError); !ok {
+				t.Errorf("%s: got %#v, want *ValidationError", test.instance, err)
+			}
+		}
+	}
+
+	var verr *jsonschema.VersionError
+	for _, inst := range []string{`{"apiVersion": "v3"}`, `{"name": "a"}`, `{"apiVersion": true}`} {
+		if err := set.Validate(decodeString(t, inst)); !errors.As(err, &verr) {
+			t.Errorf("%s: got %v, want *VersionError", inst, err)
+		}
+	}
+	if err := set.Validate(decodeString(t, `{"apiVersion": "v3"}`)); err.Error() != `jsonschema: unknown version 'v3', want one of 'v1', 'v2'` {
+		t.Errorf("got %q", err)
+	}
+
+	// default version
+	set.Default = "v1"
+	if err := set.Validate(decodeString(t, `{"name": "a"}`)); err != nil {
+		t.Errorf("default: %v", err)
+	}
+
+	// version out of band
+	if err := set.ValidateVersion("v2", decodeString(t, `{"name": "a"}`)); err == nil {
+		t.Error("ValidateVersion v2: want error")
+	}
+	if err := set.ValidateVersion("v9", decodeString(t, `{}`)); !errors.As(err, &verr) || verr.Version != "v9" {
+		t.Errorf("ValidateVersion v9: got %v", err)
+	}
+
+	// numeric version
+	set.Selector = "/version"
+	set.Add("3", jsonschema.MustCompileString("v3.json", `{"maxProperties": 1}`))
+	if err := set.Validate(decodeString(t, `{"version": 3}`)); err != nil {
+		t.Errorf("numeric version: %v", err)
+	}
+}
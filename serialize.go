@@ -0,0 +1,611 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// serialVersion is the version of format used by Schema.MarshalBinary.
+// It must be incremented, whenever the format changes incompatibly.
+const serialVersion = 1
+
+// MarshalBinary serializes the compiled schema s, along with all schemas
+// it refers to, so that it can be loaded using Compiler.UnmarshalSchema,
+// without recompiling. This is useful to warm start services with many
+// large schemas, or to share compiled schemas across processes.
+//
+// Functions are not serialized. Formats, content decoders, media types and
+// regular expressions are looked up again by their names, when loading.
+// Schemas using Extensions cannot be serialized.
+//
+// The format is internal, and is readable only by the same version
+// of this package.
+func (s *Schema) MarshalBinary() ([]byte, error) {
+	e := &schemaEncoder{index: make(map[*Schema]int)}
+	root := e.ref(s)
+	for i := 0; i < len(e.queue); i++ {
+		ws, err := e.encode(e.queue[i])
+		if err != nil {
+			return nil, err
+		}
+		e.schemas = append(e.schemas, ws)
+	}
+	return json.Marshal(serialSchemas{Version: serialVersion, Root: root, Schemas: e.schemas})
+}
+
+// UnmarshalSchema loads the schema serialized by Schema.MarshalBinary.
+//
+// Formats, content decoders and media types used by the schema are looked
+// up in c, before falling back to package globals, similar to Compile.
+// The regular expressions are compiled using c.CompileRegex. Translator,
+// ErrorValue and Limits of c apply to the loaded schema.
+func (c *Compiler) UnmarshalSchema(b []byte) (*Schema, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ss serialSchemas
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&ss); err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid serialized schema: %v", err)
+	}
+	if ss.Version != serialVersion {
+		return nil, fmt.Errorf("jsonschema: unsupported serialized schema version %d", ss.Version)
+	}
+	if ss.Root < 1 || ss.Root > len(ss.Schemas) {
+		return nil, fmt.Errorf("jsonschema: invalid serialized schema: root %d not found", ss.Root)
+	}
+	d := &schemaDecoder{c: c, schemas: make([]*Schema, len(ss.Schemas))}
+	for i, ws := range ss.Schemas {
+		draft := findSerialDraft(ws.Draft)
+		if draft == nil {
+			return nil, fmt.Errorf("jsonschema: invalid serialized schema: unknown draft %q in %s", ws.Draft, ws.Location)
+		}
+		if ws.DraftMeta {
+			d.schemas[i] = draft.meta
+		} else {
+			d.schemas[i] = &Schema{Draft: draft}
+		}
+	}
+	for i, ws := range ss.Schemas {
+		if !ws.DraftMeta {
+			if err := d.decode(d.schemas[i], ws); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d.schemas[ss.Root-1], nil
+}
+
+type serialSchemas struct {
+	Version int
+	Root    int
+	Schemas []*serialSchema
+}
+
+// serialSchema is the serialized form of Schema. References to
+// other schemas are 1-based indexes into serialSchemas.Schemas.
+// zero means nil.
+type serialSchema struct {
+	Location  string
+	Draft     string
+	DraftMeta bool `json:",omitempty"` // schema is Draft.meta. other fields are empty
+
+	Meta           int      `json:",omitempty"`
+	Vocab          []string `json:",omitempty"`
+	DynamicAnchors []int    `json:",omitempty"`
+	Anchors        []string `json:",omitempty"`
+
+	Format           string            `json:",omitempty"`
+	AssertFormat     bool              `json:",omitempty"`
+	Always           *bool             `json:",omitempty"`
+	Empty            bool              `json:",omitempty"` // schema is {}
+	Ref              int               `json:",omitempty"`
+	RecursiveAnchor  bool              `json:",omitempty"`
+	RecursiveRef     int               `json:",omitempty"`
+	DynamicAnchor    string            `json:",omitempty"`
+	DynamicRef       int               `json:",omitempty"`
+	DynamicRefAnchor string            `json:",omitempty"`
+	Types            []string          `json:",omitempty"`
+	Constant         []interface{}     `json:",omitempty"`
+	Enum             []interface{}     // nil and empty are not same
+	EnumError        string            `json:",omitempty"`
+	Data             map[string]string `json:",omitempty"`
+	Not              int               `json:",omitempty"`
+	AllOf            []int             `json:",omitempty"`
+	AnyOf            []int             `json:",omitempty"`
+	OneOf            []int             `json:",omitempty"`
+	If               int               `json:",omitempty"`
+	Then             int               `json:",omitempty"`
+	Else             int               `json:",omitempty"`
+
+	MinProperties         int
+	MaxProperties         int
+	Required              []string              `json:",omitempty"`
+	Properties            map[string]int        // nil and empty are not same
+	PropertyNames         int                   `json:",omitempty"`
+	RegexProperties       bool                  `json:",omitempty"`
+	PatternProperties     map[string]int        // key is pattern. nil and empty are not same
+	AdditionalProperties  *serialAny            `json:",omitempty"`
+	Dependencies          map[string]*serialAny `json:",omitempty"`
+	DependentRequired     map[string][]string   `json:",omitempty"`
+	DependentSchemas      map[string]int        `json:",omitempty"`
+	UnevaluatedProperties int                   `json:",omitempty"`
+
+	MinItems         int
+	MaxItems         int
+	UniqueItems      bool       `json:",omitempty"`
+	Items            *serialAny `json:",omitempty"`
+	AdditionalItems  *serialAny `json:",omitempty"`
+	PrefixItems      []int      `json:",omitempty"`
+	Items2020        int        `json:",omitempty"`
+	Contains         int        `json:",omitempty"`
+	ContainsEval     bool       `json:",omitempty"`
+	MinContains      int
+	MaxContains      int
+	UnevaluatedItems int `json:",omitempty"`
+
+	MinLength        int
+	MaxLength        int
+	Pattern          *string  `json:",omitempty"`
+	ContentEncoding  string   `json:",omitempty"`
+	AssertEncoding   bool     `json:",omitempty"`
+	ContentMediaType string   `json:",omitempty"`
+	AssertMediaType  bool     `json:",omitempty"`
+	ContentSchema    int      `json:",omitempty"`
+	Minimum          *big.Rat `json:",omitempty"`
+	ExclusiveMinimum *big.Rat `json:",omitempty"`
+	Maximum          *big.Rat `json:",omitempty"`
+	ExclusiveMaximum *big.Rat `json:",omitempty"`
+	MultipleOf       *big.Rat `json:",omitempty"`
+
+	Title       string                 `json:",omitempty"`
+	Description string                 `json:",omitempty"`
+	Default     interface{}            `json:",omitempty"`
+	Comment     string                 `json:",omitempty"`
+	ReadOnly    bool                   `json:",omitempty"`
+	WriteOnly   bool                   `json:",omitempty"`
+	Examples    []interface{}          `json:",omitempty"`
+	Deprecated  bool                   `json:",omitempty"`
+	Unknown     map[string]interface{} `json:",omitempty"`
+
+	Untracked     bool                 `json:",omitempty"`
+	ErrorMessage  *serialErrorMessage  `json:",omitempty"`
+	Discriminator *serialDiscriminator `json:",omitempty"`
+}
+
+// serialAny is the serialized form of fields like Items, which can
+// hold values of different types. Exactly one field is set.
+type serialAny struct {
+	Bool    *bool    `json:",omitempty"`
+	Schema  int      `json:",omitempty"`
+	Schemas []int    `json:",omitempty"`
+	Strings []string `json:",omitempty"`
+	Empty   bool     `json:",omitempty"` // empty Strings
+}
+
+type serialErrorMessage struct {
+	All        string            `json:",omitempty"`
+	Keywords   map[string]string `json:",omitempty"`
+	Required   map[string]string `json:",omitempty"`
+	Properties map[string]string `json:",omitempty"`
+	Other      string            `json:",omitempty"`
+}
+
+type serialDiscriminator struct {
+	Property string
+	Mapping  map[string]int
+}
+
+// findSerialDraft returns the draft with given name, as returned by Draft.String.
+func findSerialDraft(name string) *Draft {
+	for _, d := range []*Draft{Draft4, Draft6, Draft7, Draft2019, Draft2020, OpenAPI30, OpenAPI31} {
+		if d.String() == name {
+			return d
+		}
+	}
+	return nil
+}
+
+type schemaEncoder struct {
+	index   map[*Schema]int // schema to its 1-based index
+	queue   []*Schema       // schemas indexed, in order of index
+	schemas []*serialSchema
+}
+
+// ref returns the index of s, assigning one if needed.
+func (e *schemaEncoder) ref(s *Schema) int {
+	if s == nil {
+		return 0
+	}
+	if i, ok := e.index[s]; ok {
+		return i
+	}
+	e.queue = append(e.queue, s)
+	e.index[s] = len(e.queue)
+	return len(e.queue)
+}
+
+func (e *schemaEncoder) refs(schemas []*Schema) []int {
+	if schemas == nil {
+		return nil
+	}
+	refs := make([]int, len(schemas))
+	for i, s := range schemas {
+		refs[i] = e.ref(s)
+	}
+	return refs
+}
+
+func (e *schemaEncoder) refMap(schemas map[string]*Schema) map[string]int {
+	if schemas == nil {
+		return nil
+	}
+	refs := make(map[string]int, len(schemas))
+	for k, s := range schemas {
+		refs[k] = e.ref(s)
+	}
+	return refs
+}
+
+func (e *schemaEncoder) any(v interface{}) *serialAny {
+	switch v := v.(type) {
+	case bool:
+		return &serialAny{Bool: &v}
+	case *Schema:
+		return &serialAny{Schema: e.ref(v)}
+	case []*Schema:
+		return &serialAny{Schemas: e.refs(v)}
+	case []string:
+		return &serialAny{Strings: v, Empty: len(v) == 0}
+	}
+	return nil
+}
+
+func (e *schemaEncoder) encode(s *Schema) (*serialSchema, error) {
+	ws := &serialSchema{Location: s.Location}
+	if s.Draft != nil {
+		ws.Draft = s.Draft.String()
+		if s == s.Draft.meta {
+			ws.DraftMeta = true
+			return ws, nil
+		}
+	}
+	if len(s.Extensions) > 0 {
+		for name := range s.Extensions {
+			return nil, fmt.Errorf("jsonschema: cannot serialize extension %q in %s", name, s.Location)
+		}
+	}
+
+	ws.Meta = e.ref(s.meta)
+	ws.Vocab = s.vocab
+	ws.DynamicAnchors = e.refs(s.dynamicAnchors)
+	ws.Anchors = s.anchors
+
+	ws.Format, ws.AssertFormat = s.Format, s.format != nil
+	ws.Always, ws.Empty = s.Always, s.empty
+	ws.Ref = e.ref(s.Ref)
+	ws.RecursiveAnchor = s.RecursiveAnchor
+	ws.RecursiveRef = e.ref(s.RecursiveRef)
+	ws.DynamicAnchor = s.DynamicAnchor
+	ws.DynamicRef = e.ref(s.DynamicRef)
+	ws.DynamicRefAnchor = s.dynamicRefAnchor
+	ws.Types = s.Types
+	ws.Constant = s.Constant
+	ws.Enum = s.Enum
+	ws.EnumError = s.enumError
+	ws.Data = s.data
+	ws.Not = e.ref(s.Not)
+	ws.AllOf = e.refs(s.AllOf)
+	ws.AnyOf = e.refs(s.AnyOf)
+	ws.OneOf = e.refs(s.OneOf)
+	ws.If = e.ref(s.If)
+	ws.Then = e.ref(s.Then)
+	ws.Else = e.ref(s.Else)
+
+	ws.MinProperties = s.MinProperties
+	ws.MaxProperties = s.MaxProperties
+	ws.Required = s.Required
+	ws.Properties = e.refMap(s.Properties)
+	ws.PropertyNames = e.ref(s.PropertyNames)
+	ws.RegexProperties = s.RegexProperties
+	if s.PatternProperties != nil {
+		ws.PatternProperties = make(map[string]int, len(s.PatternProperties))
+		for re, sch := range s.PatternProperties {
+			ws.PatternProperties[re.String()] = e.ref(sch)
+		}
+	}
+	ws.AdditionalProperties = e.any(s.AdditionalProperties)
+	if s.Dependencies != nil {
+		ws.Dependencies = make(map[string]*serialAny, len(s.Dependencies))
+		for pname, dvalue := range s.Dependencies {
+			ws.Dependencies[pname] = e.any(dvalue)
+		}
+	}
+	ws.DependentRequired = s.DependentRequired
+	ws.DependentSchemas = e.refMap(s.DependentSchemas)
+	ws.UnevaluatedProperties = e.ref(s.UnevaluatedProperties)
+
+	ws.MinItems = s.MinItems
+	ws.MaxItems = s.MaxItems
+	ws.UniqueItems = s.UniqueItems
+	ws.Items = e.any(s.Items)
+	ws.AdditionalItems = e.any(s.AdditionalItems)
+	ws.PrefixItems = e.refs(s.PrefixItems)
+	ws.Items2020 = e.ref(s.Items2020)
+	ws.Contains = e.ref(s.Contains)
+	ws.ContainsEval = s.ContainsEval
+	ws.MinContains = s.MinContains
+	ws.MaxContains = s.MaxContains
+	ws.UnevaluatedItems = e.ref(s.UnevaluatedItems)
+
+	ws.MinLength = s.MinLength
+	ws.MaxLength = s.MaxLength
+	if s.Pattern != nil {
+		pattern := s.Pattern.String()
+		ws.Pattern = &pattern
+	}
+	ws.ContentEncoding, ws.AssertEncoding = s.ContentEncoding, s.decoder != nil
+	ws.ContentMediaType, ws.AssertMediaType = s.ContentMediaType, s.mediaType != nil
+	ws.ContentSchema = e.ref(s.ContentSchema)
+	ws.Minimum = s.Minimum
+	ws.ExclusiveMinimum = s.ExclusiveMinimum
+	ws.Maximum = s.Maximum
+	ws.ExclusiveMaximum = s.ExclusiveMaximum
+	ws.MultipleOf = s.MultipleOf
+
+	ws.Title = s.Title
+	ws.Description = s.Description
+	ws.Default = s.Default
+	ws.Comment = s.Comment
+	ws.ReadOnly = s.ReadOnly
+	ws.WriteOnly = s.WriteOnly
+	ws.Examples = s.Examples
+	ws.Deprecated = s.Deprecated
+	ws.Unknown = s.unknown
+
+	ws.Untracked = s.untracked
+	if em := s.errorMessage; em != nil {
+		ws.ErrorMessage = &serialErrorMessage{em.all, em.keywords, em.required, em.properties, em.other}
+	}
+	if d := s.discriminator; d != nil {
+		ws.Discriminator = &serialDiscriminator{d.property, d.mapping}
+	}
+	return ws, nil
+}
+
+type schemaDecoder struct {
+	c       *Compiler
+	schemas []*Schema
+}
+
+func (d *schemaDecoder) ref(i int) (*Schema, error) {
+	if i == 0 {
+		return nil, nil
+	}
+	if i < 0 || i > len(d.schemas) {
+		return nil, fmt.Errorf("jsonschema: invalid serialized schema: schema %d not found", i)
+	}
+	return d.schemas[i-1], nil
+}
+
+func (d *schemaDecoder) refs(refs []int) ([]*Schema, error) {
+	if refs == nil {
+		return nil, nil
+	}
+	schemas := make([]*Schema, len(refs))
+	for i, ref := range refs {
+		s, err := d.ref(ref)
+		if err != nil {
+			return nil, err
+		}
+		schemas[i] = s
+	}
+	return schemas, nil
+}
+
+func (d *schemaDecoder) refMap(refs map[string]int) (map[string]*Schema, error) {
+	if refs == nil {
+		return nil, nil
+	}
+	schemas := make(map[string]*Schema, len(refs))
+	for k, ref := range refs {
+		s, err := d.ref(ref)
+		if err != nil {
+			return nil, err
+		}
+		schemas[k] = s
+	}
+	return schemas, nil
+}
+
+func (d *schemaDecoder) any(wa *serialAny) (interface{}, error) {
+	switch {
+	case wa == nil:
+		return nil, nil
+	case wa.Bool != nil:
+		return *wa.Bool, nil
+	case wa.Schema != 0:
+		return d.ref(wa.Schema)
+	case wa.Schemas != nil:
+		return d.refs(wa.Schemas)
+	case wa.Strings != nil:
+		return wa.Strings, nil
+	case wa.Empty:
+		return []string{}, nil
+	}
+	return nil, nil
+}
+
+// decode fills s from ws. s.Draft must be already set.
+func (d *schemaDecoder) decode(s *Schema, ws *serialSchema) error {
+	c := d.c
+	s.Location = ws.Location
+
+	// collects first error of ref, refs, refMap and any
+	var refErr error
+	check := func(sch *Schema, err error) *Schema {
+		if refErr == nil {
+			refErr = err
+		}
+		return sch
+	}
+	checkAll := func(schemas []*Schema, err error) []*Schema {
+		if refErr == nil {
+			refErr = err
+		}
+		return schemas
+	}
+	checkMap := func(schemas map[string]*Schema, err error) map[string]*Schema {
+		if refErr == nil {
+			refErr = err
+		}
+		return schemas
+	}
+	checkAny := func(v interface{}, err error) interface{} {
+		if refErr == nil {
+			refErr = err
+		}
+		return v
+	}
+
+	s.meta = check(d.ref(ws.Meta))
+	s.vocab = ws.Vocab
+	s.dynamicAnchors = checkAll(d.refs(ws.DynamicAnchors))
+	s.anchors = ws.Anchors
+
+	s.Format = ws.Format
+	if ws.AssertFormat {
+		if s.format = c.lookupFormat(s.Format); s.format == nil {
+			return fmt.Errorf("jsonschema: unknown format %q in %s", s.Format, s.Location)
+		}
+	}
+	s.Always, s.empty = ws.Always, ws.Empty
+	s.Ref = check(d.ref(ws.Ref))
+	s.RecursiveAnchor = ws.RecursiveAnchor
+	s.RecursiveRef = check(d.ref(ws.RecursiveRef))
+	s.DynamicAnchor = ws.DynamicAnchor
+	s.DynamicRef = check(d.ref(ws.DynamicRef))
+	s.dynamicRefAnchor = ws.DynamicRefAnchor
+	s.Types = ws.Types
+	s.Constant = ws.Constant
+	s.Enum = ws.Enum
+	s.enumError = ws.EnumError
+	s.data = ws.Data
+	s.Not = check(d.ref(ws.Not))
+	s.AllOf = checkAll(d.refs(ws.AllOf))
+	s.AnyOf = checkAll(d.refs(ws.AnyOf))
+	s.OneOf = checkAll(d.refs(ws.OneOf))
+	s.If = check(d.ref(ws.If))
+	s.Then = check(d.ref(ws.Then))
+	s.Else = check(d.ref(ws.Else))
+
+	s.MinProperties = ws.MinProperties
+	s.MaxProperties = ws.MaxProperties
+	s.Required = ws.Required
+	s.Properties = checkMap(d.refMap(ws.Properties))
+	s.PropertyNames = check(d.ref(ws.PropertyNames))
+	s.RegexProperties = ws.RegexProperties
+	if ws.PatternProperties != nil {
+		s.PatternProperties = make(map[Regexp]*Schema, len(ws.PatternProperties))
+		for pattern, ref := range ws.PatternProperties {
+			re, err := d.compileRegex(s, pattern)
+			if err != nil {
+				return err
+			}
+			s.PatternProperties[re] = check(d.ref(ref))
+		}
+	}
+	s.AdditionalProperties = checkAny(d.any(ws.AdditionalProperties))
+	if ws.Dependencies != nil {
+		s.Dependencies = make(map[string]interface{}, len(ws.Dependencies))
+		for pname, wa := range ws.Dependencies {
+			s.Dependencies[pname] = checkAny(d.any(wa))
+		}
+	}
+	s.DependentRequired = ws.DependentRequired
+	s.DependentSchemas = checkMap(d.refMap(ws.DependentSchemas))
+	s.UnevaluatedProperties = check(d.ref(ws.UnevaluatedProperties))
+
+	s.MinItems = ws.MinItems
+	s.MaxItems = ws.MaxItems
+	s.UniqueItems = ws.UniqueItems
+	s.Items = checkAny(d.any(ws.Items))
+	s.AdditionalItems = checkAny(d.any(ws.AdditionalItems))
+	s.PrefixItems = checkAll(d.refs(ws.PrefixItems))
+	s.Items2020 = check(d.ref(ws.Items2020))
+	s.Contains = check(d.ref(ws.Contains))
+	s.ContainsEval = ws.ContainsEval
+	s.MinContains = ws.MinContains
+	s.MaxContains = ws.MaxContains
+	s.UnevaluatedItems = check(d.ref(ws.UnevaluatedItems))
+
+	s.MinLength = ws.MinLength
+	s.MaxLength = ws.MaxLength
+	if ws.Pattern != nil {
+		re, err := d.compileRegex(s, *ws.Pattern)
+		if err != nil {
+			return err
+		}
+		s.Pattern = re
+	}
+	s.ContentEncoding = ws.ContentEncoding
+	if ws.AssertEncoding {
+		if s.decoder = c.lookupDecoder(s.ContentEncoding); s.decoder == nil {
+			return fmt.Errorf("jsonschema: unknown contentEncoding %q in %s", s.ContentEncoding, s.Location)
+		}
+	}
+	s.ContentMediaType = ws.ContentMediaType
+	if ws.AssertMediaType {
+		if s.mediaType = c.lookupMediaType(s.ContentMediaType); s.mediaType == nil {
+			return fmt.Errorf("jsonschema: unknown contentMediaType %q in %s", s.ContentMediaType, s.Location)
+		}
+	}
+	s.ContentSchema = check(d.ref(ws.ContentSchema))
+	s.Minimum = ws.Minimum
+	s.ExclusiveMinimum = ws.ExclusiveMinimum
+	s.Maximum = ws.Maximum
+	s.ExclusiveMaximum = ws.ExclusiveMaximum
+	s.MultipleOf = ws.MultipleOf
+
+	s.Title = ws.Title
+	s.Description = ws.Description
+	s.Default = ws.Default
+	s.Comment = ws.Comment
+	s.ReadOnly = ws.ReadOnly
+	s.WriteOnly = ws.WriteOnly
+	s.Examples = ws.Examples
+	s.Deprecated = ws.Deprecated
+	s.unknown = ws.Unknown
+
+	s.untracked = ws.Untracked
+	if em := ws.ErrorMessage; em != nil {
+		s.errorMessage = &errorMessage{em.All, em.Keywords, em.Required, em.Properties, em.Other}
+	}
+	if dm := ws.Discriminator; dm != nil {
+		s.discriminator = &discriminator{dm.Property, dm.Mapping}
+	}
+	s.translator = c.Translator
+	s.errorValue = c.ErrorValue
+	s.maxDepth = c.Limits.MaxValidationDepth
+	s.severities = c.Severities
+	s.sortKeys()
+	return refErr
+}
+
+func (d *schemaDecoder) compileRegex(s *Schema, pattern string) (Regexp, error) {
+	if re, ok := d.c.regexps[pattern]; ok {
+		return re, nil
+	}
+	re, err := d.c.CompileRegex(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid regex %q in %s: %v", pattern, s.Location, err)
+	}
+	if d.c.regexps == nil {
+		d.c.regexps = make(map[string]Regexp)
+	}
+	d.c.regexps[pattern] = re
+	return re, nil
+}
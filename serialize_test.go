@@ -0,0 +1,166 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_MarshalBinary(t *testing.T) {
+	schemas := map[string]string{
+		"draft2020": `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$defs": {
+				"node": {
+					"$dynamicAnchor": "node",
+					"type": "object",
+					"properties": {
+						"value": {"type": "integer", "minimum": 1, "multipleOf": 0.5},
+						"next": {"$dynamicRef": "#node"}
+					},
+					"required": ["value"]
+				}
+			},
+			"type": "object",
+			"properties": {
+				"list": {"$ref": "#/$defs/node"},
+				"name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 5},
+				"email": {"format": "email"},
+				"kind": {"enum": ["a", "b", null]},
+				"version": {"const": 2},
+				"tags": {"type": "array", "prefixItems": [{"type": "string"}], "items": false, "uniqueItems": true},
+				"empty": {"properties": {}},
+				"any": {},
+				"never": false
+			},
+			"patternProperties": {"^x-": {"type": "boolean"}},
+			"dependentRequired": {"name": ["kind"]},
+			"dependentSchemas": {"email": {"required": ["name"]}},
+			"if": {"required": ["kind"]},
+			"then": {"properties": {"kind": {"not": {"const": null}}}},
+			"unevaluatedProperties": false
+		}`,
+		"draft7": `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"items": [{"type": "integer", "exclusiveMaximum": 10}, {"type": "string"}],
+			"additionalItems": {"type": "boolean"},
+			"contains": {"const": true},
+			"dependencies": {"a": ["b"], "c": {"required": ["d"]}}
+		}`,
+		"draft4": `{
+			"$schema": "http://json-schema.org/draft-04/schema#",
+			"properties": {"n": {"maximum": 5, "exclusiveMaximum": true}},
+			"additionalProperties": false
+		}`,
+	}
+	instances := []string{
+		`{}`,
+		`{"list": {"value": 2, "next": {"value": 1.5, "next": {"value": 0}}}}`,
+		`{"name": "abc", "kind": "a", "email": "x@example.com", "version": 2, "tags": ["a", true]}`,
+		`{"name": "ABCDEFG", "email": "invalid", "version": 3, "x-flag": 1, "other": 1}`,
+		`{"kind": null, "empty": {"a": 1}}`,
+		`[1, "a", true, false]`,
+		`[11, 2]`,
+		`{"a": 1, "c": 2}`,
+		`{"n": 5}`,
+		`{"n": 4}`,
+	}
+	for name, schema := range schemas {
+		t.Run(name, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			c.AssertFormat = true
+			if err := c.AddResource("http://example.com/schema.json", strings.NewReader(schema)); err != nil {
+				t.Fatal(err)
+			}
+			sch, err := c.Compile("http://example.com/schema.json")
+			if err != nil {
+				t.Fatalf("%#v", err)
+			}
+			b, err := sch.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := jsonschema.NewCompiler().UnmarshalSchema(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded.Location != sch.Location {
+				t.Fatalf("got location %s, want %s", loaded.Location, sch.Location)
+			}
+			for pname, psch := range sch.Properties {
+				wantValue, wantOK := psch.Boolean()
+				if gotValue, gotOK := loaded.Properties[pname].Boolean(); gotValue != wantValue || gotOK != wantOK {
+					t.Errorf("%s: Boolean got (%v, %v), want (%v, %v)", pname, gotValue, gotOK, wantValue, wantOK)
+				}
+			}
+			for _, instance := range instances {
+				v, err := jsonschema.DecodeJSON(strings.NewReader(instance))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, got := errorLines(sch.Validate(v)), errorLines(loaded.Validate(v))
+				if got != want {
+					t.Errorf("%s:\ngot:\n%s\nwant:\n%s", instance, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_MarshalBinary_Formats(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.AssertFormat = true
+	c.Formats["even"] = func(v interface{}) bool {
+		s, ok := v.(string)
+		return !ok || len(s)%2 == 0
+	}
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(`{"format": "even"}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("http://example.com/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := sch.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// format must be registered, when loading
+	if _, err := jsonschema.NewCompiler().UnmarshalSchema(b); err == nil {
+		t.Fatal("error expected for unknown format")
+	}
+	loaded, err := c.UnmarshalSchema(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Validate("abc"); err == nil {
+		t.Fatal("validation must fail")
+	}
+}
+
+func TestCompiler_UnmarshalSchema_Invalid(t *testing.T) {
+	for _, b := range []string{``, `{"Version": 0}`, `{"Version": 1, "Root": 2, "Schemas": [{"Draft": "Draft7"}]}`, `{"Version": 1, "Root": 1, "Schemas": [{"Draft": "Draft5"}]}`} {
+		if _, err := jsonschema.NewCompiler().UnmarshalSchema([]byte(b)); err == nil {
+			t.Errorf("%q: error expected", b)
+		}
+	}
+}
+
+// errorLines returns the errors in err, in sorted order.
+func errorLines(err error) string {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return fmt.Sprint(err)
+	}
+	var lines []string
+	for _, e := range ve.BasicOutput().Errors {
+		lines = append(lines, e.AbsoluteKeywordLocation+" "+e.InstanceLocation+": "+e.Error)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package jsonschema
+
+import "context"
+
+// Result is the outcome of Schema.ValidateWithWarnings.
+type Result struct {
+	// Errors is the error tree of failed assertions, as returned by
+	// Validate. nil if the instance is valid.
+	Errors *ValidationError
+
+	// Warnings are the failures of keywords with SeverityWarning in
+	// Compiler.Severities, in order of evaluation. Warnings are reported
+	// by every subschema evaluated, including failing branches of anyOf
+	// and oneOf.
+	Warnings []*ValidationError
+}
+
+// Valid tells whether the instance is valid. Warnings do not affect validity.
+func (r *Result) Valid() bool {
+	return r.Errors == nil
+}
+
+// Issues returns the leaf errors and warnings as Issue, errors first.
+func (r *Result) Issues() []Issue {
+	var issues []Issue
+	if r.Errors != nil {
+		issues = r.Errors.Flatten()
+	}
+	for _, w := range r.Warnings {
+		for _, issue := range w.Flatten() {
+			issue.Severity = SeverityWarning
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// ValidateWithWarnings is like Validate, but also reports failures of
+// keywords with SeverityWarning in Compiler.Severities, which do not fail
+// validation. This lets new constraints be rolled out as advisory, without
+// rejecting instances.
+//
+// returns error only if validation could not complete, such as
+// InfiniteLoopError or InvalidJSONTypeError.
+func (s *Schema) ValidateWithWarnings(v interface{}) (*Result, error) {
+	vd := newValidator(context.Background())
+	defer vd.release()
+	vd.warn = true
+	err := s.validateValue(vd, v, "")
+	result := &Result{Warnings: vd.warnings}
+	if err != nil {
+		ve, ok := err.(*ValidationError)
+		if !ok {
+			return nil, err
+		}
+		result.Errors = ve
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Severities(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.RegisterExtension("powerOf", powerOfMeta, powerOfCompiler{})
+	c.Severities = map[string]jsonschema.Severity{
+		"format":     jsonschema.SeverityWarning,
+		"deprecated": jsonschema.SeverityWarning,
+		"powerOf":    jsonschema.SeverityWarning,
+	}
+	if err := c.AddResource("schema.json", strings.NewReader(`{
+		"properties": {
+			"email": {"format": "email"},
+			"legacy": {"deprecated": true},
+			"count": {"powerOf": 10},
+			"age": {"minimum": 0}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := decodeString(t, `{"email": "bad", "legacy": 1, "count": 7, "age": 1}`)
+	if err := sch.Validate(inst); err != nil {
+		t.Fatalf("warnings must not fail validation: %#v", err)
+	}
+	res, err := sch.ValidateWithWarnings(inst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid() {
+		t.Fatalf("want valid, got %#v", res.Errors)
+	}
+	var got []string
+	for _, w := range res.Warnings {
+		got = append(got, w.KeywordLocation)
+	}
+	want := []string{"/properties/count/powerOf", "/properties/email/format", "/properties/legacy/deprecated"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("warnings: got %q, want %q", got, want)
+	}
+
+	// errors and warnings together
+	res, err = sch.ValidateWithWarnings(decodeString(t, `{"email": "bad", "age": -1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Valid() || len(res.Warnings) != 1 {
+		t.Fatalf("got errors %v, warnings %v", res.Errors, res.Warnings)
+	}
+	issues := res.Issues()
+	if len(issues) != 2 || issues[0].Severity != jsonschema.SeverityError || issues[1].Severity != jsonschema.SeverityWarning {
+		t.Fatalf("issues: %v", issues)
+	}
+	if issues[1].Keyword != "format" {
+		t.Fatalf("warning keyword: got %q, want format", issues[1].Keyword)
+	}
+}
+
+func TestCompiler_SeveritiesError(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.Severities = map[string]jsonschema.Severity{"deprecated": jsonschema.SeverityError}
+	if err := c.AddResource("schema.json", strings.NewReader(`{"properties": {"legacy": {"deprecated": true}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Validate(decodeString(t, `{"legacy": 1}`)); err == nil {
+		t.Fatal("deprecated value must fail validation")
+	}
+	if err := sch.Validate(decodeString(t, `{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	c = jsonschema.NewCompiler()
+	c.Severities = map[string]jsonschema.Severity{"minimum": jsonschema.SeverityWarning}
+	if err := c.AddResource("schema.json", strings.NewReader(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Compile("schema.json"); err == nil || !strings.Contains(err.Error(), `"minimum"`) {
+		t.Fatalf("got %v, want error for minimum", err)
+	}
+}
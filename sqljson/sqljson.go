@@ -0,0 +1,145 @@
+// Package sqljson validates json values stored in database columns, such
+// as JSON and JSONB columns of PostgreSQL, against json-schema.
+//
+// Typical usage:
+//
+//	// validate value scanned from database
+//	var raw []byte
+//	err := db.QueryRow("SELECT attrs FROM products WHERE id = $1", id).Scan(&raw)
+//	if err == nil {
+//		err = sqljson.Validate(attrsSchema, raw)
+//	}
+//
+//	// enforce schema on write and read
+//	attrs := sqljson.JSON{Schema: attrsSchema}
+//	if err := attrs.Encode(map[string]interface{}{"color": "red"}); err != nil {
+//		return err
+//	}
+//	_, err = db.Exec("UPDATE products SET attrs = $1 WHERE id = $2", attrs, id)
+//
+//	attrs = sqljson.JSON{Schema: attrsSchema}
+//	err = db.QueryRow("SELECT attrs FROM products WHERE id = $1", id).Scan(&attrs)
+//
+// SQL NULL is not validated, since nullability of column is enforced by
+// database. Use JSON.IsNull to check for it.
+package sqljson
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Validate validates the json value src, scanned from database, against
+// sch. src must be []byte, string, json.RawMessage or nil. nil is SQL NULL,
+// and is not validated.
+//
+// returns *jsonschema.ValidationError, if src is not valid against sch.
+func Validate(sch *jsonschema.Schema, src interface{}) error {
+	b, err := bytesOf(src)
+	if err != nil || b == nil {
+		return err
+	}
+	return validate(sch, b)
+}
+
+func bytesOf(src interface{}) ([]byte, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return src, nil
+	case json.RawMessage:
+		return src, nil
+	case string:
+		return []byte(src), nil
+	}
+	return nil, fmt.Errorf("sqljson: unsupported type %T", src)
+}
+
+func validate(sch *jsonschema.Schema, b []byte) error {
+	v, err := jsonschema.DecodeJSON(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("sqljson: invalid json: %v", err)
+	}
+	return sch.Validate(v)
+}
+
+// JSON is a json column value, which is validated against Schema, when
+// written to database and when scanned from database. It implements
+// driver.Valuer and sql.Scanner.
+//
+// The zero value with Schema set, is SQL NULL.
+type JSON struct {
+	Schema *jsonschema.Schema
+
+	// Raw is the json text. nil means SQL NULL.
+	Raw json.RawMessage
+}
+
+// IsNull tells whether j is SQL NULL.
+func (j *JSON) IsNull() bool {
+	return j.Raw == nil
+}
+
+// Encode sets j to json encoding of v, and validates it. j is not
+// changed, if v is not valid.
+func (j *JSON) Encode(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := j.validate(b); err != nil {
+		return err
+	}
+	j.Raw = b
+	return nil
+}
+
+// Decode decodes json text of j into v, using json.Unmarshal.
+func (j *JSON) Decode(v interface{}) error {
+	if j.Raw == nil {
+		return fmt.Errorf("sqljson: cannot decode SQL NULL")
+	}
+	return json.Unmarshal(j.Raw, v)
+}
+
+// Value implements driver.Valuer. It validates j before it is written
+// to database.
+func (j JSON) Value() (driver.Value, error) {
+	if j.Raw == nil {
+		return nil, nil
+	}
+	if err := j.validate(j.Raw); err != nil {
+		return nil, err
+	}
+	return []byte(j.Raw), nil
+}
+
+// Scan implements sql.Scanner. It validates the value read from
+// database. j is not changed, if the value is not valid.
+func (j *JSON) Scan(src interface{}) error {
+	b, err := bytesOf(src)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		j.Raw = nil
+		return nil
+	}
+	if err := j.validate(b); err != nil {
+		return err
+	}
+	j.Raw = append(json.RawMessage(nil), b...) // src may be reused by driver
+	return nil
+}
+
+func (j *JSON) validate(b []byte) error {
+	if j.Schema == nil {
+		return fmt.Errorf("sqljson: Schema is nil")
+	}
+	return validate(j.Schema, b)
+}
@@ -0,0 +1,108 @@
+package sqljson_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/sqljson"
+)
+
+var (
+	_ driver.Valuer = sqljson.JSON{}
+	_ sql.Scanner   = &sqljson.JSON{}
+)
+
+func schema() *jsonschema.Schema {
+	return jsonschema.MustCompileString("attrs.json", `{
+		"type": "object",
+		"properties": {"color": {"enum": ["red", "green"]}},
+		"required": ["color"]
+	}`)
+}
+
+func TestValidate(t *testing.T) {
+	sch := schema()
+	tests := []struct {
+		src   interface{}
+		valid bool
+	}{
+		{nil, true},
+		{[]byte(`{"color": "red"}`), true},
+		{`{"color": "green"}`, true},
+		{json.RawMessage(`{"color": "red"}`), true},
+		{[]byte(`{"color": "blue"}`), false},
+		{`{}`, false},
+		{`{`, false},
+		{42, false},
+	}
+	for _, test := range tests {
+		err := sqljson.Validate(sch, test.src)
+		if test.valid && err != nil {
+			t.Errorf("%v: %v", test.src, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%v: must be invalid", test.src)
+		}
+	}
+
+	var ve *jsonschema.ValidationError
+	if err := sqljson.Validate(sch, `{}`); !errors.As(err, &ve) {
+		t.Errorf("got %T, want *ValidationError", err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	j := sqljson.JSON{Schema: schema()}
+	if v, err := j.Value(); err != nil || v != nil {
+		t.Fatalf("null: got %v, %v", v, err)
+	}
+	if err := j.Encode(map[string]interface{}{"color": "blue"}); err == nil {
+		t.Fatal("invalid value encoded")
+	}
+	if !j.IsNull() {
+		t.Fatal("j is changed by invalid value")
+	}
+	if err := j.Encode(map[string]interface{}{"color": "red"}); err != nil {
+		t.Fatal(err)
+	}
+	v, err := j.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(v.([]byte)); got != `{"color":"red"}` {
+		t.Fatalf("got %s", got)
+	}
+
+	// invalid raw is rejected on write
+	bad := sqljson.JSON{Schema: j.Schema, Raw: json.RawMessage(`{"color": 1}`)}
+	if _, err := bad.Value(); err == nil {
+		t.Fatal("invalid value written")
+	}
+
+	// scan
+	src := []byte(`{"color": "green"}`)
+	if err := j.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	src[11] = 'X' // driver reuses buffer
+	var m struct{ Color string }
+	if err := j.Decode(&m); err != nil || m.Color != "green" {
+		t.Fatalf("got %v, %v", m, err)
+	}
+	if err := j.Scan(`{"color": "blue"}`); err == nil {
+		t.Fatal("invalid value scanned")
+	}
+	if err := j.Scan(nil); err != nil || !j.IsNull() {
+		t.Fatalf("scan null: got %v", err)
+	}
+	if err := j.Decode(&m); err == nil {
+		t.Fatal("null decoded")
+	}
+
+	if err := (&sqljson.JSON{}).Scan(`{}`); err == nil {
+		t.Fatal("error expected for nil Schema")
+	}
+}
@@ -0,0 +1,194 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// checkStrict reports the problems in schema m of res, which are not
+// errors per specification, but are likely mistakes. It is used when
+// Compiler.Strict is set.
+func (c *Compiler) checkStrict(r *resource, res *resource, m map[string]interface{}) error {
+	var err error
+	report := func(format string, a ...interface{}) bool {
+		err = fmt.Errorf("jsonschema: strict mode: "+format+" in %s", append(a, res)...)
+		return c.abort(err)
+	}
+
+	keywords := make([]string, 0, len(m))
+	for kw := range m {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+
+	// unknown keywords
+	known := c.knownKeywords(r.draft)
+	for _, kw := range keywords {
+		if known[kw] || (r.draft.openapi && len(kw) > 2 && kw[:2] == "x-") {
+			continue
+		}
+		var failed bool
+		if isKeyword(kw) {
+			failed = report("keyword %q is not supported in %s", kw, r.draft)
+		} else if suggestion := nearest(kw, mapKeys(known)); suggestion != "" {
+			err = fmt.Errorf("jsonschema: strict mode: unknown keyword %q in %s; did you mean %q?", kw, res, suggestion)
+			failed = c.abort(err)
+		} else {
+			failed = report("unknown keyword %q", kw)
+		}
+		if failed {
+			return err
+		}
+	}
+
+	// siblings of $ref are ignored before draft2019
+	if _, ok := m["$ref"]; ok && r.draft.version < 2019 {
+		for _, kw := range keywords {
+			switch kw {
+			case "$ref", "$schema", "$id", "id", "$comment", "definitions":
+				continue
+			}
+			if report("keyword %q next to $ref is ignored", kw) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// keywords ignored without the keyword they depend on
+	dependents := [][2]string{{"then", "if"}, {"else", "if"}, {"minContains", "contains"}, {"maxContains", "contains"}}
+	if r.draft.version < 2020 {
+		dependents = append(dependents, [2]string{"additionalItems", "items"})
+	}
+	for _, dep := range dependents {
+		if _, ok := m[dep[0]]; !ok {
+			continue
+		}
+		if _, ok := m[dep[1]]; !ok {
+			if report("keyword %q without %q is ignored", dep[0], dep[1]) {
+				return err
+			}
+		}
+	}
+	if _, ok := m["additionalItems"]; ok && r.draft.version < 2020 {
+		if _, ok := m["items"].(map[string]interface{}); ok {
+			if report("keyword %q is ignored, when %q is not array", "additionalItems", "items") {
+				return err
+			}
+		}
+	}
+
+	// enum and const values not allowed by type
+	if t, ok := m["type"]; ok {
+		var types []string
+		switch t := t.(type) {
+		case string:
+			types = []string{t}
+		case []interface{}:
+			types = toStrings(t)
+		}
+		var values []interface{}
+		if enum, ok := m["enum"].([]interface{}); ok {
+			values = append(values, enum...)
+		}
+		if v, ok := m["const"]; ok && r.draft.version >= 6 {
+			values = append(values, v)
+		}
+		for _, v := range values {
+			if !typeAllows(types, v) {
+				b, _ := json.Marshal(v)
+				if report("value %s is not of type %v", b, t) {
+					return err
+				}
+			}
+		}
+	}
+
+	// lower limit greater than upper limit
+	for _, limits := range [][2]string{
+		{"minimum", "maximum"}, {"minLength", "maxLength"}, {"minItems", "maxItems"},
+		{"minProperties", "maxProperties"}, {"minContains", "maxContains"},
+	} {
+		min, ok1 := m[limits[0]].(json.Number)
+		max, ok2 := m[limits[1]].(json.Number)
+		if !ok1 || !ok2 {
+			continue
+		}
+		rmin, ok1 := new(big.Rat).SetString(string(min))
+		rmax, ok2 := new(big.Rat).SetString(string(max))
+		if ok1 && ok2 && rmin.Cmp(rmax) > 0 {
+			if report("%s %s is greater than %s %s", limits[0], min, limits[1], max) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// knownKeywords returns the keywords, which can be used in schema
+// of draft d. This includes the keywords enabled by compiler options
+// and registered extensions.
+func (c *Compiler) knownKeywords(d *Draft) map[string]bool {
+	known := make(map[string]bool, len(d.keywords))
+	for kw := range d.keywords {
+		known[kw] = true
+	}
+	if c.AllowErrorMessage {
+		known["errorMessage"] = true
+	}
+	if c.AllowDiscriminator {
+		known["discriminator"] = true
+	}
+	if c.AllowData {
+		known["$data"] = true
+	}
+	for _, kw := range backportKeywords {
+		if c.backported(d, kw) {
+			known[kw] = true
+		}
+	}
+	for _, ext := range c.extensions {
+		if ext.meta != nil {
+			for kw := range ext.meta.Properties {
+				known[kw] = true
+			}
+		}
+	}
+	return known
+}
+
+// isKeyword tells whether kw is defined by any draft.
+func isKeyword(kw string) bool {
+	for _, d := range []*Draft{Draft4, Draft6, Draft7, Draft2019, Draft2020, OpenAPI30, OpenAPI31} {
+		if d.keywords[kw] {
+			return true
+		}
+	}
+	return false
+}
+
+// typeAllows tells whether json value v is allowed by given types.
+func typeAllows(types []string, v interface{}) bool {
+	vType := jsonType(v)
+	for _, t := range types {
+		if t == vType {
+			return true
+		}
+		if t == "integer" && vType == "number" {
+			if num, ok := new(big.Rat).SetString(fmt.Sprint(v)); ok && num.IsInt() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func mapKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
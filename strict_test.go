@@ -0,0 +1,83 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Strict(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string // empty if schema must compile
+	}{
+		{"valid", `{"type": "object", "properties": {"a": {"type": "integer", "minimum": 1, "maximum": 10}}, "required": ["a"]}`, ""},
+		{"typo", `{"properties": {"a": {}}, "requird": ["a"]}`, `schema.json#; did you mean "required"?`},
+		{"unknown", `{"foo": 1}`, `unknown keyword "foo" in file://`},
+		{"other draft", `{"$schema": "http://json-schema.org/draft-07/schema#", "$defs": {}}`, `keyword "$defs" is not supported in Draft7`},
+		{"ref siblings", `{"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {"a": {}}, "properties": {"b": {"$ref": "#/definitions/a", "type": "string"}}}`, `keyword "type" next to $ref is ignored in #/properties/b`},
+		{"ref siblings 2020", `{"$defs": {"a": {}}, "$ref": "#/$defs/a", "type": "string"}`, ""},
+		{"then without if", `{"then": {}}`, `keyword "then" without "if" is ignored`},
+		{"minContains without contains", `{"minContains": 1}`, `keyword "minContains" without "contains" is ignored`},
+		{"additionalItems", `{"$schema": "http://json-schema.org/draft-07/schema#", "items": {}, "additionalItems": false}`, `keyword "additionalItems" is ignored`},
+		{"fractional enum", `{"type": "integer", "enum": [1, 2.5]}`, `value 2.5 is not of type integer`},
+		{"enum type", `{"type": ["string", "null"], "enum": ["a", null, 1]}`, `value 1 is not of type`},
+		{"integral enum", `{"type": "integer", "enum": [1, 2.0]}`, ""},
+		{"const type", `{"type": "string", "const": 1}`, `value 1 is not of type string`},
+		{"min greater than max", `{"minLength": 5, "maxLength": 2}`, `minLength 5 is greater than maxLength 2`},
+		{"minimum greater than maximum", `{"minimum": 1.5, "maximum": 1}`, `minimum 1.5 is greater than maximum 1`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := jsonschema.NewCompiler()
+			c.Strict = true
+			if err := c.AddResource("schema.json", strings.NewReader(test.schema)); err != nil {
+				t.Fatal(err)
+			}
+			_, err := c.Compile("schema.json")
+			if test.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("got %v, want error containing %q", err, test.err)
+			}
+
+			// must compile without strict mode
+			if _, err := jsonschema.CompileString("schema.json", test.schema); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	t.Run("options", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.Strict = true
+		c.AllowErrorMessage = true
+		if err := c.AddResource("schema.json", strings.NewReader(`{"type": "string", "errorMessage": "must be string"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Compile("schema.json"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		c := jsonschema.NewCompiler()
+		c.Strict = true
+		c.CollectErrors = true
+		if err := c.AddResource("schema.json", strings.NewReader(`{"foo": 1, "properties": {"a": {"bar": 2}}}`)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := c.Compile("schema.json")
+		var errs jsonschema.CompileErrors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("got %v, want 2 errors", err)
+		}
+	})
+}
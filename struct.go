@@ -0,0 +1,461 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// ValidateStruct validates given go value v, against the json-schema s.
+//
+// Unlike Validate, v can be any go value such as struct, pointer, typed map
+// or slice. It is converted into json value using reflection, honoring json
+// struct tags, json.Marshaler and encoding.TextMarshaler the same way as
+// encoding/json does.
+//
+// returns *ValidationError if v does not confirm with schema s.
+// returns InvalidJSONTypeError if v contains value which cannot be represented in json.
+// returns *json.UnsupportedValueError if v refers to itself, like json.Marshal.
+func (s *Schema) ValidateStruct(v interface{}) error {
+	doc, err := toJSON(reflect.ValueOf(v), make(visited))
+	if err != nil {
+		return err
+	}
+	return s.Validate(doc)
+}
+
+// MapInstanceLocations replaces the InstanceLocation of ve and its causes
+// with fn(InstanceLocation). It is used to report errors with names which
+// are meaningful to users, instead of json-pointers:
+//
+//	if err := sch.ValidateStruct(order); err != nil {
+//		if ve, ok := err.(*ValidationError); ok {
+//			ve.MapInstanceLocations(FieldPaths(order))
+//		}
+//	}
+//
+// Note that the mapped locations are no longer json-pointers. So call it
+// only after consulting the error with methods like Output, Flatten which
+// depend on it.
+func (ve *ValidationError) MapInstanceLocations(fn func(loc string) string) {
+	ve.InstanceLocation = fn(ve.InstanceLocation)
+	for _, c := range ve.Causes {
+		c.MapInstanceLocations(fn)
+	}
+}
+
+// FieldPaths returns a function for ValidationError.MapInstanceLocations,
+// which maps json-pointers into the instance v, to go expressions
+// accessing the corresponding value, e.g. "/address/zip_code" to
+// "Address.ZipCode" and "/items/0" to "Items[0]". v is typically the value
+// given to Schema.ValidateStruct, but only its type is used.
+//
+// The tokens which cannot be resolved against the go type, such as those
+// into interface{} or json.Marshaler values, are used as is.
+func FieldPaths(v interface{}) func(loc string) string {
+	t := reflect.TypeOf(v)
+	return func(loc string) string {
+		tokens, err := jsonpointer.Split(loc)
+		if err != nil {
+			return loc
+		}
+		var b strings.Builder
+		field := func(name string) {
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(name)
+		}
+		t := t
+		for _, tok := range tokens {
+			for t != nil && t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t != nil && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)) {
+				t = nil
+			}
+			if t == nil {
+				field(tok)
+				continue
+			}
+			switch t.Kind() {
+			case reflect.Struct:
+				var found bool
+				for _, f := range structFields(t) {
+					if f.name == tok {
+						sf := t.FieldByIndex(f.index)
+						field(sf.Name)
+						t, found = sf.Type, true
+						break
+					}
+				}
+				if !found {
+					field(tok)
+					t = nil
+				}
+			case reflect.Map:
+				b.WriteString("[" + strconv.Quote(tok) + "]")
+				t = t.Elem()
+			case reflect.Slice, reflect.Array:
+				b.WriteString("[" + tok + "]")
+				t = t.Elem()
+			default:
+				field(tok)
+				t = nil
+			}
+		}
+		return b.String()
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+// visited has the pointers, maps and slices being converted by toJSON,
+// to detect cycles.
+type visited map[visit]bool
+
+// visit identifies pointer, map or slice. len is used for slices, since
+// slices of different length may share same pointer.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter marks v as being converted. returns error if v is already being
+// converted, i.e. v refers to itself.
+func (seen visited) enter(v reflect.Value) (visit, error) {
+	key := visit{v.Pointer(), v.Type(), 0}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if seen[key] {
+		return key, &json.UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+	}
+	seen[key] = true
+	return key, nil
+}
+
+// toJSON converts go value v into json value.
+func toJSON(v reflect.Value, seen visited) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) && !(t.Kind() == reflect.Pointer && v.IsNil()) {
+		b, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		return unmarshal(bytes.NewReader(b))
+	}
+	if t.Implements(textMarshalerType) && !(t.Kind() == reflect.Pointer && v.IsNil()) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if t == jsonNumberType {
+		return v.Interface(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32:
+		return float32(v.Float()), nil
+	case reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Kind() == reflect.Pointer {
+			vis, err := seen.enter(v)
+			if err != nil {
+				return nil, err
+			}
+			defer delete(seen, vis)
+		}
+		return toJSON(v.Elem(), seen)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(jsonMarshalerType) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		vis, err := seen.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer delete(seen, vis)
+		fallthrough
+	case reflect.Array:
+		arr := make([]interface{}, v.Len())
+		for i := range arr {
+			item, err := toJSON(v.Index(i), seen)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = item
+		}
+		return arr, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		vis, err := seen.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer delete(seen, vis)
+		obj := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := mapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			if obj[key], err = toJSON(iter.Value(), seen); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	case reflect.Struct:
+		obj := make(map[string]interface{})
+		for _, f := range structFields(t) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			fval, err := toJSON(fv, seen)
+			if err != nil {
+				return nil, err
+			}
+			if f.quoted {
+				switch fval.(type) {
+				case bool, int64, uint64, float64, string:
+					b, _ := json.Marshal(fval)
+					fval = string(b)
+				}
+			}
+			obj[f.name] = fval
+		}
+		return obj, nil
+	}
+	return nil, InvalidJSONTypeError(t.String())
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", InvalidJSONTypeError(fmt.Sprintf("map key %s", k.Type()))
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns false
+// if it encounters nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// structField describes a struct field which is encoded to json.
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool // name is from json tag
+	omitEmpty bool
+	quoted    bool // ",string" option
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// structFields returns the fields of struct type t, that are encoded
+// to json, following the rules of encoding/json for embedded structs.
+func structFields(t reflect.Type) []structField {
+	if f, ok := structFieldsCache.Load(t); ok {
+		return f.([]structField)
+	}
+
+	var fields []structField
+	type typeIndex struct {
+		typ   reflect.Type
+		index []int
+	}
+	current, next := []typeIndex{}, []typeIndex{{typ: t}}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, nil
+		var level []structField
+		for _, ti := range current {
+			if visited[ti.typ] {
+				continue
+			}
+			visited[ti.typ] = true
+			for i := 0; i < ti.typ.NumField(); i++ {
+				sf := ti.typ.Field(i)
+				ft := sf.Type
+				if sf.Anonymous {
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := make([]int, len(ti.index)+1)
+				copy(index, ti.index)
+				index[len(ti.index)] = i
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, typeIndex{ft, index})
+					continue
+				}
+				f := structField{
+					name:      name,
+					index:     index,
+					tagged:    name != "",
+					omitEmpty: hasOption(opts, "omitempty"),
+					quoted:    hasOption(opts, "string"),
+				}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				level = append(level, f)
+			}
+		}
+
+		// fields at shallower depth dominate. at same depth,
+		// tagged field dominates, otherwise all of them are dropped.
+		byName := make(map[string][]structField)
+		for _, f := range level {
+			byName[f.name] = append(byName[f.name], f)
+		}
+		for name, ff := range byName {
+			if containsField(fields, name) {
+				continue
+			}
+			if len(ff) == 1 {
+				fields = append(fields, ff[0])
+				continue
+			}
+			var tagged []structField
+			for _, f := range ff {
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+			if len(tagged) == 1 {
+				fields = append(fields, tagged[0])
+			} else {
+				// mark name as taken, so that deeper fields are ignored
+				fields = append(fields, structField{name: name})
+			}
+		}
+	}
+
+	result := fields[:0]
+	for _, f := range fields {
+		if f.index != nil {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return lessIndex(result[i].index, result[j].index)
+	})
+	structFieldsCache.Store(t, result)
+	return result
+}
+
+func containsField(fields []structField, name string) bool {
+	for _, f := range fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
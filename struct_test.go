@@ -0,0 +1,180 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type address struct {
+	Street string `json:"street"`
+	City   string `json:"city,omitempty"`
+}
+
+type audit struct {
+	Created time.Time `json:"created"`
+}
+
+type user struct {
+	audit
+	Name     string            `json:"name"`
+	Age      int16             `json:"age"`
+	Email    *string           `json:"email,omitempty"`
+	Address  *address          `json:"address"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Score    float32           `json:"score"`
+	ID       int64             `json:"id,string"`
+	Password string            `json:"-"`
+	internal string
+}
+
+func TestValidateStruct(t *testing.T) {
+	sch := jsonschema.MustCompileString("user.json", `{
+		"type": "object",
+		"required": ["name", "age", "created", "id"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer", "minimum": 18},
+			"email": {"type": "string", "format": "email"},
+			"address": {
+				"type": ["object", "null"],
+				"required": ["street"],
+				"properties": {"street": {"type": "string", "minLength": 1}}
+			},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}, "uniqueItems": true},
+			"score": {"type": "number", "multipleOf": 0.5},
+			"created": {"type": "string", "format": "date-time"},
+			"id": {"type": "string", "pattern": "^[0-9]+$"}
+		},
+		"additionalProperties": false
+	}`)
+
+	valid := user{
+		audit: audit{Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Name:  "john",
+		Age:   20,
+		Tags:  []string{"a", "b"},
+		Score: 1.5,
+		ID:    42,
+	}
+	if err := sch.ValidateStruct(valid); err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if err := sch.ValidateStruct(&valid); err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	invalid := []struct {
+		description string
+		modify      func(u *user)
+	}{
+		{"age below minimum", func(u *user) { u.Age = 10 }},
+		{"empty name", func(u *user) { u.Name = "" }},
+		{"address with empty street", func(u *user) { u.Address = &address{} }},
+		{"duplicate tags", func(u *user) { u.Tags = []string{"a", "a"} }},
+		{"score not multipleOf", func(u *user) { u.Score = 1.2 }},
+		{"additional label", func(u *user) { u.Labels = map[string]string{"k": "v"} }},
+	}
+	for _, test := range invalid {
+		t.Run(test.description, func(t *testing.T) {
+			u := valid
+			test.modify(&u)
+			err := sch.ValidateStruct(u)
+			if _, ok := err.(*jsonschema.ValidationError); !ok {
+				t.Fatalf("got %#v, want *jsonschema.ValidationError", err)
+			}
+		})
+	}
+
+	t.Run("invalid json type", func(t *testing.T) {
+		err := sch.ValidateStruct(map[string]interface{}{"name": make(chan int)})
+		if _, ok := err.(jsonschema.InvalidJSONTypeError); !ok {
+			t.Fatalf("got %#v, want jsonschema.InvalidJSONTypeError", err)
+		}
+	})
+}
+
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+func TestValidateStruct_cycle(t *testing.T) {
+	sch := jsonschema.MustCompileString("node.json", `{}`)
+
+	list := &node{Name: "a"}
+	list.Next = &node{Name: "b", Next: list}
+	m := map[string]interface{}{}
+	m["self"] = m
+	s := []interface{}{nil}
+	s[0] = s
+	for name, v := range map[string]interface{}{"pointer": list, "map": m, "slice": s} {
+		t.Run(name, func(t *testing.T) {
+			err := sch.ValidateStruct(v)
+			if _, ok := err.(*json.UnsupportedValueError); !ok {
+				t.Fatalf("got %#v, want *json.UnsupportedValueError", err)
+			}
+			if _, jerr := json.Marshal(v); jerr.Error() != err.Error() {
+				t.Errorf("got %q, want %q", err, jerr)
+			}
+		})
+	}
+
+	t.Run("shared", func(t *testing.T) {
+		b := &node{Name: "b"}
+		if err := sch.ValidateStruct([]*node{{Name: "a", Next: b}, b, b}); err != nil {
+			t.Fatalf("%#v", err)
+		}
+	})
+}
+
+func TestFieldPaths(t *testing.T) {
+	type order struct {
+		Items    []user                 `json:"items"`
+		Meta     map[string]*address    `json:"meta"`
+		Extra    map[string]interface{} `json:"extra"`
+		Placed   time.Time              `json:"placed"`
+		Customer *user
+	}
+	fieldPath := jsonschema.FieldPaths(&order{})
+	tests := []struct {
+		loc, want string
+	}{
+		{"", ""},
+		{"/items", "Items"},
+		{"/items/0/address/city", "Items[0].Address.City"},
+		{"/items/1/created", "Items[1].Created"},
+		{"/meta/home/street", `Meta["home"].Street`},
+		{"/extra/a~1b/c", `Extra["a/b"].c`},
+		{"/placed/year", "Placed.year"},
+		{"/Customer/labels/x", `Customer.Labels["x"]`},
+		{"/unknown/0", "unknown.0"},
+	}
+	for _, test := range tests {
+		if got := fieldPath(test.loc); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.loc, got, test.want)
+		}
+	}
+
+	sch := jsonschema.MustCompileString("order.json", `{
+		"properties": {
+			"items": {
+				"items": {"properties": {"age": {"minimum": 18}}}
+			}
+		}
+	}`)
+	o := order{Items: []user{{Age: 20}, {Age: 10}}}
+	err := sch.ValidateStruct(o)
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("got %#v, want *jsonschema.ValidationError", err)
+	}
+	ve.MapInstanceLocations(jsonschema.FieldPaths(o))
+	leaves := ve.Leaves()
+	if len(leaves) != 1 || leaves[0].InstanceLocation != "Items[1].Age" {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package jsonschema
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// nearest returns the candidate nearest to s, to be suggested in errors
+// as "did you mean". A candidate is near if its edit distance to s is
+// small, or else if it has the same last path segment as s. returns empty
+// string if no candidate is near.
+func nearest(s string, candidates []string) string {
+	sort.Strings(candidates) // pick same candidate on tie
+	maxDist := utf8.RuneCountInString(s) / 4
+	if maxDist < 1 {
+		maxDist = 1
+	}
+	best, bestDist := "", maxDist+1
+	for _, cand := range candidates {
+		if cand == s {
+			continue
+		}
+		if d := editDistance(s, cand); d < bestDist {
+			best, bestDist = cand, d
+		}
+	}
+	if best != "" {
+		return best
+	}
+	last := s[strings.LastIndexByte(s, '/')+1:]
+	if last == "" {
+		return ""
+	}
+	for _, cand := range candidates {
+		if cand != s && cand[strings.LastIndexByte(cand, '/')+1:] == last {
+			return cand
+		}
+	}
+	return ""
+}
+
+// nearestN returns at most n candidates nearest to s by edit distance,
+// nearest first. Unlike nearest, there is no fallback to last path segment.
+func nearestN(s string, candidates []string, n int) []string {
+	maxDist := utf8.RuneCountInString(s) / 4
+	if maxDist < 1 {
+		maxDist = 1
+	}
+	dist := make(map[string]int)
+	var near []string
+	for _, cand := range candidates {
+		if cand == s {
+			continue
+		}
+		if d := editDistance(s, cand); d <= maxDist {
+			dist[cand] = d
+			near = append(near, cand)
+		}
+	}
+	sort.Slice(near, func(i, j int) bool {
+		if di, dj := dist[near[i]], dist[near[j]]; di != dj {
+			return di < dj
+		}
+		return near[i] < near[j]
+	})
+	if len(near) > n {
+		near = near[:n]
+	}
+	return near
+}
+
+// editDistance returns the optimal string alignment distance between s
+// and t, i.e. levenshtein distance with transposition of adjacent
+// characters counted as single edit, since that is a common typo.
+func editDistance(s, t string) int {
+	a, b := []rune(s), []rune(t)
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < cur[j] {
+				cur[j] = prev2[j-2] + 1
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,247 @@
+// Package testsuite runs JSON-Schema-Test-Suite, so that custom keywords,
+// formats and dialects can verify themselves against the official suite.
+//
+// Typical usage:
+//
+//	func TestSuite(t *testing.T) {
+//		s := &testsuite.Suite{
+//			FS: os.DirFS("testdata/JSON-Schema-Test-Suite"),
+//			Setup: func(c *jsonschema.Compiler) {
+//				c.RegisterExtension("myKeyword", meta, compiler)
+//			},
+//			Skip: map[string]map[string][]string{
+//				"draft2020-12/optional/format/idn-email.json": {}, // not implemented
+//			},
+//		}
+//		s.Run(t, "draft2020-12", "draft2019-09")
+//	}
+//
+// Each draft folder, test file, test group and test case runs as subtest,
+// so that go test -run can select them:
+//
+//	go test -run 'TestSuite/draft2020-12/optional/format/email.json'
+//
+// Remote references to http://localhost:1234/ are loaded from "remotes"
+// folder of the suite, without starting http server.
+package testsuite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Drafts maps folders in "tests" directory of JSON-Schema-Test-Suite to
+// the draft they test.
+var Drafts = map[string]*jsonschema.Draft{
+	"draft4":       jsonschema.Draft4,
+	"draft6":       jsonschema.Draft6,
+	"draft7":       jsonschema.Draft7,
+	"draft2019-09": jsonschema.Draft2019,
+	"draft2020-12": jsonschema.Draft2020,
+}
+
+// Suite runs tests of JSON-Schema-Test-Suite, or of any directory
+// laid out like it.
+type Suite struct {
+	// FS is the root of the suite, containing "tests" and "remotes" folders.
+	FS fs.FS
+
+	// Drafts maps folders in "tests", to the draft used for schemas
+	// without "$schema". nil means package global Drafts.
+	Drafts map[string]*jsonschema.Draft
+
+	// Remotes maps url prefix to file system, from which documents under
+	// that prefix are loaded. nil means "http://localhost:1234/" is loaded
+	// from "remotes" folder in FS. Other urls are loaded using
+	// Compiler.LoadURL set by Setup, or package global jsonschema.LoadURL.
+	Remotes map[string]fs.FS
+
+	// SkipOptional skips tests in "optional" folders, which test
+	// optional behavior such as format and content assertions.
+	SkipOptional bool
+
+	// Skip lists the tests to be skipped. Key is path of test file
+	// relative to "tests", such as "draft2020-12/optional/format/email.json".
+	// Value maps description of test group to descriptions of test cases
+	// to be skipped. Empty map or slice skips entire file or group.
+	Skip map[string]map[string][]string
+
+	// Setup, if not nil, is called with the compiler created for each test
+	// group, to register extensions, formats etc. The compiler has Draft
+	// set, and AssertFormat and AssertContent enabled for optional tests.
+	Setup func(c *jsonschema.Compiler)
+}
+
+// group is a test group in test file.
+type group struct {
+	Description string
+	Schema      json.RawMessage
+	Tests       []struct {
+		Description string
+		Data        interface{}
+		Valid       bool
+	}
+}
+
+// Run runs tests in given draft folders, such as "draft2020-12". If no
+// draft is given, all folders in Drafts are run.
+func (s *Suite) Run(t *testing.T, drafts ...string) {
+	all := s.Drafts
+	if all == nil {
+		all = Drafts
+	}
+	if len(drafts) == 0 {
+		for name := range all {
+			drafts = append(drafts, name)
+		}
+		sort.Strings(drafts)
+	}
+	for _, name := range drafts {
+		draft, ok := all[name]
+		if !ok {
+			t.Fatalf("testsuite: unknown draft folder %q", name)
+		}
+		t.Run(name, func(t *testing.T) {
+			s.runFolder(t, name, draft)
+		})
+	}
+}
+
+// runFolder runs test files in dir, relative to "tests".
+func (s *Suite) runFolder(t *testing.T, dir string, draft *jsonschema.Draft) {
+	des, err := fs.ReadDir(s.FS, path.Join("tests", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, de := range des {
+		name := path.Join(dir, de.Name())
+		if de.IsDir() {
+			if de.Name() == "optional" && s.SkipOptional {
+				continue
+			}
+			t.Run(de.Name(), func(t *testing.T) {
+				s.runFolder(t, name, draft)
+			})
+			continue
+		}
+		if path.Ext(de.Name()) != ".json" {
+			continue
+		}
+		t.Run(de.Name(), func(t *testing.T) {
+			s.runFile(t, name, draft)
+		})
+	}
+}
+
+// runFile runs test file at name, relative to "tests".
+func (s *Suite) runFile(t *testing.T, name string, draft *jsonschema.Draft) {
+	skip, ok := s.Skip[name]
+	if ok && len(skip) == 0 {
+		t.Skip()
+	}
+	f, err := s.FS.Open(path.Join("tests", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var groups []group
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	if err := dec.Decode(&groups); err != nil {
+		t.Fatalf("invalid test file %s: %v", name, err)
+	}
+	optional := strings.Contains("/"+name, "/optional/")
+	for _, g := range groups {
+		g := g
+		t.Run(g.Description, func(t *testing.T) {
+			skip, ok := skip[g.Description]
+			if ok && len(skip) == 0 {
+				t.Skip()
+			}
+			s.runGroup(t, &g, draft, optional, skip)
+		})
+	}
+}
+
+func (s *Suite) runGroup(t *testing.T, g *group, draft *jsonschema.Draft, optional bool, skip []string) {
+	c := jsonschema.NewCompiler()
+	c.Draft = draft
+	if optional {
+		c.AssertFormat = true
+		c.AssertContent = true
+	}
+	if s.Setup != nil {
+		s.Setup(c)
+	}
+	c.LoadURL = s.loader(c.LoadURL)
+	if err := c.AddResource("schema.json", bytes.NewReader(g.Schema)); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	for _, test := range g.Tests {
+		test := test
+		t.Run(test.Description, func(t *testing.T) {
+			for _, desc := range skip {
+				if test.Description == desc {
+					t.Skip()
+				}
+			}
+			err := schema.Validate(test.Data)
+			valid := err == nil
+			if !valid {
+				ve, ok := err.(*jsonschema.ValidationError)
+				if !ok {
+					t.Fatalf("got: %#v, want: *jsonschema.ValidationError", err)
+				}
+				for _, line := range strings.Split(ve.GoString(), "\n") {
+					t.Logf("%s", line)
+				}
+			}
+			if test.Valid != valid {
+				t.Fatalf("valid: got %v, want %v", valid, test.Valid)
+			}
+			if test.Valid != schema.Valid(test.Data) {
+				t.Fatalf("Schema.Valid: got %v, want %v", !test.Valid, test.Valid)
+			}
+		})
+	}
+}
+
+// loader returns function that loads remotes from s.Remotes,
+// and delegates other urls to next.
+func (s *Suite) loader(next func(string) (io.ReadCloser, error)) func(string) (io.ReadCloser, error) {
+	remotes := s.Remotes
+	if remotes == nil {
+		sub, err := fs.Sub(s.FS, "remotes")
+		if err == nil {
+			remotes = map[string]fs.FS{"http://localhost:1234/": sub}
+		}
+	}
+	if next == nil {
+		next = jsonschema.LoadURL
+	}
+	return func(url string) (io.ReadCloser, error) {
+		for prefix, fsys := range remotes {
+			if strings.HasPrefix(url, prefix) {
+				f, err := fsys.Open(strings.TrimPrefix(url, prefix))
+				if err != nil {
+					return nil, fmt.Errorf("testsuite: %v", err)
+				}
+				return f, nil
+			}
+		}
+		return next(url)
+	}
+}
@@ -0,0 +1,77 @@
+package testsuite_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/testsuite"
+)
+
+var suite = fstest.MapFS{
+	"tests/draft2020-12/type.json": {Data: []byte(`[{
+		"description": "integer type",
+		"schema": {"type": "integer"},
+		"tests": [
+			{"description": "an integer is an integer", "data": 1, "valid": true},
+			{"description": "a float with zero fractional part is an integer", "data": 1.0, "valid": true},
+			{"description": "a string is not an integer", "data": "1", "valid": false},
+			{"description": "wrong expectation", "data": 1.5, "valid": true}
+		]
+	}]`)},
+	"tests/draft2020-12/refRemote.json": {Data: []byte(`[{
+		"description": "remote ref",
+		"schema": {"$ref": "http://localhost:1234/integer.json"},
+		"tests": [
+			{"description": "remote ref valid", "data": 1, "valid": true},
+			{"description": "remote ref invalid", "data": "a", "valid": false}
+		]
+	}]`)},
+	"tests/draft2020-12/optional/format/email.json": {Data: []byte(`[{
+		"description": "email format",
+		"schema": {"format": "email"},
+		"tests": [
+			{"description": "invalid email is asserted", "data": "x", "valid": false}
+		]
+	}]`)},
+	"tests/draft2020-12/optional/evenKeyword.json": {Data: []byte(`[{
+		"description": "custom keyword",
+		"schema": {"even": true},
+		"tests": [
+			{"description": "odd number", "data": 3, "valid": false}
+		]
+	}]`)},
+	"tests/draft4/type.json": {Data: []byte(`[{
+		"description": "broken",
+		"schema": {"type": "integer"},
+		"tests": [{"description": "wrong expectation", "data": "x", "valid": true}]
+	}]`)},
+	"remotes/integer.json": {Data: []byte(`{"type": "integer"}`)},
+}
+
+func TestSuite_Run(t *testing.T) {
+	var setups int
+	s := &testsuite.Suite{
+		FS: suite,
+		Skip: map[string]map[string][]string{
+			"draft2020-12/type.json": {
+				"integer type": {"wrong expectation"},
+			},
+			"draft2020-12/optional/evenKeyword.json": {},
+		},
+		Setup: func(c *jsonschema.Compiler) {
+			setups++
+		},
+	}
+	s.Run(t, "draft2020-12")
+	if setups != 3 {
+		t.Fatalf("Setup called %d times, want 3", setups)
+	}
+
+	setups = 0
+	s.SkipOptional = true
+	s.Run(t, "draft2020-12")
+	if setups != 2 {
+		t.Fatalf("Setup called %d times, want 2", setups)
+	}
+}
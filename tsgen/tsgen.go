@@ -0,0 +1,402 @@
+// Package tsgen generates typescript type declarations from compiled json-schemas.
+// This lets go services, which own the schemas, ship types to frontend
+// consumers from a single source of truth.
+//
+// Typical usage:
+//
+//	compiler := jsonschema.NewCompiler()
+//	compiler.ExtractAnnotations = true // to generate doc comments
+//	sch, err := compiler.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//	g := tsgen.NewGenerator()
+//	g.Add("Person", sch)
+//	src := g.Source()
+//
+// The mapping from json-schema to typescript types is as follows:
+//   - object with properties is mapped to exported interface, with member for
+//     each property. Optional properties are marked with '?'.
+//   - object without properties is mapped to index signature { [key: string]: T },
+//     where T is from additionalProperties.
+//   - array is mapped to T[], where T is from items. prefixItems, or items array
+//     in older drafts, is mapped to tuple.
+//   - string, integer, number, boolean, null are mapped to string, number,
+//     number, boolean, null.
+//   - enum and const are mapped to union of literal types, if all values are
+//     string, number, boolean or null.
+//   - multiple types, such as "type": ["string", "null"], are mapped to union.
+//   - schema referenced via $ref is mapped to exported type alias or interface.
+//   - allOf is mapped to interface with properties of all subschemas, or to
+//     intersection of the subschemas, if none of them has properties.
+//   - oneOf and anyOf are mapped to union of the alternatives.
+//   - false schema is mapped to never, anything else is mapped to unknown.
+//
+// Since compiled schema does not preserve the order of properties, interface
+// members are generated in sorted order of property names.
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/internal/schemautil"
+)
+
+// Generator generates typescript type declarations for the schemas added to it.
+type Generator struct {
+	names map[*jsonschema.Schema]string // named types generated
+	used  map[string]bool               // names already used
+	decls []string
+}
+
+// NewGenerator returns new Generator.
+func NewGenerator() *Generator {
+	return &Generator{
+		names: make(map[*jsonschema.Schema]string),
+		used:  make(map[string]bool),
+	}
+}
+
+// Add generates exported type with given name for sch, along with types for
+// its subschemas. If name is empty, it is derived from the schema location.
+//
+// returns the name of generated type. It differs from name if the name is
+// already used or sch is already added.
+func (g *Generator) Add(name string, sch *jsonschema.Schema) string {
+	if name == "" {
+		name = tsName(schemautil.LocationName(sch.Location))
+	}
+	return g.named(sch, tsName(name))
+}
+
+// Source returns the source code of generated declarations.
+func (g *Generator) Source() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated from json-schema. DO NOT EDIT.")
+	for _, decl := range g.decls {
+		fmt.Fprintln(&buf)
+		buf.WriteString(decl)
+	}
+	return buf.Bytes()
+}
+
+// named returns the name of typescript type generated for sch.
+func (g *Generator) named(sch *jsonschema.Schema, name string) string {
+	if n, ok := g.names[sch]; ok {
+		return n
+	}
+	name = schemautil.UniqueName(g.used, name)
+	g.names[sch] = name
+	index := len(g.decls)
+	g.decls = append(g.decls, "") // reserve, so that it precedes types it uses
+
+	var decl bytes.Buffer
+	writeDoc(&decl, "", name, sch)
+	sch = schemautil.Deref(sch, false)
+	if isInterface(sch) {
+		g.writeInterface(&decl, name, sch)
+	} else {
+		fmt.Fprintf(&decl, "export type %s = %s;\n", name, g.expr(sch, name))
+	}
+	g.decls[index] = decl.String()
+	return name
+}
+
+// typeOf returns the typescript type expression for sch. hint is used as
+// name, if a named type has to be generated.
+func (g *Generator) typeOf(sch *jsonschema.Schema, hint string) string {
+	if name, ok := g.names[sch]; ok {
+		return name
+	}
+	return g.expr(sch, hint)
+}
+
+// expr is like typeOf, but does not use the named type of sch.
+func (g *Generator) expr(sch *jsonschema.Schema, hint string) string {
+	if ref := schemautil.RefOf(sch); ref != nil && schemautil.IsRefOnly(sch) && len(sch.AllOf) == 0 {
+		return g.named(ref, tsName(schemautil.LocationName(ref.Location)))
+	}
+	if sch.Always != nil {
+		if *sch.Always {
+			return "unknown"
+		}
+		return "never"
+	}
+	if len(sch.Constant) > 0 {
+		if t, ok := literals(sch.Constant[:1]); ok {
+			return t
+		}
+	}
+	if len(sch.Enum) > 0 {
+		if t, ok := literals(sch.Enum); ok {
+			return t
+		}
+	}
+	if alts := append(append([]*jsonschema.Schema{}, sch.OneOf...), sch.AnyOf...); len(alts) > 0 {
+		var types []string
+		for i, alt := range alts {
+			types = append(types, g.typeOf(alt, hint+strconv.Itoa(i)))
+		}
+		return union(types)
+	}
+	if len(sch.AllOf) > 0 && schemautil.IsRefOnly(sch) {
+		if schemautil.HasProperties(sch) {
+			return g.named(sch, hint)
+		}
+		var types []string
+		if ref := schemautil.RefOf(sch); ref != nil {
+			types = append(types, g.named(ref, tsName(schemautil.LocationName(ref.Location))))
+		}
+		for i, s := range sch.AllOf {
+			t := g.typeOf(s, hint+strconv.Itoa(i))
+			if strings.Contains(t, "|") {
+				t = "(" + t + ")"
+			}
+			if t != "unknown" {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 0 {
+			return "unknown"
+		}
+		return strings.Join(types, " & ")
+	}
+
+	types, nullable := schemautil.TypesOf(sch, true)
+	if nullable {
+		types = append(types, "null")
+	}
+	if len(types) == 0 {
+		return "unknown"
+	}
+	var list []string
+	for _, t := range types {
+		switch t {
+		case "string", "boolean", "null":
+			list = append(list, t)
+		case "integer", "number":
+			list = append(list, "number")
+		case "array":
+			list = append(list, g.arrayType(sch, hint))
+		case "object":
+			switch {
+			case schemautil.HasProperties(sch):
+				list = append(list, g.named(sch, hint))
+			default:
+				value := "unknown"
+				if ap, ok := sch.AdditionalProperties.(*jsonschema.Schema); ok {
+					value = g.typeOf(ap, hint+"Value")
+				}
+				list = append(list, "{ [key: string]: "+value+" }")
+			}
+		}
+	}
+	return union(list)
+}
+
+func (g *Generator) arrayType(sch *jsonschema.Schema, hint string) string {
+	itemType := func(sch *jsonschema.Schema, hint string) string {
+		t := g.typeOf(sch, hint)
+		if strings.ContainsAny(t, "|&") {
+			t = "(" + t + ")"
+		}
+		return t
+	}
+	tuple := func(prefix []*jsonschema.Schema, rest *jsonschema.Schema) string {
+		var types []string
+		for i, item := range prefix {
+			types = append(types, g.typeOf(item, hint+"Item"+strconv.Itoa(i)))
+		}
+		if rest == nil || rest.Always == nil || *rest.Always {
+			t := "unknown"
+			if rest != nil {
+				t = itemType(rest, hint+"Item")
+			}
+			types = append(types, "..."+t+"[]")
+		}
+		return "[" + strings.Join(types, ", ") + "]"
+	}
+	switch items := sch.Items.(type) {
+	case *jsonschema.Schema:
+		return itemType(items, hint+"Item") + "[]"
+	case []*jsonschema.Schema:
+		rest, _ := sch.AdditionalItems.(*jsonschema.Schema)
+		if b, ok := sch.AdditionalItems.(bool); ok && !b {
+			f := false
+			rest = &jsonschema.Schema{Always: &f}
+		}
+		return tuple(items, rest)
+	}
+	if len(sch.PrefixItems) > 0 {
+		return tuple(sch.PrefixItems, sch.Items2020)
+	}
+	if sch.Items2020 != nil {
+		return itemType(sch.Items2020, hint+"Item") + "[]"
+	}
+	return "unknown[]"
+}
+
+func (g *Generator) writeInterface(w *bytes.Buffer, name string, sch *jsonschema.Schema) {
+	props, required := schemautil.Properties(sch)
+	pnames := make([]string, 0, len(props))
+	for pname := range props {
+		pnames = append(pnames, pname)
+	}
+	sort.Strings(pnames)
+
+	fmt.Fprintf(w, "export interface %s {\n", name)
+	for _, pname := range pnames {
+		psch := props[pname]
+		writeDoc(w, "  ", "", psch)
+		key := pname
+		if !isIdentifier(key) {
+			key = strconv.Quote(key)
+		}
+		if !required[pname] {
+			key += "?"
+		}
+		fmt.Fprintf(w, "  %s: %s;\n", key, g.typeOf(psch, name+tsName(pname)))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// literals returns union of literal types for values, if all of them are
+// string, number, boolean or null.
+func literals(values []interface{}) (string, bool) {
+	var types []string
+	for _, v := range values {
+		switch v.(type) {
+		case nil, string, bool, json.Number, float64, int, int64:
+		default:
+			return "", false
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		types = append(types, string(b))
+	}
+	return union(types), true
+}
+
+// union returns union of given types, ignoring duplicates.
+func union(types []string) string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, t := range types {
+		if t == "unknown" {
+			return t
+		}
+		if !seen[t] {
+			seen[t] = true
+			list = append(list, t)
+		}
+	}
+	return strings.Join(list, " | ")
+}
+
+// writeDoc writes jsdoc comment with description of sch, and its
+// deprecation. name, if not empty, is included with location of sch.
+func writeDoc(w *bytes.Buffer, indent, name string, sch *jsonschema.Schema) {
+	var lines []string
+	if name != "" {
+		loc := sch.Location
+		if strings.HasPrefix(loc, "file://") {
+			// avoid local paths in generated source
+			loc = path.Base(loc)
+		}
+		lines = append(lines, fmt.Sprintf("%s is generated from %s.", name, loc))
+	}
+	if desc := description(sch); desc != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(desc, "\n")...)
+	}
+	if schemautil.Deref(sch, false).Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s/**\n", indent)
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, "*/", "*\\/")
+		if line == "" {
+			fmt.Fprintf(w, "%s *\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(w, "%s */\n", indent)
+}
+
+func description(sch *jsonschema.Schema) string {
+	for sch != nil {
+		if desc := strings.TrimSpace(sch.Description); desc != "" {
+			return desc
+		}
+		if title := strings.TrimSpace(sch.Title); title != "" {
+			return title
+		}
+		if !schemautil.IsRefOnly(sch) {
+			break
+		}
+		sch = schemautil.RefOf(sch)
+	}
+	return ""
+}
+
+// isInterface tells whether sch is mapped to interface declaration.
+func isInterface(sch *jsonschema.Schema) bool {
+	if sch.Always != nil || len(sch.Enum) > 0 || len(sch.Constant) > 0 ||
+		len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 || !schemautil.HasProperties(sch) {
+		return false
+	}
+	types, nullable := schemautil.TypesOf(sch, true)
+	return !nullable && len(types) == 1 && types[0] == "object"
+}
+
+// isIdentifier tells whether s can be used as property name without quotes.
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// tsName converts s into PascalCase typescript identifier.
+// for example first_name and first-name are converted to FirstName.
+func tsName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" {
+		return "Value"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "T" + name
+	}
+	return name
+}
@@ -0,0 +1,131 @@
+package tsgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/tsgen"
+)
+
+func TestGenerator(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	c.ExtractAnnotations = true
+	if err := c.AddResource("https://example.com/person.json", strings.NewReader(`{
+		"description": "Person represents a person.",
+		"type": "object",
+		"required": ["first_name", "id"],
+		"properties": {
+			"id": {"type": "integer"},
+			"first_name": {"type": "string", "description": "given name"},
+			"nick": {"type": ["string", "null"], "deprecated": true},
+			"color": {"enum": ["red", "green", "dark-blue"]},
+			"kind": {"const": "human"},
+			"address": {"$ref": "#/$defs/address"},
+			"friends": {"type": "array", "items": {"$ref": "#"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+			"pet": {"oneOf": [{"$ref": "#/$defs/cat"}, {"$ref": "#/$defs/dog"}]},
+			"tags": {"type": "array", "items": {"type": ["string", "integer"]}},
+			"x-id": {"type": "boolean"},
+			"extra": {}
+		},
+		"$defs": {
+			"address": {
+				"type": "object",
+				"properties": {"street": {"type": "string"}, "zip_code": {"type": "string"}},
+				"required": ["street"]
+			},
+			"cat": {"type": "object", "properties": {"meow": {"type": "boolean"}}},
+			"dog": {"allOf": [{"$ref": "#/$defs/animal"}], "properties": {"bark": {"type": "boolean"}}},
+			"animal": {"properties": {"name": {"type": "string"}}}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("https://example.com/person.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	g := tsgen.NewGenerator()
+	if name := g.Add("", sch); name != "Person" {
+		t.Fatalf("name: got %q, want %q", name, "Person")
+	}
+	want := `// Code generated from json-schema. DO NOT EDIT.
+
+/**
+ * Person is generated from https://example.com/person.json#.
+ *
+ * Person represents a person.
+ */
+export interface Person {
+  address?: Address;
+  color?: "red" | "green" | "dark-blue";
+  extra?: unknown;
+  /**
+   * given name
+   */
+  first_name: string;
+  friends?: Person[];
+  id: number;
+  kind?: "human";
+  labels?: { [key: string]: string };
+  /**
+   * @deprecated
+   */
+  nick?: string | null;
+  pet?: Cat | Dog;
+  point?: [number, number];
+  tags?: (string | number)[];
+  "x-id"?: boolean;
+}
+
+/**
+ * Address is generated from https://example.com/person.json#/$defs/address.
+ */
+export interface Address {
+  street: string;
+  zip_code?: string;
+}
+
+/**
+ * Cat is generated from https://example.com/person.json#/$defs/cat.
+ */
+export interface Cat {
+  meow?: boolean;
+}
+
+/**
+ * Dog is generated from https://example.com/person.json#/$defs/dog.
+ */
+export interface Dog {
+  bark?: boolean;
+  name?: string;
+}
+`
+	if got := string(g.Source()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerator_typeAlias(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("https://example.com/id.json", strings.NewReader(`{
+		"oneOf": [{"type": "string", "format": "uuid"}, {"type": "integer", "minimum": 1}]
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("https://example.com/id.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	g := tsgen.NewGenerator()
+	g.Add("", sch)
+	if name := g.Add("ID", sch); name != "Id" {
+		t.Errorf("name of already added schema: got %q, want %q", name, "Id")
+	}
+	want := "export type Id = string | number;\n"
+	if got := string(g.Source()); !strings.HasSuffix(got, want) {
+		t.Errorf("got:\n%s\nwant suffix:\n%s", got, want)
+	}
+}
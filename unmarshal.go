@@ -0,0 +1,338 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal validates json document data against s, and stores it in the
+// value pointed to by v, following the rules of json.Unmarshal. data is
+// parsed only once, so the value validated is exactly the one decoded into
+// v. v is not modified, if data is not valid.
+//
+// Since the parsed value is decoded into v, types implementing
+// json.Unmarshaler receive their part of data encoded again, rather than
+// the original bytes. Numbers decoded into interface{} are float64, as by
+// json.Unmarshal; use json.Number to retain precision.
+//
+// returns *ValidationError if data does not conform to s.
+// returns *json.UnmarshalTypeError if a json value is not appropriate for
+// the go type it is decoded into, and *json.InvalidUnmarshalError if v is
+// not a non-nil pointer.
+func (s *Schema) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	doc, err := unmarshal(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if err := s.Validate(doc); err != nil {
+		return err
+	}
+	return fromJSON(doc, rv.Elem(), nil)
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fromJSON stores json value v into go value rv, which must be settable.
+// fields is the path of struct fields to rv, used in errors.
+func fromJSON(v interface{}, rv reflect.Value, fields []string) error {
+	if v == nil {
+		// null sets pointers, interfaces, maps and slices to nil. others are unchanged
+		switch rv.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+
+	ju, tu, rv := indirect(rv)
+	if ju != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return ju.UnmarshalJSON(b)
+	}
+	if tu != nil {
+		s, ok := v.(string)
+		if !ok {
+			return typeError(v, rv.Type(), fields)
+		}
+		return tu.UnmarshalText([]byte(s))
+	}
+
+	t := rv.Type()
+	if t.Kind() == reflect.Interface {
+		if t.NumMethod() != 0 {
+			return typeError(v, t, fields)
+		}
+		rv.Set(reflect.ValueOf(toInterface(v)))
+		return nil
+	}
+
+	switch v := v.(type) {
+	case bool:
+		if t.Kind() != reflect.Bool {
+			return typeError(v, t, fields)
+		}
+		rv.SetBool(v)
+	case string:
+		switch {
+		case t.Kind() == reflect.String:
+			rv.SetString(v)
+		case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+			b, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return err
+			}
+			rv.SetBytes(b)
+		default:
+			return typeError(v, t, fields)
+		}
+	case json.Number:
+		return setNumber(v, rv, fields)
+	case []interface{}:
+		switch t.Kind() {
+		case reflect.Slice:
+			rv.Set(reflect.MakeSlice(t, len(v), len(v)))
+		case reflect.Array:
+			rv.Set(reflect.Zero(t))
+		default:
+			return typeError(v, t, fields)
+		}
+		for i, item := range v {
+			if i >= rv.Len() {
+				break
+			}
+			if err := fromJSON(item, rv.Index(i), fields); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Map:
+			return setMap(v, rv, fields)
+		case reflect.Struct:
+			return setStruct(v, rv, fields)
+		}
+		return typeError(v, t, fields)
+	}
+	return nil
+}
+
+// indirect allocates nil pointers in rv, and returns the json.Unmarshaler
+// or encoding.TextUnmarshaler found on the way. Otherwise it returns the
+// value, which is not a pointer.
+func indirect(rv reflect.Value) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	if rv.Kind() != reflect.Pointer && rv.Type().Name() != "" && rv.CanAddr() {
+		// methods with pointer receiver
+		rv = rv.Addr()
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		if rv.Type().NumMethod() > 0 {
+			if rv.Type().Implements(jsonUnmarshalerType) {
+				return rv.Interface().(json.Unmarshaler), nil, reflect.Value{}
+			}
+			if rv.Type().Implements(textUnmarshalerType) {
+				return nil, rv.Interface().(encoding.TextUnmarshaler), rv.Elem()
+			}
+		}
+		rv = rv.Elem()
+	}
+	return nil, nil, rv
+}
+
+func setNumber(n json.Number, rv reflect.Value, fields []string) error {
+	t := rv.Type()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(n), 10, t.Bits())
+		if err != nil {
+			return typeError(n, t, fields)
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(string(n), 10, t.Bits())
+		if err != nil {
+			return typeError(n, t, fields)
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(n), t.Bits())
+		if err != nil {
+			return typeError(n, t, fields)
+		}
+		rv.SetFloat(f)
+	case reflect.String:
+		if t != jsonNumberType {
+			return typeError(n, t, fields)
+		}
+		rv.SetString(string(n))
+	default:
+		return typeError(n, t, fields)
+	}
+	return nil
+}
+
+func setMap(obj map[string]interface{}, rv reflect.Value, fields []string) error {
+	t := rv.Type()
+	kt := t.Key()
+	switch {
+	case kt.Kind() == reflect.String, reflect.PointerTo(kt).Implements(textUnmarshalerType):
+	default:
+		switch kt.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return typeError(obj, t, fields)
+		}
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(t, len(obj)))
+	}
+	for _, key := range sortedKeys(obj) {
+		kv := reflect.New(kt).Elem()
+		switch {
+		case reflect.PointerTo(kt).Implements(textUnmarshalerType):
+			if err := kv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+				return err
+			}
+		case kt.Kind() == reflect.String:
+			kv.SetString(key)
+		default:
+			if err := setNumber(json.Number(key), kv, fields); err != nil {
+				return err
+			}
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		if err := fromJSON(obj[key], ev, fields); err != nil {
+			return err
+		}
+		rv.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+func setStruct(obj map[string]interface{}, rv reflect.Value, fields []string) error {
+	sfields := structFields(rv.Type())
+	for _, key := range sortedKeys(obj) {
+		f, ok := findField(sfields, key)
+		if !ok {
+			continue
+		}
+		fv, ok := fieldByIndexAlloc(rv, f.index)
+		if !ok {
+			continue
+		}
+		val := obj[key]
+		if s, ok := val.(string); ok && f.quoted && isQuotable(fv.Type()) {
+			if val, ok = unquote(s); !ok {
+				return &json.UnmarshalTypeError{Value: "string " + strconv.Quote(s), Type: fv.Type(), Field: strings.Join(append(fields, f.name), ".")}
+			}
+		}
+		if err := fromJSON(val, fv, append(fields, f.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unquote returns the json literal in s, for fields with ",string" option.
+func unquote(s string) (interface{}, bool) {
+	v, err := unmarshal(strings.NewReader(s))
+	if err != nil {
+		return nil, false
+	}
+	switch v.(type) {
+	case nil, bool, json.Number, string:
+		return v, true
+	}
+	return nil, false
+}
+
+// findField returns the field with given name. like encoding/json, exact
+// match is preferred over case-insensitive match.
+func findField(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+// fieldByIndexAlloc is like reflect.Value.FieldByIndex, but allocates nil
+// embedded pointers. returns false if the embedded pointer is unexported.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// toInterface converts json.Number in v to float64, as by json.Unmarshal.
+func toInterface(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := strconv.ParseFloat(string(v), 64)
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = toInterface(item)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = toInterface(item)
+		}
+	}
+	return v
+}
+
+func typeError(v interface{}, t reflect.Type, fields []string) error {
+	return &json.UnmarshalTypeError{Value: describe(v), Type: t, Field: strings.Join(fields, ".")}
+}
+
+// describe returns description of json value v, used in errors.
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case json.Number:
+		return "number " + string(v)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,150 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type UnmarshalBase struct {
+	ID int64 `json:"id"`
+}
+
+type unmarshalColor string
+
+func (c *unmarshalColor) UnmarshalText(b []byte) error {
+	*c = unmarshalColor(strings.ToUpper(string(b)))
+	return nil
+}
+
+type unmarshalPoint struct{ X, Y int }
+
+func (p *unmarshalPoint) UnmarshalJSON(b []byte) error {
+	var xy []int
+	if err := json.Unmarshal(b, &xy); err != nil {
+		return err
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+type unmarshalOrder struct {
+	*UnmarshalBase
+	Customer string                 `json:"customer"`
+	Quantity uint8                  `json:"qty"`
+	Price    float64                `json:"price,string"`
+	Amount   json.Number            `json:"amount"`
+	Color    unmarshalColor         `json:"color"`
+	Where    *unmarshalPoint        `json:"where"`
+	Created  time.Time              `json:"created"`
+	Tags     []string               `json:"tags"`
+	Counts   map[int]int            `json:"counts"`
+	Extra    map[string]interface{} `json:"extra"`
+	Note     *string                `json:"note"`
+	Ignored  string                 `json:"-"`
+}
+
+func TestSchema_Unmarshal(t *testing.T) {
+	sch := jsonschema.MustCompileString("order.json", `{
+		"type": "object",
+		"required": ["customer", "qty"],
+		"properties": {
+			"qty": {"type": "integer", "minimum": 1}
+		}
+	}`)
+
+	data := `{
+		"id": 7,
+		"customer": "john",
+		"qty": 2,
+		"price": "9.5",
+		"amount": 12345678901234567890.5,
+		"color": "red",
+		"where": [1, 2],
+		"created": "2020-01-02T03:04:05Z",
+		"tags": ["a", "b"],
+		"counts": {"1": 10},
+		"extra": {"n": 1},
+		"note": null,
+		"-": "x",
+		"unknown": true
+	}`
+	var order unmarshalOrder
+	if err := sch.Unmarshal([]byte(data), &order); err != nil {
+		t.Fatalf("%#v", err)
+	}
+	want := unmarshalOrder{
+		UnmarshalBase: &UnmarshalBase{ID: 7},
+		Customer:      "john",
+		Quantity:      2,
+		Price:         9.5,
+		Amount:        "12345678901234567890.5",
+		Color:         "RED",
+		Where:         &unmarshalPoint{1, 2},
+		Created:       time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:          []string{"a", "b"},
+		Counts:        map[int]int{1: 10},
+		Extra:         map[string]interface{}{"n": float64(1)},
+	}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("got  %+v\nwant %+v", order, want)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var order unmarshalOrder
+		err := sch.Unmarshal([]byte(`{"customer": "john", "qty": 0}`), &order)
+		if _, ok := err.(*jsonschema.ValidationError); !ok {
+			t.Fatalf("got %#v, want *ValidationError", err)
+		}
+		if order.Customer != "" {
+			t.Error("v must not be modified")
+		}
+	})
+
+	t.Run("typeError", func(t *testing.T) {
+		var order unmarshalOrder
+		err := sch.Unmarshal([]byte(`{"customer": "john", "qty": 300}`), &order)
+		var te *json.UnmarshalTypeError
+		if !errors.As(err, &te) {
+			t.Fatalf("got %#v, want *json.UnmarshalTypeError", err)
+		}
+		if te.Value != "number 300" || te.Field != "qty" || te.Type != reflect.TypeOf(uint8(0)) {
+			t.Errorf("got %+v", te)
+		}
+	})
+
+	t.Run("syntaxError", func(t *testing.T) {
+		var v interface{}
+		err := sch.Unmarshal([]byte(`{"customer": `), &v)
+		if err == nil {
+			t.Fatal("error expected")
+		}
+	})
+
+	t.Run("nonPointer", func(t *testing.T) {
+		var order unmarshalOrder
+		err := sch.Unmarshal([]byte(`{"customer": "john", "qty": 1}`), order)
+		if _, ok := err.(*json.InvalidUnmarshalError); !ok {
+			t.Fatalf("got %#v, want *json.InvalidUnmarshalError", err)
+		}
+	})
+
+	t.Run("sameAsEncodingJSON", func(t *testing.T) {
+		data := `{"customer": "john", "qty": 1, "tags": null, "extra": {"a": [1, "x", true, null]}}`
+		var got, want unmarshalOrder
+		if err := sch.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("%#v", err)
+		}
+		if err := json.Unmarshal([]byte(data), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got  %+v\nwant %+v", got, want)
+		}
+	})
+}
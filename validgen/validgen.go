@@ -0,0 +1,664 @@
+// Package validgen generates go functions, which validate json values
+// against compiled json-schemas. The generated functions do not interpret
+// the schema at runtime, so they are faster than Schema.Validate. This is
+// useful in hot paths, where the schema is known at build time.
+//
+// Typical usage:
+//
+//	sch, err := jsonschema.Compile("person.json")
+//	if err != nil {
+//		return err
+//	}
+//	g := validgen.NewGenerator("model")
+//	g.Add("ValidatePerson", sch)
+//	src, err := g.Source()
+//
+// The generated function has signature:
+//
+//	func ValidatePerson(v interface{}) error
+//
+// where v is decoded json value, as returned by json.Unmarshal into
+// interface{}, optionally with json.Decoder.UseNumber. It returns
+// *ValidationError, declared in generated source, for the first violation
+// found. Unlike Schema.Validate, the causes of failure in subschemas of
+// "anyOf", "oneOf" and "not" are not reported.
+//
+// Keywords "format", "contentEncoding", "contentMediaType" and
+// "contentSchema" are not validated. Schemas with "unevaluatedProperties",
+// "unevaluatedItems", "$recursiveRef", "$dynamicRef" or extensions are not
+// supported. Schemas compiled with Compiler.AllowData, AllowErrorMessage or
+// AllowDiscriminator are not supported, because these keywords are not
+// visible in compiled schema, and hence are silently ignored.
+package validgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Generator generates go validation functions for the schemas added to it.
+type Generator struct {
+	pkg     string
+	funcs   map[*jsonschema.Schema]string // function generated for schema
+	vars    []string                      // package level variables
+	decls   []string
+	regexp  bool // whether regexp package is used
+	utf8    bool // whether unicode/utf8 package is used
+	unknown []error
+}
+
+// NewGenerator returns Generator that generates source of given go package.
+func NewGenerator(pkg string) *Generator {
+	return &Generator{
+		pkg:   pkg,
+		funcs: make(map[*jsonschema.Schema]string),
+	}
+}
+
+// Add generates exported function with given name, which validates
+// against sch, along with unexported functions for its subschemas.
+//
+// Errors for unsupported keywords are reported by Source.
+func (g *Generator) Add(name string, sch *jsonschema.Schema) {
+	f := g.fn(sch)
+	var decl bytes.Buffer
+	fmt.Fprintf(&decl, "// %s validates v against %s.\n", name, sch.Location)
+	fmt.Fprintf(&decl, "func %s(v interface{}) error {\n\treturn %s(v)\n}\n", name, f)
+	g.decls = append(g.decls, decl.String())
+}
+
+// Source returns the gofmt-ed source code of generated functions.
+func (g *Generator) Source() ([]byte, error) {
+	if len(g.unknown) > 0 {
+		return nil, g.unknown[0]
+	}
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated from json-schema. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "package %s\n", g.pkg)
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, `import (`)
+	fmt.Fprintln(&buf, `	"encoding/json"`)
+	fmt.Fprintln(&buf, `	"fmt"`)
+	fmt.Fprintln(&buf, `	"math"`)
+	fmt.Fprintln(&buf, `	"math/big"`)
+	if g.regexp {
+		fmt.Fprintln(&buf, `	"regexp"`)
+	}
+	fmt.Fprintln(&buf, `	"strconv"`)
+	fmt.Fprintln(&buf, `	"strings"`)
+	if g.utf8 {
+		fmt.Fprintln(&buf, `	"unicode/utf8"`)
+	}
+	fmt.Fprintln(&buf, `)`)
+	if len(g.vars) > 0 {
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "var (")
+		for _, v := range g.vars {
+			fmt.Fprintf(&buf, "\t%s\n", v)
+		}
+		fmt.Fprintln(&buf, ")")
+	}
+	for _, decl := range g.decls {
+		fmt.Fprintln(&buf)
+		buf.WriteString(decl)
+	}
+	fmt.Fprintln(&buf)
+	buf.WriteString(helpers)
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("validgen: generated invalid source: %v", err)
+	}
+	return src, nil
+}
+
+// addVar adds package level variable with given value, and returns its name.
+func (g *Generator) addVar(prefix, value string) string {
+	name := fmt.Sprintf("%s%d", prefix, len(g.vars))
+	g.vars = append(g.vars, fmt.Sprintf("%s = %s", name, value))
+	return name
+}
+
+func (g *Generator) unsupported(sch *jsonschema.Schema, keyword string) {
+	g.unknown = append(g.unknown, fmt.Errorf("validgen: %s in %s is not supported", keyword, sch.Location))
+}
+
+// fn returns the name of function generated for sch.
+func (g *Generator) fn(sch *jsonschema.Schema) string {
+	if name, ok := g.funcs[sch]; ok {
+		return name
+	}
+	name := fmt.Sprintf("validate%d", len(g.funcs))
+	g.funcs[sch] = name
+	index := len(g.decls)
+	g.decls = append(g.decls, "") // reserve, so that it precedes functions it uses
+
+	var w bytes.Buffer
+	fmt.Fprintf(&w, "// %s validates v against %s.\n", name, sch.Location)
+	fmt.Fprintf(&w, "func %s(v interface{}) error {\n", name)
+	if sch.Always != nil && !*sch.Always {
+		fmt.Fprintln(&w, `return newError("false", "not allowed")`)
+	} else {
+		g.writeBody(&w, sch)
+		fmt.Fprintln(&w, "return nil")
+	}
+	fmt.Fprintln(&w, "}")
+	g.decls[index] = w.String()
+	return name
+}
+
+func (g *Generator) writeBody(w *bytes.Buffer, sch *jsonschema.Schema) {
+	switch {
+	case sch.UnevaluatedProperties != nil:
+		g.unsupported(sch, "unevaluatedProperties")
+	case sch.UnevaluatedItems != nil:
+		g.unsupported(sch, "unevaluatedItems")
+	case sch.RecursiveRef != nil:
+		g.unsupported(sch, "$recursiveRef")
+	case sch.DynamicRef != nil:
+		g.unsupported(sch, "$dynamicRef")
+	case len(sch.Extensions) > 0:
+		g.unsupported(sch, "extension")
+	}
+
+	if sch.Always != nil {
+		return
+	}
+	if sch.Ref != nil {
+		fmt.Fprintf(w, "if err := %s(v); err != nil {\nreturn err\n}\n", g.fn(sch.Ref))
+	}
+	if len(sch.Types) > 0 {
+		g.writeTypes(w, sch.Types)
+	}
+	if len(sch.Constant) > 0 {
+		c := g.addVar("const", literal(sch.Constant[0]))
+		fmt.Fprintf(w, "if !jsonEqual(v, %s) {\nreturn newError(\"const\", %q)\n}\n", c, "value must be "+display(sch.Constant[0]))
+	}
+	if sch.Enum != nil {
+		g.writeEnum(w, sch.Enum)
+	}
+	if sch.Not != nil {
+		fmt.Fprintf(w, "if %s(v) == nil {\nreturn newError(\"not\", \"not failed\")\n}\n", g.fn(sch.Not))
+	}
+	for _, s := range sch.AllOf {
+		fmt.Fprintf(w, "if err := %s(v); err != nil {\nreturn err\n}\n", g.fn(s))
+	}
+	if len(sch.AnyOf) > 0 {
+		var conds []string
+		for _, s := range sch.AnyOf {
+			conds = append(conds, g.fn(s)+"(v) != nil")
+		}
+		fmt.Fprintf(w, "if %s {\nreturn newError(\"anyOf\", \"anyOf failed\")\n}\n", strings.Join(conds, " && "))
+	}
+	if len(sch.OneOf) > 0 {
+		fmt.Fprintln(w, "{\nmatched := 0")
+		for _, s := range sch.OneOf {
+			fmt.Fprintf(w, "if %s(v) == nil {\nmatched++\n}\n", g.fn(s))
+		}
+		w.WriteString("if matched != 1 {\nreturn newError(\"oneOf\", fmt.Sprintf(\"oneOf failed, %d subschemas matched\", matched))\n}\n}\n")
+	}
+	if sch.If != nil && (sch.Then != nil || sch.Else != nil) {
+		fmt.Fprintf(w, "if %s(v) == nil {\n", g.fn(sch.If))
+		if sch.Then != nil {
+			fmt.Fprintf(w, "if err := %s(v); err != nil {\nreturn err\n}\n", g.fn(sch.Then))
+		}
+		fmt.Fprintln(w, "} else {")
+		if sch.Else != nil {
+			fmt.Fprintf(w, "if err := %s(v); err != nil {\nreturn err\n}\n", g.fn(sch.Else))
+		}
+		fmt.Fprintln(w, "}")
+	}
+	g.writeObject(w, sch)
+	g.writeArray(w, sch)
+	g.writeString(w, sch)
+	g.writeNumber(w, sch)
+}
+
+func (g *Generator) writeTypes(w *bytes.Buffer, types []string) {
+	var conds []string
+	for _, t := range types {
+		switch t {
+		case "integer":
+			conds = append(conds, "jsonIsInteger(v)")
+		default:
+			conds = append(conds, fmt.Sprintf("t == %q", t))
+		}
+	}
+	fmt.Fprintf(w, "if t := jsonType(v); !(%s) {\n", strings.Join(conds, " || "))
+	fmt.Fprintf(w, "return newError(\"type\", \"expected %s, but got \"+t)\n}\n", strings.Join(types, " or "))
+}
+
+func (g *Generator) writeEnum(w *bytes.Buffer, enum []interface{}) {
+	var values []string
+	strs := true
+	for _, item := range enum {
+		if _, ok := item.(string); !ok {
+			strs = false
+		}
+		values = append(values, display(item))
+	}
+	msg := "value must be one of " + strings.Join(values, ", ")
+	if strs && len(enum) > 0 {
+		var cases []string
+		for _, item := range enum {
+			cases = append(cases, strconv.Quote(item.(string)))
+		}
+		fmt.Fprintf(w, "if s, ok := v.(string); !ok {\nreturn newError(\"enum\", %q)\n} else {\n", msg)
+		fmt.Fprintf(w, "switch s {\ncase %s:\ndefault:\nreturn newError(\"enum\", %q)\n}\n}\n", strings.Join(cases, ", "), msg)
+		return
+	}
+	e := g.addVar("enum", literal(enum))
+	fmt.Fprintf(w, "if !jsonIn(v, %s) {\nreturn newError(\"enum\", %q)\n}\n", e, msg)
+}
+
+func (g *Generator) writeObject(w *bytes.Buffer, sch *jsonschema.Schema) {
+	var b bytes.Buffer
+	if sch.MinProperties != -1 {
+		fmt.Fprintf(&b, "if len(obj) < %d {\nreturn newError(\"minProperties\", fmt.Sprintf(\"minimum %d properties allowed, but found %%d properties\", len(obj)))\n}\n", sch.MinProperties, sch.MinProperties)
+	}
+	if sch.MaxProperties != -1 {
+		fmt.Fprintf(&b, "if len(obj) > %d {\nreturn newError(\"maxProperties\", fmt.Sprintf(\"maximum %d properties allowed, but found %%d properties\", len(obj)))\n}\n", sch.MaxProperties, sch.MaxProperties)
+	}
+	for _, pname := range sch.Required {
+		fmt.Fprintf(&b, "if _, ok := obj[%q]; !ok {\nreturn newError(\"required\", %q)\n}\n", pname, "missing property "+strconv.Quote(pname))
+	}
+	for _, pname := range sortedKeys(sch.Dependencies) {
+		switch dep := sch.Dependencies[pname].(type) {
+		case []string:
+			writeDependentRequired(&b, "dependencies", pname, dep)
+		case *jsonschema.Schema:
+			fmt.Fprintf(&b, "if _, ok := obj[%q]; ok {\nif err := %s(v); err != nil {\nreturn err\n}\n}\n", pname, g.fn(dep))
+		}
+	}
+	for _, pname := range sortedKeys(sch.DependentRequired) {
+		writeDependentRequired(&b, "dependentRequired", pname, sch.DependentRequired[pname])
+	}
+	for _, pname := range sortedKeys(sch.DependentSchemas) {
+		fmt.Fprintf(&b, "if _, ok := obj[%q]; ok {\nif err := %s(v); err != nil {\nreturn err\n}\n}\n", pname, g.fn(sch.DependentSchemas[pname]))
+	}
+	for _, pname := range sortedKeys(sch.Properties) {
+		fmt.Fprintf(&b, "if pv, ok := obj[%q]; ok {\nif err := %s(pv); err != nil {\nreturn prefix(err, %q)\n}\n}\n", pname, g.fn(sch.Properties[pname]), escape(pname))
+	}
+
+	// keywords which loop over properties
+	var patterns []string
+	for re := range sch.PatternProperties {
+		patterns = append(patterns, re.String())
+	}
+	sort.Strings(patterns)
+	_, additionalAllowed := sch.AdditionalProperties.(bool)
+	additionalAllowed = additionalAllowed && sch.AdditionalProperties.(bool)
+	if sch.PropertyNames != nil || len(patterns) > 0 || (sch.AdditionalProperties != nil && !additionalAllowed) {
+		fmt.Fprintln(&b, "for pname, pv := range obj {")
+		fmt.Fprintln(&b, "_ = pv")
+		if sch.PropertyNames != nil {
+			fmt.Fprintf(&b, "if err := %s(pname); err != nil {\nreturn prefix(err, pname)\n}\n", g.fn(sch.PropertyNames))
+		}
+		if sch.AdditionalProperties != nil && !additionalAllowed {
+			fmt.Fprintln(&b, "additional := true")
+			if len(sch.Properties) > 0 {
+				var cases []string
+				for _, pname := range sortedKeys(sch.Properties) {
+					cases = append(cases, strconv.Quote(pname))
+				}
+				fmt.Fprintf(&b, "switch pname {\ncase %s:\nadditional = false\n}\n", strings.Join(cases, ", "))
+			}
+		}
+		for _, pattern := range patterns {
+			var sub *jsonschema.Schema
+			for re, s := range sch.PatternProperties {
+				if re.String() == pattern {
+					sub = s
+				}
+			}
+			re := g.regexpVar(sch, pattern)
+			fmt.Fprintf(&b, "if %s.MatchString(pname) {\n", re)
+			if sch.AdditionalProperties != nil && !additionalAllowed {
+				fmt.Fprintln(&b, "additional = false")
+			}
+			fmt.Fprintf(&b, "if err := %s(pv); err != nil {\nreturn prefix(err, pname)\n}\n}\n", g.fn(sub))
+		}
+		switch ap := sch.AdditionalProperties.(type) {
+		case bool:
+			if !ap {
+				b.WriteString("if additional {\nreturn newError(\"additionalProperties\", fmt.Sprintf(\"additionalProperties %q not allowed\", pname))\n}\n")
+			}
+		case *jsonschema.Schema:
+			fmt.Fprintf(&b, "if additional {\nif err := %s(pv); err != nil {\nreturn prefix(err, pname)\n}\n}\n", g.fn(ap))
+		}
+		fmt.Fprintln(&b, "}")
+	}
+	if b.Len() > 0 {
+		fmt.Fprintf(w, "if obj, ok := v.(map[string]interface{}); ok {\n%s}\n", b.String())
+	}
+}
+
+func writeDependentRequired(w *bytes.Buffer, keyword, pname string, required []string) {
+	if len(required) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "if _, ok := obj[%q]; ok {\n", pname)
+	for _, req := range required {
+		msg := fmt.Sprintf("property %q is required, if %q property exists", req, pname)
+		fmt.Fprintf(w, "if _, ok := obj[%q]; !ok {\nreturn newError(%q, %q)\n}\n", req, keyword, msg)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *Generator) writeArray(w *bytes.Buffer, sch *jsonschema.Schema) {
+	var b bytes.Buffer
+	if sch.MinItems != -1 {
+		fmt.Fprintf(&b, "if len(arr) < %d {\nreturn newError(\"minItems\", fmt.Sprintf(\"minimum %d items required, but found %%d items\", len(arr)))\n}\n", sch.MinItems, sch.MinItems)
+	}
+	if sch.MaxItems != -1 {
+		fmt.Fprintf(&b, "if len(arr) > %d {\nreturn newError(\"maxItems\", fmt.Sprintf(\"maximum %d items required, but found %%d items\", len(arr)))\n}\n", sch.MaxItems, sch.MaxItems)
+	}
+	if sch.UniqueItems {
+		b.WriteString("for i := 1; i < len(arr); i++ {\nfor j := 0; j < i; j++ {\nif jsonEqual(arr[i], arr[j]) {\nreturn newError(\"uniqueItems\", fmt.Sprintf(\"items at index %d and %d are equal\", j, i))\n}\n}\n}\n")
+	}
+
+	// items, as prefix schemas followed by schema for rest of items
+	var prefixItems []*jsonschema.Schema
+	var rest interface{}
+	switch items := sch.Items.(type) {
+	case *jsonschema.Schema:
+		rest = items
+	case []*jsonschema.Schema:
+		prefixItems, rest = items, sch.AdditionalItems
+	}
+	if len(sch.PrefixItems) > 0 || sch.Items2020 != nil {
+		prefixItems, rest = sch.PrefixItems, nil
+		if sch.Items2020 != nil {
+			rest = sch.Items2020
+		}
+	}
+	for i, s := range prefixItems {
+		fmt.Fprintf(&b, "if len(arr) > %d {\nif err := %s(arr[%d]); err != nil {\nreturn prefix(err, \"%d\")\n}\n}\n", i, g.fn(s), i, i)
+	}
+	switch rest := rest.(type) {
+	case bool:
+		if !rest {
+			fmt.Fprintf(&b, "if len(arr) > %d {\nreturn newError(\"items\", \"only %d items are allowed, but found \"+fmt.Sprint(len(arr))+\" items\")\n}\n", len(prefixItems), len(prefixItems))
+		}
+	case *jsonschema.Schema:
+		fmt.Fprintf(&b, "for i := %d; i < len(arr); i++ {\nif err := %s(arr[i]); err != nil {\nreturn prefix(err, fmt.Sprint(i))\n}\n}\n", len(prefixItems), g.fn(rest))
+	}
+
+	if sch.Contains != nil {
+		fmt.Fprintf(&b, "{\nmatched := 0\nfor _, item := range arr {\nif %s(item) == nil {\nmatched++\n}\n}\n", g.fn(sch.Contains))
+		if sch.MinContains > 0 {
+			fmt.Fprintf(&b, "if matched < %d {\nreturn newError(\"contains\", fmt.Sprintf(\"minimum %d valid items required, but found %%d valid items\", matched))\n}\n", sch.MinContains, sch.MinContains)
+		}
+		if sch.MaxContains != -1 {
+			fmt.Fprintf(&b, "if matched > %d {\nreturn newError(\"maxContains\", fmt.Sprintf(\"maximum %d valid items allowed, but found %%d valid items\", matched))\n}\n", sch.MaxContains, sch.MaxContains)
+		}
+		fmt.Fprintln(&b, "}")
+	}
+	if b.Len() > 0 {
+		fmt.Fprintf(w, "if arr, ok := v.([]interface{}); ok {\n%s}\n", b.String())
+	}
+}
+
+func (g *Generator) writeString(w *bytes.Buffer, sch *jsonschema.Schema) {
+	var b bytes.Buffer
+	if sch.MinLength != -1 || sch.MaxLength != -1 {
+		g.utf8 = true
+		fmt.Fprintln(&b, "length := utf8.RuneCountInString(str)")
+		if sch.MinLength != -1 {
+			fmt.Fprintf(&b, "if length < %d {\nreturn newError(\"minLength\", fmt.Sprintf(\"length must be >= %d, but got %%d\", length))\n}\n", sch.MinLength, sch.MinLength)
+		}
+		if sch.MaxLength != -1 {
+			fmt.Fprintf(&b, "if length > %d {\nreturn newError(\"maxLength\", fmt.Sprintf(\"length must be <= %d, but got %%d\", length))\n}\n", sch.MaxLength, sch.MaxLength)
+		}
+	}
+	if sch.Pattern != nil {
+		re := g.regexpVar(sch, sch.Pattern.String())
+		fmt.Fprintf(&b, "if !%s.MatchString(str) {\nreturn newError(\"pattern\", %q)\n}\n", re, "does not match pattern "+strconv.Quote(sch.Pattern.String()))
+	}
+	if b.Len() > 0 {
+		fmt.Fprintf(w, "if str, ok := v.(string); ok {\n%s}\n", b.String())
+	}
+}
+
+// regexpVar returns the package level variable holding compiled pattern.
+func (g *Generator) regexpVar(sch *jsonschema.Schema, pattern string) string {
+	if _, err := regexp.Compile(pattern); err != nil {
+		g.unsupported(sch, fmt.Sprintf("pattern %q", pattern))
+	}
+	g.regexp = true
+	return g.addVar("pattern", fmt.Sprintf("regexp.MustCompile(%s)", strconv.Quote(pattern)))
+}
+
+func (g *Generator) writeNumber(w *bytes.Buffer, sch *jsonschema.Schema) {
+	var b bytes.Buffer
+	limit := func(keyword string, r *big.Rat, cmp, msg string) {
+		if r == nil {
+			return
+		}
+		lv := g.addVar("limit", fmt.Sprintf("jsonRat(%q)", r.RatString()))
+		fmt.Fprintf(&b, "if jsonCompare(v, %s) %s {\nreturn newError(%q, fmt.Sprintf(\"must be %s %s but found %%v\", v))\n}\n", lv, cmp, keyword, msg, ratString(r))
+	}
+	limit("minimum", sch.Minimum, "< 0", ">=")
+	limit("exclusiveMinimum", sch.ExclusiveMinimum, "<= 0", ">")
+	limit("maximum", sch.Maximum, "> 0", "<=")
+	limit("exclusiveMaximum", sch.ExclusiveMaximum, ">= 0", "<")
+	if sch.MultipleOf != nil {
+		lv := g.addVar("limit", fmt.Sprintf("jsonRat(%q)", sch.MultipleOf.RatString()))
+		fmt.Fprintf(&b, "if !new(big.Rat).Quo(jsonNumber(v), %s).IsInt() {\nreturn newError(\"multipleOf\", fmt.Sprintf(\"%%v not multipleOf %s\", v))\n}\n", lv, ratString(sch.MultipleOf))
+	}
+	if b.Len() > 0 {
+		fmt.Fprintf(w, "if jsonType(v) == \"number\" {\n%s}\n", b.String())
+	}
+}
+
+// literal returns go expression for json value v.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, literal(item))
+		}
+		return "[]interface{}{" + strings.Join(items, ", ") + "}"
+	case map[string]interface{}:
+		var props []string
+		for _, pname := range sortedKeys(v) {
+			props = append(props, strconv.Quote(pname)+": "+literal(v[pname]))
+		}
+		return "map[string]interface{}{" + strings.Join(props, ", ") + "}"
+	default:
+		return fmt.Sprintf("json.Number(%q)", fmt.Sprint(v))
+	}
+}
+
+// display returns json text of v, for use in error messages.
+func display(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of map m, which has string keys, in sorted order.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const helpers = `// ValidationError is the error returned by generated validation functions.
+type ValidationError struct {
+	InstanceLocation string // json-pointer to the invalid value
+	Keyword          string // keyword that failed
+	Message          string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("'%s': %s: %s", e.InstanceLocation, e.Keyword, e.Message)
+}
+
+func newError(keyword, msg string) error {
+	return &ValidationError{Keyword: keyword, Message: msg}
+}
+
+// prefix prepends token to the InstanceLocation of err.
+func prefix(err error, token string) error {
+	e := err.(*ValidationError)
+	token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	e.InstanceLocation = "/" + token + e.InstanceLocation
+	return e
+}
+
+// jsonType returns the json type of v.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float32, float64, int, int8, int32, int64, uint, uint8, uint32, uint64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func jsonIsInteger(v interface{}) bool {
+	switch v := v.(type) {
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			return true
+		}
+		num := jsonNumber(v)
+		return num != nil && num.IsInt()
+	case float32:
+		return float64(v) == math.Trunc(float64(v))
+	case float64:
+		return v == math.Trunc(v)
+	case int, int8, int32, int64, uint, uint8, uint32, uint64:
+		return true
+	}
+	return false
+}
+
+// jsonNumber returns the value of number v. nil if v is not number.
+func jsonNumber(v interface{}) *big.Rat {
+	switch v := v.(type) {
+	case json.Number:
+		r, _ := new(big.Rat).SetString(string(v))
+		return r
+	case float32:
+		return new(big.Rat).SetFloat64(float64(v))
+	case float64:
+		return new(big.Rat).SetFloat64(v)
+	case int, int8, int32, int64, uint, uint8, uint32, uint64:
+		r, _ := new(big.Rat).SetString(fmt.Sprint(v))
+		return r
+	}
+	return nil
+}
+
+// jsonCompare compares number v with limit. It avoids allocation,
+// if both are integers that fit in int64.
+func jsonCompare(v interface{}, limit *big.Rat) int {
+	if n, ok := v.(json.Number); ok && limit.IsInt() && limit.Num().IsInt64() {
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			switch l := limit.Num().Int64(); {
+			case i < l:
+				return -1
+			case i > l:
+				return 1
+			}
+			return 0
+		}
+	}
+	return jsonNumber(v).Cmp(limit)
+}
+
+func jsonRat(s string) *big.Rat {
+	r, _ := new(big.Rat).SetString(s)
+	return r
+}
+
+func jsonEqual(v1, v2 interface{}) bool {
+	t1, t2 := jsonType(v1), jsonType(v2)
+	if t1 != t2 {
+		return false
+	}
+	switch t1 {
+	case "number":
+		n1, n2 := jsonNumber(v1), jsonNumber(v2)
+		return n1 != nil && n2 != nil && n1.Cmp(n2) == 0
+	case "array":
+		arr1, arr2 := v1.([]interface{}), v2.([]interface{})
+		if len(arr1) != len(arr2) {
+			return false
+		}
+		for i := range arr1 {
+			if !jsonEqual(arr1[i], arr2[i]) {
+				return false
+			}
+		}
+		return true
+	case "object":
+		obj1, obj2 := v1.(map[string]interface{}), v2.(map[string]interface{})
+		if len(obj1) != len(obj2) {
+			return false
+		}
+		for k, pv1 := range obj1 {
+			pv2, ok := obj2[k]
+			if !ok || !jsonEqual(pv1, pv2) {
+				return false
+			}
+		}
+		return true
+	}
+	return v1 == v2
+}
+
+func jsonIn(v interface{}, values []interface{}) bool {
+	for _, value := range values {
+		if jsonEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+`
@@ -0,0 +1,159 @@
+package validgen_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/validgen"
+	"github.com/santhosh-tekuri/jsonschema/v5/validgen/internal/example"
+)
+
+var update = flag.Bool("update", false, "update generated source in internal/example")
+
+func compilePerson(t testing.TB) *jsonschema.Schema {
+	sch, err := jsonschema.Compile("internal/example/person.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	return sch
+}
+
+func TestGenerator(t *testing.T) {
+	g := validgen.NewGenerator("example")
+	g.Add("ValidatePerson", compilePerson(t))
+	src, err := g.Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// schema location is absolute file url, which depends on working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = bytes.ReplaceAll(src, []byte("file://"+cwd+"/"), nil)
+	if *update {
+		if err := os.WriteFile("internal/example/person.go", src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("internal/example/person.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Fatal("internal/example/person.go is outdated. run go test with -update flag")
+	}
+}
+
+var personInstances = []string{
+	`{"id": 1, "name": "a"}`,
+	`{"id": 0, "name": "a"}`,
+	`{"id": 1.5, "name": "a"}`,
+	`{"id": 1}`,
+	`{"id": 1, "name": ""}`,
+	`{"id": 1, "name": "a", "email": "a@b"}`,
+	`{"id": 1, "name": "a", "email": "ab"}`,
+	`{"id": 1, "name": "a", "age": 20.5}`,
+	`{"id": 1, "name": "a", "age": 20.25}`,
+	`{"id": 1, "name": "a", "age": 0}`,
+	`{"id": 1, "name": "a", "age": 151}`,
+	`{"id": 1, "name": "a", "role": "user"}`,
+	`{"id": 1, "name": "a", "role": "root"}`,
+	`{"id": 1, "name": "a", "role": "admin"}`,
+	`{"id": 1, "name": "a", "role": "admin", "email": "a@b"}`,
+	`{"id": 1, "name": "a", "version": 1.0}`,
+	`{"id": 1, "name": "a", "version": 2}`,
+	`{"id": 1, "name": "a", "tags": ["x", "y"]}`,
+	`{"id": 1, "name": "a", "tags": ["x", "x"]}`,
+	`{"id": 1, "name": "a", "tags": ["x", 1]}`,
+	`{"id": 1, "name": "a", "address": {"street": "s", "zip": "12345"}}`,
+	`{"id": 1, "name": "a", "address": {"street": "s", "zip": "1234"}}`,
+	`{"id": 1, "name": "a", "address": {"zip": "12345"}}`,
+	`{"id": 1, "name": "a", "address": {"street": "s", "longPropertyName": 1}}`,
+	`{"id": 1, "name": "a", "friends": [{"id": 2, "name": "b"}]}`,
+	`{"id": 1, "name": "a", "friends": [{"id": 2}]}`,
+	`{"id": 1, "name": "a", "contact": "phone"}`,
+	`{"id": 1, "name": "a", "contact": {"phone": 1}}`,
+	`{"id": 1, "name": "a", "contact": {}}`,
+	`{"id": 1, "name": "a", "misc": null}`,
+	`{"id": 1, "name": "a", "misc": "str"}`,
+	`{"id": 1, "name": "a", "point": [1, 0]}`,
+	`{"id": 1, "name": "a", "point": [1, 2]}`,
+	`{"id": 1, "name": "a", "point": [1, 0, 3]}`,
+	`{"id": 1, "name": "a", "x-note": "n"}`,
+	`{"id": 1, "name": "a", "x-note": 1}`,
+	`{"id": 1, "name": "a", "other": 1}`,
+	`[]`,
+}
+
+func TestGenerated(t *testing.T) {
+	sch := compilePerson(t)
+	for _, instance := range personInstances {
+		v, err := jsonschema.DecodeJSON(strings.NewReader(instance))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, got := sch.Validate(v), example.ValidatePerson(v)
+		if (want == nil) != (got == nil) {
+			t.Errorf("%s: got %v, want %v", instance, got, want)
+		}
+	}
+}
+
+func TestGenerated_Error(t *testing.T) {
+	v, err := jsonschema.DecodeJSON(strings.NewReader(`{"id": 1, "name": "a", "friends": [{"id": 2, "name": "b", "address": {"street": 1}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = example.ValidatePerson(v)
+	ve, ok := err.(*example.ValidationError)
+	if !ok {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	if ve.InstanceLocation != "/friends/0/address/street" || ve.Keyword != "type" {
+		t.Fatalf("got %s", ve)
+	}
+}
+
+func TestGenerator_Unsupported(t *testing.T) {
+	sch, err := jsonschema.CompileString("https://example.com/schema.json", `{"unevaluatedProperties": false}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := validgen.NewGenerator("model")
+	g.Add("Validate", sch)
+	if _, err := g.Source(); err == nil || !strings.Contains(err.Error(), "unevaluatedProperties") {
+		t.Fatalf("got %v, want unsupported error", err)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	sch := compilePerson(b)
+	v, err := jsonschema.DecodeJSON(strings.NewReader(`{
+		"id": 1, "name": "alice", "email": "alice@example.com", "age": 30, "role": "admin",
+		"tags": ["a", "b", "c"], "address": {"street": "main", "zip": "12345"},
+		"friends": [{"id": 2, "name": "bob"}, {"id": 3, "name": "carol", "contact": "phone"}],
+		"point": [1, 0], "x-note": "note"
+	}`))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("interpreted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := sch.Validate(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("generated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := example.ValidatePerson(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package jsonschema
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VendorManifestFile is the name of the manifest file written by
+// Compiler.Vendor into its directory.
+const VendorManifestFile = "manifest.json"
+
+// VendorManifest describes the remote resources vendored by Compiler.Vendor.
+type VendorManifest struct {
+	// Root is the url of schema, whose remote references are vendored.
+	Root string `json:"root"`
+
+	// Resources are the vendored resources, in sorted order of url.
+	Resources []VendoredResource `json:"resources"`
+}
+
+// VendoredResource is a remote resource vendored by Compiler.Vendor.
+type VendoredResource struct {
+	URL    string `json:"url"`
+	Path   string `json:"path"`   // slash separated path of file, relative to vendor directory
+	SHA256 string `json:"sha256"` // hex encoded sha256 of file content
+}
+
+// Vendor compiles the schema at given url, and saves all http and https
+// resources it depends on, directly or indirectly, into directory dir,
+// along with VendorManifestFile. The resources are saved as they are
+// fetched, without rewriting any "$ref", in the layout expected by
+// AddRemoteFS. So the schema can be compiled later without network access:
+//
+//	compiler.AddRemoteFS(os.DirFS(dir))
+//	compiler.Offline = true
+//
+// Resources added to the compiler by user, and meta-schemas of drafts are
+// not vendored. Any compilation error is returned as *SchemaError.
+func (c *Compiler) Vendor(url, dir string) (*VendorManifest, error) {
+	g, err := c.Dependencies(url)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := &VendorManifest{Root: g.Root, Resources: []VendoredResource{}}
+	for _, u := range g.Resources() {
+		if r, ok := c.resources[u]; !ok || !r.loaded {
+			continue
+		}
+		name, ok := vendorPath(u)
+		if !ok {
+			continue
+		}
+		sum, err := c.vendor(u, filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		m.Resources = append(m.Resources, VendoredResource{URL: u, Path: name, SHA256: sum})
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, VendorManifestFile), append(b, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// vendor saves resource at url u into file, and returns sha256 of its content.
+func (c *Compiler) vendor(u, file string) (string, error) {
+	r, err := c.load(context.Background(), u)
+	if err != nil {
+		return "", &loadError{u, err}
+	}
+	defer r.Close()
+	b, err := io.ReadAll(c.limitReader(u, r))
+	if err != nil {
+		return "", &loadError{u, err}
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, b, 0o644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// vendorPath returns path of http or https url u, as used by AddRemoteFS.
+func vendorPath(u string) (string, bool) {
+	pu, err := url.Parse(u)
+	if err != nil || (pu.Scheme != "http" && pu.Scheme != "https") {
+		return "", false
+	}
+	name := strings.TrimSuffix(pu.Host+pu.Path, "/")
+	return name, name != VendorManifestFile && fs.ValidPath(name)
+}
@@ -0,0 +1,89 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompiler_Vendor(t *testing.T) {
+	remotes := map[string]string{
+		"https://example.com/schemas/name.json":   "{\n  \"$ref\": \"common.json#/$defs/str\"\n}",
+		"https://example.com/schemas/common.json": `{"$defs": {"str": {"type": "string"}}}`,
+		"http://example.com/schemas/age.json?v=1": `{"type": "integer"}`,
+	}
+	loadURL := func(s string) (io.ReadCloser, error) {
+		if doc, ok := remotes[s]; ok {
+			return io.NopCloser(strings.NewReader(doc)), nil
+		}
+		return nil, fmt.Errorf("network access to %s", s)
+	}
+	schema := `{
+		"properties": {
+			"name": {"$ref": "https://example.com/schemas/name.json"},
+			"age": {"$ref": "http://example.com/schemas/age.json?v=1"}
+		}
+	}`
+
+	c := jsonschema.NewCompiler()
+	c.LoadURL = loadURL
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	m, err := c.Vendor("http://example.com/schema.json", dir)
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+
+	var paths []string
+	for _, r := range m.Resources {
+		paths = append(paths, r.Path)
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(r.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != remotes[r.URL] {
+			t.Errorf("%s: got %q, want %q", r.URL, b, remotes[r.URL])
+		}
+	}
+	if got, want := strings.Join(paths, " "), "example.com/schemas/age.json example.com/schemas/common.json example.com/schemas/name.json"; got != want {
+		t.Errorf("paths: got %q, want %q", got, want)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, jsonschema.VendorManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest jsonschema.VendorManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Root != "http://example.com/schema.json" || len(manifest.Resources) != 3 {
+		t.Errorf("manifest: got %+v", manifest)
+	}
+
+	// compile offline using vendored resources
+	c = jsonschema.NewCompiler()
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("network access to %s", s)
+	}
+	c.Offline = true
+	c.AddRemoteFS(os.DirFS(dir))
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(schema)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("http://example.com/schema.json")
+	if err != nil {
+		t.Fatalf("%#v", err)
+	}
+	if err := sch.Validate(decodeString(t, `{"name": 1}`)); err == nil {
+		t.Fatal("validation must fail")
+	}
+}
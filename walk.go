@@ -0,0 +1,203 @@
+package jsonschema
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5/jsonpointer"
+)
+
+// Subschemas returns the immediate subschemas of s, keyed by their
+// keyword location relative to s. for example "properties/name", "items/0"
+// or "$ref".
+//
+// Note that subschemas of user defined extensions are not included.
+func (s *Schema) Subschemas() map[string]*Schema {
+	m := make(map[string]*Schema)
+	add := func(path string, sch *Schema) {
+		if sch != nil {
+			m[path] = sch
+		}
+	}
+	addList := func(kw string, list []*Schema) {
+		for i, sch := range list {
+			add(kw+"/"+strconv.Itoa(i), sch)
+		}
+	}
+	addAny := func(kw string, v interface{}) {
+		switch v := v.(type) {
+		case *Schema:
+			add(kw, v)
+		case []*Schema:
+			addList(kw, v)
+		}
+	}
+
+	add("$ref", s.Ref)
+	add("$recursiveRef", s.RecursiveRef)
+	add("$dynamicRef", s.DynamicRef)
+	add("not", s.Not)
+	addList("allOf", s.AllOf)
+	addList("anyOf", s.AnyOf)
+	addList("oneOf", s.OneOf)
+	add("if", s.If)
+	add("then", s.Then)
+	add("else", s.Else)
+
+	for pname, sch := range s.Properties {
+		add("properties/"+escape(pname), sch)
+	}
+	add("propertyNames", s.PropertyNames)
+	for re, sch := range s.PatternProperties {
+		add("patternProperties/"+escape(re.String()), sch)
+	}
+	addAny("additionalProperties", s.AdditionalProperties)
+	for pname, dep := range s.Dependencies {
+		addAny("dependencies/"+escape(pname), dep)
+	}
+	for pname, sch := range s.DependentSchemas {
+		add("dependentSchemas/"+escape(pname), sch)
+	}
+	add("unevaluatedProperties", s.UnevaluatedProperties)
+
+	addAny("items", s.Items)
+	addAny("additionalItems", s.AdditionalItems)
+	addList("prefixItems", s.PrefixItems)
+	add("items", s.Items2020)
+	add("contains", s.Contains)
+	add("unevaluatedItems", s.UnevaluatedItems)
+
+	add("contentSchema", s.ContentSchema)
+	return m
+}
+
+// Walk calls fn for s and all the schemas reachable from s, in depth-first
+// order. Each schema is visited only once, even if it is referenced from
+// multiple places or recursively. Subschemas of a schema are visited in
+// sorted order of their keyword location.
+//
+// If fn returns false, the subschemas of that schema are not visited.
+func (s *Schema) Walk(fn func(sch *Schema) bool) {
+	visited := make(map[*Schema]bool)
+	var walk func(sch *Schema)
+	walk = func(sch *Schema) {
+		if visited[sch] {
+			return
+		}
+		visited[sch] = true
+		if !fn(sch) {
+			return
+		}
+		subschemas := sch.Subschemas()
+		paths := make([]string, 0, len(subschemas))
+		for path := range subschemas {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			walk(subschemas[path])
+		}
+	}
+	walk(s)
+}
+
+// Resolve returns the schema at location loc, or nil if there is no
+// such schema. loc is one of:
+//
+//   - json-pointer relative to s, such as "/properties/name/items". Tokens
+//     are the keyword locations returned by Subschemas, so KeywordLocation
+//     of a ValidationError, which passes through "$ref", can be resolved
+//     against the schema that was validated.
+//   - anchor or fragment within resource of s, such as "#name" or
+//     "#/$defs/address".
+//   - absolute location, with optional anchor, of any schema reachable from
+//     s, such as Schema.Location or AbsoluteKeywordLocation of a
+//     ValidationError without the trailing keyword.
+//
+// If followRefs is true, tokens of json-pointer that are not subschemas of
+// a schema are looked up in the target of its $ref, $recursiveRef or
+// $dynamicRef. For example "/properties/addr/properties/city" resolves even
+// if addr is {"$ref": "address.json"}.
+func (s *Schema) Resolve(loc string, followRefs bool) *Schema {
+	if loc == "" || loc[0] == '/' {
+		return s.resolvePtr(loc, followRefs)
+	}
+	if loc[0] == '#' {
+		loc = s.url() + loc
+	}
+	u, f := split(loc)
+	var found *Schema
+	s.Walk(func(sch *Schema) bool {
+		if found != nil {
+			return false
+		}
+		if sch.url() != u {
+			return true
+		}
+		if strings.HasPrefix(f, "#/") || f == "#" {
+			if sch.Location == u+f {
+				found = sch
+			}
+		} else {
+			for _, anchor := range sch.anchors {
+				if "#"+anchor == f {
+					found = sch
+				}
+			}
+		}
+		return found == nil
+	})
+	return found
+}
+
+func (s *Schema) resolvePtr(ptr string, followRefs bool) *Schema {
+	if ptr == "" {
+		return s
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i := range tokens {
+		tokens[i] = normalizeToken(tokens[i])
+	}
+	sch := s
+	followed := make(map[*Schema]bool) // refs followed for current token
+	for len(tokens) > 0 {
+		subschemas := sch.Subschemas()
+		if sub, ok := subschemas[tokens[0]]; ok {
+			sch, tokens = sub, tokens[1:]
+			followed = make(map[*Schema]bool)
+			continue
+		}
+		if len(tokens) > 1 {
+			if sub, ok := subschemas[tokens[0]+"/"+tokens[1]]; ok {
+				sch, tokens = sub, tokens[2:]
+				followed = make(map[*Schema]bool)
+				continue
+			}
+		}
+		if !followRefs || followed[sch] {
+			return nil
+		}
+		followed[sch] = true
+		switch {
+		case sch.Ref != nil:
+			sch = sch.Ref
+		case sch.RecursiveRef != nil:
+			sch = sch.RecursiveRef
+		case sch.DynamicRef != nil:
+			sch = sch.DynamicRef
+		default:
+			return nil
+		}
+	}
+	return sch
+}
+
+// normalizeToken returns json-pointer token tok, escaped as in Subschemas.
+func normalizeToken(tok string) string {
+	if t, err := url.PathUnescape(tok); err == nil {
+		tok = t
+	}
+	return escape(jsonpointer.Unescape(tok))
+}
@@ -0,0 +1,86 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestSchema_Resolve(t *testing.T) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("http://example.com/address.json", strings.NewReader(`{
+		"properties": {"city": {"type": "string", "minLength": 2}}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddResource("http://example.com/schema.json", strings.NewReader(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"properties": {
+			"addr": {"$ref": "address.json"},
+			"a/b c": {"$ref": "#count"},
+			"tags": {"items": {"type": "string"}},
+			"ids": {"allOf": [{"type": "integer"}]}
+		},
+		"$defs": {
+			"count": {"$anchor": "count", "minimum": 0}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := c.Compile("http://example.com/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		loc        string
+		followRefs bool
+		want       string // location of resolved schema; empty if not found
+	}{
+		{"", false, "schema.json#"},
+		{"/properties/tags/items", false, "schema.json#/properties/tags/items"},
+		{"/properties/ids/allOf/0", false, "schema.json#/properties/ids/allOf/0"},
+		{"/properties/addr/$ref/properties/city", false, "address.json#/properties/city"},
+		{"/properties/addr/properties/city", false, ""},
+		{"/properties/addr/properties/city", true, "address.json#/properties/city"},
+		{"/properties/a~1b c/$ref", false, "schema.json#/$defs/count"},
+		{"/properties/a~1b%20c/$ref", false, "schema.json#/$defs/count"},
+		{"/properties/missing", true, ""},
+		{"#count", false, "schema.json#/$defs/count"},
+		{"#/properties/tags", false, "schema.json#/properties/tags"},
+		{"http://example.com/address.json#/properties/city", false, "address.json#/properties/city"},
+		{"http://example.com/address.json", false, "address.json#"},
+		{"http://example.com/other.json#", false, ""},
+	}
+	for _, test := range tests {
+		got := sch.Resolve(test.loc, test.followRefs)
+		if test.want == "" {
+			if got != nil {
+				t.Errorf("%q: got %s, want nil", test.loc, got.Location)
+			}
+			continue
+		}
+		if got == nil || got.Location != "http://example.com/"+test.want {
+			t.Errorf("%q: got %v, want %s", test.loc, got, test.want)
+		}
+	}
+
+	t.Run("keywordLocation", func(t *testing.T) {
+		err := sch.Validate(map[string]interface{}{
+			"addr": map[string]interface{}{"city": "x"},
+		})
+		var ve *jsonschema.ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("got %v, want *ValidationError", err)
+		}
+		for len(ve.Causes) > 0 {
+			ve = ve.Causes[0]
+		}
+		ptr := strings.TrimSuffix(ve.KeywordLocation, "/minLength")
+		got := sch.Resolve(ptr, false)
+		if got == nil || got.MinLength != 2 {
+			t.Fatalf("%q: got %v, want schema with minLength 2", ptr, got)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+// Package wasm exposes the validator to javascript, when compiled with
+// GOOS=js GOARCH=wasm. It can be used in browsers and in javascript
+// runtimes like Node.js, Deno or Cloudflare Workers.
+//
+// The package has no dependency on os or file system. Remote schemas are
+// loaded using fetch API of the javascript runtime.
+//
+// Typical usage, in main package compiled to wasm:
+//
+//	func main() {
+//		wasm.Register("jsonschema")
+//		select {} // keep the go program running
+//	}
+//
+// and in javascript, after instantiating the wasm module:
+//
+//	const schema = await jsonschema.compile("https://example.com/schema.json", {
+//		"https://example.com/schema.json": '{"type": "string"}',
+//	});
+//	const result = schema.validate('"hello"');
+//	if (!result.valid) {
+//		console.log(result.error, result.errors);
+//	}
+//
+// compile returns Promise, which resolves to object with validate method.
+// Its second argument is optional object, mapping url to schema text, which
+// are added as resources. Other urls are loaded using FetchLoader.
+//
+// validate takes json text of instance, and returns object with fields:
+// valid, and when not valid, error with the error message and errors with
+// the basic output format of the validation error.
+//
+// cmd/jsonschema-wasm is such main package. It can be built with:
+//
+//	GOOS=js GOARCH=wasm go build -o jsonschema.wasm ./cmd/jsonschema-wasm
+package wasm
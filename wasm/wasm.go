@@ -0,0 +1,146 @@
+//go:build js && wasm
+
+package wasm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall/js"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// FetchLoader loads the document at given url, using fetch API of the
+// javascript runtime. It can be used as Compiler.LoadURL or registered
+// in jsonschema.Loaders.
+//
+// It blocks until the document is fetched, so it must not be called from
+// the goroutine running javascript callback.
+func FetchLoader(url string) (io.ReadCloser, error) {
+	resp, err := await(js.Global().Call("fetch", url))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Get("ok").Bool() {
+		return nil, fmt.Errorf("%s returned status code %d", url, resp.Get("status").Int())
+	}
+	text, err := await(resp.Call("text"))
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(strings.NewReader(text.String())), nil
+}
+
+// Register sets javascript global object with given name, whose compile
+// function compiles schema. See package doc for its usage.
+func Register(name string) {
+	obj := js.Global().Get("Object").New()
+	obj.Set("compile", js.FuncOf(compile))
+	js.Global().Set(name, obj)
+}
+
+// compile implements compile(url, resources) in javascript.
+func compile(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return reject(errors.New("jsonschema: compile requires url argument"))
+	}
+	url := args[0].String()
+	resources := make(map[string][]byte)
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			resources[key] = []byte(args[1].Get(key).String())
+		}
+	}
+	return promise(func() (interface{}, error) {
+		c := jsonschema.NewCompiler()
+		c.LoadURL = FetchLoader
+		if err := c.AddResources(resources); err != nil {
+			return nil, err
+		}
+		sch, err := c.Compile(url)
+		if err != nil {
+			return nil, err
+		}
+		obj := js.Global().Get("Object").New()
+		obj.Set("validate", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if len(args) == 0 {
+				return result(errors.New("jsonschema: validate requires instance argument"))
+			}
+			v, err := jsonschema.DecodeJSON(strings.NewReader(args[0].String()))
+			if err != nil {
+				return result(err)
+			}
+			return result(sch.Validate(v))
+		}))
+		return obj, nil
+	})
+}
+
+// result returns javascript object describing the validation result.
+func result(err error) interface{} {
+	obj := js.Global().Get("Object").New()
+	obj.Set("valid", err == nil)
+	if err == nil {
+		return obj
+	}
+	obj.Set("error", err.Error())
+	var ve *jsonschema.ValidationError
+	if errors.As(err, &ve) {
+		if b, err := json.Marshal(ve.BasicOutput()); err == nil {
+			obj.Set("errors", js.Global().Get("JSON").Call("parse", string(b)).Get("errors"))
+		}
+	}
+	return obj
+}
+
+// promise returns javascript Promise, which is settled with the result
+// of f. f is run in new goroutine, so that it can block.
+func promise(f func() (interface{}, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, rejectFn := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			v, err := f()
+			if err != nil {
+				rejectFn.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// reject returns javascript Promise, which is rejected with err.
+func reject(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(err.Error()))
+}
+
+// await blocks until javascript promise p is settled.
+func await(p js.Value) (js.Value, error) {
+	type outcome struct {
+		v   js.Value
+		err error
+	}
+	ch := make(chan outcome, 1)
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- outcome{v: args[0]}
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- outcome{err: errors.New(js.Global().Get("String").Invoke(args[0]).String())}
+		return nil
+	})
+	defer onReject.Release()
+	p.Call("then", onResolve, onReject)
+	o := <-ch
+	return o.v, o.err
+}